@@ -96,9 +96,81 @@ func main() {
 	}
 	table.Render() // Send output
 
+	if clusterName != "" {
+		for i := range rcs.Items {
+			printConditions(&rcs.Items[i])
+			printNodes(&rcs.Items[i])
+		}
+	}
+
 	os.Exit(0)
 }
 
+// healthConditionTypes are the conditions reporting a problem of the redis cluster when they are true
+var healthConditionTypes = []v1.RedisClusterConditionType{
+	v1.RedisClusterDegraded,
+	v1.RedisClusterPartitioned,
+	v1.RedisClusterSlotsMigrating,
+	v1.RedisClusterUnhealthyNodes,
+}
+
+// printConditions prints the conditions of the RedisCluster with their reason and message
+func printConditions(rc *v1.RedisCluster) {
+	if len(rc.Status.Conditions) == 0 {
+		return
+	}
+	fmt.Println()
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Condition", "Status", "Reason", "Message", "Last Transition"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAutoWrapText(false)
+	for _, cond := range rc.Status.Conditions {
+		table.Append([]string{string(cond.Type), string(cond.Status), cond.Reason, cond.Message, cond.LastTransitionTime.Format("2006-01-02 15:04:05")})
+	}
+	table.Render()
+}
+
+// printNodes prints the nodes of the RedisCluster with their placement and runtime details
+func printNodes(rc *v1.RedisCluster) {
+	if len(rc.Status.Cluster.Nodes) == 0 {
+		return
+	}
+	fmt.Println()
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Pod", "Role", "Kube Node", "Zone", "Version", "Link", "Memory", "Keys", "Clients", "Offset", "Lag", "Last Failover"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	for _, node := range rc.Status.Cluster.Nodes {
+		memory, keys, clients, offset, lag := "", "", "", "", ""
+		if node.Metrics != nil {
+			memory = fmt.Sprintf("%d/%d", node.Metrics.UsedMemory, node.Metrics.MaxMemory)
+			keys = fmt.Sprintf("%d", node.Metrics.Keys)
+			clients = fmt.Sprintf("%d", node.Metrics.ConnectedClients)
+			offset = fmt.Sprintf("%d", node.Metrics.ReplicationOffset)
+			lag = fmt.Sprintf("%d", node.Metrics.ReplicationLag)
+		}
+		lastFailover := ""
+		if node.LastFailoverTime != nil {
+			lastFailover = node.LastFailoverTime.Format("2006-01-02 15:04:05")
+		}
+		table.Append([]string{node.PodName, string(node.Role), node.KubeNode, node.Zone, node.RedisVersion, node.LinkState, memory, keys, clients, offset, lag, lastFailover})
+	}
+	table.Render()
+}
+
 func hasStatus(rc *v1.RedisCluster, conditionType v1.RedisClusterConditionType, status kapiv1.ConditionStatus) bool {
 	for _, cond := range rc.Status.Conditions {
 		if cond.Type == conditionType && cond.Status == status {
@@ -117,6 +189,12 @@ func buildClusterStatus(rc *v1.RedisCluster) string {
 		status = append(status, string(v1.RedisClusterOK))
 	}
 
+	for _, conditionType := range healthConditionTypes {
+		if hasStatus(rc, conditionType, kapiv1.ConditionTrue) {
+			status = append(status, string(conditionType))
+		}
+	}
+
 	if hasStatus(rc, v1.RedisClusterRollingUpdate, kapiv1.ConditionTrue) {
 		status = append(status, string(v1.RedisClusterRollingUpdate))
 	} else if hasStatus(rc, v1.RedisClusterScaling, kapiv1.ConditionTrue) {
@@ -7,4 +7,28 @@ const (
 	PodNoLabelKey string = "redis-operator.k8s.io/pod-no"
 	// PodSpecMD5LabelKey label key for the PodSpec MD5 hash
 	PodSpecMD5LabelKey string = "redis-operator.k8s.io/podspec-md5"
+	// ShardLabelKey Label key for the shard of a redis pod, with the PerShard PodDisruptionBudget policy
+	ShardLabelKey string = "redis-operator.k8s.io/shard"
+	// StandbyRoleAnnotationKey annotation key of the pods of a promoted standby cluster, its value is the role of the
+	// redis node in the cluster: master or replica. The redis node restarts in cluster mode once annotated.
+	StandbyRoleAnnotationKey string = "redis-operator.k8s.io/standby-role"
+	// SentinelNameLabelKey Label key for the sentinel pods of a RedisCluster in Sentinel mode
+	SentinelNameLabelKey string = "redis-operator.k8s.io/sentinel-name"
+	// LastAppliedAnnotationKey annotation key of the Services and PodDisruptionBudgets of a RedisCluster recording the
+	// labels, annotations and selector last applied by the operator
+	LastAppliedAnnotationKey string = "redis-operator.k8s.io/last-applied"
+	// ApproveSplitResolutionAnnotationKey annotation key of the RedisCluster approving the resolution of the quarantined
+	// cluster split whose ID is the value
+	ApproveSplitResolutionAnnotationKey string = "redis-operator.k8s.io/approve-split-resolution"
+	// GuardRailOverrideAnnotationPrefix prefix of the annotation keys of the RedisCluster overriding the guard rails
+	GuardRailOverrideAnnotationPrefix string = "redis-operator.k8s.io/allow-"
+	// ApproveActionAnnotationPrefix prefix of the annotation keys of the RedisCluster approving a disruptive action
+	ApproveActionAnnotationPrefix string = "redis-operator.k8s.io/approve-"
+)
+
+const (
+	// StandbyRoleMaster the redis node of a promoted standby cluster keeps its keys and owns the slots of its shard
+	StandbyRoleMaster string = "master"
+	// StandbyRoleReplica the redis node of a promoted standby cluster is emptied and replicates the master of its shard
+	StandbyRoleReplica string = "replica"
 )
@@ -12,12 +12,25 @@ func IsRedisClusterDefaulted(rc *RedisCluster) bool {
 	if rc.Spec.ReplicationFactor == nil {
 		return false
 	}
+	if rc.Spec.Mode == SentinelMode && (rc.Spec.Sentinel == nil || rc.Spec.Sentinel.Replicas == nil) {
+		return false
+	}
 	return true
 }
 
 // DefaultRedisCluster defaults RedisCluster
 func DefaultRedisCluster(undefaultRedisCluster *RedisCluster) *RedisCluster {
 	rc := undefaultRedisCluster.DeepCopy()
+	if rc.Spec.Mode == SentinelMode {
+		// a single master in Sentinel mode, the replicas are defined by the ReplicationFactor
+		rc.Spec.NumberOfMaster = NewInt32(1)
+		if rc.Spec.Sentinel == nil {
+			rc.Spec.Sentinel = &RedisSentinelSpec{}
+		}
+		if rc.Spec.Sentinel.Replicas == nil {
+			rc.Spec.Sentinel.Replicas = NewInt32(3)
+		}
+	}
 	if rc.Spec.NumberOfMaster == nil {
 		rc.Spec.NumberOfMaster = NewInt32(3)
 	}
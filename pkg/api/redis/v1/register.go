@@ -25,6 +25,13 @@ const (
 	ResourceKind = "RedisCluster"
 	// ResourceVersion represent the resource version
 	ResourceVersion = "v1"
+
+	// ImportResourcePlural is the id to indentify pluarals of RedisClusterImport
+	ImportResourcePlural = "redisclusterimports"
+	// ImportResourceSingular represents the id for identify singular RedisClusterImport resource
+	ImportResourceSingular = "redisclusterimport"
+	// ImportResourceKind represent the RedisClusterImport resource kind
+	ImportResourceKind = "RedisClusterImport"
 )
 
 var (
@@ -47,6 +54,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&RedisCluster{},
 		&RedisClusterList{},
+		&RedisClusterImport{},
+		&RedisClusterImportList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
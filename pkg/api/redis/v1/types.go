@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	kapiv1 "k8s.io/api/core/v1"
 )
@@ -55,6 +56,301 @@ type RedisClusterSpec struct {
 
 	// Labels for created redis-cluster (deployment, rs, pod) (if any)
 	AdditionalLabels map[string]string `json:"AdditionalLabels,omitempty"`
+
+	// UpdateStrategy contains the options used to drive the rolling update of the cluster nodes
+	// when the PodTemplate changes
+	UpdateStrategy *RedisClusterUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// QuorumLossRecovery allows the operator to promote the slaves of the lost masters with a CLUSTER FAILOVER TAKEOVER
+	// when the majority of the masters is lost and the cluster can't elect new masters by itself.
+	// Writes accepted by the lost masters and not yet replicated are lost.
+	QuorumLossRecovery bool `json:"quorumLossRecovery,omitempty"`
+
+	// Operations contains the on-demand operations to execute on the cluster. Each operation is executed once,
+	// its result is reported in the status with the same name.
+	Operations []RedisClusterOperation `json:"operations,omitempty"`
+
+	// Mode of the RedisCluster: Cluster (default) runs a sharded redis cluster, Sentinel runs a single master
+	// replicated on ReplicationFactor replicas, the failover being handled by redis sentinels.
+	Mode RedisClusterMode `json:"mode,omitempty"`
+	// Sentinel contains the sentinels options, used only in Sentinel mode
+	Sentinel *RedisSentinelSpec `json:"sentinel,omitempty"`
+
+	// ReplicaOf makes the cluster a standby of a source cluster: the redis nodes run standalone, a standby master
+	// replicates each source master with REPLICAOF and the other nodes replicate their standby master. The standby
+	// cluster is read-only until a promote operation detaches it from its source cluster and restarts its nodes in
+	// cluster mode, each standby master owning the slots of its source master.
+	ReplicaOf *RedisClusterReplicaOf `json:"replicaOf,omitempty"`
+
+	// Adopt selects the pods of an existing redis cluster, not created by the operator, to adopt. The pods are
+	// labeled and owned by the RedisCluster, and the cluster is managed once its topology matches
+	// NumberOfMaster and ReplicationFactor. The existing slots and keys are kept.
+	Adopt *metav1.LabelSelector `json:"adopt,omitempty"`
+
+	// SanityChecks overrides the operator defaults of the sanity checks run before any other action on the cluster:
+	// order, enabling, dry-run only mode and thresholds. The checks not listed keep the operator defaults.
+	SanityChecks []RedisClusterSanityCheck `json:"sanityChecks,omitempty"`
+
+	// SplitResolution configures how the cluster-split sanity check resolves a split: the partition owning the most
+	// slots, then the most keys, is kept and the nodes of the other partitions are flushed and attached to it.
+	SplitResolution *RedisClusterSplitResolution `json:"splitResolution,omitempty"`
+
+	// GuardRails configures the checks done before the destructive redis commands: a node is not forgotten while it
+	// owns slots, a master is not removed until its own view reports no slot, and a node holding more than
+	// MaxFlushKeys keys is not flushed. Each refusal can be overridden with an annotation, see GuardRailRule.
+	GuardRails *RedisClusterGuardRails `json:"guardRails,omitempty"`
+
+	// MaintenanceWindows are the recurring time ranges during which the disruptive actions (rolling update, scale
+	// down, rebalance) run, at any time if empty. An action still in progress when its window closes is paused after
+	// its current step. The sanity checks, the failovers away from unavailable kubernetes nodes and the operations
+	// run outside of the windows.
+	MaintenanceWindows []RedisClusterMaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// RequireApprovalFor lists the disruptive actions (rolling-update, scale-down, rebalance) waiting for an approval
+	// before they start: the action is summarized in the AwaitingApproval condition and in status.approval, and
+	// runs once the RedisCluster is annotated with the ID of the request, see ApproveActionAnnotationKey.
+	RequireApprovalFor []RedisClusterDisruptiveAction `json:"requireApprovalFor,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudgets protecting the redis pods from the voluntary evictions:
+	// one over the whole cluster (default), one per shard, or none.
+	PodDisruptionBudget *RedisClusterPodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+}
+
+// RedisClusterPodDisruptionBudgetPolicy tells which PodDisruptionBudgets protect the redis pods
+type RedisClusterPodDisruptionBudgetPolicy string
+
+const (
+	// PodDisruptionBudgetPolicyCluster one PodDisruptionBudget selects all the redis pods of the cluster
+	PodDisruptionBudgetPolicyCluster RedisClusterPodDisruptionBudgetPolicy = "Cluster"
+	// PodDisruptionBudgetPolicyPerShard one PodDisruptionBudget per shard selects a master and its slaves, the pods are
+	// labeled with the shard they belong to. Used only in Cluster mode, Sentinel mode having a single shard.
+	PodDisruptionBudgetPolicyPerShard RedisClusterPodDisruptionBudgetPolicy = "PerShard"
+	// PodDisruptionBudgetPolicyNone no PodDisruptionBudget protects the redis pods
+	PodDisruptionBudgetPolicyNone RedisClusterPodDisruptionBudgetPolicy = "None"
+)
+
+// RedisClusterPodDisruptionBudget contains the configuration of the PodDisruptionBudgets of the redis pods
+type RedisClusterPodDisruptionBudget struct {
+	// Policy is Cluster, PerShard or None, defaulted to Cluster
+	Policy RedisClusterPodDisruptionBudgetPolicy `json:"policy,omitempty"`
+	// MaxUnavailable is the number of pods of each PodDisruptionBudget that can be evicted at the same time.
+	// Defaulted to 1, ignored if MinAvailable is set.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// MinAvailable is the number of pods of each PodDisruptionBudget that must stay available
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+}
+
+// RedisClusterMaintenanceWindow is a recurring time range during which the disruptive actions can run
+type RedisClusterMaintenanceWindow struct {
+	// Schedule is the start of the window as a cron expression: minute hour day-of-month month day-of-week,
+	// e.g. "0 2 * * 1-5" opens the window at 2:00 from monday to friday
+	Schedule string `json:"schedule"`
+	// DurationMinutes is the duration of the window
+	DurationMinutes int32 `json:"durationMinutes"`
+	// TimeZone is the IANA time zone of the schedule, e.g. "Europe/Paris", UTC if empty
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// RedisClusterDisruptiveAction is an action of the operator moving slots or restarting redis nodes
+type RedisClusterDisruptiveAction string
+
+const (
+	// DisruptiveActionRollingUpdate replaces the redis nodes after a change of the pod template
+	DisruptiveActionRollingUpdate RedisClusterDisruptiveAction = "rolling-update"
+	// DisruptiveActionScaleDown moves the slots away from the masters removed and deletes their pods
+	DisruptiveActionScaleDown RedisClusterDisruptiveAction = "scale-down"
+	// DisruptiveActionRebalance moves the slots to the new masters
+	DisruptiveActionRebalance RedisClusterDisruptiveAction = "rebalance"
+)
+
+// ApproveActionAnnotationKey returns the annotation key of the RedisCluster approving the disruptive action, its value
+// is the ID of the approval request reported in status.approval
+func ApproveActionAnnotationKey(action RedisClusterDisruptiveAction) string {
+	return ApproveActionAnnotationPrefix + string(action)
+}
+
+// RedisClusterGuardRails contains the configuration of the guard rails
+type RedisClusterGuardRails struct {
+	// MaxFlushKeys is the number of keys above which a node is not flushed, no limit if not set
+	MaxFlushKeys *int64 `json:"maxFlushKeys,omitempty"`
+}
+
+// RedisClusterGuardRailRule is the name of a guard rail
+type RedisClusterGuardRailRule string
+
+const (
+	// GuardRailFlushKeys refuses to flush a node holding more than spec.guardRails.maxFlushKeys keys
+	GuardRailFlushKeys RedisClusterGuardRailRule = "flush-keys"
+	// GuardRailForgetNodeWithSlots refuses to forget a node that still owns slots
+	GuardRailForgetNodeWithSlots RedisClusterGuardRailRule = "forget-node-with-slots"
+	// GuardRailMasterRemoval refuses to remove a master whose own view doesn't confirm that it owns no slot
+	GuardRailMasterRemoval RedisClusterGuardRailRule = "master-removal"
+)
+
+// GuardRailOverrideAnnotationKey returns the annotation key of the RedisCluster overriding the guard rail, its value
+// is a comma separated list of the targets allowed (node address for flush-keys, node ID otherwise) or "*"
+func GuardRailOverrideAnnotationKey(rule RedisClusterGuardRailRule) string {
+	return GuardRailOverrideAnnotationPrefix + string(rule)
+}
+
+// RedisClusterSplitResolutionPolicy tells when the losing partitions of a cluster split are flushed
+type RedisClusterSplitResolutionPolicy string
+
+const (
+	// SplitResolutionFlush the losing partitions are flushed as soon as the split is detected
+	SplitResolutionFlush RedisClusterSplitResolutionPolicy = "Flush"
+	// SplitResolutionQuarantine the losing partitions are left untouched until the resolution is approved with the
+	// approve-split-resolution annotation
+	SplitResolutionQuarantine RedisClusterSplitResolutionPolicy = "Quarantine"
+)
+
+// RedisClusterSplitResolution contains the configuration of the resolution of a cluster split
+type RedisClusterSplitResolution struct {
+	// Policy is Flush or Quarantine, defaulted to Flush
+	Policy RedisClusterSplitResolutionPolicy `json:"policy,omitempty"`
+	// Backup the masters of the losing partitions dump their keys with BGSAVE in their data directory before being
+	// flushed, in a file named split-<split id>-<timestamp>.rdb. The nodes are not flushed if a backup fails.
+	Backup bool `json:"backup,omitempty"`
+	// BackupTimeoutSeconds is the time given to each backup to complete. Defaulted to 600.
+	BackupTimeoutSeconds *int32 `json:"backupTimeoutSeconds,omitempty"`
+}
+
+// RedisClusterSanityCheckName is the name of a sanity check
+type RedisClusterSanityCheckName string
+
+const (
+	// SanityCheckLostQuorum promotes the slaves of the lost masters when the quorum of masters is lost
+	SanityCheckLostQuorum RedisClusterSanityCheckName = "lost-quorum"
+	// SanityCheckFailedNodes forgets the failed nodes that are not hosted by a pod anymore
+	SanityCheckFailedNodes RedisClusterSanityCheckName = "failed-nodes"
+	// SanityCheckUntrustedNodes forgets the nodes trying to rejoin the cluster after being forgotten
+	SanityCheckUntrustedNodes RedisClusterSanityCheckName = "untrusted-nodes"
+	// SanityCheckTerminatingPods deletes again the pods blocked in terminating status
+	SanityCheckTerminatingPods RedisClusterSanityCheckName = "terminating-pods"
+	// SanityCheckClusterSplit merges the partitions of a split cluster
+	SanityCheckClusterSplit RedisClusterSanityCheckName = "cluster-split"
+	// SanityCheckGhostMasters forgets the masters without slot whose pod doesn't exist anymore
+	SanityCheckGhostMasters RedisClusterSanityCheckName = "ghost-masters"
+	// SanityCheckNodesNotMeet makes the nodes that don't know each other meet
+	SanityCheckNodesNotMeet RedisClusterSanityCheckName = "nodes-not-meet"
+)
+
+// RedisClusterSanityCheck contains the configuration of a sanity check, the fields not set keep the operator defaults
+type RedisClusterSanityCheck struct {
+	Name RedisClusterSanityCheckName `json:"name"`
+	// Enabled the check is run
+	Enabled *bool `json:"enabled,omitempty"`
+	// Order the checks run by ascending order, the operator default order of a check is its position in the
+	// --sanity-checks-order list
+	Order *int32 `json:"order,omitempty"`
+	// DryRunOnly the problems found by the check are reported in the status and the events, but not fixed
+	DryRunOnly *bool `json:"dryRunOnly,omitempty"`
+	// ThresholdSeconds is the threshold of the checks waiting before fixing a problem: the time after which a pod
+	// blocked in terminating status is deleted again for terminating-pods
+	ThresholdSeconds *int32 `json:"thresholdSeconds,omitempty"`
+}
+
+// RedisClusterReplicaOf contains the source cluster of a standby cluster
+type RedisClusterReplicaOf struct {
+	// Addrs contains the addresses (host:port) of nodes of the source cluster, used to discover its masters. The
+	// source masters must be reachable from the standby pods.
+	Addrs []string `json:"addrs"`
+}
+
+// RedisClusterMode is the topology of the redis nodes managed by a RedisCluster
+type RedisClusterMode string
+
+const (
+	// ClusterMode sharded redis cluster, NumberOfMaster masters with ReplicationFactor slaves each
+	ClusterMode RedisClusterMode = "Cluster"
+	// SentinelMode one master and ReplicationFactor replicas monitored by redis sentinels
+	SentinelMode RedisClusterMode = "Sentinel"
+)
+
+// RedisSentinelSpec contains the sentinels options
+type RedisSentinelSpec struct {
+	// Replicas is the number of sentinels. Defaulted to 3.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Quorum is the number of sentinels that need to agree about the master failure. Defaulted to replicas/2+1.
+	Quorum *int32 `json:"quorum,omitempty"`
+	// Image of the sentinel container, it has to provide redis-server. Defaulted to the image of the
+	// first container of the PodTemplate.
+	Image string `json:"image,omitempty"`
+}
+
+// RedisClusterUpdateStrategy contains the rolling update options
+type RedisClusterUpdateStrategy struct {
+	// Type of update strategy, RollingUpdate or InPlaceFailover. Defaulted to RollingUpdate.
+	Type RedisClusterUpdateStrategyType `json:"type,omitempty"`
+	// MaxSurgeShards is the number of shards (a master and its slaves) that can be replaced at the same time.
+	// Defaulted to 1.
+	MaxSurgeShards *int32 `json:"maxSurgeShards,omitempty"`
+	// Partition limits the rolling update to the first N shards, the others shards keep the previous PodTemplate.
+	// If not set, all the shards are updated.
+	Partition *int32 `json:"partition,omitempty"`
+	// Paused stops the rolling update at the current step.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RedisClusterUpdateStrategyType defines how the cluster nodes are replaced when the PodTemplate changes
+type RedisClusterUpdateStrategyType string
+
+const (
+	// RollingUpdateStrategyType creates new masters and migrates the slots and the keys from the old masters
+	RollingUpdateStrategyType RedisClusterUpdateStrategyType = "RollingUpdate"
+	// InPlaceFailoverStrategyType replaces the slaves first, waits for the replication to be in sync,
+	// then promotes a new slave with a CLUSTER FAILOVER before removing the old master. No slot is migrated.
+	InPlaceFailoverStrategyType RedisClusterUpdateStrategyType = "InPlaceFailover"
+)
+
+// RedisClusterOperationType is the type of an on-demand operation
+type RedisClusterOperationType string
+
+const (
+	// OperationFailover failover of a master, identified by its node ID or its pod name, to one of its slaves
+	OperationFailover RedisClusterOperationType = "failover"
+	// OperationRebalance dispatches evenly the slots between the current masters
+	OperationRebalance RedisClusterOperationType = "rebalance"
+	// OperationForgetNode makes all the cluster nodes forget a node, identified by its node ID or its pod name
+	OperationForgetNode RedisClusterOperationType = "forget-node"
+	// OperationResetNode flushes and resets the cluster configuration of a node without slot
+	OperationResetNode RedisClusterOperationType = "reset-node"
+	// OperationPromote detaches a standby cluster from its source cluster and makes it writable
+	OperationPromote RedisClusterOperationType = "promote"
+)
+
+// RedisClusterOperation represents an on-demand operation on the cluster
+type RedisClusterOperation struct {
+	// Name identifies the operation, it has to be unique in the RedisCluster
+	Name string `json:"name"`
+	// Type of the operation: failover, rebalance, forget-node, reset-node or promote
+	Type RedisClusterOperationType `json:"type"`
+	// NodeID is the redis node ID targeted by the operation (failover, forget-node, reset-node)
+	NodeID string `json:"nodeID,omitempty"`
+	// PodName is the pod name targeted by the operation, used if NodeID is empty
+	PodName string `json:"podName,omitempty"`
+}
+
+// RedisClusterOperationPhase is the phase of an on-demand operation
+type RedisClusterOperationPhase string
+
+const (
+	// OperationPhaseRunning the operation is being executed, it is not executed again if its result isn't saved
+	OperationPhaseRunning RedisClusterOperationPhase = "Running"
+	// OperationPhaseSucceeded the operation has been executed successfully
+	OperationPhaseSucceeded RedisClusterOperationPhase = "Succeeded"
+	// OperationPhaseFailed the operation execution failed
+	OperationPhaseFailed RedisClusterOperationPhase = "Failed"
+)
+
+// RedisClusterOperationStatus represents the result of an on-demand operation
+type RedisClusterOperationStatus struct {
+	Name    string                     `json:"name"`
+	Type    RedisClusterOperationType  `json:"type"`
+	Phase   RedisClusterOperationPhase `json:"phase"`
+	Message string                     `json:"message,omitempty"`
+	// CompletionTime is the time the operation has been executed
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
 }
 
 // RedisClusterStatus contains RedisCluster status
@@ -74,6 +370,207 @@ type RedisClusterStatus struct {
 	Message string `json:"message,omitempty"`
 	// Cluster a view of the current RedisCluster
 	Cluster RedisClusterClusterStatus
+	// RollingUpdate represents the progression of the current rolling update, if any
+	RollingUpdate *RedisClusterRollingUpdateStatus `json:"rollingUpdate,omitempty"`
+	// Operations contains the results of the on-demand operations
+	Operations []RedisClusterOperationStatus `json:"operations,omitempty"`
+	// Replication a view of the master and its replicas in Sentinel mode
+	Replication *RedisReplicationStatus `json:"replication,omitempty"`
+	// ReplicaOf represents the replication of a standby cluster from its source cluster
+	ReplicaOf *RedisClusterReplicaOfStatus `json:"replicaOf,omitempty"`
+	// Adoption represents the adoption of the pods selected by spec.adopt
+	Adoption *RedisClusterAdoptionStatus `json:"adoption,omitempty"`
+	// SanityChecks contains the result of the last run of each sanity check
+	SanityChecks []RedisClusterSanityCheckStatus `json:"sanityChecks,omitempty"`
+	// SplitQuarantine represents the cluster split waiting for an approval before its losing partitions are flushed
+	SplitQuarantine *RedisClusterSplitQuarantineStatus `json:"splitQuarantine,omitempty"`
+	// GuardRailRefusals contains the destructive commands refused by the guard rails, until they are allowed or their
+	// target leaves the cluster
+	GuardRailRefusals []RedisClusterGuardRailRefusal `json:"guardRailRefusals,omitempty"`
+	// Maintenance represents the disruptive action waiting for the next maintenance window, if any
+	Maintenance *RedisClusterMaintenanceStatus `json:"maintenance,omitempty"`
+	// Approval represents the approval request of the disruptive action in progress, if any
+	Approval *RedisClusterApprovalStatus `json:"approval,omitempty"`
+}
+
+// RedisClusterApprovalStatus represents the approval request of a disruptive action
+type RedisClusterApprovalStatus struct {
+	// Action is the disruptive action to approve
+	Action RedisClusterDisruptiveAction `json:"action"`
+	// ID identifies the request, the value to set in the approval annotation of the action
+	ID string `json:"id"`
+	// Target is the state reached by the action: the pod template hash of a rolling update, the number of masters
+	// and the replication factor of a scale down, the masters of a rebalance. A new target needs a new approval.
+	Target string `json:"target,omitempty"`
+	// Summary describes what the action will do
+	Summary string `json:"summary,omitempty"`
+	// Approved is true once the request has been approved, until the end of the action
+	Approved bool `json:"approved,omitempty"`
+	// RequestTime is the time the approval has been requested
+	RequestTime metav1.Time `json:"requestTime,omitempty"`
+}
+
+// RedisClusterMaintenanceStatus represents a disruptive action waiting for a maintenance window
+type RedisClusterMaintenanceStatus struct {
+	// PendingAction is the disruptive action waiting for the next window
+	PendingAction RedisClusterDisruptiveAction `json:"pendingAction"`
+	// NextWindow is the start of the next maintenance window, not set if none can be computed
+	NextWindow *metav1.Time `json:"nextWindow,omitempty"`
+	// Message explains why the action waits, e.g. an invalid window
+	Message string `json:"message,omitempty"`
+}
+
+// RedisClusterGuardRailRefusal represents a destructive command refused by a guard rail
+type RedisClusterGuardRailRefusal struct {
+	Rule RedisClusterGuardRailRule `json:"rule"`
+	// Target is the address of the node for flush-keys, its ID otherwise
+	Target  string `json:"target"`
+	Message string `json:"message,omitempty"`
+	// LastRefusalTime is the last time the command has been refused
+	LastRefusalTime metav1.Time `json:"lastRefusalTime,omitempty"`
+}
+
+// RedisClusterSplitQuarantineStatus represents a cluster split whose resolution waits for an approval
+type RedisClusterSplitQuarantineStatus struct {
+	// ID identifies the split, the value to set in the approve-split-resolution annotation to approve its resolution
+	ID string `json:"id"`
+	// MainPartition contains the addresses of the nodes of the partition kept
+	MainPartition []string `json:"mainPartition,omitempty"`
+	// QuarantinedNodes contains the addresses of the nodes of the partitions that will be flushed once approved
+	QuarantinedNodes []string `json:"quarantinedNodes,omitempty"`
+	// StartTime is the time the split has been quarantined
+	StartTime metav1.Time `json:"startTime,omitempty"`
+}
+
+// RedisClusterSanityCheckResult is the result of the last run of a sanity check
+type RedisClusterSanityCheckResult string
+
+const (
+	// SanityCheckPassed no problem found
+	SanityCheckPassed RedisClusterSanityCheckResult = "Passed"
+	// SanityCheckProblemFound a problem has been found and will be fixed, or is only reported in dry-run only mode
+	SanityCheckProblemFound RedisClusterSanityCheckResult = "ProblemFound"
+	// SanityCheckFixed the problem found has been fixed
+	SanityCheckFixed RedisClusterSanityCheckResult = "Fixed"
+	// SanityCheckFailed the check or the fix of the problem failed
+	SanityCheckFailed RedisClusterSanityCheckResult = "Failed"
+	// SanityCheckDisabled the check is not run
+	SanityCheckDisabled RedisClusterSanityCheckResult = "Disabled"
+	// SanityCheckAwaitingApproval the problem found will be fixed once approved
+	SanityCheckAwaitingApproval RedisClusterSanityCheckResult = "AwaitingApproval"
+)
+
+// RedisClusterSanityCheckStatus represents the result of the last run of a sanity check
+type RedisClusterSanityCheckStatus struct {
+	Name   RedisClusterSanityCheckName   `json:"name"`
+	Result RedisClusterSanityCheckResult `json:"result"`
+	// DryRunOnly true if the problems found are not fixed
+	DryRunOnly bool   `json:"dryRunOnly,omitempty"`
+	Message    string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the result changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// RedisClusterAdoptionStatus represents the adoption of an existing redis cluster
+type RedisClusterAdoptionStatus struct {
+	// Adopted true once the adopted cluster matches the spec and is managed by the operator
+	Adopted bool `json:"adopted,omitempty"`
+	// AdoptionTime is the time the cluster has been adopted
+	AdoptionTime *metav1.Time `json:"adoptionTime,omitempty"`
+	// AdoptedPods contains the name of the adopted pods
+	AdoptedPods []string `json:"adoptedPods,omitempty"`
+	// Reason explains why the adoption is not complete
+	Reason string `json:"reason,omitempty"`
+}
+
+// RedisClusterStandbyPhase is the phase of a standby cluster
+type RedisClusterStandbyPhase string
+
+const (
+	// StandbyPhaseReplicating the standby masters replicate the source masters
+	StandbyPhaseReplicating RedisClusterStandbyPhase = "Replicating"
+	// StandbyPhasePromoting the standby masters have been detached from the source masters, the redis nodes are
+	// restarted in cluster mode and the cluster is formed
+	StandbyPhasePromoting RedisClusterStandbyPhase = "Promoting"
+	// StandbyPhasePromoted the standby cluster is a writable redis cluster
+	StandbyPhasePromoted RedisClusterStandbyPhase = "Promoted"
+)
+
+// IsStandbyRedisCluster returns true if the RedisCluster is a standby cluster not promoted yet: its redis nodes run
+// standalone
+func IsStandbyRedisCluster(rc *RedisCluster) bool {
+	return rc.Spec.ReplicaOf != nil && (rc.Status.ReplicaOf == nil || rc.Status.ReplicaOf.Phase != StandbyPhasePromoted)
+}
+
+// RedisClusterReplicaOfStatus represents the replication of a standby cluster from its source cluster
+type RedisClusterReplicaOfStatus struct {
+	// Phase of the standby cluster: Replicating, Promoting or Promoted
+	Phase RedisClusterStandbyPhase `json:"phase,omitempty"`
+	// PromotionTime is the time the standby cluster has been detached from its source cluster
+	PromotionTime *metav1.Time `json:"promotionTime,omitempty"`
+	// Shards contains the replication state of each shard, the shards are not updated anymore once promoted
+	Shards []RedisClusterStandbyShardStatus `json:"shards,omitempty"`
+}
+
+// RedisClusterStandbyShardStatus represents the replication of a source master by a shard of the standby cluster
+type RedisClusterStandbyShardStatus struct {
+	// SourceMaster is the address of the source master
+	SourceMaster string `json:"sourceMaster"`
+	// Slots are the slots of the source master, owned by the standby master once promoted
+	Slots []string `json:"slots,omitempty"`
+	// MasterPodName is the name of the pod of the standby master replicating the source master
+	MasterPodName string `json:"masterPodName,omitempty"`
+	// ReplicaPodNames are the names of the pods replicating the standby master
+	ReplicaPodNames []string `json:"replicaPodNames,omitempty"`
+	// MasterLinkStatus is the state of the replication link of the standby master: up or down
+	MasterLinkStatus string `json:"masterLinkStatus,omitempty"`
+	// Lag is the replication lag of the standby master in bytes
+	Lag int64 `json:"lag"`
+}
+
+// RedisReplicationStatus represents the replication topology in Sentinel mode
+type RedisReplicationStatus struct {
+	// MasterPodName is the name of the pod running the current master
+	MasterPodName string `json:"masterPodName,omitempty"`
+	// MasterAddr is the address of the current master
+	MasterAddr string `json:"masterAddr,omitempty"`
+	// Replicas contains the replication state of each replica
+	Replicas []RedisReplicaStatus `json:"replicas,omitempty"`
+	// NbSentinels is the number of sentinel pods
+	NbSentinels int32 `json:"nbSentinels"`
+	// NbSentinelsReady is the number of sentinels monitoring the current master
+	NbSentinelsReady int32 `json:"nbSentinelsReady"`
+}
+
+// RedisReplicaStatus represents the replication state of a replica
+type RedisReplicaStatus struct {
+	PodName          string `json:"podName"`
+	Addr             string `json:"addr"`
+	MasterLinkStatus string `json:"masterLinkStatus,omitempty"`
+	// Lag is the replication lag in bytes with the master
+	Lag int64 `json:"lag"`
+}
+
+// RedisClusterRollingUpdateStatus represents the progression of a rolling update
+type RedisClusterRollingUpdateStatus struct {
+	// PodSpecMD5 is the hash of the PodTemplate targeted by the rolling update
+	PodSpecMD5 string `json:"podSpecMD5"`
+	// NbShards is the number of shards in the cluster
+	NbShards int32 `json:"nbShards"`
+	// NbUpdatedShards is the number of shards already running the targeted PodTemplate
+	NbUpdatedShards int32 `json:"nbUpdatedShards"`
+	// Paused true if the rolling update is currently paused
+	Paused bool `json:"paused,omitempty"`
+	// Shards contains the rolling update state of each shard
+	Shards []RedisClusterShardStatus `json:"shards,omitempty"`
+}
+
+// RedisClusterShardStatus represents the rolling update state of a shard
+type RedisClusterShardStatus struct {
+	MasterID string   `json:"masterID"`
+	PodName  string   `json:"podName,omitempty"`
+	Slots    []string `json:"slots,omitempty"`
+	Updated  bool     `json:"updated"`
 }
 
 // RedisClusterCondition represent the condition of the RedisCluster
@@ -107,6 +604,8 @@ type RedisClusterClusterStatus struct {
 	NbRedisRunning int32 `json:"nbRedisNodesRunning,omitempty"`
 
 	Nodes []RedisClusterNode `json:"nodes"`
+	// MetricsTime is the time the metrics of the nodes have been retrieved
+	MetricsTime *metav1.Time `json:"metricsTime,omitempty"`
 }
 
 func (s RedisClusterClusterStatus) String() string {
@@ -147,6 +646,35 @@ type RedisClusterNode struct {
 	MasterRef string               `json:"masterRef,omitempty"`
 	PodName   string               `json:"podName"`
 	Pod       *kapiv1.Pod          `json:"-"`
+
+	// KubeNode is the kubernetes node hosting the pod
+	KubeNode string `json:"kubeNode,omitempty"`
+	// Zone is the zone of the kubernetes node hosting the pod
+	Zone string `json:"zone,omitempty"`
+	// RedisVersion is the version of the redis server
+	RedisVersion string `json:"redisVersion,omitempty"`
+	// LinkState is the state of the cluster bus link with the node, as seen by the other nodes
+	LinkState string `json:"linkState,omitempty"`
+	// LastFailoverTime is the last time the node has been promoted master in place of its master
+	LastFailoverTime *metav1.Time `json:"lastFailoverTime,omitempty"`
+	// Metrics are the runtime metrics of the redis server, refreshed every RedisClusterClusterStatus.MetricsTime
+	Metrics *RedisClusterNodeMetrics `json:"metrics,omitempty"`
+}
+
+// RedisClusterNodeMetrics represent the runtime metrics of a RedisCluster Node, retrieved with the INFO command
+type RedisClusterNodeMetrics struct {
+	// UsedMemory is the number of bytes allocated by redis
+	UsedMemory int64 `json:"usedMemory"`
+	// MaxMemory is the maxmemory configuration of the node, 0 when unlimited
+	MaxMemory int64 `json:"maxMemory"`
+	// Keys is the number of keys stored on the node
+	Keys int64 `json:"keys"`
+	// ConnectedClients is the number of client connections
+	ConnectedClients int64 `json:"connectedClients"`
+	// ReplicationOffset is the replication offset of the node
+	ReplicationOffset int64 `json:"replicationOffset"`
+	// ReplicationLag is the number of bytes a slave is behind its master
+	ReplicationLag int64 `json:"replicationLag,omitempty"`
 }
 
 func (n RedisClusterNode) String() string {
@@ -168,6 +696,20 @@ const (
 	RedisClusterRebalancing RedisClusterConditionType = "Rebalancing"
 	// RedisClusterRollingUpdate means the RedisCluster is currenlty performing a rolling update of its nodes
 	RedisClusterRollingUpdate RedisClusterConditionType = "RollingUpdate"
+	// RedisClusterDegraded means some slots are not served, or some shards have less slaves than the replication factor
+	RedisClusterDegraded RedisClusterConditionType = "Degraded"
+	// RedisClusterPartitioned means the redis nodes don't agree on the slots owners, or are split in several clusters
+	RedisClusterPartitioned RedisClusterConditionType = "Partitioned"
+	// RedisClusterSlotsMigrating means some slots are in migrating or importing state
+	RedisClusterSlotsMigrating RedisClusterConditionType = "SlotsMigrating"
+	// RedisClusterUnhealthyNodes means some redis nodes are flagged PFAIL or FAIL, or can't be reached by the operator
+	RedisClusterUnhealthyNodes RedisClusterConditionType = "UnhealthyNodes"
+	// RedisClusterSplitQuarantined means the losing partitions of a cluster split wait for an approval before being flushed
+	RedisClusterSplitQuarantined RedisClusterConditionType = "SplitQuarantined"
+	// RedisClusterGuardRailBlocked means some destructive commands have been refused by the guard rails
+	RedisClusterGuardRailBlocked RedisClusterConditionType = "GuardRailBlocked"
+	// RedisClusterAwaitingApproval means a disruptive action waits for an approval, see RequireApprovalFor
+	RedisClusterAwaitingApproval RedisClusterConditionType = "AwaitingApproval"
 )
 
 // RedisClusterNodeRole RedisCluster Node Role type
@@ -208,4 +750,105 @@ const (
 	ServiceTypeInternal ServiceType = "Internal"
 	// ServiceTypeExternal means create a list of nodePort services for each pod and a headless service
 	ServiceTypeExternal ServiceType = "External"
-)
\ No newline at end of file
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RedisClusterImport represents an online import of the keys of an external redis into a RedisCluster
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RedisClusterImport struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec represents the desired RedisClusterImport specification
+	Spec RedisClusterImportSpec `json:"spec,omitempty"`
+
+	// Status represents the current RedisClusterImport status
+	Status RedisClusterImportStatus `json:"status,omitempty"`
+}
+
+// RedisClusterImportList implements list of RedisClusterImport.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RedisClusterImportList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of RedisClusterImport
+	Items []RedisClusterImport `json:"items"`
+}
+
+// RedisClusterImportSpec contains RedisClusterImport specification
+type RedisClusterImportSpec struct {
+	// ClusterName is the name of the RedisCluster receiving the keys, in the same namespace
+	ClusterName string `json:"clusterName"`
+	// Source is the redis the keys are imported from
+	Source RedisClusterImportSource `json:"source"`
+	// BatchSize is the number of keys copied at once. Defaulted to 100.
+	BatchSize *int32 `json:"batchSize,omitempty"`
+	// Tail if true, the keys modified in the source are copied after the initial copy until the cutover
+	Tail bool `json:"tail,omitempty"`
+	// Cutover if true, the import completes once the keys modified in the source are copied.
+	// It should be set once the writes to the source are stopped.
+	Cutover bool `json:"cutover,omitempty"`
+}
+
+// RedisClusterImportSource contains the addresses of the source redis
+type RedisClusterImportSource struct {
+	// Addrs are the addresses (ip:port) of the source redis nodes
+	Addrs []string `json:"addrs"`
+	// Cluster true if the source is a redis cluster, the keys of all its masters are imported.
+	// Otherwise only the database 0 of the first address is imported.
+	Cluster bool `json:"cluster,omitempty"`
+}
+
+// RedisClusterImportPhase is the phase of a RedisClusterImport
+type RedisClusterImportPhase string
+
+const (
+	// ImportPhasePending the import waits for the RedisCluster to be ready
+	ImportPhasePending RedisClusterImportPhase = "Pending"
+	// ImportPhaseCopying the keys of the source are copied
+	ImportPhaseCopying RedisClusterImportPhase = "Copying"
+	// ImportPhaseTailing the keys modified in the source are copied
+	ImportPhaseTailing RedisClusterImportPhase = "Tailing"
+	// ImportPhaseCompleted the import is completed
+	ImportPhaseCompleted RedisClusterImportPhase = "Completed"
+	// ImportPhaseFailed the import has failed
+	ImportPhaseFailed RedisClusterImportPhase = "Failed"
+)
+
+// RedisClusterImportStatus contains RedisClusterImport status
+type RedisClusterImportStatus struct {
+	Phase RedisClusterImportPhase `json:"phase,omitempty"`
+	// Reason explains the current phase
+	Reason string `json:"reason,omitempty"`
+	// StartTime is the start time of the copy
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the import has been completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Sources contains the copy progress of each source node
+	Sources []RedisClusterImportSourceStatus `json:"sources,omitempty"`
+	// KeysImported is the number of keys written in the RedisCluster, including the modified keys copied again
+	KeysImported int64 `json:"keysImported"`
+	// KeysDeleted is the number of keys deleted in the RedisCluster because they were deleted in the source
+	KeysDeleted int64 `json:"keysDeleted"`
+	// LastTailTime is the last time modified keys have been copied
+	LastTailTime *metav1.Time `json:"lastTailTime,omitempty"`
+}
+
+// RedisClusterImportSourceStatus represents the copy progress of a source node
+type RedisClusterImportSourceStatus struct {
+	// Addr is the address of the source node
+	Addr string `json:"addr"`
+	// Cursor is the SCAN cursor of the copy
+	Cursor string `json:"cursor,omitempty"`
+	// KeysScanned is the number of keys scanned on the source node
+	KeysScanned int64 `json:"keysScanned"`
+	// Done true once all the keys of the source node have been scanned
+	Done bool `json:"done,omitempty"`
+}
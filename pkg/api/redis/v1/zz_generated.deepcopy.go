@@ -33,6 +33,7 @@ import (
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 	reflect "reflect"
 )
 
@@ -50,6 +51,14 @@ func RegisterDeepCopies(scheme *runtime.Scheme) error {
 			in.(*RedisCluster).DeepCopyInto(out.(*RedisCluster))
 			return nil
 		}, InType: reflect.TypeOf(&RedisCluster{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterAdoptionStatus).DeepCopyInto(out.(*RedisClusterAdoptionStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterAdoptionStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterApprovalStatus).DeepCopyInto(out.(*RedisClusterApprovalStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterApprovalStatus{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*RedisClusterClusterStatus).DeepCopyInto(out.(*RedisClusterClusterStatus))
 			return nil
@@ -58,22 +67,130 @@ func RegisterDeepCopies(scheme *runtime.Scheme) error {
 			in.(*RedisClusterCondition).DeepCopyInto(out.(*RedisClusterCondition))
 			return nil
 		}, InType: reflect.TypeOf(&RedisClusterCondition{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterGuardRailRefusal).DeepCopyInto(out.(*RedisClusterGuardRailRefusal))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterGuardRailRefusal{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterGuardRails).DeepCopyInto(out.(*RedisClusterGuardRails))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterGuardRails{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterImport).DeepCopyInto(out.(*RedisClusterImport))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterImport{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterImportList).DeepCopyInto(out.(*RedisClusterImportList))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterImportList{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterImportSource).DeepCopyInto(out.(*RedisClusterImportSource))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterImportSource{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterImportSourceStatus).DeepCopyInto(out.(*RedisClusterImportSourceStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterImportSourceStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterImportSpec).DeepCopyInto(out.(*RedisClusterImportSpec))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterImportSpec{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterImportStatus).DeepCopyInto(out.(*RedisClusterImportStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterImportStatus{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*RedisClusterList).DeepCopyInto(out.(*RedisClusterList))
 			return nil
 		}, InType: reflect.TypeOf(&RedisClusterList{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterMaintenanceStatus).DeepCopyInto(out.(*RedisClusterMaintenanceStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterMaintenanceStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterMaintenanceWindow).DeepCopyInto(out.(*RedisClusterMaintenanceWindow))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterMaintenanceWindow{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*RedisClusterNode).DeepCopyInto(out.(*RedisClusterNode))
 			return nil
 		}, InType: reflect.TypeOf(&RedisClusterNode{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterNodeMetrics).DeepCopyInto(out.(*RedisClusterNodeMetrics))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterNodeMetrics{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterOperation).DeepCopyInto(out.(*RedisClusterOperation))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterOperation{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterOperationStatus).DeepCopyInto(out.(*RedisClusterOperationStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterOperationStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterPodDisruptionBudget).DeepCopyInto(out.(*RedisClusterPodDisruptionBudget))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterPodDisruptionBudget{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterReplicaOf).DeepCopyInto(out.(*RedisClusterReplicaOf))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterReplicaOf{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterReplicaOfStatus).DeepCopyInto(out.(*RedisClusterReplicaOfStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterReplicaOfStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterRollingUpdateStatus).DeepCopyInto(out.(*RedisClusterRollingUpdateStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterRollingUpdateStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterSanityCheck).DeepCopyInto(out.(*RedisClusterSanityCheck))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterSanityCheck{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterSanityCheckStatus).DeepCopyInto(out.(*RedisClusterSanityCheckStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterSanityCheckStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterShardStatus).DeepCopyInto(out.(*RedisClusterShardStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterShardStatus{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*RedisClusterSpec).DeepCopyInto(out.(*RedisClusterSpec))
 			return nil
 		}, InType: reflect.TypeOf(&RedisClusterSpec{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterSplitQuarantineStatus).DeepCopyInto(out.(*RedisClusterSplitQuarantineStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterSplitQuarantineStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterSplitResolution).DeepCopyInto(out.(*RedisClusterSplitResolution))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterSplitResolution{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterStandbyShardStatus).DeepCopyInto(out.(*RedisClusterStandbyShardStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterStandbyShardStatus{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*RedisClusterStatus).DeepCopyInto(out.(*RedisClusterStatus))
 			return nil
 		}, InType: reflect.TypeOf(&RedisClusterStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisClusterUpdateStrategy).DeepCopyInto(out.(*RedisClusterUpdateStrategy))
+			return nil
+		}, InType: reflect.TypeOf(&RedisClusterUpdateStrategy{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisReplicaStatus).DeepCopyInto(out.(*RedisReplicaStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisReplicaStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisReplicationStatus).DeepCopyInto(out.(*RedisReplicationStatus))
+			return nil
+		}, InType: reflect.TypeOf(&RedisReplicationStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RedisSentinelSpec).DeepCopyInto(out.(*RedisSentinelSpec))
+			return nil
+		}, InType: reflect.TypeOf(&RedisSentinelSpec{})},
 	)
 }
 
@@ -106,6 +223,53 @@ func (in *RedisCluster) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterAdoptionStatus) DeepCopyInto(out *RedisClusterAdoptionStatus) {
+	*out = *in
+	if in.AdoptionTime != nil {
+		in, out := &in.AdoptionTime, &out.AdoptionTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.AdoptedPods != nil {
+		in, out := &in.AdoptedPods, &out.AdoptedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterAdoptionStatus.
+func (in *RedisClusterAdoptionStatus) DeepCopy() *RedisClusterAdoptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterAdoptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterApprovalStatus) DeepCopyInto(out *RedisClusterApprovalStatus) {
+	*out = *in
+	in.RequestTime.DeepCopyInto(&out.RequestTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterApprovalStatus.
+func (in *RedisClusterApprovalStatus) DeepCopy() *RedisClusterApprovalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterApprovalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisClusterClusterStatus) DeepCopyInto(out *RedisClusterClusterStatus) {
 	*out = *in
@@ -116,6 +280,15 @@ func (in *RedisClusterClusterStatus) DeepCopyInto(out *RedisClusterClusterStatus
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MetricsTime != nil {
+		in, out := &in.MetricsTime, &out.MetricsTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -148,13 +321,84 @@ func (in *RedisClusterCondition) DeepCopy() *RedisClusterCondition {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterList) DeepCopyInto(out *RedisClusterList) {
+func (in *RedisClusterGuardRailRefusal) DeepCopyInto(out *RedisClusterGuardRailRefusal) {
+	*out = *in
+	in.LastRefusalTime.DeepCopyInto(&out.LastRefusalTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterGuardRailRefusal.
+func (in *RedisClusterGuardRailRefusal) DeepCopy() *RedisClusterGuardRailRefusal {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterGuardRailRefusal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterGuardRails) DeepCopyInto(out *RedisClusterGuardRails) {
+	*out = *in
+	if in.MaxFlushKeys != nil {
+		in, out := &in.MaxFlushKeys, &out.MaxFlushKeys
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterGuardRails.
+func (in *RedisClusterGuardRails) DeepCopy() *RedisClusterGuardRails {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterGuardRails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterImport) DeepCopyInto(out *RedisClusterImport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterImport.
+func (in *RedisClusterImport) DeepCopy() *RedisClusterImport {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisClusterImport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterImportList) DeepCopyInto(out *RedisClusterImportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]RedisCluster, len(*in))
+		*out = make([]RedisClusterImport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -162,18 +406,18 @@ func (in *RedisClusterList) DeepCopyInto(out *RedisClusterList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterList.
-func (in *RedisClusterList) DeepCopy() *RedisClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterImportList.
+func (in *RedisClusterImportList) DeepCopy() *RedisClusterImportList {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterList)
+	out := new(RedisClusterImportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RedisClusterList) DeepCopyObject() runtime.Object {
+func (in *RedisClusterImportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	} else {
@@ -182,40 +426,48 @@ func (in *RedisClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterNode) DeepCopyInto(out *RedisClusterNode) {
+func (in *RedisClusterImportSource) DeepCopyInto(out *RedisClusterImportSource) {
 	*out = *in
-	if in.Slots != nil {
-		in, out := &in.Slots, &out.Slots
+	if in.Addrs != nil {
+		in, out := &in.Addrs, &out.Addrs
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Pod != nil {
-		in, out := &in.Pod, &out.Pod
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(core_v1.Pod)
-			(*in).DeepCopyInto(*out)
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterImportSource.
+func (in *RedisClusterImportSource) DeepCopy() *RedisClusterImportSource {
+	if in == nil {
+		return nil
 	}
+	out := new(RedisClusterImportSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterImportSourceStatus) DeepCopyInto(out *RedisClusterImportSourceStatus) {
+	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterNode.
-func (in *RedisClusterNode) DeepCopy() *RedisClusterNode {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterImportSourceStatus.
+func (in *RedisClusterImportSourceStatus) DeepCopy() *RedisClusterImportSourceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterNode)
+	out := new(RedisClusterImportSourceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterSpec) DeepCopyInto(out *RedisClusterSpec) {
+func (in *RedisClusterImportSpec) DeepCopyInto(out *RedisClusterImportSpec) {
 	*out = *in
-	if in.NumberOfMaster != nil {
-		in, out := &in.NumberOfMaster, &out.NumberOfMaster
+	in.Source.DeepCopyInto(&out.Source)
+	if in.BatchSize != nil {
+		in, out := &in.BatchSize, &out.BatchSize
 		if *in == nil {
 			*out = nil
 		} else {
@@ -223,73 +475,870 @@ func (in *RedisClusterSpec) DeepCopyInto(out *RedisClusterSpec) {
 			**out = **in
 		}
 	}
-	if in.ReplicationFactor != nil {
-		in, out := &in.ReplicationFactor, &out.ReplicationFactor
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterImportSpec.
+func (in *RedisClusterImportSpec) DeepCopy() *RedisClusterImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterImportStatus) DeepCopyInto(out *RedisClusterImportStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(int32)
-			**out = **in
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
 		}
 	}
-	if in.PodTemplate != nil {
-		in, out := &in.PodTemplate, &out.PodTemplate
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(core_v1.PodTemplateSpec)
+			*out = new(meta_v1.Time)
 			(*in).DeepCopyInto(*out)
 		}
 	}
-	if in.AdditionalLabels != nil {
-		in, out := &in.AdditionalLabels, &out.AdditionalLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]RedisClusterImportSourceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastTailTime != nil {
+		in, out := &in.LastTailTime, &out.LastTailTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSpec.
-func (in *RedisClusterSpec) DeepCopy() *RedisClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterImportStatus.
+func (in *RedisClusterImportStatus) DeepCopy() *RedisClusterImportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterSpec)
+	out := new(RedisClusterImportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterStatus) DeepCopyInto(out *RedisClusterStatus) {
+func (in *RedisClusterList) DeepCopyInto(out *RedisClusterList) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]RedisClusterCondition, len(*in))
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RedisCluster, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(meta_v1.Time)
-			(*in).DeepCopyInto(*out)
-		}
-	}
-	in.Cluster.DeepCopyInto(&out.Cluster)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterStatus.
-func (in *RedisClusterStatus) DeepCopy() *RedisClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterList.
+func (in *RedisClusterList) DeepCopy() *RedisClusterList {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterStatus)
+	out := new(RedisClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterMaintenanceStatus) DeepCopyInto(out *RedisClusterMaintenanceStatus) {
+	*out = *in
+	if in.NextWindow != nil {
+		in, out := &in.NextWindow, &out.NextWindow
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterMaintenanceStatus.
+func (in *RedisClusterMaintenanceStatus) DeepCopy() *RedisClusterMaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterMaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterMaintenanceWindow) DeepCopyInto(out *RedisClusterMaintenanceWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterMaintenanceWindow.
+func (in *RedisClusterMaintenanceWindow) DeepCopy() *RedisClusterMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterNode) DeepCopyInto(out *RedisClusterNode) {
+	*out = *in
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.Pod)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LastFailoverTime != nil {
+		in, out := &in.LastFailoverTime, &out.LastFailoverTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterNodeMetrics)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterNode.
+func (in *RedisClusterNode) DeepCopy() *RedisClusterNode {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterNodeMetrics) DeepCopyInto(out *RedisClusterNodeMetrics) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterNodeMetrics.
+func (in *RedisClusterNodeMetrics) DeepCopy() *RedisClusterNodeMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterNodeMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterOperation) DeepCopyInto(out *RedisClusterOperation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterOperation.
+func (in *RedisClusterOperation) DeepCopy() *RedisClusterOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterOperationStatus) DeepCopyInto(out *RedisClusterOperationStatus) {
+	*out = *in
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterOperationStatus.
+func (in *RedisClusterOperationStatus) DeepCopy() *RedisClusterOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterPodDisruptionBudget) DeepCopyInto(out *RedisClusterPodDisruptionBudget) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(intstr.IntOrString)
+			**out = **in
+		}
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(intstr.IntOrString)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterPodDisruptionBudget.
+func (in *RedisClusterPodDisruptionBudget) DeepCopy() *RedisClusterPodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterPodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterReplicaOf) DeepCopyInto(out *RedisClusterReplicaOf) {
+	*out = *in
+	if in.Addrs != nil {
+		in, out := &in.Addrs, &out.Addrs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterReplicaOf.
+func (in *RedisClusterReplicaOf) DeepCopy() *RedisClusterReplicaOf {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterReplicaOf)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterReplicaOfStatus) DeepCopyInto(out *RedisClusterReplicaOfStatus) {
+	*out = *in
+	if in.PromotionTime != nil {
+		in, out := &in.PromotionTime, &out.PromotionTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]RedisClusterStandbyShardStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterReplicaOfStatus.
+func (in *RedisClusterReplicaOfStatus) DeepCopy() *RedisClusterReplicaOfStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterReplicaOfStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterRollingUpdateStatus) DeepCopyInto(out *RedisClusterRollingUpdateStatus) {
+	*out = *in
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]RedisClusterShardStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterRollingUpdateStatus.
+func (in *RedisClusterRollingUpdateStatus) DeepCopy() *RedisClusterRollingUpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterRollingUpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterSanityCheck) DeepCopyInto(out *RedisClusterSanityCheck) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.Order != nil {
+		in, out := &in.Order, &out.Order
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.DryRunOnly != nil {
+		in, out := &in.DryRunOnly, &out.DryRunOnly
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.ThresholdSeconds != nil {
+		in, out := &in.ThresholdSeconds, &out.ThresholdSeconds
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSanityCheck.
+func (in *RedisClusterSanityCheck) DeepCopy() *RedisClusterSanityCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterSanityCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterSanityCheckStatus) DeepCopyInto(out *RedisClusterSanityCheckStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSanityCheckStatus.
+func (in *RedisClusterSanityCheckStatus) DeepCopy() *RedisClusterSanityCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterSanityCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterShardStatus) DeepCopyInto(out *RedisClusterShardStatus) {
+	*out = *in
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterShardStatus.
+func (in *RedisClusterShardStatus) DeepCopy() *RedisClusterShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterSpec) DeepCopyInto(out *RedisClusterSpec) {
+	*out = *in
+	if in.NumberOfMaster != nil {
+		in, out := &in.NumberOfMaster, &out.NumberOfMaster
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.ReplicationFactor != nil {
+		in, out := &in.ReplicationFactor, &out.ReplicationFactor
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.PodTemplateSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.AdditionalLabels != nil {
+		in, out := &in.AdditionalLabels, &out.AdditionalLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterUpdateStrategy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]RedisClusterOperation, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sentinel != nil {
+		in, out := &in.Sentinel, &out.Sentinel
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisSentinelSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ReplicaOf != nil {
+		in, out := &in.ReplicaOf, &out.ReplicaOf
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterReplicaOf)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Adopt != nil {
+		in, out := &in.Adopt, &out.Adopt
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.SanityChecks != nil {
+		in, out := &in.SanityChecks, &out.SanityChecks
+		*out = make([]RedisClusterSanityCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SplitResolution != nil {
+		in, out := &in.SplitResolution, &out.SplitResolution
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterSplitResolution)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.GuardRails != nil {
+		in, out := &in.GuardRails, &out.GuardRails
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterGuardRails)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]RedisClusterMaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequireApprovalFor != nil {
+		in, out := &in.RequireApprovalFor, &out.RequireApprovalFor
+		*out = make([]RedisClusterDisruptiveAction, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterPodDisruptionBudget)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSpec.
+func (in *RedisClusterSpec) DeepCopy() *RedisClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterSplitQuarantineStatus) DeepCopyInto(out *RedisClusterSplitQuarantineStatus) {
+	*out = *in
+	if in.MainPartition != nil {
+		in, out := &in.MainPartition, &out.MainPartition
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.QuarantinedNodes != nil {
+		in, out := &in.QuarantinedNodes, &out.QuarantinedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSplitQuarantineStatus.
+func (in *RedisClusterSplitQuarantineStatus) DeepCopy() *RedisClusterSplitQuarantineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterSplitQuarantineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterSplitResolution) DeepCopyInto(out *RedisClusterSplitResolution) {
+	*out = *in
+	if in.BackupTimeoutSeconds != nil {
+		in, out := &in.BackupTimeoutSeconds, &out.BackupTimeoutSeconds
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSplitResolution.
+func (in *RedisClusterSplitResolution) DeepCopy() *RedisClusterSplitResolution {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterSplitResolution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterStandbyShardStatus) DeepCopyInto(out *RedisClusterStandbyShardStatus) {
+	*out = *in
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReplicaPodNames != nil {
+		in, out := &in.ReplicaPodNames, &out.ReplicaPodNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterStandbyShardStatus.
+func (in *RedisClusterStandbyShardStatus) DeepCopy() *RedisClusterStandbyShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterStandbyShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterStatus) DeepCopyInto(out *RedisClusterStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RedisClusterCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	in.Cluster.DeepCopyInto(&out.Cluster)
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterRollingUpdateStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]RedisClusterOperationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisReplicationStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ReplicaOf != nil {
+		in, out := &in.ReplicaOf, &out.ReplicaOf
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterReplicaOfStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterAdoptionStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.SanityChecks != nil {
+		in, out := &in.SanityChecks, &out.SanityChecks
+		*out = make([]RedisClusterSanityCheckStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SplitQuarantine != nil {
+		in, out := &in.SplitQuarantine, &out.SplitQuarantine
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterSplitQuarantineStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.GuardRailRefusals != nil {
+		in, out := &in.GuardRailRefusals, &out.GuardRailRefusals
+		*out = make([]RedisClusterGuardRailRefusal, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterMaintenanceStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RedisClusterApprovalStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterStatus.
+func (in *RedisClusterStatus) DeepCopy() *RedisClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterUpdateStrategy) DeepCopyInto(out *RedisClusterUpdateStrategy) {
+	*out = *in
+	if in.MaxSurgeShards != nil {
+		in, out := &in.MaxSurgeShards, &out.MaxSurgeShards
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterUpdateStrategy.
+func (in *RedisClusterUpdateStrategy) DeepCopy() *RedisClusterUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisReplicaStatus) DeepCopyInto(out *RedisReplicaStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicaStatus.
+func (in *RedisReplicaStatus) DeepCopy() *RedisReplicaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisReplicaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisReplicationStatus) DeepCopyInto(out *RedisReplicationStatus) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]RedisReplicaStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicationStatus.
+func (in *RedisReplicationStatus) DeepCopy() *RedisReplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSentinelSpec) DeepCopyInto(out *RedisSentinelSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.Quorum != nil {
+		in, out := &in.Quorum, &out.Quorum
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelSpec.
+func (in *RedisSentinelSpec) DeepCopy() *RedisSentinelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSentinelSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -22,21 +22,35 @@ import (
 
 // DefineRedisClusterResource defines a RedisClusterResource as a k8s CR
 func DefineRedisClusterResource(clientset apiextensionsclient.Interface) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
-	redisClusterResourceName := v1.ResourcePlural + "." + redis.GroupName
+	return defineResource(clientset, apiextensionsv1beta1.CustomResourceDefinitionNames{
+		Plural:     v1.ResourcePlural,
+		Singular:   v1.ResourceSingular,
+		Kind:       reflect.TypeOf(v1.RedisCluster{}).Name(),
+		ShortNames: []string{"rdc"},
+	})
+}
+
+// DefineRedisClusterImportResource defines a RedisClusterImport as a k8s CR
+func DefineRedisClusterImportResource(clientset apiextensionsclient.Interface) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+	return defineResource(clientset, apiextensionsv1beta1.CustomResourceDefinitionNames{
+		Plural:     v1.ImportResourcePlural,
+		Singular:   v1.ImportResourceSingular,
+		Kind:       reflect.TypeOf(v1.RedisClusterImport{}).Name(),
+		ShortNames: []string{"rdci"},
+	})
+}
+
+func defineResource(clientset apiextensionsclient.Interface, names apiextensionsv1beta1.CustomResourceDefinitionNames) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+	resourceName := names.Plural + "." + redis.GroupName
 	crd := &apiextensionsv1beta1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: redisClusterResourceName,
+			Name: resourceName,
 		},
 		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
 			Group:   redis.GroupName,
 			Version: v1.SchemeGroupVersion.Version,
 			Scope:   apiextensionsv1beta1.NamespaceScoped,
-			Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
-				Plural:     v1.ResourcePlural,
-				Singular:   v1.ResourceSingular,
-				Kind:       reflect.TypeOf(v1.RedisCluster{}).Name(),
-				ShortNames: []string{"rdc"},
-			},
+			Names:   names,
 		},
 	}
 	_, err := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Create(crd)
@@ -46,7 +60,7 @@ func DefineRedisClusterResource(clientset apiextensionsclient.Interface) (*apiex
 
 	// wait for CRD being established
 	err = wait.Poll(500*time.Millisecond, 60*time.Second, func() (bool, error) {
-		crd, err = clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Get(redisClusterResourceName, metav1.GetOptions{})
+		crd, err = clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Get(resourceName, metav1.GetOptions{})
 		if err != nil {
 			return false, err
 		}
@@ -65,7 +79,7 @@ func DefineRedisClusterResource(clientset apiextensionsclient.Interface) (*apiex
 		return false, err
 	})
 	if err != nil {
-		deleteErr := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(redisClusterResourceName, nil)
+		deleteErr := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(resourceName, nil)
 		if deleteErr != nil {
 			return nil, errors.NewAggregate([]error{err, deleteErr})
 		}
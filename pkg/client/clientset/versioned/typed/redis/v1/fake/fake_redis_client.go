@@ -37,6 +37,10 @@ func (c *FakeRedisoperatorV1) RedisClusters(namespace string) v1.RedisClusterInt
 	return &FakeRedisClusters{c, namespace}
 }
 
+func (c *FakeRedisoperatorV1) RedisClusterImports(namespace string) v1.RedisClusterImportInterface {
+	return &FakeRedisClusterImports{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeRedisoperatorV1) RESTClient() rest.Interface {
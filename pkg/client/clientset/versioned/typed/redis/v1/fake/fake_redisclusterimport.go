@@ -0,0 +1,145 @@
+/*
+MIT License
+
+Copyright (c) 2018 Amadeus s.a.s.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package fake
+
+import (
+	redis_v1 "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeRedisClusterImports implements RedisClusterImportInterface
+type FakeRedisClusterImports struct {
+	Fake *FakeRedisoperatorV1
+	ns   string
+}
+
+var redisclusterimportsResource = schema.GroupVersionResource{Group: "redisoperator.k8s.io", Version: "v1", Resource: "redisclusterimports"}
+
+var redisclusterimportsKind = schema.GroupVersionKind{Group: "redisoperator.k8s.io", Version: "v1", Kind: "RedisClusterImport"}
+
+// Get takes name of the redisClusterImport, and returns the corresponding redisClusterImport object, and an error if there is any.
+func (c *FakeRedisClusterImports) Get(name string, options v1.GetOptions) (result *redis_v1.RedisClusterImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(redisclusterimportsResource, c.ns, name), &redis_v1.RedisClusterImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*redis_v1.RedisClusterImport), err
+}
+
+// List takes label and field selectors, and returns the list of RedisClusterImports that match those selectors.
+func (c *FakeRedisClusterImports) List(opts v1.ListOptions) (result *redis_v1.RedisClusterImportList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(redisclusterimportsResource, redisclusterimportsKind, c.ns, opts), &redis_v1.RedisClusterImportList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &redis_v1.RedisClusterImportList{}
+	for _, item := range obj.(*redis_v1.RedisClusterImportList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested redisClusterImports.
+func (c *FakeRedisClusterImports) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(redisclusterimportsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a redisClusterImport and creates it.  Returns the server's representation of the redisClusterImport, and an error, if there is any.
+func (c *FakeRedisClusterImports) Create(redisClusterImport *redis_v1.RedisClusterImport) (result *redis_v1.RedisClusterImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(redisclusterimportsResource, c.ns, redisClusterImport), &redis_v1.RedisClusterImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*redis_v1.RedisClusterImport), err
+}
+
+// Update takes the representation of a redisClusterImport and updates it. Returns the server's representation of the redisClusterImport, and an error, if there is any.
+func (c *FakeRedisClusterImports) Update(redisClusterImport *redis_v1.RedisClusterImport) (result *redis_v1.RedisClusterImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(redisclusterimportsResource, c.ns, redisClusterImport), &redis_v1.RedisClusterImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*redis_v1.RedisClusterImport), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeRedisClusterImports) UpdateStatus(redisClusterImport *redis_v1.RedisClusterImport) (*redis_v1.RedisClusterImport, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(redisclusterimportsResource, "status", c.ns, redisClusterImport), &redis_v1.RedisClusterImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*redis_v1.RedisClusterImport), err
+}
+
+// Delete takes name of the redisClusterImport and deletes it. Returns an error if one occurs.
+func (c *FakeRedisClusterImports) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(redisclusterimportsResource, c.ns, name), &redis_v1.RedisClusterImport{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeRedisClusterImports) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(redisclusterimportsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &redis_v1.RedisClusterImportList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched redisClusterImport.
+func (c *FakeRedisClusterImports) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *redis_v1.RedisClusterImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(redisclusterimportsResource, c.ns, name, data, subresources...), &redis_v1.RedisClusterImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*redis_v1.RedisClusterImport), err
+}
@@ -24,3 +24,5 @@ SOFTWARE.
 package v1
 
 type RedisClusterExpansion interface{}
+
+type RedisClusterImportExpansion interface{}
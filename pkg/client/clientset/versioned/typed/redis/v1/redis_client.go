@@ -33,6 +33,7 @@ import (
 type RedisoperatorV1Interface interface {
 	RESTClient() rest.Interface
 	RedisClustersGetter
+	RedisClusterImportsGetter
 }
 
 // RedisoperatorV1Client is used to interact with features provided by the redisoperator.k8s.io group.
@@ -44,6 +45,10 @@ func (c *RedisoperatorV1Client) RedisClusters(namespace string) RedisClusterInte
 	return newRedisClusters(c, namespace)
 }
 
+func (c *RedisoperatorV1Client) RedisClusterImports(namespace string) RedisClusterImportInterface {
+	return newRedisClusterImports(c, namespace)
+}
+
 // NewForConfig creates a new RedisoperatorV1Client for the given config.
 func NewForConfig(c *rest.Config) (*RedisoperatorV1Client, error) {
 	config := *c
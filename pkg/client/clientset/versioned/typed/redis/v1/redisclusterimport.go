@@ -0,0 +1,179 @@
+/*
+MIT License
+
+Copyright (c) 2018 Amadeus s.a.s.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package v1
+
+import (
+	v1 "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	scheme "github.com/zh168654/Redis-Operator/pkg/client/clientset/versioned/scheme"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// RedisClusterImportsGetter has a method to return a RedisClusterImportInterface.
+// A group's client should implement this interface.
+type RedisClusterImportsGetter interface {
+	RedisClusterImports(namespace string) RedisClusterImportInterface
+}
+
+// RedisClusterImportInterface has methods to work with RedisClusterImport resources.
+type RedisClusterImportInterface interface {
+	Create(*v1.RedisClusterImport) (*v1.RedisClusterImport, error)
+	Update(*v1.RedisClusterImport) (*v1.RedisClusterImport, error)
+	UpdateStatus(*v1.RedisClusterImport) (*v1.RedisClusterImport, error)
+	Delete(name string, options *meta_v1.DeleteOptions) error
+	DeleteCollection(options *meta_v1.DeleteOptions, listOptions meta_v1.ListOptions) error
+	Get(name string, options meta_v1.GetOptions) (*v1.RedisClusterImport, error)
+	List(opts meta_v1.ListOptions) (*v1.RedisClusterImportList, error)
+	Watch(opts meta_v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1.RedisClusterImport, err error)
+	RedisClusterImportExpansion
+}
+
+// redisClusterImports implements RedisClusterImportInterface
+type redisClusterImports struct {
+	client rest.Interface
+	ns     string
+}
+
+// newRedisClusterImports returns a RedisClusterImports
+func newRedisClusterImports(c *RedisoperatorV1Client, namespace string) *redisClusterImports {
+	return &redisClusterImports{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the redisClusterImport, and returns the corresponding redisClusterImport object, and an error if there is any.
+func (c *redisClusterImports) Get(name string, options meta_v1.GetOptions) (result *v1.RedisClusterImport, err error) {
+	result = &v1.RedisClusterImport{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of RedisClusterImports that match those selectors.
+func (c *redisClusterImports) List(opts meta_v1.ListOptions) (result *v1.RedisClusterImportList, err error) {
+	result = &v1.RedisClusterImportList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested redisClusterImports.
+func (c *redisClusterImports) Watch(opts meta_v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a redisClusterImport and creates it.  Returns the server's representation of the redisClusterImport, and an error, if there is any.
+func (c *redisClusterImports) Create(redisClusterImport *v1.RedisClusterImport) (result *v1.RedisClusterImport, err error) {
+	result = &v1.RedisClusterImport{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		Body(redisClusterImport).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a redisClusterImport and updates it. Returns the server's representation of the redisClusterImport, and an error, if there is any.
+func (c *redisClusterImports) Update(redisClusterImport *v1.RedisClusterImport) (result *v1.RedisClusterImport, err error) {
+	result = &v1.RedisClusterImport{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		Name(redisClusterImport.Name).
+		Body(redisClusterImport).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *redisClusterImports) UpdateStatus(redisClusterImport *v1.RedisClusterImport) (result *v1.RedisClusterImport, err error) {
+	result = &v1.RedisClusterImport{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		Name(redisClusterImport.Name).
+		SubResource("status").
+		Body(redisClusterImport).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the redisClusterImport and deletes it. Returns an error if one occurs.
+func (c *redisClusterImports) Delete(name string, options *meta_v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *redisClusterImports) DeleteCollection(options *meta_v1.DeleteOptions, listOptions meta_v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched redisClusterImport.
+func (c *redisClusterImports) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1.RedisClusterImport, err error) {
+	result = &v1.RedisClusterImport{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("redisclusterimports").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -62,6 +62,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=redisoperator.k8s.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("redisclusters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Redisoperator().V1().RedisClusters().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("redisclusterimports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Redisoperator().V1().RedisClusterImports().Informer()}, nil
 
 	}
 
@@ -34,6 +34,8 @@ import (
 type Interface interface {
 	// RedisClusters returns a RedisClusterInformer.
 	RedisClusters() RedisClusterInformer
+	// RedisClusterImports returns a RedisClusterImportInformer.
+	RedisClusterImports() RedisClusterImportInformer
 }
 
 type version struct {
@@ -51,3 +53,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) RedisClusters() RedisClusterInformer {
 	return &redisClusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// RedisClusterImports returns a RedisClusterImportInformer.
+func (v *version) RedisClusterImports() RedisClusterImportInformer {
+	return &redisClusterImportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
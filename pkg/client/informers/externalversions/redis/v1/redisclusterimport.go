@@ -0,0 +1,96 @@
+/*
+MIT License
+
+Copyright (c) 2018 Amadeus s.a.s.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// This file was automatically generated by informer-gen
+
+package v1
+
+import (
+	redis_v1 "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	versioned "github.com/zh168654/Redis-Operator/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/zh168654/Redis-Operator/pkg/client/informers/externalversions/internalinterfaces"
+	v1 "github.com/zh168654/Redis-Operator/pkg/client/listers/redis/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+	time "time"
+)
+
+// RedisClusterImportInformer provides access to a shared informer and lister for
+// RedisClusterImports.
+type RedisClusterImportInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.RedisClusterImportLister
+}
+
+type redisClusterImportInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewRedisClusterImportInformer constructs a new informer for RedisClusterImport type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewRedisClusterImportInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredRedisClusterImportInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredRedisClusterImportInformer constructs a new informer for RedisClusterImport type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredRedisClusterImportInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options meta_v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.RedisoperatorV1().RedisClusterImports(namespace).List(options)
+			},
+			WatchFunc: func(options meta_v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.RedisoperatorV1().RedisClusterImports(namespace).Watch(options)
+			},
+		},
+		&redis_v1.RedisClusterImport{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *redisClusterImportInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredRedisClusterImportInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *redisClusterImportInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&redis_v1.RedisClusterImport{}, f.defaultInformer)
+}
+
+func (f *redisClusterImportInformer) Lister() v1.RedisClusterImportLister {
+	return v1.NewRedisClusterImportLister(f.Informer().GetIndexer())
+}
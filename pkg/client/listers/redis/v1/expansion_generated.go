@@ -33,3 +33,11 @@ type RedisClusterListerExpansion interface{}
 // RedisClusterNamespaceListerExpansion allows custom methods to be added to
 // RedisClusterNamespaceLister.
 type RedisClusterNamespaceListerExpansion interface{}
+
+// RedisClusterImportListerExpansion allows custom methods to be added to
+// RedisClusterImportLister.
+type RedisClusterImportListerExpansion interface{}
+
+// RedisClusterImportNamespaceListerExpansion allows custom methods to be added to
+// RedisClusterImportNamespaceLister.
+type RedisClusterImportNamespaceListerExpansion interface{}
@@ -0,0 +1,102 @@
+/*
+MIT License
+
+Copyright (c) 2018 Amadeus s.a.s.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// This file was automatically generated by lister-gen
+
+package v1
+
+import (
+	v1 "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// RedisClusterImportLister helps list RedisClusterImports.
+type RedisClusterImportLister interface {
+	// List lists all RedisClusterImports in the indexer.
+	List(selector labels.Selector) (ret []*v1.RedisClusterImport, err error)
+	// RedisClusterImports returns an object that can list and get RedisClusterImports.
+	RedisClusterImports(namespace string) RedisClusterImportNamespaceLister
+	RedisClusterImportListerExpansion
+}
+
+// redisClusterImportLister implements the RedisClusterImportLister interface.
+type redisClusterImportLister struct {
+	indexer cache.Indexer
+}
+
+// NewRedisClusterImportLister returns a new RedisClusterImportLister.
+func NewRedisClusterImportLister(indexer cache.Indexer) RedisClusterImportLister {
+	return &redisClusterImportLister{indexer: indexer}
+}
+
+// List lists all RedisClusterImports in the indexer.
+func (s *redisClusterImportLister) List(selector labels.Selector) (ret []*v1.RedisClusterImport, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.RedisClusterImport))
+	})
+	return ret, err
+}
+
+// RedisClusterImports returns an object that can list and get RedisClusterImports.
+func (s *redisClusterImportLister) RedisClusterImports(namespace string) RedisClusterImportNamespaceLister {
+	return redisClusterImportNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// RedisClusterImportNamespaceLister helps list and get RedisClusterImports.
+type RedisClusterImportNamespaceLister interface {
+	// List lists all RedisClusterImports in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1.RedisClusterImport, err error)
+	// Get retrieves the RedisClusterImport from the indexer for a given namespace and name.
+	Get(name string) (*v1.RedisClusterImport, error)
+	RedisClusterImportNamespaceListerExpansion
+}
+
+// redisClusterImportNamespaceLister implements the RedisClusterImportNamespaceLister
+// interface.
+type redisClusterImportNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all RedisClusterImports in the indexer for a given namespace.
+func (s redisClusterImportNamespaceLister) List(selector labels.Selector) (ret []*v1.RedisClusterImport, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.RedisClusterImport))
+	})
+	return ret, err
+}
+
+// Get retrieves the RedisClusterImport from the indexer for a given namespace and name.
+func (s redisClusterImportNamespaceLister) Get(name string) (*v1.RedisClusterImport, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1.Resource("redisclusterimport"), name)
+	}
+	return obj.(*v1.RedisClusterImport), nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// AuditSinkNone disables the audit log
+	AuditSinkNone = "none"
+	// AuditSinkStdout writes the audit records as JSON lines on the standard output
+	AuditSinkStdout = "stdout"
+	// AuditSinkFile appends the audit records as JSON lines to a file
+	AuditSinkFile = "file"
+	// AuditSinkConfigMap writes the audit records of each RedisCluster in a ConfigMap of its namespace
+	AuditSinkConfigMap = "configmap"
+
+	// DefaultAuditFile default file of the file audit sink
+	DefaultAuditFile = "/var/log/redis-operator/audit.jsonl"
+	// DefaultAuditConfigMapMaxBytes default size above which the oldest records of an audit ConfigMap are dropped
+	DefaultAuditConfigMapMaxBytes = 256 * 1024
+	// DefaultAuditConfigMapFlushInterval default interval between two writes of the audit ConfigMaps
+	DefaultAuditConfigMapFlushInterval = 5 * time.Second
+)
+
+// Audit used to store the configuration of the audit log of the mutating redis commands
+type Audit struct {
+	Sink                   string
+	File                   string
+	ConfigMapMaxBytes      int
+	ConfigMapFlushInterval time.Duration
+}
+
+// AddFlags use to add the audit Config flags to the command line
+func (a *Audit) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&a.Sink, "audit-sink", AuditSinkNone, "sink of the audit log of the mutating redis commands: none, stdout, file or configmap")
+	fs.StringVar(&a.File, "audit-file", DefaultAuditFile, "file where the audit records are appended as JSON lines with the file audit sink")
+	fs.IntVar(&a.ConfigMapMaxBytes, "audit-configmap-max-bytes", DefaultAuditConfigMapMaxBytes, "size of the records kept in the audit ConfigMap of a RedisCluster, the oldest records are dropped above it")
+	fs.DurationVar(&a.ConfigMapFlushInterval, "audit-configmap-flush-interval", DefaultAuditConfigMapFlushInterval, "interval between two writes of the records in the audit ConfigMaps")
+}
+
+// String stringer interface
+func (a Audit) String() string {
+	var output string
+	output += fmt.Sprintln("[ Audit Configuration ]")
+	output += fmt.Sprintln("- Sink:", a.Sink)
+	output += fmt.Sprintln("- File:", a.File)
+	output += fmt.Sprintln("- ConfigMapMaxBytes:", a.ConfigMapMaxBytes)
+	output += fmt.Sprintln("- ConfigMapFlushInterval:", a.ConfigMapFlushInterval)
+	return output
+}
@@ -2,6 +2,17 @@ package config
 
 import "github.com/spf13/pflag"
 
+const (
+	// ClusterModeDefault redis-node mode running a redis cluster node
+	ClusterModeDefault = "Cluster"
+	// SentinelMode redis-node mode running a standalone redis node, replicated and monitored by sentinels
+	SentinelMode = "Sentinel"
+	// StandbyMode redis-node mode running a standalone redis node of a standby cluster, replicating a master of the
+	// source cluster or a standby master. The redis node restarts in Cluster mode once its pod is annotated with
+	// its role by the promotion of the standby cluster.
+	StandbyMode = "Standby"
+)
+
 // Cluster used to store all Redis Cluster configuration information
 type Cluster struct {
 	Name                string
@@ -9,6 +20,17 @@ type Cluster struct {
 	NodeService         string
 	NodeServiceType     string
 	NodeServiceNodePort string
+	Mode                string
+}
+
+// IsSentinelMode returns true if the redis node is not a cluster node but a standalone node monitored by sentinels
+func (c *Cluster) IsSentinelMode() bool {
+	return c.Mode == SentinelMode
+}
+
+// IsStandbyMode returns true if the redis node is a standalone node of a standby cluster not promoted yet
+func (c *Cluster) IsStandbyMode() bool {
+	return c.Mode == StandbyMode
 }
 
 // AddFlags use to add the Redis-Cluster Config flags to the command line
@@ -18,5 +40,6 @@ func (c *Cluster) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.NodeService, "rs", "", "redis-node k8s service name")
 	fs.StringVar(&c.NodeServiceType, "rstype", "", "redis-node k8s service type")
 	fs.StringVar(&c.NodeServiceNodePort, "rsnodeport", "", "redis-node k8s service nodePort start")
+	fs.StringVar(&c.Mode, "mode", ClusterModeDefault, "redis-node mode: Cluster, Sentinel or Standby")
 
 }
@@ -13,6 +13,14 @@ const (
 	//DefaultClusterNodeTimeout default cluster node timeout (ms)
 	//The maximum amount of time a Redis Cluster node can be unavailable, without it being considered as failing
 	DefaultClusterNodeTimeout = 2000
+	// DefaultIdleCheckInterval default interval between two health checks of the idle admin connections (ms)
+	DefaultIdleCheckInterval = 30000
+	// DefaultRetryAttempts default number of times an idempotent command is sent to a redis node failing with a retryable error
+	DefaultRetryAttempts = 3
+	// DefaultCircuitBreakerThreshold default number of consecutive network failures opening the circuit breaker of a redis node
+	DefaultCircuitBreakerThreshold = 5
+	// DefaultCircuitBreakerCooldown default time during which no command is sent to a node once its circuit breaker is open (ms)
+	DefaultCircuitBreakerCooldown = 30000
 	// RedisRenameCommandsDefaultPath default path to volume storing rename commands
 	RedisRenameCommandsDefaultPath = "/etc/secret-volume"
 	// RedisRenameCommandsDefaultFile default file name containing rename commands
@@ -31,24 +39,32 @@ const (
 
 // Redis used to store all Redis configuration information
 type Redis struct {
-	DialTimeout        int
-	ClusterNodeTimeout int
-	ConfigFileName     string
-	renameCommandsPath string
-	renameCommandsFile string
-	HTTPServerAddr     string
-	ServerBin          string
-	ServerPort         string
-	ServerIP           string
-	MaxMemory          uint32
-	MaxMemoryPolicy    string
-	ConfigFiles        []string
+	DialTimeout             int
+	ClusterNodeTimeout      int
+	IdleCheckInterval       int
+	RetryAttempts           int
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  int
+	ConfigFileName          string
+	renameCommandsPath      string
+	renameCommandsFile      string
+	HTTPServerAddr          string
+	ServerBin               string
+	ServerPort              string
+	ServerIP                string
+	MaxMemory               uint32
+	MaxMemoryPolicy         string
+	ConfigFiles             []string
 }
 
 // AddFlags use to add the Redis Config flags to the command line
 func (r *Redis) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&r.DialTimeout, "rdt", DefaultRedisTimeout, "redis dial timeout (ms)")
 	fs.IntVar(&r.ClusterNodeTimeout, "cluster-node-timeout", DefaultClusterNodeTimeout, "redis node timeout (ms)")
+	fs.IntVar(&r.IdleCheckInterval, "idle-check-interval", DefaultIdleCheckInterval, "interval between two health checks of the idle admin connections kept by the operator (ms), disabled if 0")
+	fs.IntVar(&r.RetryAttempts, "retry-attempts", DefaultRetryAttempts, "number of times an idempotent command is sent to a redis node failing with LOADING, TRYAGAIN, CLUSTERDOWN or a network error, 1 disables the retries")
+	fs.IntVar(&r.CircuitBreakerThreshold, "circuit-breaker-threshold", DefaultCircuitBreakerThreshold, "number of consecutive network failures after which no command is sent to a redis node during the cool down, disabled if negative")
+	fs.IntVar(&r.CircuitBreakerCooldown, "circuit-breaker-cooldown", DefaultCircuitBreakerCooldown, "time during which no command is sent to a redis node once its circuit breaker is open (ms)")
 	fs.StringVar(&r.ConfigFileName, "c", RedisConfigFileDefault, "redis config file path")
 	fs.StringVar(&r.renameCommandsPath, "rename-command-path", RedisRenameCommandsDefaultPath, "Path to the folder where rename-commands option for redis are available")
 	fs.StringVar(&r.renameCommandsFile, "rename-command-file", RedisRenameCommandsDefaultFile, "Name of the file where rename-commands option for redis are available, disabled if empty")
@@ -75,6 +91,10 @@ func (r Redis) String() string {
 	output += fmt.Sprintln("[ Redis Configuration ]")
 	output += fmt.Sprintln("- DialTimeout:", r.DialTimeout)
 	output += fmt.Sprintln("- ClusterNodeTimeout:", r.ClusterNodeTimeout)
+	output += fmt.Sprintln("- IdleCheckInterval:", r.IdleCheckInterval)
+	output += fmt.Sprintln("- RetryAttempts:", r.RetryAttempts)
+	output += fmt.Sprintln("- CircuitBreakerThreshold:", r.CircuitBreakerThreshold)
+	output += fmt.Sprintln("- CircuitBreakerCooldown:", r.CircuitBreakerCooldown)
 	output += fmt.Sprintln("- Rename commands:", r.GetRenameCommandsFile())
 	output += fmt.Sprintln("- max-memory:", r.MaxMemory)
 	output += fmt.Sprintln("- max-memory-policy:", r.MaxMemoryPolicy)
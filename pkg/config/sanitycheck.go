@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// DefaultTerminatingPodTimeout default time after which a pod blocked in terminating status is deleted again
+	DefaultTerminatingPodTimeout = 5 * time.Minute
+)
+
+var (
+	// DefaultSanityChecksOrder default order of the sanity checks
+	DefaultSanityChecksOrder = []string{"lost-quorum", "failed-nodes", "untrusted-nodes", "terminating-pods", "cluster-split", "ghost-masters", "nodes-not-meet"}
+	// DefaultSanityChecksDisabled sanity checks disabled by default
+	DefaultSanityChecksDisabled = []string{"ghost-masters", "nodes-not-meet"}
+)
+
+// SanityChecks used to store the operator defaults of the sanity checks, they can be overridden in the RedisCluster spec
+type SanityChecks struct {
+	Order                 []string
+	Disabled              []string
+	DryRunOnly            []string
+	TerminatingPodTimeout time.Duration
+}
+
+// AddFlags use to add the sanity checks Config flags to the command line
+func (s *SanityChecks) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&s.Order, "sanity-checks-order", DefaultSanityChecksOrder, "order of the sanity checks, the checks not listed run after the others")
+	fs.StringSliceVar(&s.Disabled, "sanity-checks-disabled", DefaultSanityChecksDisabled, "sanity checks not run")
+	fs.StringSliceVar(&s.DryRunOnly, "sanity-checks-dry-run-only", []string{}, "sanity checks only reporting the problems found, without fixing them")
+	fs.DurationVar(&s.TerminatingPodTimeout, "terminating-pod-timeout", DefaultTerminatingPodTimeout, "time after which a pod blocked in terminating status is deleted again by the terminating-pods sanity check, disabled if 0")
+}
+
+// String stringer interface
+func (s SanityChecks) String() string {
+	var output string
+	output += fmt.Sprintln("[ Sanity Checks Configuration ]")
+	output += fmt.Sprintln("- Order:", s.Order)
+	output += fmt.Sprintln("- Disabled:", s.Disabled)
+	output += fmt.Sprintln("- DryRunOnly:", s.DryRunOnly)
+	output += fmt.Sprintln("- TerminatingPodTimeout:", s.TerminatingPodTimeout)
+	return output
+}
@@ -2,10 +2,13 @@ package controller
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"time"
 
 	"github.com/golang/glog"
 
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/errors"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
@@ -18,14 +21,14 @@ import (
 func (c *Controller) clusterAction(admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos) (bool, error) {
 	var err error
 	// run sanity check if needed
-	needSanity, err := sanitycheck.RunSanityChecks(admin, &c.config.redis, c.podControl, cluster, infos, true)
+	needSanity, err := sanitycheck.RunSanityChecks(admin, &c.config.redis, &c.config.sanityChecks, c.podControl, c.recorder, cluster, infos, true)
 	if err != nil {
 		glog.Errorf("[clusterAction] cluster %s/%s, an error occurs during sanitycheck: %v ", cluster.Namespace, cluster.Name, err)
 		return false, err
 	}
 	if needSanity {
 		glog.V(3).Infof("[clusterAction] run sanitycheck cluster: %s/%s", cluster.Namespace, cluster.Name)
-		return sanitycheck.RunSanityChecks(admin, &c.config.redis, c.podControl, cluster, infos, false)
+		return sanitycheck.RunSanityChecks(admin, &c.config.redis, &c.config.sanityChecks, c.podControl, c.recorder, cluster, infos, false)
 	}
 
 	// Start more pods in needed
@@ -40,12 +43,6 @@ func (c *Controller) clusterAction(admin redis.AdminInterface, cluster *rapi.Red
 			glog.Errorf("[clusterAction] unable to create a pod associated to the RedisCluster: %s/%s, err: %v", cluster.Namespace, cluster.Name, err2)
 			return false, err2
 		}
-		_, err3 := c.serviceControl.AddRedisPodService(cluster, currentPods)
-		if err3 != nil {
-			glog.Errorf("[clusterAction] unable to create an external service exposed by the new pod associated to the RedisCluster: %s/%s, err: %v", cluster.Namespace, cluster.Name, err3)
-			return false, err3
-		}
-
 		glog.V(3).Infof("[clusterAction]create a Pod %s/%s", pod.Namespace, pod.Name)
 		return true, nil
 	}
@@ -73,19 +70,6 @@ func (c *Controller) clusterAction(admin redis.AdminInterface, cluster *rapi.Red
 
 // manageRollingUpdate used to manage properly a cluster rolling update if the podtemplate spec has changed
 func (c *Controller) manageRollingUpdate(admin redis.AdminInterface, cluster *rapi.RedisCluster, rCluster *redis.Cluster, nodes redis.Nodes) (bool, error) {
-	nbRequirePodForSpec := *cluster.Spec.NumberOfMaster * (1 + *cluster.Spec.ReplicationFactor)
-	nbPodByNodeMigration := 1 + *cluster.Spec.ReplicationFactor
-	nbPodToCreate := nbRequirePodForSpec + nbPodByNodeMigration - cluster.Status.Cluster.NbPods
-	if nbPodToCreate > 0 {
-		for i := int32(0); i < nbPodToCreate; i++ {
-			_, err := c.podControl.CreatePod(cluster, cluster.Status.Cluster.NbPods)
-			if err != nil {
-				return false, err
-			}
-		}
-		return true, nil
-	}
-
 	clusterPodSpecHash, err := podctrl.GenerateMD5Spec(&cluster.Spec.PodTemplate.Spec)
 	if err != nil {
 		return false, err
@@ -106,7 +90,41 @@ func (c *Controller) manageRollingUpdate(admin redis.AdminInterface, cluster *ra
 	newMasterNodes, newSlaveNodes, newNoneNodes := clustering.ClassifyNodesByRole(newNodes)
 	oldMasterNodes, oldSlaveNodes, _ := clustering.ClassifyNodesByRole(oldNodes)
 
-	selectedMasters, selectedNewMasters, err := clustering.SelectMastersToReplace(oldMasterNodes, newMasterNodes, newNoneNodes, *cluster.Spec.NumberOfMaster, 1)
+	rollingUpdateStatus := buildRollingUpdateStatus(clusterPodSpecHash, oldMasterNodes, newMasterNodes, isRollingUpdatePaused(cluster))
+	if !reflect.DeepEqual(cluster.Status.RollingUpdate, rollingUpdateStatus) {
+		cluster.Status.RollingUpdate = rollingUpdateStatus
+		if _, err = c.updateHandler(cluster); err != nil {
+			return false, err
+		}
+		// requeue in order to continue the rolling update with the stored RedisCluster
+		return true, nil
+	}
+
+	if isRollingUpdatePaused(cluster) {
+		glog.V(3).Infof("rolling update paused for cluster %s/%s", cluster.Namespace, cluster.Name)
+		return false, nil
+	}
+
+	nbShards := nbShardsToReplace(cluster, int32(len(newMasterNodes)))
+	if nbShards == 0 {
+		glog.V(3).Infof("rolling update partition reached for cluster %s/%s", cluster.Namespace, cluster.Name)
+		return false, nil
+	}
+
+	nbRequirePodForSpec := *cluster.Spec.NumberOfMaster * (1 + *cluster.Spec.ReplicationFactor)
+	nbPodByNodeMigration := nbShards * (1 + *cluster.Spec.ReplicationFactor)
+	nbPodToCreate := nbRequirePodForSpec + nbPodByNodeMigration - cluster.Status.Cluster.NbPods
+	if nbPodToCreate > 0 {
+		for i := int32(0); i < nbPodToCreate; i++ {
+			_, err := c.podControl.CreatePod(cluster, cluster.Status.Cluster.NbPods)
+			if err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	selectedMasters, selectedNewMasters, err := clustering.SelectMastersToReplace(oldMasterNodes, newMasterNodes, newNoneNodes, *cluster.Spec.NumberOfMaster, nbShards)
 	if err != nil {
 		return false, err
 	}
@@ -142,7 +160,7 @@ func (c *Controller) manageRollingUpdate(admin redis.AdminInterface, cluster *ra
 
 	removedMasters, removeSlaves := getOldNodesToRemove(currentMasters, selectedMasters, nodes)
 
-	nodesToDelete, err := detachAndForgetNodes(admin, removedMasters, removeSlaves)
+	nodesToDelete, err := c.detachAndForgetNodes(admin, cluster, removedMasters, removeSlaves)
 	if err != nil {
 		glog.Error("Unable to detach and forhet old masters and associated slaves, err:", err)
 		return false, err
@@ -158,8 +176,174 @@ func (c *Controller) manageRollingUpdate(admin redis.AdminInterface, cluster *ra
 	return false, nil
 }
 
+// inPlaceFailoverMaxLag is the maximum replication lag (in bytes) accepted to consider a slave in sync with its master
+const inPlaceFailoverMaxLag int64 = 1024
+
+// manageInPlaceFailoverUpdate used to replace the cluster nodes without slot migration: for each shard the slaves are replaced first,
+// then a new slave is promoted with a CLUSTER FAILOVER once the replication is in sync, and finally the old master is removed.
+// The shards are updated one at a time, only one extra pod is created at each step.
+func (c *Controller) manageInPlaceFailoverUpdate(admin redis.AdminInterface, cluster *rapi.RedisCluster, nodes redis.Nodes) (bool, error) {
+	clusterPodSpecHash, err := podctrl.GenerateMD5Spec(&cluster.Spec.PodTemplate.Spec)
+	if err != nil {
+		return false, err
+	}
+	updatedMasters, outdatedMasters := classifyShardsByPodSpec(clusterPodSpecHash, nodes)
+
+	rollingUpdateStatus := buildRollingUpdateStatus(clusterPodSpecHash, outdatedMasters, updatedMasters, isRollingUpdatePaused(cluster))
+	if !reflect.DeepEqual(cluster.Status.RollingUpdate, rollingUpdateStatus) {
+		cluster.Status.RollingUpdate = rollingUpdateStatus
+		if _, err = c.updateHandler(cluster); err != nil {
+			return false, err
+		}
+		// requeue in order to continue the update with the stored RedisCluster
+		return true, nil
+	}
+
+	if isRollingUpdatePaused(cluster) {
+		glog.V(3).Infof("in-place failover update paused for cluster %s/%s", cluster.Namespace, cluster.Name)
+		return false, nil
+	}
+	if nbShardsToReplace(cluster, int32(len(updatedMasters))) == 0 || len(outdatedMasters) == 0 {
+		glog.V(3).Infof("in-place failover update partition reached for cluster %s/%s", cluster.Namespace, cluster.Name)
+		return false, nil
+	}
+
+	master := selectShardToUpdate(clusterPodSpecHash, outdatedMasters)
+	slaves := nodes.FilterByFunc(func(n *redis.Node) bool {
+		return redis.IsSlave(n) && n.MasterReferent == master.ID
+	})
+	newSlaves := slaves.FilterByFunc(func(n *redis.Node) bool {
+		return n.Pod != nil && comparePodSpecMD5Hash(clusterPodSpecHash, n.Pod)
+	})
+	oldSlaves := slaves.FilterByFunc(func(n *redis.Node) bool {
+		return n.Pod == nil || !comparePodSpecMD5Hash(clusterPodSpecHash, n.Pod)
+	})
+	masterUpdated := master.Pod != nil && comparePodSpecMD5Hash(clusterPodSpecHash, master.Pod)
+
+	// first, add a slave running the new PodTemplate to the shard
+	if len(newSlaves) == 0 || (len(oldSlaves) > 0 && int32(len(slaves)) <= *cluster.Spec.ReplicationFactor) {
+		return c.attachNewSlaveToMaster(admin, cluster, clusterPodSpecHash, master, nodes)
+	}
+
+	// all the new slaves need to be in sync with the master before removing a node of the shard
+	for _, slave := range newSlaves {
+		inSync, err := isSlaveInSync(admin, master, slave)
+		if err != nil {
+			return false, err
+		}
+		if !inSync {
+			glog.V(3).Infof("in-place failover update, waiting for slave %s to be in sync with master %s", slave.ID, master.ID)
+			return true, nil
+		}
+	}
+
+	// then, remove the old slaves one by one
+	if len(oldSlaves) > 0 {
+		oldSlave := oldSlaves[0]
+		glog.Infof("in-place failover update, removing old slave %s of master %s", oldSlave.ID, master.ID)
+		removed, err := c.detachAndForgetNodes(admin, cluster, redis.Nodes{}, redis.Nodes{oldSlave})
+		if err != nil {
+			return false, err
+		}
+		if len(removed) == 0 {
+			return false, fmt.Errorf("the forget of the old slave %s has been refused by the guard rails", oldSlave.ID)
+		}
+		if oldSlave.Pod != nil {
+			if err = c.podControl.DeletePod(cluster, oldSlave.Pod.Name); err != nil {
+				glog.Errorf("unable to delete the pod %s/%s, err:%v", oldSlave.Pod.Namespace, oldSlave.Pod.Name, err)
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	// finally, promote a new slave, the old master becomes a slave and it will be removed at the next step
+	if !masterUpdated {
+		glog.Infof("in-place failover update, failover of master %s", master.ID)
+		if err = admin.StartFailover(master.IPPort(), nil); err != nil {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, clustering.FailoverFailedEventReason, "Unable to failover master %s (slots %s) during the in-place update: %v", clustering.NodeDescription(master), clustering.FormatSlots(master.Slots), err)
+			return false, err
+		}
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, clustering.FailoverEventReason, "Failover of master %s (slots %s) started, the in-place update promotes an updated slave", clustering.NodeDescription(master), clustering.FormatSlots(master.Slots))
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// attachNewSlaveToMaster attach a node without slot running the new PodTemplate to the master, or create a new pod if none is available
+func (c *Controller) attachNewSlaveToMaster(admin redis.AdminInterface, cluster *rapi.RedisCluster, podSpecHash string, master *redis.Node, nodes redis.Nodes) (bool, error) {
+	freeNodes := nodes.FilterByFunc(func(n *redis.Node) bool {
+		return redis.IsMasterWithNoSlot(n) && n.Pod != nil && comparePodSpecMD5Hash(podSpecHash, n.Pod)
+	})
+	if len(freeNodes) > 0 {
+		glog.Infof("in-place failover update, attaching new slave %s to master %s", freeNodes[0].ID, master.ID)
+		if err := admin.AttachSlaveToMaster(freeNodes[0], master); err != nil {
+			glog.Errorf("unable to attach the slave %s to the master %s, err:%v", freeNodes[0].ID, master.ID, err)
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, clustering.SlaveAttachFailedEventReason, "Unable to attach node %s to master %s: %v", clustering.NodeDescription(freeNodes[0]), clustering.NodeDescription(master), err)
+			return false, err
+		}
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, clustering.SlaveAttachedEventReason, "Node %s attached as slave of master %s", clustering.NodeDescription(freeNodes[0]), clustering.NodeDescription(master))
+		return true, nil
+	}
+
+	nbRequirePodForSpec := *cluster.Spec.NumberOfMaster * (1 + *cluster.Spec.ReplicationFactor)
+	if cluster.Status.Cluster.NbPods <= nbRequirePodForSpec {
+		if _, err := c.podControl.CreatePod(cluster, cluster.Status.Cluster.NbPods); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// classifyShardsByPodSpec returns the masters with slots for which the master and all its slaves run the PodTemplate
+// corresponding to the hash, and the other masters with slots. Both lists are sorted by node ID.
+func classifyShardsByPodSpec(podSpecHash string, nodes redis.Nodes) (updatedMasters, outdatedMasters redis.Nodes) {
+	updatedMasters = redis.Nodes{}
+	outdatedMasters = redis.Nodes{}
+	isUpdated := func(n *redis.Node) bool {
+		return n.Pod != nil && comparePodSpecMD5Hash(podSpecHash, n.Pod)
+	}
+	for _, master := range nodes.FilterByFunc(redis.IsMasterWithSlot).SortNodes() {
+		nbOutdatedSlaves := nodes.CountByFunc(func(n *redis.Node) bool {
+			return redis.IsSlave(n) && n.MasterReferent == master.ID && !isUpdated(n)
+		})
+		if isUpdated(master) && nbOutdatedSlaves == 0 {
+			updatedMasters = append(updatedMasters, master)
+		} else {
+			outdatedMasters = append(outdatedMasters, master)
+		}
+	}
+	return updatedMasters, outdatedMasters
+}
+
+// selectShardToUpdate returns the master of the shard to update among the outdated ones. The failover of a shard
+// changes its master ID and may reorder the shards: a shard whose master is already updated is being updated, it is
+// finished before another shard is started.
+func selectShardToUpdate(podSpecHash string, outdatedMasters redis.Nodes) *redis.Node {
+	for _, master := range outdatedMasters {
+		if master.Pod != nil && comparePodSpecMD5Hash(podSpecHash, master.Pod) {
+			return master
+		}
+	}
+	return outdatedMasters[0]
+}
+
+// isSlaveInSync returns true if the replication link between the slave and the master is up and the lag is acceptable
+func isSlaveInSync(admin redis.AdminInterface, master, slave *redis.Node) (bool, error) {
+	masterInfo, err := admin.GetReplicationInfo(master.IPPort())
+	if err != nil {
+		return false, err
+	}
+	slaveInfo, err := admin.GetReplicationInfo(slave.IPPort())
+	if err != nil {
+		return false, err
+	}
+	return slaveInfo.IsLinkUp() && slaveInfo.Lag(masterInfo) <= inPlaceFailoverMaxLag, nil
+}
+
 // managePodScaleDown used to manage properly the scale down of a cluster
-func (c *Controller) managePodScaleDown(admin redis.AdminInterface, cluster *rapi.RedisCluster, rCluster *redis.Cluster, nodes redis.Nodes, currentPods int32) (bool, error) {
+func (c *Controller) managePodScaleDown(admin redis.AdminInterface, cluster *rapi.RedisCluster, rCluster *redis.Cluster, nodes redis.Nodes) (bool, error) {
 	glog.V(6).Info("managePodScaleDown START")
 	defer glog.V(6).Info("managePodScaleDown STOP")
 
@@ -168,9 +352,6 @@ func (c *Controller) managePodScaleDown(admin redis.AdminInterface, cluster *rap
 			if err := c.podControl.DeletePod(cluster, node.PodName); err != nil {
 				return false, err
 			}
-			if err := c.serviceControl.RemoveRedisPodService(cluster, currentPods); err != nil {
-				return false, err
-			}
 		}
 	}
 
@@ -192,6 +373,7 @@ func (c *Controller) managePodScaleDown(admin redis.AdminInterface, cluster *rap
 					errGlobal = err
 					continue
 				}
+				c.recorder.Eventf(cluster, apiv1.EventTypeNormal, clustering.SlaveDetachedEventReason, "Slave %s detached, its master %s is itself a slave", clustering.NodeDescription(node), slave.MasterRef)
 				if err := c.podControl.DeletePod(cluster, slave.PodName); err != nil {
 					glog.Errorf("unable to delete the pod %s corresponding to the Slave Node with the ID: %s", slave.PodName, node.ID)
 					errGlobal = err
@@ -227,7 +409,7 @@ func (c *Controller) managePodScaleDown(admin redis.AdminInterface, cluster *rap
 
 		removedMasters, removeSlaves := getOldNodesToRemove(curMasters, newMasters, nodes)
 
-		if _, err := detachAndForgetNodes(admin, removedMasters, removeSlaves); err != nil {
+		if _, err := c.detachAndForgetNodes(admin, cluster, removedMasters, removeSlaves); err != nil {
 			glog.Error("Unable to detach and forhet old masters and associated slaves, err:", err)
 			return false, err
 		}
@@ -255,7 +437,10 @@ func (c *Controller) managePodScaleDown(admin redis.AdminInterface, cluster *rap
 					if err = admin.AttachSlaveToMaster(node, master); err != nil {
 						glog.Errorf("error during manageScaleDown")
 						errorAppends += fmt.Sprintf("[%s]err:%s ,", node.ID, err)
+						c.recorder.Eventf(cluster, apiv1.EventTypeWarning, clustering.SlaveAttachFailedEventReason, "Unable to attach node %s to master %s: %v", clustering.NodeDescription(node), clustering.NodeDescription(master), err)
+						continue
 					}
+					c.recorder.Eventf(cluster, apiv1.EventTypeNormal, clustering.SlaveAttachedEventReason, "Node %s attached as slave of master %s", clustering.NodeDescription(node), clustering.NodeDescription(master))
 				}
 
 				if errorAppends != "" {
@@ -271,7 +456,9 @@ func (c *Controller) managePodScaleDown(admin redis.AdminInterface, cluster *rap
 				}
 				errs := []error{}
 				for _, rNode := range podsToDeletion {
-					admin.DetachSlave(rNode)
+					if err := admin.DetachSlave(rNode); err == nil {
+						c.recorder.Eventf(cluster, apiv1.EventTypeNormal, clustering.SlaveDetachedEventReason, "Slave %s of master %s detached, the master has too many slaves", clustering.NodeDescription(rNode), idMaster)
+					}
 					if rNode.Pod != nil {
 						if err := c.podControl.DeletePod(cluster, rNode.Pod.Name); err != nil {
 							errs = append(errs, err)
@@ -286,6 +473,36 @@ func (c *Controller) managePodScaleDown(admin redis.AdminInterface, cluster *rap
 	return false, nil
 }
 
+// buildRollingUpdateStatus returns the rolling update progression of each shard
+func buildRollingUpdateStatus(podSpecHash string, oldMasterNodes, newMasterNodes redis.Nodes, paused bool) *rapi.RedisClusterRollingUpdateStatus {
+	status := &rapi.RedisClusterRollingUpdateStatus{
+		PodSpecMD5:      podSpecHash,
+		NbShards:        int32(len(oldMasterNodes) + len(newMasterNodes)),
+		NbUpdatedShards: int32(len(newMasterNodes)),
+		Paused:          paused,
+	}
+	addShards := func(masters redis.Nodes, updated bool) {
+		for _, master := range masters {
+			shard := rapi.RedisClusterShardStatus{
+				MasterID: master.ID,
+				Updated:  updated,
+			}
+			if master.Pod != nil {
+				shard.PodName = master.Pod.Name
+			}
+			for _, slot := range redis.SlotRangesFromSlots(master.Slots) {
+				shard.Slots = append(shard.Slots, slot.String())
+			}
+			status.Shards = append(status.Shards, shard)
+		}
+	}
+	addShards(newMasterNodes, true)
+	addShards(oldMasterNodes, false)
+	sort.Slice(status.Shards, func(i, j int) bool { return status.Shards[i].MasterID < status.Shards[j].MasterID })
+
+	return status
+}
+
 func getOldNodesToRemove(curMasters, newMasters, nodes redis.Nodes) (removedMasters, removeSlaves redis.Nodes) {
 	removedMasters = redis.Nodes{}
 	for _, node := range curMasters {
@@ -309,18 +526,30 @@ func getOldNodesToRemove(curMasters, newMasters, nodes redis.Nodes) (removedMast
 	return removedMasters, removeSlaves
 }
 
-func detachAndForgetNodes(admin redis.AdminInterface, masters, slaves redis.Nodes) (redis.Nodes, error) {
+// detachAndForgetNodes detaches the slaves and makes the cluster forget the nodes, returns the nodes whose pod can be
+// deleted: the nodes whose forget is refused by a guard rail are still known by the cluster and are not returned
+func (c *Controller) detachAndForgetNodes(admin redis.AdminInterface, cluster *rapi.RedisCluster, masters, slaves redis.Nodes) (redis.Nodes, error) {
 	for _, node := range slaves {
 		if err := admin.DetachSlave(node); err != nil {
 			glog.Errorf("unable to detach the slave with ID:%s, err:%v", node.ID, err)
+			continue
 		}
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, clustering.SlaveDetachedEventReason, "Slave %s of master %s detached before its removal", clustering.NodeDescription(node), node.MasterReferent)
 	}
 
-	removedNodes := append(masters, slaves...)
-	for _, node := range removedNodes {
+	removedNodes := redis.Nodes{}
+	for _, node := range append(masters, slaves...) {
 		if err := admin.ForgetNode(node.ID); err != nil {
 			glog.Errorf("unable to forger the node with ID:%s, err:%v", node.ID, err)
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, clustering.ForgetNodeFailedEventReason, "Unable to forget node %s: %v", clustering.NodeDescription(node), err)
+			if redis.IsGuardRailError(err) {
+				// the node may still own slots, its pod is kept
+				continue
+			}
+		} else {
+			c.recorder.Eventf(cluster, apiv1.EventTypeNormal, clustering.NodeForgottenEventReason, "Node %s removed from the cluster", clustering.NodeDescription(node))
 		}
+		removedNodes = append(removedNodes, node)
 	}
 	return removedNodes, nil
 }
@@ -409,8 +638,12 @@ func (c *Controller) applyConfiguration(admin redis.AdminInterface, cluster *rap
 		glog.Errorf("Unable to create the RedisCluster view, error:%v", err)
 		return false, err
 	}
+	defer clustering.RecordActions(c.recorder, cluster, rCluster)
 
 	if needRollingUpdate(cluster) {
+		if wait, err := c.holdDisruptiveAction(cluster, rollingUpdateApprovalRequest(cluster)); wait || err != nil {
+			return false, err
+		}
 		if setRollingUpdategCondition(&cluster.Status, true) {
 			if cluster, err = c.updateHandler(cluster); err != nil {
 				return false, err
@@ -418,22 +651,29 @@ func (c *Controller) applyConfiguration(admin redis.AdminInterface, cluster *rap
 		}
 
 		glog.Info("applyConfiguration needRollingUpdate")
+		if getUpdateStrategyType(cluster) == rapi.InPlaceFailoverStrategyType {
+			return c.manageInPlaceFailoverUpdate(admin, cluster, nodes)
+		}
 		return c.manageRollingUpdate(admin, cluster, rCluster, nodes)
 	}
-	if setRollingUpdategCondition(&cluster.Status, false) {
+	if setRollingUpdategCondition(&cluster.Status, false) || cluster.Status.RollingUpdate != nil {
+		cluster.Status.RollingUpdate = nil
 		if cluster, err = c.updateHandler(cluster); err != nil {
 			return false, err
 		}
 	}
 
-	if need, currentPods := needLessPods(cluster); need {
+	if need, _ := needLessPods(cluster); need {
+		if wait, err := c.holdDisruptiveAction(cluster, scaleDownApprovalRequest(cluster)); wait || err != nil {
+			return false, err
+		}
 		if setRebalancingCondition(&cluster.Status, true) {
 			if cluster, err = c.updateHandler(cluster); err != nil {
 				return false, err
 			}
 		}
 		glog.Info("applyConfiguration needLessPods")
-		return c.managePodScaleDown(admin, cluster, rCluster, nodes, currentPods)
+		return c.managePodScaleDown(admin, cluster, rCluster, nodes)
 	}
 	if setRebalancingCondition(&cluster.Status, false) {
 		if cluster, err = c.updateHandler(cluster); err != nil {
@@ -457,6 +697,11 @@ func (c *Controller) applyConfiguration(admin redis.AdminInterface, cluster *rap
 	if len(newMasters) != len(curMasters) {
 		asChanged = true
 	}
+	if isRebalance(newMasters, curMasters) {
+		if wait, err := c.holdDisruptiveAction(cluster, rebalanceApprovalRequest(newMasters, curMasters)); wait || err != nil {
+			return false, err
+		}
+	}
 
 	// Second select Node that is already a slave
 	currentSlaveNodes := nodes.FilterByFunc(redis.IsSlave)
@@ -10,6 +10,7 @@ import (
 	"github.com/zh168654/Redis-Operator/pkg/redis/fake/admin"
 	kapiv1 "k8s.io/api/core/v1"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 func Test_searchAvailableSlaveForMasterID(t *testing.T) {
@@ -362,6 +363,7 @@ func TestController_applyConfiguration(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Controller{
 				updateHandler: func(rc *rapi.RedisCluster) (*rapi.RedisCluster, error) { return rc, nil },
+				recorder:      record.NewFakeRecorder(100),
 			}
 			fakeAdmin := admin.NewFakeAdmin(nodesAddr)
 			tt.args.updateFakeAdminFunc(fakeAdmin)
@@ -420,3 +422,164 @@ func Test_getOldNodesToRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestController_detachAndForgetNodes(t *testing.T) {
+	redis1 := &redis.Node{ID: "redis1", Role: "master", IP: "10.0.0.1", Pod: newPod("pod1", "node1")}
+	redis2 := &redis.Node{ID: "redis2", Role: "slave", MasterReferent: "redis3", IP: "10.0.0.2", Pod: newPod("pod2", "node2")}
+	fakeAdmin := admin.NewFakeAdmin([]string{"10.0.0.1:6379", "10.0.0.2:6379"})
+	fakeAdmin.ForgetNodeRet["redis1"] = &redis.GuardRailError{Rule: rapi.GuardRailForgetNodeWithSlots, Target: "redis1", Message: "redis1 still owns slots"}
+	c := &Controller{recorder: record.NewFakeRecorder(10)}
+
+	removed, err := c.detachAndForgetNodes(fakeAdmin, &rapi.RedisCluster{}, redis.Nodes{redis1}, redis.Nodes{redis2})
+	if err != nil {
+		t.Fatalf("detachAndForgetNodes() error = %v", err)
+	}
+	if !reflect.DeepEqual(removed, redis.Nodes{redis2}) {
+		t.Errorf("detachAndForgetNodes() = %v, want the node whose forget is refused kept", removed)
+	}
+}
+
+func Test_classifyShardsByPodSpec(t *testing.T) {
+	newPodWithHash := func(name, hash string) *kapiv1.Pod {
+		pod := newPod(name, "node")
+		pod.Annotations = map[string]string{rapi.PodSpecMD5LabelKey: hash}
+		return pod
+	}
+	master1 := &redis.Node{ID: "master1", Role: "master", Pod: newPodWithHash("pod1", "new"), Slots: []redis.Slot{1}}
+	slave1 := &redis.Node{ID: "slave1", Role: "slave", MasterReferent: "master1", Pod: newPodWithHash("pod2", "new")}
+	master2 := &redis.Node{ID: "master2", Role: "master", Pod: newPodWithHash("pod3", "new"), Slots: []redis.Slot{2}}
+	slave2 := &redis.Node{ID: "slave2", Role: "slave", MasterReferent: "master2", Pod: newPodWithHash("pod4", "old")}
+	master3 := &redis.Node{ID: "master3", Role: "master", Pod: newPodWithHash("pod5", "old"), Slots: []redis.Slot{3}}
+	slave3 := &redis.Node{ID: "slave3", Role: "slave", MasterReferent: "master3", Pod: newPodWithHash("pod6", "new")}
+	free := &redis.Node{ID: "free", Role: "master", Pod: newPodWithHash("pod7", "new")}
+
+	tests := []struct {
+		name         string
+		nodes        redis.Nodes
+		wantUpdated  redis.Nodes
+		wantOutdated redis.Nodes
+	}{
+		{
+			name:         "empty",
+			nodes:        redis.Nodes{},
+			wantUpdated:  redis.Nodes{},
+			wantOutdated: redis.Nodes{},
+		},
+		{
+			name:         "master and slaves updated",
+			nodes:        redis.Nodes{slave1, master1, free},
+			wantUpdated:  redis.Nodes{master1},
+			wantOutdated: redis.Nodes{},
+		},
+		{
+			name:         "outdated slave or outdated master",
+			nodes:        redis.Nodes{master3, slave3, slave2, master2, slave1, master1, free},
+			wantUpdated:  redis.Nodes{master1},
+			wantOutdated: redis.Nodes{master2, master3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUpdated, gotOutdated := classifyShardsByPodSpec("new", tt.nodes)
+			if !reflect.DeepEqual(gotUpdated, tt.wantUpdated) {
+				t.Errorf("classifyShardsByPodSpec() gotUpdated = %v, want %v", gotUpdated, tt.wantUpdated)
+			}
+			if !reflect.DeepEqual(gotOutdated, tt.wantOutdated) {
+				t.Errorf("classifyShardsByPodSpec() gotOutdated = %v, want %v", gotOutdated, tt.wantOutdated)
+			}
+		})
+	}
+}
+
+func Test_selectShardToUpdate(t *testing.T) {
+	newPodWithHash := func(name, hash string) *kapiv1.Pod {
+		pod := newPod(name, "node")
+		pod.Annotations = map[string]string{rapi.PodSpecMD5LabelKey: hash}
+		return pod
+	}
+	// the shard of master "b" is being updated: the failover promoted the updated slave "z", "b" is an outdated slave
+	masterA := &redis.Node{ID: "a", Role: "master", Pod: newPodWithHash("pod1", "old"), Slots: []redis.Slot{1}}
+	slaveA := &redis.Node{ID: "a-slave", Role: "slave", MasterReferent: "a", Pod: newPodWithHash("pod2", "old")}
+	masterZ := &redis.Node{ID: "z", Role: "master", Pod: newPodWithHash("pod3", "new"), Slots: []redis.Slot{2}}
+	oldMasterB := &redis.Node{ID: "b", Role: "slave", MasterReferent: "z", Pod: newPodWithHash("pod4", "old")}
+
+	tests := []struct {
+		name  string
+		nodes redis.Nodes
+		want  *redis.Node
+	}{
+		{
+			name:  "no shard started",
+			nodes: redis.Nodes{masterA, slaveA, &redis.Node{ID: "b", Role: "master", Pod: newPodWithHash("pod4", "old"), Slots: []redis.Slot{2}}},
+			want:  masterA,
+		},
+		{
+			name:  "failover reordering the shards",
+			nodes: redis.Nodes{masterZ, oldMasterB, slaveA, masterA},
+			want:  masterZ,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, outdatedMasters := classifyShardsByPodSpec("new", tt.nodes)
+			if got := selectShardToUpdate("new", outdatedMasters); got != tt.want {
+				t.Errorf("selectShardToUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isSlaveInSync(t *testing.T) {
+	master := &redis.Node{ID: "master", Role: "master", IP: "10.0.0.1", Port: "6379", Slots: []redis.Slot{1}}
+	slave := &redis.Node{ID: "slave", Role: "slave", IP: "10.0.0.2", Port: "6379", MasterReferent: "master"}
+
+	tests := []struct {
+		name       string
+		masterInfo *redis.ReplicationInfo
+		slaveInfo  *redis.ReplicationInfo
+		slaveErr   error
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name:       "in sync",
+			masterInfo: &redis.ReplicationInfo{Role: "master", MasterReplOffset: 2000},
+			slaveInfo:  &redis.ReplicationInfo{Role: "slave", MasterLinkStatus: redis.RedisMasterLinkStatusUp, SlaveReplOffset: 1990},
+			want:       true,
+		},
+		{
+			name:       "link down",
+			masterInfo: &redis.ReplicationInfo{Role: "master", MasterReplOffset: 2000},
+			slaveInfo:  &redis.ReplicationInfo{Role: "slave", MasterLinkStatus: redis.RedisMasterLinkStatusDown, SlaveReplOffset: 2000},
+			want:       false,
+		},
+		{
+			name:       "lag too important",
+			masterInfo: &redis.ReplicationInfo{Role: "master", MasterReplOffset: 200000},
+			slaveInfo:  &redis.ReplicationInfo{Role: "slave", MasterLinkStatus: redis.RedisMasterLinkStatusUp, SlaveReplOffset: 1000},
+			want:       false,
+		},
+		{
+			name:       "slave unreachable",
+			masterInfo: &redis.ReplicationInfo{Role: "master", MasterReplOffset: 2000},
+			slaveErr:   fmt.Errorf("connection refused"),
+			want:       false,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAdmin := admin.NewFakeAdmin([]string{master.IPPort(), slave.IPPort()})
+			fakeAdmin.GetReplicationInfoRet[master.IPPort()] = admin.ReplicationInfoRetType{Info: tt.masterInfo}
+			fakeAdmin.GetReplicationInfoRet[slave.IPPort()] = admin.ReplicationInfoRetType{Info: tt.slaveInfo, Err: tt.slaveErr}
+			got, err := isSlaveInSync(fakeAdmin, master, slave)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("isSlaveInSync() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("isSlaveInSync() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
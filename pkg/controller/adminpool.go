@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/zh168654/Redis-Operator/pkg/config"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+// adminPool keeps the admin connections to the redis nodes of each RedisCluster across the reconciliations,
+// instead of connecting to all the pods at each sync
+type adminPool struct {
+	mutex            sync.Mutex
+	admins           map[string]*pooledAdmin
+	newAdmin         func(addrs []string) redis.AdminInterface // builds the admin of a new cluster. Added as member for testing
+	newExternalAdmin func(addrs []string) redis.AdminInterface // builds the admin of a redis not managed by the operator. Added as member for testing
+}
+
+// pooledAdmin is the admin of a RedisCluster stored in the pool
+type pooledAdmin struct {
+	// mutex is held by the sync of the cluster or by the health check while they use the admin
+	mutex    sync.Mutex
+	admin    redis.AdminInterface
+	users    int
+	lastUsed time.Time
+}
+
+// newAdminPool builds and returns a new adminPool, the admins are created with the redis configuration
+func newAdminPool(cfg *config.Redis) *adminPool {
+	options := newAdminOptions(cfg)
+	externalOptions := newExternalAdminOptions(cfg)
+	return &adminPool{
+		admins: map[string]*pooledAdmin{},
+		newAdmin: func(addrs []string) redis.AdminInterface {
+			return redis.NewAdmin(addrs, options)
+		},
+		newExternalAdmin: func(addrs []string) redis.AdminInterface {
+			return redis.NewAdmin(addrs, externalOptions)
+		},
+	}
+}
+
+// Get returns the admin of the RedisCluster identified by key, connected to the redis nodes of the pods:
+// the connections to the nodes that are gone are closed and the new nodes are connected.
+// The admin must be given back with Release once the sync of the cluster is done.
+func (p *adminPool) Get(key string, pods []*apiv1.Pod) redis.AdminInterface {
+	return p.get(key, getPodsAddrs(pods), p.newAdmin)
+}
+
+// GetExternal returns the admin identified by key, connected to the addresses of a redis not managed by the operator,
+// like the source of a standby cluster or of an import. The admin must be given back with Release.
+func (p *adminPool) GetExternal(key string, addrs []string) redis.AdminInterface {
+	return p.get(key, addrs, p.newExternalAdmin)
+}
+
+func (p *adminPool) get(key string, addrs []string, newAdmin func(addrs []string) redis.AdminInterface) redis.AdminInterface {
+	p.mutex.Lock()
+	entry, ok := p.admins[key]
+	if !ok {
+		entry = &pooledAdmin{}
+		p.admins[key] = entry
+	}
+	entry.users++
+	p.mutex.Unlock()
+
+	entry.mutex.Lock()
+	if entry.admin == nil {
+		glog.V(3).Infof("creating the admin connections of cluster %s", key)
+		entry.admin = newAdmin(addrs)
+		return entry.admin
+	}
+	updateConnections(entry.admin.Connections(), addrs)
+	return entry.admin
+}
+
+// Release gives back the admin of the RedisCluster identified by key to the pool
+func (p *adminPool) Release(key string) {
+	p.mutex.Lock()
+	entry, ok := p.admins[key]
+	p.mutex.Unlock()
+	if ok {
+		p.release(entry)
+	}
+}
+
+func (p *adminPool) release(entry *pooledAdmin) {
+	entry.mutex.Unlock()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	entry.users--
+	entry.lastUsed = time.Now()
+}
+
+// Close closes the connections of the RedisCluster identified by key and removes it from the pool
+func (p *adminPool) Close(key string) {
+	p.mutex.Lock()
+	entry, ok := p.admins[key]
+	delete(p.admins, key)
+	p.mutex.Unlock()
+	if !ok {
+		return
+	}
+	// wait for the end of a running health check
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	if entry.admin != nil {
+		glog.V(3).Infof("closing the admin connections of cluster %s", key)
+		entry.admin.Close()
+	}
+}
+
+// CheckIdleConnections sends a PING on the connections of the admins not used since the interval,
+// the connections that don't answer, even after a reconnection, are removed from the pool
+func (p *adminPool) CheckIdleConnections(interval time.Duration) {
+	idles := map[string]*pooledAdmin{}
+	p.mutex.Lock()
+	for key, entry := range p.admins {
+		if entry.users == 0 && time.Since(entry.lastUsed) >= interval {
+			entry.users++
+			idles[key] = entry
+		}
+	}
+	p.mutex.Unlock()
+
+	for key, entry := range idles {
+		entry.mutex.Lock()
+		if entry.admin != nil {
+			cnx := entry.admin.Connections()
+			for addr, c := range cnx.GetAll() {
+				if err := cnx.ValidateResp(c.Cmd("PING"), addr, "Idle connection health check failed"); err != nil {
+					glog.V(3).Infof("removing the connection to %s from the pool of cluster %s", addr, key)
+					cnx.Remove(addr)
+				}
+			}
+		}
+		p.release(entry)
+	}
+}
+
+// updateConnections connects to the new addresses and closes the connections to the addresses not in the list anymore.
+// If no connection is left, all the connections are rebuilt.
+func updateConnections(cnx redis.AdminConnectionsInterface, addrs []string) {
+	if len(cnx.GetAll()) == 0 {
+		cnx.ReplaceAll(addrs)
+		return
+	}
+	wanted := map[string]bool{}
+	for _, addr := range addrs {
+		wanted[addr] = true
+		if _, ok := cnx.GetAll()[addr]; !ok {
+			if err := cnx.Add(addr); err != nil {
+				glog.V(3).Infof("unable to connect to %s: %v", addr, err)
+			}
+		}
+	}
+	for addr := range cnx.GetAll() {
+		if !wanted[addr] {
+			cnx.Remove(addr)
+		}
+	}
+}
+
+// getPodsAddrs returns the redis address of each pod, the pods without IP yet are skipped
+func getPodsAddrs(pods []*apiv1.Pod) []string {
+	addrs := []string{}
+	for _, pod := range pods {
+		if pod.Status.PodIP == "" {
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(pod.Status.PodIP, getRedisPort(pod)))
+	}
+	return addrs
+}
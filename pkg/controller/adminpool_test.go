@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	kapiv1 "k8s.io/api/core/v1"
+
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake/admin"
+)
+
+// recordingConnections is a fake connection map keeping track of the connected addresses
+type recordingConnections struct {
+	*admin.Connections
+	clients  map[string]redis.ClientInterface
+	replaced bool
+}
+
+func (cnx *recordingConnections) Add(addr string) error {
+	cnx.clients[addr] = nil
+	return nil
+}
+
+func (cnx *recordingConnections) Remove(addr string) {
+	delete(cnx.clients, addr)
+}
+
+func (cnx *recordingConnections) ReplaceAll(addrs []string) {
+	cnx.replaced = true
+	cnx.clients = map[string]redis.ClientInterface{}
+	for _, addr := range addrs {
+		cnx.clients[addr] = nil
+	}
+}
+
+func (cnx *recordingConnections) GetAll() map[string]redis.ClientInterface {
+	return cnx.clients
+}
+
+func Test_updateConnections(t *testing.T) {
+	tests := []struct {
+		name         string
+		connected    []string
+		addrs        []string
+		want         []string
+		wantReplaced bool
+	}{
+		{
+			name:      "pods added and removed",
+			connected: []string{"10.0.0.1:6379", "10.0.0.2:6379"},
+			addrs:     []string{"10.0.0.2:6379", "10.0.0.3:6379"},
+			want:      []string{"10.0.0.2:6379", "10.0.0.3:6379"},
+		},
+		{
+			name:      "same pods",
+			connected: []string{"10.0.0.1:6379"},
+			addrs:     []string{"10.0.0.1:6379"},
+			want:      []string{"10.0.0.1:6379"},
+		},
+		{
+			name:         "no connection left",
+			addrs:        []string{"10.0.0.1:6379", "10.0.0.2:6379"},
+			want:         []string{"10.0.0.1:6379", "10.0.0.2:6379"},
+			wantReplaced: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cnx := &recordingConnections{clients: map[string]redis.ClientInterface{}}
+			for _, addr := range tt.connected {
+				cnx.clients[addr] = nil
+			}
+			updateConnections(cnx, tt.addrs)
+			got := []string{}
+			for addr := range cnx.clients {
+				got = append(got, addr)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("updateConnections() connected = %v, want %v", got, tt.want)
+			}
+			if cnx.replaced != tt.wantReplaced {
+				t.Errorf("updateConnections() replaced = %v, want %v", cnx.replaced, tt.wantReplaced)
+			}
+		})
+	}
+}
+
+func Test_adminPool(t *testing.T) {
+	nbCreated := 0
+	pool := &adminPool{
+		admins: map[string]*pooledAdmin{},
+		newAdmin: func(addrs []string) redis.AdminInterface {
+			nbCreated++
+			return admin.NewFakeAdmin(addrs)
+		},
+	}
+	nbExternalCreated := 0
+	pool.newExternalAdmin = func(addrs []string) redis.AdminInterface {
+		nbExternalCreated++
+		return admin.NewFakeAdmin(addrs)
+	}
+	pods := []*kapiv1.Pod{{Status: kapiv1.PodStatus{PodIP: "10.0.0.1"}}}
+
+	first := pool.Get("ns/cluster", pods)
+	pool.Release("ns/cluster")
+	second := pool.Get("ns/cluster", pods)
+	pool.Release("ns/cluster")
+	if first != second || nbCreated != 1 {
+		t.Errorf("the admin of the cluster should be reused across the syncs, %d admins created", nbCreated)
+	}
+
+	pool.Get("ns/cluster", pods)
+	pool.CheckIdleConnections(0)
+	if pool.admins["ns/cluster"].users != 1 {
+		t.Errorf("the health check should skip the admin in use")
+	}
+	pool.Release("ns/cluster")
+	pool.CheckIdleConnections(0)
+	if users := pool.admins["ns/cluster"].users; users != 0 {
+		t.Errorf("the health check should give back the admin, users = %d", users)
+	}
+	pool.CheckIdleConnections(time.Hour)
+
+	pool.Close("ns/cluster")
+	if _, ok := pool.admins["ns/cluster"]; ok {
+		t.Errorf("the admin of the deleted cluster should be removed from the pool")
+	}
+	pool.Get("ns/cluster", pods)
+	pool.Release("ns/cluster")
+	if nbCreated != 2 {
+		t.Errorf("a new admin should be created after the close, %d admins created", nbCreated)
+	}
+
+	external := pool.GetExternal(getSentinelAdminKey("ns/cluster"), []string{"10.1.0.1:26379"})
+	pool.Release(getSentinelAdminKey("ns/cluster"))
+	if external == pool.Get("ns/cluster", pods) || nbExternalCreated != 1 || nbCreated != 2 {
+		t.Errorf("the admin of a redis not managed by the operator should be built with its own options")
+	}
+	pool.Release("ns/cluster")
+}
+
+func Test_getPodsAddrs(t *testing.T) {
+	pods := []*kapiv1.Pod{{Status: kapiv1.PodStatus{PodIP: "10.0.0.1"}}, {Status: kapiv1.PodStatus{}}}
+	if addrs := getPodsAddrs(pods); !reflect.DeepEqual(addrs, []string{"10.0.0.1:6379"}) {
+		t.Errorf("getPodsAddrs() = %v, want the pods without IP skipped", addrs)
+	}
+}
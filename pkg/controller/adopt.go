@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// adoptedEventReason is the reason of the event emitted when the adopted cluster is managed by the operator
+	adoptedEventReason = "Adopted"
+	// adoptionPendingEventReason is the reason of the event emitted when the adopted cluster can't be managed yet
+	adoptionPendingEventReason = "AdoptionPending"
+	// adoptPodFailedEventReason is the reason of the event emitted when a pod selected by spec.adopt can't be adopted
+	adoptPodFailedEventReason = "AdoptPodFailed"
+)
+
+// isAdoptionPending returns true if the pods selected by spec.adopt are not managed yet
+func isAdoptionPending(cluster *rapi.RedisCluster) bool {
+	return cluster.Spec.Adopt != nil && (cluster.Status.Adoption == nil || !cluster.Status.Adoption.Adopted)
+}
+
+// adoptPods labels and owns the pods selected by spec.adopt. Returns true if pods have been adopted.
+func (c *Controller) adoptPods(cluster *rapi.RedisCluster) (bool, error) {
+	if len(cluster.Spec.Adopt.MatchLabels) == 0 && len(cluster.Spec.Adopt.MatchExpressions) == 0 {
+		return false, fmt.Errorf("spec.adopt is empty, it would select all the pods of the namespace")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cluster.Spec.Adopt)
+	if err != nil {
+		return false, err
+	}
+	pods, err := c.podLister.Pods(cluster.Namespace).List(selector)
+	if err != nil {
+		return false, err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	adopted := false
+	var errs []error
+	for i, pod := range pods {
+		if isPodAdopted(cluster, pod) {
+			continue
+		}
+		if _, err = c.podControl.AdoptPod(cluster, pod, int32(i)); err != nil {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, adoptPodFailedEventReason, "Unable to adopt pod %s: %v", pod.Name, err)
+			errs = append(errs, err)
+			continue
+		}
+		glog.Infof("cluster %s/%s, pod %s adopted", cluster.Namespace, cluster.Name, pod.Name)
+		adopted = true
+	}
+	return adopted, errors.NewAggregate(errs)
+}
+
+// manageAdoption marks the cluster as adopted once its topology matches the spec. Until then the operator doesn't
+// run any action on the cluster: no flush, no reset and no slot migration.
+func (c *Controller) manageAdoption(cluster *rapi.RedisCluster, pods []*apiv1.Pod, infos *redis.ClusterInfos) (bool, error) {
+	previous := cluster.Status.Adoption.DeepCopy()
+	if cluster.Status.Adoption == nil {
+		cluster.Status.Adoption = &rapi.RedisClusterAdoptionStatus{}
+	}
+	status := cluster.Status.Adoption
+	status.AdoptedPods = nil
+	for _, pod := range pods {
+		status.AdoptedPods = append(status.AdoptedPods, pod.Name)
+	}
+	sort.Strings(status.AdoptedPods)
+
+	status.Reason = getAdoptionPendingReason(cluster, pods, infos)
+	if status.Reason == "" {
+		now := metav1.Now()
+		status.Adopted = true
+		status.AdoptionTime = &now
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, adoptedEventReason, "%d pods adopted, the cluster is managed by the operator", len(pods))
+	} else if previous == nil || previous.Reason != status.Reason {
+		c.recorder.Eventf(cluster, apiv1.EventTypeWarning, adoptionPendingEventReason, "Adoption pending: %s", status.Reason)
+	}
+
+	if reflect.DeepEqual(previous, status) {
+		return false, nil
+	}
+	_, err := c.updateHandler(cluster)
+	return false, err
+}
+
+// isPodAdopted returns true if the pod is labeled and controlled by the RedisCluster
+func isPodAdopted(cluster *rapi.RedisCluster, pod *apiv1.Pod) bool {
+	if pod.Labels[rapi.ClusterNameLabelKey] != cluster.Name {
+		return false
+	}
+	controllerRef := metav1.GetControllerOf(pod)
+	return controllerRef != nil && controllerRef.UID == cluster.UID
+}
+
+// getAdoptionPendingReason returns why the adopted cluster can't be managed yet, empty if its topology matches the spec
+func getAdoptionPendingReason(cluster *rapi.RedisCluster, pods []*apiv1.Pod, infos *redis.ClusterInfos) string {
+	if len(pods) == 0 {
+		return "no pod selected by spec.adopt"
+	}
+	if infos == nil || infos.Status != redis.ClusterInfosConsistent {
+		return "the nodes don't agree on the cluster configuration"
+	}
+
+	podIPs := map[string]bool{}
+	for _, pod := range pods {
+		podIPs[pod.Status.PodIP] = true
+	}
+	known := map[string]bool{}
+	nbSlots := 0
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil || nodeinfos.Node == nil {
+			continue
+		}
+		for _, node := range append(redis.Nodes{nodeinfos.Node}, nodeinfos.Friends...) {
+			if known[node.ID] || node.HasStatus(redis.NodeStatusFail) {
+				continue
+			}
+			known[node.ID] = true
+			if !podIPs[node.IP] {
+				return fmt.Sprintf("node %s (%s) is not hosted by an adopted pod", node.ID, node.IPPort())
+			}
+			if redis.IsMasterWithSlot(node) {
+				nbSlots += len(node.Slots)
+			}
+		}
+	}
+
+	clusterStatus := &cluster.Status.Cluster
+	if clusterStatus.NbRedisRunning != clusterStatus.NbPods {
+		return fmt.Sprintf("%d adopted pods, but only %d redis nodes in the cluster", clusterStatus.NbPods, clusterStatus.NbRedisRunning)
+	}
+	if nbSlots != redis.HashMaxSlots+1 {
+		return fmt.Sprintf("%d slots assigned, %d expected", nbSlots, redis.HashMaxSlots+1)
+	}
+	if clusterStatus.NumberOfMaster != *cluster.Spec.NumberOfMaster {
+		return fmt.Sprintf("%d masters in the cluster, spec.numberOfMaster is %d", clusterStatus.NumberOfMaster, *cluster.Spec.NumberOfMaster)
+	}
+	if clusterStatus.MinReplicationFactor != *cluster.Spec.ReplicationFactor || clusterStatus.MaxReplicationFactor != *cluster.Spec.ReplicationFactor {
+		return fmt.Sprintf("%d to %d slaves per master in the cluster, spec.replicationFactor is %d", clusterStatus.MinReplicationFactor, clusterStatus.MaxReplicationFactor, *cluster.Spec.ReplicationFactor)
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+func Test_getAdoptionPendingReason(t *testing.T) {
+	newAdoptedPod := func(name, ip string) *kapiv1.Pod {
+		return &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: kapiv1.PodStatus{PodIP: ip}}
+	}
+	allSlots := []redis.Slot{}
+	for slot := redis.Slot(0); slot <= redis.HashMaxSlots; slot++ {
+		allSlots = append(allSlots, slot)
+	}
+	master := newMasterNode("master", "10.0.0.1", allSlots...)
+	slave := redis.NewNode("slave", "10.0.0.2", nil)
+	slave.Port = "6379"
+	slave.Role = "slave"
+	slave.MasterReferent = "master"
+	stranger := redis.NewNode("stranger", "10.0.0.9", nil)
+	stranger.Port = "6379"
+
+	newInfos := func(status string, friends ...*redis.Node) *redis.ClusterInfos {
+		infos := redis.NewClusterInfos()
+		infos.Status = status
+		infos.Infos["10.0.0.1:6379"] = &redis.NodeInfos{Node: master, Friends: append(redis.Nodes{slave}, friends...)}
+		return infos
+	}
+	pods := []*kapiv1.Pod{newAdoptedPod("redis-0", "10.0.0.1"), newAdoptedPod("redis-1", "10.0.0.2")}
+	matchingStatus := rapi.RedisClusterClusterStatus{NumberOfMaster: 1, MinReplicationFactor: 1, MaxReplicationFactor: 1, NbPods: 2, NbRedisRunning: 2}
+
+	tests := []struct {
+		name          string
+		spec          rapi.RedisClusterSpec
+		clusterStatus rapi.RedisClusterClusterStatus
+		pods          []*kapiv1.Pod
+		infos         *redis.ClusterInfos
+		want          string
+	}{
+		{
+			name:  "no pod",
+			spec:  rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(1), ReplicationFactor: rapi.NewInt32(1)},
+			infos: newInfos(redis.ClusterInfosConsistent),
+			want:  "no pod",
+		},
+		{
+			name:          "inconsistent cluster",
+			spec:          rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(1), ReplicationFactor: rapi.NewInt32(1)},
+			clusterStatus: matchingStatus,
+			pods:          pods,
+			infos:         newInfos(redis.ClusterInfosInconsistent),
+			want:          "don't agree",
+		},
+		{
+			name:          "node outside of the adopted pods",
+			spec:          rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(1), ReplicationFactor: rapi.NewInt32(1)},
+			clusterStatus: matchingStatus,
+			pods:          pods,
+			infos:         newInfos(redis.ClusterInfosConsistent, stranger),
+			want:          "node stranger",
+		},
+		{
+			name:          "spec doesn't match the number of masters",
+			spec:          rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(3), ReplicationFactor: rapi.NewInt32(1)},
+			clusterStatus: matchingStatus,
+			pods:          pods,
+			infos:         newInfos(redis.ClusterInfosConsistent),
+			want:          "spec.numberOfMaster",
+		},
+		{
+			name:          "spec doesn't match the replication factor",
+			spec:          rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(1), ReplicationFactor: rapi.NewInt32(2)},
+			clusterStatus: matchingStatus,
+			pods:          pods,
+			infos:         newInfos(redis.ClusterInfosConsistent),
+			want:          "spec.replicationFactor",
+		},
+		{
+			name:          "topology matching the spec",
+			spec:          rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(1), ReplicationFactor: rapi.NewInt32(1)},
+			clusterStatus: matchingStatus,
+			pods:          pods,
+			infos:         newInfos(redis.ClusterInfosConsistent),
+			want:          "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &rapi.RedisCluster{Spec: tt.spec, Status: rapi.RedisClusterStatus{Cluster: tt.clusterStatus}}
+			got := getAdoptionPendingReason(cluster, tt.pods, tt.infos)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("getAdoptionPendingReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isPodAdopted(t *testing.T) {
+	cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", UID: "cluster-uid"}}
+	labeled := &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{rapi.ClusterNameLabelKey: "cluster"}}}
+	if isPodAdopted(cluster, labeled) {
+		t.Errorf("isPodAdopted() should be false without owner reference")
+	}
+	labeled.OwnerReferences = []metav1.OwnerReference{pod.BuildOwnerReference(cluster)}
+	if !isPodAdopted(cluster, labeled) {
+		t.Errorf("isPodAdopted() should be true")
+	}
+}
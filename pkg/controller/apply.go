@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+)
+
+// appliedFields are the maps and the port names of a Service or a PodDisruptionBudget owned by the operator. They are
+// recorded in the last-applied annotation of the object: at the next reconcile, an entry of the last applied maps or
+// ports missing from the desired ones has been removed from the spec and is deleted, while an entry set by another
+// controller or by a user is kept.
+type appliedFields struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Selector    map[string]string `json:"selector,omitempty"`
+	Ports       []string          `json:"ports,omitempty"`
+}
+
+// recordLastApplied records the fields in the last-applied annotation of the object to create or update
+func recordLastApplied(meta *metav1.ObjectMeta, applied appliedFields) {
+	data, err := json.Marshal(applied)
+	if err != nil {
+		glog.Errorf("unable to record the last applied fields of %s: %v", meta.Name, err)
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[rapi.LastAppliedAnnotationKey] = string(data)
+}
+
+// getLastApplied returns the fields recorded in the last-applied annotation of the object, none if the object has
+// been created by a previous version of the operator
+func getLastApplied(meta metav1.ObjectMeta) appliedFields {
+	applied := appliedFields{}
+	if data, ok := meta.Annotations[rapi.LastAppliedAnnotationKey]; ok {
+		if err := json.Unmarshal([]byte(data), &applied); err != nil {
+			glog.Warningf("invalid last applied fields of %s, ignored: %v", meta.Name, err)
+		}
+	}
+	return applied
+}
+
+// mergeOwnedMap applies the desired entries on the live map with a three-way comparison: the entries of the last
+// applied map missing from the desired map are deleted, the entries of the others are kept. Returns the merged map
+// and true if it differs from the live map.
+func mergeOwnedMap(live, lastApplied, desired map[string]string) (map[string]string, bool) {
+	merged := map[string]string{}
+	for k, v := range live {
+		merged[k] = v
+	}
+	changed := false
+	for k := range lastApplied {
+		if _, ok := desired[k]; !ok {
+			if _, ok := merged[k]; ok {
+				delete(merged, k)
+				changed = true
+			}
+		}
+	}
+	for k, v := range desired {
+		if current, ok := merged[k]; !ok || current != v {
+			merged[k] = v
+			changed = true
+		}
+	}
+	return merged, changed
+}
+
+// applyMetadata applies the desired labels and annotations on the live metadata, the last-applied annotation included.
+// Returns true if the metadata changed.
+func applyMetadata(live *metav1.ObjectMeta, desired metav1.ObjectMeta) bool {
+	lastApplied := getLastApplied(*live)
+	var labelsChanged, annotationsChanged bool
+	live.Labels, labelsChanged = mergeOwnedMap(live.Labels, lastApplied.Labels, desired.Labels)
+	live.Annotations, annotationsChanged = mergeOwnedMap(live.Annotations, lastApplied.Annotations, desired.Annotations)
+	return labelsChanged || annotationsChanged
+}
+
+// applyServiceSpec applies the desired Service on the live one: the type as desired, the labels, annotations, selector
+// and ports with a three-way comparison. The node ports and cluster IP allocated by kubernetes are kept. Returns the updated Service and true if it changed, or recreate true if the cluster IP can't be updated.
+func applyServiceSpec(live, desired *apiv1.Service) (updated *apiv1.Service, changed, recreate bool) {
+	if desired.Spec.ClusterIP != "" && desired.Spec.ClusterIP != live.Spec.ClusterIP {
+		return nil, false, true
+	}
+	lastApplied := getLastApplied(live.ObjectMeta)
+	updated = live.DeepCopy()
+	changed = applyMetadata(&updated.ObjectMeta, desired.ObjectMeta)
+
+	var selectorChanged bool
+	updated.Spec.Selector, selectorChanged = mergeOwnedMap(updated.Spec.Selector, lastApplied.Selector, desired.Spec.Selector)
+	changed = changed || selectorChanged
+
+	serviceType := desired.Spec.Type
+	if serviceType == "" {
+		serviceType = apiv1.ServiceTypeClusterIP
+	}
+	if updated.Spec.Type != serviceType {
+		updated.Spec.Type = serviceType
+		changed = true
+	}
+	ports := mergeServicePorts(updated.Spec.Ports, lastApplied.Ports, desired.Spec.Ports, serviceType)
+	if !apiequality.Semantic.DeepEqual(ports, updated.Spec.Ports) {
+		updated.Spec.Ports = ports
+		changed = true
+	}
+	return updated, changed, false
+}
+
+// mergeServicePorts applies the desired ports on the live ports with a three-way comparison by port name: the live
+// ports named in the last applied ports and not desired anymore are deleted, the ports added by others are kept, and
+// the desired ports replace the live ports of the same name. The node port allocated to a live port is kept if the
+// service type exposes node ports and no node port is desired, otherwise the node ports are cleared.
+func mergeServicePorts(live []apiv1.ServicePort, lastApplied []string, desired []apiv1.ServicePort, serviceType apiv1.ServiceType) []apiv1.ServicePort {
+	exposeNodePorts := serviceType == apiv1.ServiceTypeNodePort || serviceType == apiv1.ServiceTypeLoadBalancer
+	owned := map[string]bool{}
+	for _, name := range lastApplied {
+		owned[name] = true
+	}
+	desiredByName := map[string]apiv1.ServicePort{}
+	for _, port := range desired {
+		desiredByName[port.Name] = port
+	}
+
+	ports := []apiv1.ServicePort{}
+	merged := map[string]bool{}
+	for _, livePort := range live {
+		port, ok := desiredByName[livePort.Name]
+		if !ok {
+			if owned[livePort.Name] {
+				continue
+			}
+			port = livePort
+		} else if port.NodePort == 0 {
+			port.NodePort = livePort.NodePort
+		}
+		if !exposeNodePorts {
+			port.NodePort = 0
+		}
+		ports = append(ports, port)
+		merged[port.Name] = true
+	}
+	for _, port := range desired {
+		if !merged[port.Name] {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// servicePortNames returns the names of the ports, to record them in the last-applied annotation
+func servicePortNames(ports []apiv1.ServicePort) []string {
+	names := []string{}
+	for _, port := range ports {
+		names = append(names, port.Name)
+	}
+	return names
+}
+
+// applyService creates the desired Service if it doesn't exist, otherwise applies its owned fields on the live Service
+func (c *Controller) applyService(cluster *rapi.RedisCluster, live, desired *apiv1.Service) error {
+	if live == nil {
+		glog.V(3).Infof("cluster %s/%s: create service %s", cluster.Namespace, cluster.Name, desired.Name)
+		_, err := c.serviceControl.CreateService(cluster, desired)
+		return err
+	}
+	updated, changed, recreate := applyServiceSpec(live, desired)
+	if recreate {
+		// the cluster IP of a Service is immutable, the Service is created again at the next reconcile
+		glog.V(3).Infof("cluster %s/%s: delete service %s to change its cluster IP", cluster.Namespace, cluster.Name, live.Name)
+		return c.serviceControl.DeleteService(cluster, live.Name)
+	}
+	if !changed {
+		return nil
+	}
+	glog.V(3).Infof("cluster %s/%s: update service %s", cluster.Namespace, cluster.Name, live.Name)
+	_, err := c.serviceControl.UpdateService(cluster, updated)
+	return err
+}
+
+// applyPodDisruptionBudget creates the desired PodDisruptionBudget if it doesn't exist, otherwise applies its owned
+// fields on the live PodDisruptionBudget
+func (c *Controller) applyPodDisruptionBudget(cluster *rapi.RedisCluster, live, desired *policyv1.PodDisruptionBudget) error {
+	if live == nil {
+		glog.V(3).Infof("cluster %s/%s: create PodDisruptionBudget %s", cluster.Namespace, cluster.Name, desired.Name)
+		_, err := c.podDisruptionBudgetControl.CreatePodDisruptionBudget(cluster, desired)
+		return err
+	}
+	if !apiequality.Semantic.DeepEqual(live.Spec, desired.Spec) {
+		// the spec of a PodDisruptionBudget can't be updated: it is deleted, then created again
+		glog.V(3).Infof("cluster %s/%s: recreate PodDisruptionBudget %s", cluster.Namespace, cluster.Name, live.Name)
+		if err := c.podDisruptionBudgetControl.DeletePodDisruptionBudget(cluster, live.Name); err != nil {
+			return err
+		}
+		_, err := c.podDisruptionBudgetControl.CreatePodDisruptionBudget(cluster, desired)
+		return err
+	}
+	updated := live.DeepCopy()
+	if !applyMetadata(&updated.ObjectMeta, desired.ObjectMeta) {
+		return nil
+	}
+	glog.V(3).Infof("cluster %s/%s: update PodDisruptionBudget %s", cluster.Namespace, cluster.Name, live.Name)
+	_, err := c.podDisruptionBudgetControl.UpdatePodDisruptionBudget(cluster, updated)
+	return err
+}
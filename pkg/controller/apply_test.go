@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+)
+
+func Test_mergeOwnedMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		live        map[string]string
+		lastApplied map[string]string
+		desired     map[string]string
+		want        map[string]string
+		wantChanged bool
+	}{
+		{
+			name:        "unchanged",
+			live:        map[string]string{"app": "redis", "other": "x"},
+			lastApplied: map[string]string{"app": "redis"},
+			desired:     map[string]string{"app": "redis"},
+			want:        map[string]string{"app": "redis", "other": "x"},
+		},
+		{
+			name:        "manual edit reverted",
+			live:        map[string]string{"app": "edited"},
+			lastApplied: map[string]string{"app": "redis"},
+			desired:     map[string]string{"app": "redis"},
+			want:        map[string]string{"app": "redis"},
+			wantChanged: true,
+		},
+		{
+			name:        "entry removed from the spec, entry of another controller kept",
+			live:        map[string]string{"app": "redis", "team": "a", "other": "x"},
+			lastApplied: map[string]string{"app": "redis", "team": "a"},
+			desired:     map[string]string{"app": "redis"},
+			want:        map[string]string{"app": "redis", "other": "x"},
+			wantChanged: true,
+		},
+		{
+			name:        "no last applied",
+			live:        map[string]string{"team": "a"},
+			desired:     map[string]string{"app": "redis"},
+			want:        map[string]string{"app": "redis", "team": "a"},
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := mergeOwnedMap(tt.live, tt.lastApplied, tt.desired)
+			if !reflect.DeepEqual(got, tt.want) || changed != tt.wantChanged {
+				t.Errorf("mergeOwnedMap() = %v, %v, want %v, %v", got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func Test_applyServiceSpec(t *testing.T) {
+	cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns"}}
+	cluster.Spec.ServiceType = string(rapi.ServiceTypeExternal)
+	desired, err := newRedisPodService(cluster, 2, 0)
+	if err != nil {
+		t.Fatalf("newRedisPodService() error = %v", err)
+	}
+	// without a node port in the spec, the node port is allocated by kubernetes
+	desired.Spec.Ports[0].NodePort = 0
+
+	live := desired.DeepCopy()
+	live.Spec.ClusterIP = "10.0.0.12"
+	live.Spec.Ports[0].NodePort = 30012
+	live.Spec.SessionAffinity = apiv1.ServiceAffinityNone
+	live.Labels["other-controller"] = "x"
+	if _, changed, recreate := applyServiceSpec(live, desired); changed || recreate {
+		t.Errorf("applyServiceSpec() should keep the fields allocated by kubernetes and set by other controllers")
+	}
+
+	live.Spec.Ports[0].Port = 6380
+	live.Spec.Type = apiv1.ServiceTypeClusterIP
+	updated, changed, _ := applyServiceSpec(live, desired)
+	if !changed || updated.Spec.Type != apiv1.ServiceTypeNodePort || updated.Spec.Ports[0].Port != 6379 || updated.Spec.Ports[0].NodePort != 30012 {
+		t.Errorf("applyServiceSpec() = %+v, want the type and port reverted with the allocated node port", updated.Spec)
+	}
+	if updated.Labels["other-controller"] != "x" || updated.Spec.ClusterIP != "10.0.0.12" {
+		t.Errorf("applyServiceSpec() should keep the label and the cluster IP, got %v %s", updated.Labels, updated.Spec.ClusterIP)
+	}
+
+	// a port removed from the spec is deleted, a port added by another controller is kept
+	previous := desired.DeepCopy()
+	previous.Spec.Ports = append(previous.Spec.Ports, newServicePort("metrics", 9121, 0))
+	recordLastApplied(&previous.ObjectMeta, appliedFields{Ports: servicePortNames(previous.Spec.Ports)})
+	live = previous.DeepCopy()
+	live.Spec.Ports[0].NodePort = 30012
+	live.Spec.Ports[1].NodePort = 30121
+	live.Spec.Ports = append(live.Spec.Ports, apiv1.ServicePort{Name: "sidecar", Protocol: apiv1.ProtocolTCP, Port: 8080, NodePort: 30080})
+	updated, changed, _ = applyServiceSpec(live, desired)
+	if !changed || len(updated.Spec.Ports) != 2 || updated.Spec.Ports[0].Name != "redis" || updated.Spec.Ports[0].NodePort != 30012 || updated.Spec.Ports[1].Name != "sidecar" || updated.Spec.Ports[1].NodePort != 30080 {
+		t.Errorf("applyServiceSpec() ports = %+v, want the redis port and the port of the other controller", updated.Spec.Ports)
+	}
+
+	headless, _ := newRedisClusterService(cluster)
+	if _, _, recreate := applyServiceSpec(live, headless); !recreate {
+		t.Errorf("applyServiceSpec() should recreate a service to make it headless")
+	}
+}
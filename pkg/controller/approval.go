@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	podctrl "github.com/zh168654/Redis-Operator/pkg/controller/pod"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// AwaitingApprovalEventReason is the reason of the event emitted when a disruptive action waits for an approval
+	AwaitingApprovalEventReason = "AwaitingApproval"
+	// ActionApprovedEventReason is the reason of the event emitted when a disruptive action has been approved
+	ActionApprovedEventReason = "ActionApproved"
+
+	// approvalIDLength is the number of hexadecimal characters of the ID of an approval request
+	approvalIDLength = 10
+)
+
+// approvalRequest describes a disruptive action to approve
+type approvalRequest struct {
+	action rapi.RedisClusterDisruptiveAction
+	// target identifies the state reached by the action, the approval holds as long as the target doesn't change
+	target  string
+	summary string
+}
+
+// requiresApproval returns true if the disruptive action waits for an approval before it starts
+func requiresApproval(cluster *rapi.RedisCluster, action rapi.RedisClusterDisruptiveAction) bool {
+	for _, a := range cluster.Spec.RequireApprovalFor {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// holdDisruptiveAction returns true if the disruptive action has to wait for its approval or for a maintenance window
+func (c *Controller) holdDisruptiveAction(cluster *rapi.RedisCluster, request approvalRequest) (bool, error) {
+	now := time.Now()
+	if wait, err := c.awaitApproval(cluster, request, now); wait || err != nil {
+		return wait, err
+	}
+	return c.waitMaintenanceWindow(cluster, request.action, now)
+}
+
+// awaitApproval returns true if the disruptive action waits for its approval. The first call publishes the request in
+// status.approval and in the AwaitingApproval condition, the action runs once the RedisCluster is annotated with the
+// ID of the request, and until its end or a change of its target.
+func (c *Controller) awaitApproval(cluster *rapi.RedisCluster, request approvalRequest, now time.Time) (bool, error) {
+	if !requiresApproval(cluster, request.action) {
+		return false, nil
+	}
+	approval := cluster.Status.Approval
+	if approval == nil || approval.Action != request.action || approval.Target != request.target {
+		approval = &rapi.RedisClusterApprovalStatus{
+			Action:      request.action,
+			ID:          approvalID(request, now),
+			Target:      request.target,
+			Summary:     request.summary,
+			RequestTime: metav1.NewTime(now),
+		}
+		cluster.Status.Approval = approval
+		message := awaitingApprovalMessage(approval)
+		glog.Infof("cluster %s/%s: %s", cluster.Namespace, cluster.Name, message)
+		setCondition(&cluster.Status, rapi.RedisClusterAwaitingApproval, apiv1.ConditionTrue, metav1.NewTime(now), "approval requested", message)
+		c.recorder.Event(cluster, apiv1.EventTypeNormal, AwaitingApprovalEventReason, message)
+		_, err := c.updateHandler(cluster)
+		return true, err
+	}
+	if approval.Approved {
+		return false, nil
+	}
+	if cluster.Annotations[rapi.ApproveActionAnnotationKey(request.action)] != approval.ID {
+		if approval.Summary == request.summary {
+			return true, nil
+		}
+		// same target, the summary is refreshed but the request keeps its ID
+		approval.Summary = request.summary
+		setCondition(&cluster.Status, rapi.RedisClusterAwaitingApproval, apiv1.ConditionTrue, metav1.NewTime(now), "approval requested", awaitingApprovalMessage(approval))
+		_, err := c.updateHandler(cluster)
+		return true, err
+	}
+
+	// the action starts at the next reconcile, once the approval is stored
+	approval.Approved = true
+	message := fmt.Sprintf("%s %s approved: %s", request.action, approval.ID, approval.Summary)
+	glog.Infof("cluster %s/%s: %s", cluster.Namespace, cluster.Name, message)
+	setCondition(&cluster.Status, rapi.RedisClusterAwaitingApproval, apiv1.ConditionFalse, metav1.NewTime(now), "approved", message)
+	c.recorder.Event(cluster, apiv1.EventTypeNormal, ActionApprovedEventReason, message)
+	_, err := c.updateHandler(cluster)
+	return true, err
+}
+
+// clearApproval forgets the approval request of the last disruptive action, returns true if the status changed
+func clearApproval(clusterStatus *rapi.RedisClusterStatus) bool {
+	updated := clusterStatus.Approval != nil
+	clusterStatus.Approval = nil
+	for _, condition := range clusterStatus.Conditions {
+		if condition.Type == rapi.RedisClusterAwaitingApproval && condition.Status != apiv1.ConditionFalse {
+			updated = setCondition(clusterStatus, rapi.RedisClusterAwaitingApproval, apiv1.ConditionFalse, metav1.Now(), "no action pending", "no disruptive action waits for an approval") || updated
+		}
+	}
+	return updated
+}
+
+func awaitingApprovalMessage(approval *rapi.RedisClusterApprovalStatus) string {
+	return fmt.Sprintf("%s waits for an approval: %s. Annotate the RedisCluster with %s=%s to approve it", approval.Action, approval.Summary, rapi.ApproveActionAnnotationKey(approval.Action), approval.ID)
+}
+
+// approvalID returns the ID of an approval request, unique to the action, its target and the time of the request
+func approvalID(request approvalRequest, now time.Time) string {
+	hash := sha1.Sum([]byte(fmt.Sprintf("%s/%s/%d", request.action, request.target, now.UnixNano())))
+	return hex.EncodeToString(hash[:])[:approvalIDLength]
+}
+
+// rollingUpdateApprovalRequest describes the rolling update of the pods to the PodTemplate of the spec
+func rollingUpdateApprovalRequest(cluster *rapi.RedisCluster) approvalRequest {
+	hash, _ := podctrl.GenerateMD5Spec(&cluster.Spec.PodTemplate.Spec)
+	target := hash
+	if cluster.Spec.UpdateStrategy != nil && cluster.Spec.UpdateStrategy.Partition != nil {
+		target = fmt.Sprintf("%s,partition=%d", hash, *cluster.Spec.UpdateStrategy.Partition)
+	}
+	outdated := 0
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Pod != nil && !comparePodSpecMD5Hash(hash, node.Pod) {
+			outdated++
+		}
+	}
+	return approvalRequest{
+		action:  rapi.DisruptiveActionRollingUpdate,
+		target:  target,
+		summary: fmt.Sprintf("recreate %d of %d pods with the pod template %s", outdated, cluster.Status.Cluster.NbPods, hash),
+	}
+}
+
+// scaleDownApprovalRequest describes the removal of the pods above the number of masters and the replication factor of the spec
+func scaleDownApprovalRequest(cluster *rapi.RedisCluster) approvalRequest {
+	nbMaster, replicationFactor := *cluster.Spec.NumberOfMaster, *cluster.Spec.ReplicationFactor
+	return approvalRequest{
+		action: rapi.DisruptiveActionScaleDown,
+		target: fmt.Sprintf("masters=%d,replicationFactor=%d", nbMaster, replicationFactor),
+		summary: fmt.Sprintf("scale down from %d masters and %d pods to %d masters with %d replicas each, the slots of the removed masters are migrated before their pods are deleted",
+			cluster.Status.Cluster.NumberOfMaster, cluster.Status.Cluster.NbPods, nbMaster, replicationFactor),
+	}
+}
+
+// rebalanceApprovalRequest describes the migration of the slots from the current masters to the new masters
+func rebalanceApprovalRequest(newMasters, curMasters redis.Nodes) approvalRequest {
+	added, removed := 0, 0
+	for _, master := range newMasters {
+		if _, err := curMasters.GetNodeByID(master.ID); err != nil {
+			added++
+		}
+	}
+	for _, master := range curMasters {
+		if _, err := newMasters.GetNodeByID(master.ID); err != nil {
+			removed++
+		}
+	}
+	current := make([]string, 0, len(curMasters))
+	for _, master := range curMasters {
+		current = append(current, master.ID)
+	}
+	sort.Strings(current)
+	return approvalRequest{
+		action: rapi.DisruptiveActionRebalance,
+		// the new masters may be elected differently at each reconcile, the target is the change of the number of masters
+		target:  fmt.Sprintf("masters=%d,from=%s", len(newMasters), strings.Join(current, ",")),
+		summary: fmt.Sprintf("migrate the slots from %d to %d masters, %d masters added and %d removed", len(curMasters), len(newMasters), added, removed),
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+func TestController_awaitApproval(t *testing.T) {
+	updates := 0
+	c := &Controller{
+		updateHandler: func(rc *rapi.RedisCluster) (*rapi.RedisCluster, error) {
+			updates++
+			return rc, nil
+		},
+		recorder: record.NewFakeRecorder(10),
+	}
+	cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	now := time.Date(2018, 5, 2, 10, 0, 0, 0, time.UTC)
+	request := approvalRequest{action: rapi.DisruptiveActionScaleDown, target: "masters=3,replicationFactor=1", summary: "scale down from 4 to 3 masters"}
+
+	if wait, _ := c.awaitApproval(cluster, request, now); wait || updates != 0 {
+		t.Errorf("awaitApproval() should not wait if the action doesn't require an approval")
+	}
+
+	cluster.Spec.RequireApprovalFor = []rapi.RedisClusterDisruptiveAction{rapi.DisruptiveActionRollingUpdate, rapi.DisruptiveActionScaleDown}
+	for i := 0; i < 2; i++ {
+		if wait, err := c.awaitApproval(cluster, request, now); !wait || err != nil {
+			t.Errorf("awaitApproval() = %v, %v, want to wait for the approval", wait, err)
+		}
+	}
+	approval := cluster.Status.Approval
+	if approval == nil || approval.ID == "" || approval.Approved || approval.Summary != request.summary {
+		t.Fatalf("status.approval = %+v, want the pending request", approval)
+	}
+	if updates != 1 {
+		t.Errorf("the status should be updated once, got %d updates", updates)
+	}
+	if condition := getCondition(&cluster.Status, rapi.RedisClusterAwaitingApproval); condition == nil || condition.Status != apiv1.ConditionTrue || !strings.Contains(condition.Message, approval.ID) {
+		t.Errorf("AwaitingApproval condition = %+v, want true with the ID of the request", condition)
+	}
+
+	// an approval of another action, or of another request, doesn't approve the action
+	cluster.Annotations = map[string]string{
+		rapi.ApproveActionAnnotationKey(rapi.DisruptiveActionRollingUpdate): approval.ID,
+		rapi.ApproveActionAnnotationKey(rapi.DisruptiveActionScaleDown):     "0123456789",
+	}
+	if wait, _ := c.awaitApproval(cluster, request, now); !wait || cluster.Status.Approval.Approved {
+		t.Errorf("awaitApproval() should wait for the approval of the request")
+	}
+
+	cluster.Annotations[rapi.ApproveActionAnnotationKey(rapi.DisruptiveActionScaleDown)] = approval.ID
+	if wait, _ := c.awaitApproval(cluster, request, now); !wait || !cluster.Status.Approval.Approved {
+		t.Errorf("awaitApproval() should store the approval before the action starts")
+	}
+	if condition := getCondition(&cluster.Status, rapi.RedisClusterAwaitingApproval); condition == nil || condition.Status != apiv1.ConditionFalse {
+		t.Errorf("AwaitingApproval condition = %+v, want false once approved", condition)
+	}
+	request.summary = "scale down from 4 to 3 masters, 1 pod deleted"
+	if wait, _ := c.awaitApproval(cluster, request, now.Add(time.Minute)); wait {
+		t.Errorf("awaitApproval() should not wait once approved, until the target changes")
+	}
+
+	request.target = "masters=2,replicationFactor=1"
+	if wait, _ := c.awaitApproval(cluster, request, now.Add(time.Minute)); !wait || cluster.Status.Approval.Approved || cluster.Status.Approval.ID == approval.ID {
+		t.Errorf("awaitApproval() should request a new approval for a new target, status.approval = %+v", cluster.Status.Approval)
+	}
+
+	if !clearApproval(&cluster.Status) || cluster.Status.Approval != nil {
+		t.Errorf("clearApproval() should forget the request")
+	}
+	if condition := getCondition(&cluster.Status, rapi.RedisClusterAwaitingApproval); condition == nil || condition.Status != apiv1.ConditionFalse {
+		t.Errorf("AwaitingApproval condition = %+v, want false once cleared", condition)
+	}
+	if clearApproval(&cluster.Status) {
+		t.Errorf("clearApproval() should not change a cleared status")
+	}
+}
+
+func Test_rebalanceApprovalRequest(t *testing.T) {
+	a, b, c := &redis.Node{ID: "a"}, &redis.Node{ID: "b"}, &redis.Node{ID: "c"}
+	scaleUp := rebalanceApprovalRequest(redis.Nodes{a, b, c}, redis.Nodes{b, a})
+	if scaleUp.target != "masters=3,from=a,b" || !strings.Contains(scaleUp.summary, "from 2 to 3 masters, 1 masters added and 0 removed") {
+		t.Errorf("rebalanceApprovalRequest() = %+v", scaleUp)
+	}
+	if other := rebalanceApprovalRequest(redis.Nodes{c, b, a}, redis.Nodes{a, b}); other.target != scaleUp.target {
+		t.Errorf("the target should not depend on the order of the masters, got %q and %q", other.target, scaleUp.target)
+	}
+}
+
+func getCondition(clusterStatus *rapi.RedisClusterStatus, conditionType rapi.RedisClusterConditionType) *rapi.RedisClusterCondition {
+	for i := range clusterStatus.Conditions {
+		if clusterStatus.Conditions[i].Type == conditionType {
+			return &clusterStatus.Conditions[i]
+		}
+	}
+	return nil
+}
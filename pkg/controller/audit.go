@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	rlisters "github.com/zh168654/Redis-Operator/pkg/client/listers/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/config"
+	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// auditConfigMapDataKey is the key of the audit records in the audit ConfigMap of a RedisCluster
+	auditConfigMapDataKey = "audit.jsonl"
+	// auditConfigMapUpdateAttempts is the number of times the update of an audit ConfigMap is tried on conflict
+	auditConfigMapUpdateAttempts = 3
+)
+
+// newAuditSink returns the sink of the audit log configured, nil if the audit log is disabled
+func newAuditSink(cfg *config.Audit, kubeClient clientset.Interface, clusterLister rlisters.RedisClusterLister) (redis.AuditSink, error) {
+	switch cfg.Sink {
+	case "", config.AuditSinkNone:
+		return nil, nil
+	case config.AuditSinkStdout:
+		return redis.NewJSONLinesAuditSink(os.Stdout), nil
+	case config.AuditSinkFile:
+		sink, err := redis.NewFileAuditSink(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		return sink, nil
+	case config.AuditSinkConfigMap:
+		return newConfigMapAuditSink(kubeClient, clusterLister, cfg.ConfigMapMaxBytes), nil
+	}
+	return nil, fmt.Errorf("unknown audit sink %q", cfg.Sink)
+}
+
+// auditContext returns the context recording in the audit sink the mutating commands of this reconcile of the cluster,
+// the reconcile is identified by the resource version of the cluster and its start time
+func (c *Controller) auditContext(ctx context.Context, cluster *rapi.RedisCluster) context.Context {
+	if c.auditSink == nil {
+		return ctx
+	}
+	reconcile := fmt.Sprintf("%s-%d", cluster.ResourceVersion, time.Now().UnixNano())
+	return redis.WithAudit(ctx, c.auditSink, cluster.Namespace+"/"+cluster.Name, reconcile)
+}
+
+// getAuditConfigMapName returns the name of the ConfigMap storing the audit records of the cluster
+func getAuditConfigMapName(clusterName string) string {
+	return clusterName + "-audit"
+}
+
+// configMapAuditSink writes the audit records of each RedisCluster as JSON lines in a ConfigMap of its namespace,
+// owned by the RedisCluster. The records are buffered and written by Flush, the oldest records are dropped above maxBytes.
+type configMapAuditSink struct {
+	kubeClient    clientset.Interface
+	clusterLister rlisters.RedisClusterLister
+	maxBytes      int
+
+	mutex   sync.Mutex
+	pending map[string][]byte // JSON lines not written yet, by RedisCluster key
+}
+
+func newConfigMapAuditSink(kubeClient clientset.Interface, clusterLister rlisters.RedisClusterLister, maxBytes int) *configMapAuditSink {
+	return &configMapAuditSink{kubeClient: kubeClient, clusterLister: clusterLister, maxBytes: maxBytes, pending: map[string][]byte{}}
+}
+
+// Write buffers the record until the next Flush
+func (s *configMapAuditSink) Write(record *redis.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pending[record.Cluster] = trimAuditLines(append(append(s.pending[record.Cluster], line...), '\n'), s.maxBytes)
+	return nil
+}
+
+// Flush writes the buffered records in the ConfigMaps, the records of a ConfigMap that can't be written are kept for
+// the next Flush
+func (s *configMapAuditSink) Flush() {
+	s.mutex.Lock()
+	pending := s.pending
+	s.pending = map[string][]byte{}
+	s.mutex.Unlock()
+
+	for key, lines := range pending {
+		if err := s.write(key, lines); err != nil {
+			glog.Errorf("unable to write the audit records of cluster %s: %v", key, err)
+			s.mutex.Lock()
+			s.pending[key] = trimAuditLines(append(lines, s.pending[key]...), s.maxBytes)
+			s.mutex.Unlock()
+		}
+	}
+}
+
+// write appends the lines to the audit ConfigMap of the cluster, created if needed. The lines of a deleted cluster
+// are dropped, and a ConfigMap not owned by the cluster is not written.
+func (s *configMapAuditSink) write(key string, lines []byte) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	cluster, err := s.clusterLister.RedisClusters(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		glog.V(3).Infof("cluster %s deleted, its audit records are dropped", key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	configMaps := s.kubeClient.CoreV1().ConfigMaps(namespace)
+	for attempt := 0; ; attempt++ {
+		cm, err := configMaps.Get(getAuditConfigMapName(name), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            getAuditConfigMapName(name),
+					Namespace:       namespace,
+					OwnerReferences: []metav1.OwnerReference{pod.BuildOwnerReference(cluster)},
+				},
+				Data: map[string]string{auditConfigMapDataKey: string(trimAuditLines(lines, s.maxBytes))},
+			}
+			_, err = configMaps.Create(cm)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(cm, cluster) {
+			return fmt.Errorf("the ConfigMap %s/%s is not owned by the RedisCluster, the audit records are not written in it", namespace, cm.Name)
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[auditConfigMapDataKey] = string(trimAuditLines(append([]byte(cm.Data[auditConfigMapDataKey]), lines...), s.maxBytes))
+		if _, err = configMaps.Update(cm); !apierrors.IsConflict(err) || attempt+1 >= auditConfigMapUpdateAttempts {
+			return err
+		}
+	}
+}
+
+// trimAuditLines drops the oldest lines until the lines fit in maxBytes
+func trimAuditLines(lines []byte, maxBytes int) []byte {
+	for len(lines) > maxBytes {
+		i := bytes.IndexByte(lines, '\n')
+		if i < 0 {
+			return lines[:0]
+		}
+		lines = lines[i+1:]
+	}
+	return lines
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	rlisters "github.com/zh168654/Redis-Operator/pkg/client/listers/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/config"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+// newAuditClusterLister returns a lister of the clusters
+func newAuditClusterLister(clusters ...*rapi.RedisCluster) rlisters.RedisClusterLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, cluster := range clusters {
+		indexer.Add(cluster)
+	}
+	return rlisters.NewRedisClusterLister(indexer)
+}
+
+func Test_configMapAuditSink(t *testing.T) {
+	foo := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns", UID: "foo-uid"}}
+	bar := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "ns", UID: "bar-uid"}}
+	// the ConfigMap of bar has been created by someone else
+	kubeClient := kubefake.NewSimpleClientset(&apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "bar-audit", Namespace: "ns"}})
+	sink := newConfigMapAuditSink(kubeClient, newAuditClusterLister(foo, bar), 300)
+
+	record := func(cluster, addr string) *redis.AuditRecord {
+		return &redis.AuditRecord{Cluster: cluster, Addr: addr, Command: "CLUSTER FORGET", Args: []string{"0123456789abcdef"}, Result: redis.AuditResultOK}
+	}
+	sink.Write(record("ns/foo", "10.0.0.1:6379"))
+	sink.Write(record("ns/bar", "10.0.0.2:6379"))
+	sink.Flush()
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("ns").Get("foo-audit", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("the audit ConfigMap of the cluster should be created: %v", err)
+	}
+	if data := cm.Data[auditConfigMapDataKey]; strings.Count(data, "\n") != 1 || !strings.Contains(data, "10.0.0.1:6379") {
+		t.Errorf("audit records = %q, want the record of the cluster", data)
+	}
+	if !metav1.IsControlledBy(cm, foo) {
+		t.Errorf("the audit ConfigMap should be owned by the cluster, owners: %v", cm.OwnerReferences)
+	}
+	if cm, _ = kubeClient.CoreV1().ConfigMaps("ns").Get("bar-audit", metav1.GetOptions{}); len(cm.Data) != 0 {
+		t.Errorf("the ConfigMap not owned by the cluster should not be written, data: %v", cm.Data)
+	}
+
+	// the records of a deleted cluster are dropped
+	sink.Write(record("ns/deleted", "10.0.0.6:6379"))
+	sink.Flush()
+	if _, ok := sink.pending["ns/deleted"]; ok {
+		t.Errorf("the records of a deleted cluster should be dropped")
+	}
+
+	for _, addr := range []string{"10.0.0.3:6379", "10.0.0.4:6379", "10.0.0.5:6379"} {
+		sink.Write(record("ns/foo", addr))
+	}
+	sink.Flush()
+	cm, _ = kubeClient.CoreV1().ConfigMaps("ns").Get("foo-audit", metav1.GetOptions{})
+	data := cm.Data[auditConfigMapDataKey]
+	if len(data) > 300 || strings.Contains(data, "10.0.0.1:6379") || !strings.HasSuffix(data, "\n") || !strings.Contains(data, "10.0.0.5:6379") {
+		t.Errorf("audit records = %q, want the oldest records dropped above 300 bytes", data)
+	}
+}
+
+func Test_newAuditSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		sink    string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "default", sink: "", wantNil: true},
+		{name: "none", sink: config.AuditSinkNone, wantNil: true},
+		{name: "stdout", sink: config.AuditSinkStdout},
+		{name: "configmap", sink: config.AuditSinkConfigMap},
+		{name: "unknown", sink: "syslog", wantNil: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := newAuditSink(&config.Audit{Sink: tt.sink}, kubefake.NewSimpleClientset(), newAuditClusterLister())
+			if (err != nil) != tt.wantErr || (sink == nil) != tt.wantNil {
+				t.Errorf("newAuditSink() = %v, %v, want nil:%v err:%v", sink, err, tt.wantNil, tt.wantErr)
+			}
+		})
+	}
+}
@@ -42,6 +42,10 @@ func compareStatus(old, new *rapi.RedisClusterClusterStatus) bool {
 	if compareInts("len(Nodes)", int32(len(old.Nodes)), int32(len(new.Nodes))) {
 		return true
 	}
+	if new.MetricsTime != nil && (old.MetricsTime == nil || new.MetricsTime.Sub(old.MetricsTime.Time) >= nodesMetricsRefreshInterval) {
+		glog.V(3).Infof("nodes metrics older than %v", nodesMetricsRefreshInterval)
+		return true
+	}
 
 	if len(old.Nodes) != len(new.Nodes) {
 		return true
@@ -92,6 +96,22 @@ func compareNodes(nodeA, nodeB *rapi.RedisClusterNode) bool {
 	if compareStringValue("Node.Role", string(nodeA.Role), string(nodeB.Role)) {
 		return true
 	}
+	if compareStringValue("Node.KubeNode", nodeA.KubeNode, nodeB.KubeNode) {
+		return true
+	}
+	if compareStringValue("Node.Zone", nodeA.Zone, nodeB.Zone) {
+		return true
+	}
+	if compareStringValue("Node.RedisVersion", nodeA.RedisVersion, nodeB.RedisVersion) {
+		return true
+	}
+	if compareStringValue("Node.LinkState", nodeA.LinkState, nodeB.LinkState) {
+		return true
+	}
+	if !reflect.DeepEqual(nodeA.LastFailoverTime, nodeB.LastFailoverTime) {
+		glog.Infof("compare Node.LastFailoverTime: %v - %v", nodeA.LastFailoverTime, nodeB.LastFailoverTime)
+		return true
+	}
 
 	sizeSlotsA := 0
 	sizeSlotsB := 0
@@ -180,7 +200,81 @@ func needClusterOperation(cluster *rapi.RedisCluster) bool {
 }
 
 func needRollingUpdate(cluster *rapi.RedisCluster) bool {
-	return !comparePodsWithPodTemplate(cluster)
+	if comparePodsWithPodTemplate(cluster) {
+		return false
+	}
+	if cluster.Spec.UpdateStrategy != nil && cluster.Spec.UpdateStrategy.Partition != nil {
+		// the rolling update is over as soon as the partition is reached
+		clusterPodSpecHash, _ := podctrl.GenerateMD5Spec(&cluster.Spec.PodTemplate.Spec)
+		return countUpdatedShards(cluster, clusterPodSpecHash) < *cluster.Spec.UpdateStrategy.Partition
+	}
+	return true
+}
+
+// countUpdatedShards returns the number of master with slots running the PodTemplate corresponding to the hash
+// and for which all the slaves are also running this PodTemplate
+func countUpdatedShards(cluster *rapi.RedisCluster, hash string) int32 {
+	var nbUpdated int32
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Pod == nil || node.Role != rapi.RedisClusterNodeRoleMaster || len(node.Slots) == 0 {
+			continue
+		}
+		if !comparePodSpecMD5Hash(hash, node.Pod) {
+			continue
+		}
+		updated := true
+		for _, slave := range cluster.Status.Cluster.Nodes {
+			if slave.Role != rapi.RedisClusterNodeRoleSlave || slave.MasterRef != node.ID || slave.Pod == nil {
+				continue
+			}
+			if !comparePodSpecMD5Hash(hash, slave.Pod) {
+				updated = false
+				break
+			}
+		}
+		if updated {
+			nbUpdated++
+		}
+	}
+	return nbUpdated
+}
+
+// getUpdateStrategyType returns the update strategy type, defaulted to RollingUpdate
+func getUpdateStrategyType(cluster *rapi.RedisCluster) rapi.RedisClusterUpdateStrategyType {
+	if cluster.Spec.UpdateStrategy == nil || cluster.Spec.UpdateStrategy.Type == "" {
+		return rapi.RollingUpdateStrategyType
+	}
+	return cluster.Spec.UpdateStrategy.Type
+}
+
+// getMaxSurgeShards returns the number of shards that can be replaced at the same time during a rolling update
+func getMaxSurgeShards(cluster *rapi.RedisCluster) int32 {
+	if cluster.Spec.UpdateStrategy == nil || cluster.Spec.UpdateStrategy.MaxSurgeShards == nil || *cluster.Spec.UpdateStrategy.MaxSurgeShards < 1 {
+		return 1
+	}
+	return *cluster.Spec.UpdateStrategy.MaxSurgeShards
+}
+
+// isRollingUpdatePaused returns true if the rolling update has been paused by the user
+func isRollingUpdatePaused(cluster *rapi.RedisCluster) bool {
+	return cluster.Spec.UpdateStrategy != nil && cluster.Spec.UpdateStrategy.Paused
+}
+
+// nbShardsToReplace returns the number of shards to replace during the next rolling update step,
+// according to the update strategy and the number of shards already updated
+func nbShardsToReplace(cluster *rapi.RedisCluster, nbUpdatedShards int32) int32 {
+	target := *cluster.Spec.NumberOfMaster
+	if cluster.Spec.UpdateStrategy != nil && cluster.Spec.UpdateStrategy.Partition != nil && *cluster.Spec.UpdateStrategy.Partition < target {
+		target = *cluster.Spec.UpdateStrategy.Partition
+	}
+	remaining := target - nbUpdatedShards
+	if remaining <= 0 {
+		return 0
+	}
+	if maxSurge := getMaxSurgeShards(cluster); remaining > maxSurge {
+		return maxSurge
+	}
+	return remaining
 }
 
 func comparePodsWithPodTemplate(cluster *rapi.RedisCluster) bool {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
 	kapi "k8s.io/api/core/v1"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -218,6 +219,30 @@ func Test_compareStatus(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "Nodes metrics recently refreshed",
+			args: args{
+				old: &rapi.RedisClusterClusterStatus{MetricsTime: &kmetav1.Time{Time: time.Unix(1000, 0)}},
+				new: &rapi.RedisClusterClusterStatus{MetricsTime: &kmetav1.Time{Time: time.Unix(1010, 0)}},
+			},
+			want: false,
+		},
+		{
+			name: "Nodes metrics outdated",
+			args: args{
+				old: &rapi.RedisClusterClusterStatus{MetricsTime: &kmetav1.Time{Time: time.Unix(1000, 0)}},
+				new: &rapi.RedisClusterClusterStatus{MetricsTime: &kmetav1.Time{Time: time.Unix(1060, 0)}},
+			},
+			want: true,
+		},
+		{
+			name: "Nodes metrics never stored",
+			args: args{
+				old: &rapi.RedisClusterClusterStatus{},
+				new: &rapi.RedisClusterClusterStatus{MetricsTime: &kmetav1.Time{Time: time.Unix(1000, 0)}},
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -294,6 +319,30 @@ func Test_compareNodes(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "Zone change",
+			args: args{
+				nodeA: &rapi.RedisClusterNode{KubeNode: "node1", Zone: "zone-a"},
+				nodeB: &rapi.RedisClusterNode{KubeNode: "node1", Zone: "zone-b"},
+			},
+			want: true,
+		},
+		{
+			name: "LastFailoverTime change",
+			args: args{
+				nodeA: &rapi.RedisClusterNode{},
+				nodeB: &rapi.RedisClusterNode{LastFailoverTime: &kmetav1.Time{Time: time.Unix(1000, 0)}},
+			},
+			want: true,
+		},
+		{
+			name: "Metrics change",
+			args: args{
+				nodeA: &rapi.RedisClusterNode{Metrics: &rapi.RedisClusterNodeMetrics{Keys: 10}},
+				nodeB: &rapi.RedisClusterNode{Metrics: &rapi.RedisClusterNodeMetrics{Keys: 12}},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1049,6 +1098,63 @@ func Test_comparePodsWithPodTemplate(t *testing.T) {
 	}
 }
 
+func Test_nbShardsToReplace(t *testing.T) {
+	type args struct {
+		strategy        *rapi.RedisClusterUpdateStrategy
+		nbUpdatedShards int32
+	}
+	tests := []struct {
+		name string
+		args args
+		want int32
+	}{
+		{
+			name: "no strategy, one shard at a time",
+			args: args{nbUpdatedShards: 0},
+			want: 1,
+		},
+		{
+			name: "max surge",
+			args: args{strategy: &rapi.RedisClusterUpdateStrategy{MaxSurgeShards: rapi.NewInt32(2)}, nbUpdatedShards: 0},
+			want: 2,
+		},
+		{
+			name: "max surge bigger than the remaining shards",
+			args: args{strategy: &rapi.RedisClusterUpdateStrategy{MaxSurgeShards: rapi.NewInt32(2)}, nbUpdatedShards: 3},
+			want: 1,
+		},
+		{
+			name: "partition not reached",
+			args: args{strategy: &rapi.RedisClusterUpdateStrategy{MaxSurgeShards: rapi.NewInt32(3), Partition: rapi.NewInt32(2)}, nbUpdatedShards: 1},
+			want: 1,
+		},
+		{
+			name: "partition reached",
+			args: args{strategy: &rapi.RedisClusterUpdateStrategy{Partition: rapi.NewInt32(2)}, nbUpdatedShards: 2},
+			want: 0,
+		},
+		{
+			name: "all shards updated",
+			args: args{nbUpdatedShards: 4},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &rapi.RedisCluster{
+				Spec: rapi.RedisClusterSpec{
+					NumberOfMaster:    rapi.NewInt32(4),
+					ReplicationFactor: rapi.NewInt32(1),
+					UpdateStrategy:    tt.args.strategy,
+				},
+			}
+			if got := nbShardsToReplace(cluster, tt.args.nbUpdatedShards); got != tt.want {
+				t.Errorf("nbShardsToReplace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func newPodWithContainer(name, node string, containersInfos map[string]string) *kapi.Pod {
 	var containers []kapi.Container
 	for name, image := range containersInfos {
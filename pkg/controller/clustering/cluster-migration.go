@@ -54,9 +54,15 @@ func DispatchMasters(cluster *redis.Cluster, nodes redis.Nodes, nbMaster int32,
 func DispatchSlotToNewMasters(cluster *redis.Cluster, admin redis.AdminInterface, newMasterNodes, currentMasterNodes, allMasterNodes redis.Nodes) error {
 	// Calculate the Migration slot information (which slots goes from where to where)
 	migrationSlotInfo, info := feedMigInfo(newMasterNodes, currentMasterNodes, allMasterNodes, int(admin.GetHashMaxSlot()+1))
-	cluster.ActionsInfo = info
+	cluster.ActionsInfo.NbslotsToMigrate = info.NbslotsToMigrate
 	cluster.Status = v1.ClusterStatusRebalancing
+	return applyMigInfo(cluster, admin, migrationSlotInfo, allMasterNodes)
+}
+
+// applyMigInfo moves the slots and their keys between the masters, each migration is recorded in the cluster actions info
+func applyMigInfo(cluster *redis.Cluster, admin redis.AdminInterface, migrationSlotInfo mapSlotByMigInfo, allMasterNodes redis.Nodes) error {
 	for nodesInfo, slots := range migrationSlotInfo {
+		migration := redis.SlotsMigration{From: nodesInfo.From, To: nodesInfo.To, Slots: slots}
 		// There is a need for real error handling here, we must ensure we don't keep a slot in abnormal state
 		if nodesInfo.From == nil {
 			if glog.V(4) {
@@ -65,6 +71,8 @@ func DispatchSlotToNewMasters(cluster *redis.Cluster, admin redis.AdminInterface
 			err := admin.AddSlots(nodesInfo.To.IPPort(), slots)
 			if err != nil {
 				glog.Error("Error during ADDSLOTS:", err)
+				migration.Err = err
+				cluster.ActionsInfo.Migrations = append(cluster.ActionsInfo.Migrations, migration)
 				return err
 			}
 		} else {
@@ -72,19 +80,25 @@ func DispatchSlotToNewMasters(cluster *redis.Cluster, admin redis.AdminInterface
 			err := admin.SetSlots(nodesInfo.To.IPPort(), "IMPORTING", slots, nodesInfo.From.ID)
 			if err != nil {
 				glog.Error("Error during IMPORTING:", err)
+				migration.Err = err
+				cluster.ActionsInfo.Migrations = append(cluster.ActionsInfo.Migrations, migration)
 				return err
 			}
 			glog.V(6).Info("2) Send SETSLOT MIGRATION command target:", nodesInfo.From.ID, " destination-node:", nodesInfo.To.ID, " total:", len(slots), " : ", redis.SlotSlice(slots))
 			err = admin.SetSlots(nodesInfo.From.IPPort(), "MIGRATING", slots, nodesInfo.To.ID)
 			if err != nil {
 				glog.Error("Error during MIGRATING:", err)
+				migration.Err = err
+				cluster.ActionsInfo.Migrations = append(cluster.ActionsInfo.Migrations, migration)
 				return err
 			}
 
 			glog.V(6).Info("3) Migrate Key")
 			nbMigrated, migerr := admin.MigrateKeys(nodesInfo.From.IPPort(), nodesInfo.To, slots, 10, 30000, true)
+			migration.NbKeys = nbMigrated
 			if migerr != nil {
 				glog.Error("Error during MIGRATION:", migerr)
+				migration.Err = migerr
 			} else {
 				glog.V(7).Infof("   Migrated %d Key", nbMigrated)
 			}
@@ -130,14 +144,19 @@ func DispatchSlotToNewMasters(cluster *redis.Cluster, admin redis.AdminInterface
 		}
 		// Update bom
 		nodesInfo.To.Slots = redis.AddSlots(nodesInfo.To.Slots, slots)
+		cluster.ActionsInfo.Migrations = append(cluster.ActionsInfo.Migrations, migration)
 	}
 	return nil
 }
 
 func feedMigInfo(newMasterNodes, oldMasterNodes, allMasterNodes redis.Nodes, nbSlots int) (mapOut mapSlotByMigInfo, info redis.ClusterActionsInfo) {
-	mapOut = make(mapSlotByMigInfo)
 	mapSlotToUpdate := buildSlotsByNode(newMasterNodes, oldMasterNodes, allMasterNodes, nbSlots)
+	return buildMigInfo(mapSlotToUpdate, newMasterNodes, oldMasterNodes)
+}
 
+// buildMigInfo builds the migrations (which slots goes from where to where) needed to give the slots to the nodes
+func buildMigInfo(mapSlotToUpdate map[string][]redis.Slot, newMasterNodes, oldMasterNodes redis.Nodes) (mapOut mapSlotByMigInfo, info redis.ClusterActionsInfo) {
+	mapOut = make(mapSlotByMigInfo)
 	for id, slots := range mapSlotToUpdate {
 		for _, s := range slots {
 			found := false
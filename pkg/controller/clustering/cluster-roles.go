@@ -58,6 +58,7 @@ func AttachingSlavesToMaster(cluster *redis.Cluster, admin redis.AdminInterface,
 				glog.Errorf("Error while attaching node %s to master %s: %v", slave.ID, masterID, err)
 				globalErr = err
 			}
+			cluster.ActionsInfo.Attachments = append(cluster.ActionsInfo.Attachments, redis.SlaveAttachment{Slave: slave, Master: masterNode, Err: err})
 		}
 	}
 	return globalErr
@@ -0,0 +1,93 @@
+package clustering
+
+import (
+	"fmt"
+	"strings"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// SlotsMigratedEventReason is the reason of the event emitted when slots and their keys have been moved to another master
+	SlotsMigratedEventReason = "SlotsMigrated"
+	// SlotsAssignedEventReason is the reason of the event emitted when slots without owner have been assigned to a master
+	SlotsAssignedEventReason = "SlotsAssigned"
+	// SlotsMigrationFailedEventReason is the reason of the event emitted when slots can't be moved to another master
+	SlotsMigrationFailedEventReason = "SlotsMigrationFailed"
+	// SlaveAttachedEventReason is the reason of the event emitted when a node has been attached as slave of a master
+	SlaveAttachedEventReason = "SlaveAttached"
+	// SlaveAttachFailedEventReason is the reason of the event emitted when a node can't be attached to a master
+	SlaveAttachFailedEventReason = "SlaveAttachFailed"
+	// SlaveDetachedEventReason is the reason of the event emitted when a slave has been detached from its master
+	SlaveDetachedEventReason = "SlaveDetached"
+	// NodeForgottenEventReason is the reason of the event emitted when a node has been removed from the cluster with CLUSTER FORGET
+	NodeForgottenEventReason = "NodeForgotten"
+	// ForgetNodeFailedEventReason is the reason of the event emitted when a node can't be forgotten
+	ForgetNodeFailedEventReason = "ForgetNodeFailed"
+	// NodeResetEventReason is the reason of the event emitted when the keys of a node have been flushed and the node has been reset
+	NodeResetEventReason = "NodeReset"
+	// NodeResetFailedEventReason is the reason of the event emitted when a node can't be flushed and reset
+	NodeResetFailedEventReason = "NodeResetFailed"
+	// NodeBackedUpEventReason is the reason of the event emitted when the keys of a node have been dumped with BGSAVE
+	NodeBackedUpEventReason = "NodeBackedUp"
+	// NodeBackupFailedEventReason is the reason of the event emitted when the keys of a node can't be dumped
+	NodeBackupFailedEventReason = "NodeBackupFailed"
+	// FailoverEventReason is the reason of the event emitted when a failover of a master has been started
+	FailoverEventReason = "Failover"
+	// FailoverFailedEventReason is the reason of the event emitted when a failover of a master can't be started
+	FailoverFailedEventReason = "FailoverFailed"
+)
+
+// RecordActions emits an event on obj for each slots migration and slave attachment stored in the actions info
+// of the cluster, then the recorded actions are cleared
+func RecordActions(recorder record.EventRecorder, obj runtime.Object, cluster *redis.Cluster) {
+	if cluster == nil {
+		return
+	}
+	for _, migration := range cluster.ActionsInfo.Migrations {
+		slots := FormatSlots(migration.Slots)
+		switch {
+		case migration.From == nil && migration.Err != nil:
+			recorder.Eventf(obj, kapiv1.EventTypeWarning, SlotsMigrationFailedEventReason, "Unable to assign slots %s to master %s: %v", slots, NodeDescription(migration.To), migration.Err)
+		case migration.From == nil:
+			recorder.Eventf(obj, kapiv1.EventTypeNormal, SlotsAssignedEventReason, "Slots %s assigned to master %s", slots, NodeDescription(migration.To))
+		case migration.Err != nil:
+			recorder.Eventf(obj, kapiv1.EventTypeWarning, SlotsMigrationFailedEventReason, "Unable to move slots %s from master %s to master %s: %v", slots, NodeDescription(migration.From), NodeDescription(migration.To), migration.Err)
+		default:
+			recorder.Eventf(obj, kapiv1.EventTypeNormal, SlotsMigratedEventReason, "Slots %s moved from master %s to master %s, %d keys migrated", slots, NodeDescription(migration.From), NodeDescription(migration.To), migration.NbKeys)
+		}
+	}
+	for _, attachment := range cluster.ActionsInfo.Attachments {
+		if attachment.Err != nil {
+			recorder.Eventf(obj, kapiv1.EventTypeWarning, SlaveAttachFailedEventReason, "Unable to attach node %s to master %s: %v", NodeDescription(attachment.Slave), NodeDescription(attachment.Master), attachment.Err)
+			continue
+		}
+		recorder.Eventf(obj, kapiv1.EventTypeNormal, SlaveAttachedEventReason, "Node %s attached as slave of master %s", NodeDescription(attachment.Slave), NodeDescription(attachment.Master))
+	}
+	cluster.ActionsInfo.Migrations = nil
+	cluster.ActionsInfo.Attachments = nil
+}
+
+// NodeDescription returns the node ID followed by the name of its pod, or by its address if the pod is unknown
+func NodeDescription(node *redis.Node) string {
+	if node == nil {
+		return "<none>"
+	}
+	if node.Pod != nil {
+		return fmt.Sprintf("%s (pod %s)", node.ID, node.Pod.Name)
+	}
+	return fmt.Sprintf("%s (%s)", node.ID, node.IPPort())
+}
+
+// FormatSlots returns the slots as a list of ranges, for instance "0-99,200-200"
+func FormatSlots(slots []redis.Slot) string {
+	ranges := []string{}
+	for _, slotRange := range redis.SlotRangesFromSlots(slots) {
+		ranges = append(ranges, slotRange.String())
+	}
+	return strings.Join(ranges, ",")
+}
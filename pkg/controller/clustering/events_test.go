@@ -0,0 +1,47 @@
+package clustering
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+func TestRecordActions(t *testing.T) {
+	master1 := &redis.Node{ID: "master1", IP: "10.0.0.1", Port: "6379", Pod: &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}}
+	master2 := &redis.Node{ID: "master2", IP: "10.0.0.2", Port: "6379"}
+	slave := &redis.Node{ID: "slave", IP: "10.0.0.3", Port: "6379"}
+
+	cluster := redis.NewCluster("cluster", "ns")
+	cluster.ActionsInfo.Migrations = []redis.SlotsMigration{
+		{From: master1, To: master2, Slots: []redis.Slot{4, 1, 2, 3, 10}, NbKeys: 12},
+		{To: master2, Slots: []redis.Slot{20}},
+		{From: master1, To: master2, Slots: []redis.Slot{30}, Err: fmt.Errorf("timeout")},
+	}
+	cluster.ActionsInfo.Attachments = []redis.SlaveAttachment{{Slave: slave, Master: master1}}
+
+	recorder := record.NewFakeRecorder(10)
+	RecordActions(recorder, &kapiv1.Pod{}, cluster)
+	close(recorder.Events)
+	got := []string{}
+	for event := range recorder.Events {
+		got = append(got, event)
+	}
+	want := []string{
+		"Normal SlotsMigrated Slots 1-4,10-10 moved from master master1 (pod pod1) to master master2 (10.0.0.2:6379), 12 keys migrated",
+		"Normal SlotsAssigned Slots 20-20 assigned to master master2 (10.0.0.2:6379)",
+		"Warning SlotsMigrationFailed Unable to move slots 30-30 from master master1 (pod pod1) to master master2 (10.0.0.2:6379): timeout",
+		"Normal SlaveAttached Node slave (10.0.0.3:6379) attached as slave of master master1 (pod pod1)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordActions() events = %v, want %v", got, want)
+	}
+	if len(cluster.ActionsInfo.Migrations) != 0 || len(cluster.ActionsInfo.Attachments) != 0 {
+		t.Errorf("RecordActions() should clear the recorded actions")
+	}
+}
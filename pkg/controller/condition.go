@@ -39,7 +39,7 @@ func setCondition(clusterStatus *rapi.RedisClusterStatus, conditionType rapi.Red
 	for i, c := range clusterStatus.Conditions {
 		if c.Type == conditionType {
 			found = true
-			if c.Status != status {
+			if c.Status != status || c.Reason != reason || c.Message != message {
 				updated = true
 				clusterStatus.Conditions[i] = updateCondition(c, status, now, reason, message)
 			}
@@ -77,9 +77,8 @@ func setRollingUpdategCondition(clusterStatus *rapi.RedisClusterStatus, status b
 }
 
 func setClusterStatusCondition(clusterStatus *rapi.RedisClusterStatus, status bool) bool {
-	statusCondition := apiv1.ConditionFalse
 	if status {
-		statusCondition = apiv1.ConditionTrue
+		return setCondition(clusterStatus, rapi.RedisClusterOK, apiv1.ConditionTrue, metav1.Now(), "redis-cluster is correctly configure", "redis-cluster is correctly configure")
 	}
-	return setCondition(clusterStatus, rapi.RedisClusterOK, statusCondition, metav1.Now(), "redis-cluster is correctly configure", "redis-cluster is correctly configure")
+	return setCondition(clusterStatus, rapi.RedisClusterOK, apiv1.ConditionFalse, metav1.Now(), "redis-cluster is not correctly configured", "redis-cluster is not correctly configured")
 }
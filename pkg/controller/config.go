@@ -4,14 +4,18 @@ import "github.com/zh168654/Redis-Operator/pkg/config"
 
 // Config contains the Controller settings
 type Config struct {
-	NbWorker int
-	redis    config.Redis
+	NbWorker     int
+	redis        config.Redis
+	sanityChecks config.SanityChecks
+	audit        config.Audit
 }
 
 // NewConfig builds and returns new Config instance
-func NewConfig(nbWorker int, redis config.Redis) *Config {
+func NewConfig(nbWorker int, redis config.Redis, sanityChecks config.SanityChecks, audit config.Audit) *Config {
 	return &Config{
-		NbWorker: nbWorker,
-		redis:    redis,
+		NbWorker:     nbWorker,
+		redis:        redis,
+		sanityChecks: sanityChecks,
+		audit:        audit,
 	}
 }
@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 
 	"math"
+	"os"
 	"reflect"
 	"time"
 
@@ -11,7 +13,6 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	apiv1 "k8s.io/api/core/v1"
-	policyv1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -43,6 +44,9 @@ type Controller struct {
 	redisClusterLister rlisters.RedisClusterLister
 	RedisClusterSynced cache.InformerSynced
 
+	redisClusterImportLister rlisters.RedisClusterImportLister
+	RedisClusterImportSynced cache.InformerSynced
+
 	podLister corev1listers.PodLister
 	PodSynced cache.InformerSynced
 
@@ -52,14 +56,25 @@ type Controller struct {
 	podDisruptionBudgetLister  policyv1listers.PodDisruptionBudgetLister
 	PodDiscruptionBudgetSynced cache.InformerSynced
 
+	nodeLister corev1listers.NodeLister
+	NodeSynced cache.InformerSynced
+
+	adminPool *adminPool // admin connections of each RedisCluster, kept across the syncs
+	auditSink redis.AuditSink // records the mutating redis commands, nil if the audit log is disabled
+
 	podControl                 pod.RedisClusterControlInteface
 	serviceControl             ServicesControlInterface
 	podDisruptionBudgetControl PodDisruptionBudgetsControlInterface
 
 	updateHandler func(*rapi.RedisCluster) (*rapi.RedisCluster, error) // callback to update RedisCluster. Added as member for testing
 
+	updateImportHandler func(*rapi.RedisClusterImport) (*rapi.RedisClusterImport, error) // callback to update RedisClusterImport. Added as member for testing
+
 	queue workqueue.RateLimitingInterface // RedisClusters to be synced
 
+	importQueue    workqueue.RateLimitingInterface // RedisClusterImports to be synced
+	importWatchers *importWatchers
+
 	recorder record.EventRecorder
 
 	config *Config
@@ -75,22 +90,31 @@ func NewController(cfg *Config, kubeClient clientset.Interface, redisClient rcli
 	serviceInformer := kubeInformer.Core().V1().Services()
 	podInformer := kubeInformer.Core().V1().Pods()
 	redisInformer := rInformer.Redisoperator().V1().RedisClusters()
+	redisImportInformer := rInformer.Redisoperator().V1().RedisClusterImports()
 	podDisruptionBudgetInformer := kubeInformer.Policy().V1beta1().PodDisruptionBudgets()
+	nodeInformer := kubeInformer.Core().V1().Nodes()
 
 	ctrl := &Controller{
 		kubeClient:                 kubeClient,
 		redisClient:                redisClient,
 		redisClusterLister:         redisInformer.Lister(),
 		RedisClusterSynced:         redisInformer.Informer().HasSynced,
+		redisClusterImportLister:   redisImportInformer.Lister(),
+		RedisClusterImportSynced:   redisImportInformer.Informer().HasSynced,
 		podLister:                  podInformer.Lister(),
 		PodSynced:                  podInformer.Informer().HasSynced,
 		serviceLister:              serviceInformer.Lister(),
 		ServiceSynced:              serviceInformer.Informer().HasSynced,
 		podDisruptionBudgetLister:  podDisruptionBudgetInformer.Lister(),
 		PodDiscruptionBudgetSynced: podDisruptionBudgetInformer.Informer().HasSynced,
+		nodeLister:                 nodeInformer.Lister(),
+		NodeSynced:                 nodeInformer.Informer().HasSynced,
 
-		queue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "rediscluster"),
-		recorder: eventBroadcaster.NewRecorder(scheme.Scheme, apiv1.EventSource{Component: "rediscluster-controller"}),
+		queue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "rediscluster"),
+		importQueue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "redisclusterimport"),
+		importWatchers: newImportWatchers(),
+		adminPool:      newAdminPool(&cfg.redis),
+		recorder:       eventBroadcaster.NewRecorder(scheme.Scheme, apiv1.EventSource{Component: "rediscluster-controller"}),
 
 		config: cfg,
 	}
@@ -103,6 +127,14 @@ func NewController(cfg *Config, kubeClient clientset.Interface, redisClient rcli
 		},
 	)
 
+	redisImportInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    ctrl.onAddRedisClusterImport,
+			UpdateFunc: ctrl.onUpdateRedisClusterImport,
+			DeleteFunc: ctrl.onDeleteRedisClusterImport,
+		},
+	)
+
 	podInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
 			AddFunc:    ctrl.onAddPod,
@@ -111,7 +143,23 @@ func NewController(cfg *Config, kubeClient clientset.Interface, redisClient rcli
 		},
 	)
 
+	nodeInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    ctrl.onAddNode,
+			UpdateFunc: ctrl.onUpdateNode,
+		},
+	)
+
+	auditSink, err := newAuditSink(&cfg.audit, kubeClient, ctrl.redisClusterLister)
+	if err != nil {
+		// the audit records are written in the operator log rather than lost
+		glog.Errorf("Unable to init the audit log, the records are written on the standard output: %v", err)
+		auditSink = redis.NewJSONLinesAuditSink(os.Stdout)
+	}
+	ctrl.auditSink = auditSink
+
 	ctrl.updateHandler = ctrl.updateRedisCluster
+	ctrl.updateImportHandler = ctrl.updateRedisClusterImport
 	ctrl.podControl = pod.NewRedisClusterControl(ctrl.podLister, ctrl.kubeClient, ctrl.recorder)
 	ctrl.serviceControl = NewServicesControl(ctrl.kubeClient, ctrl.recorder)
 	ctrl.podDisruptionBudgetControl = NewPodDisruptionBudgetsControl(ctrl.kubeClient, ctrl.recorder)
@@ -123,30 +171,42 @@ func NewController(cfg *Config, kubeClient clientset.Interface, redisClient rcli
 func (c *Controller) Run(stop <-chan struct{}) error {
 	glog.Infof("Starting RedisCluster controller")
 
-	if !cache.WaitForCacheSync(stop, c.PodSynced, c.RedisClusterSynced, c.ServiceSynced) {
+	if !cache.WaitForCacheSync(stop, c.PodSynced, c.RedisClusterSynced, c.RedisClusterImportSynced, c.ServiceSynced, c.NodeSynced) {
 		return fmt.Errorf("Timed out waiting for caches to sync")
 	}
 
+	// the redis operations of the workers are interrupted when the controller stops
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	for i := 0; i < c.config.NbWorker; i++ {
-		go wait.Until(c.runWorker, time.Second, stop)
+		go wait.Until(func() { c.runWorker(ctx) }, time.Second, stop)
+	}
+	go wait.Until(func() { c.runImportWorker(ctx) }, time.Second, stop)
+	if c.config.redis.IdleCheckInterval > 0 {
+		interval := time.Duration(c.config.redis.IdleCheckInterval) * time.Millisecond
+		go wait.Until(func() { c.adminPool.CheckIdleConnections(interval) }, interval, stop)
+	}
+	if sink, ok := c.auditSink.(*configMapAuditSink); ok {
+		go wait.Until(sink.Flush, c.config.audit.ConfigMapFlushInterval, stop)
+		defer sink.Flush()
 	}
 
 	<-stop
 	return nil
 }
 
-func (c *Controller) runWorker() {
-	for c.processNextItem() {
+func (c *Controller) runWorker(ctx context.Context) {
+	for c.processNextItem(ctx) {
 	}
 }
 
-func (c *Controller) processNextItem() bool {
+func (c *Controller) processNextItem(ctx context.Context) bool {
 	key, quit := c.queue.Get()
 	if quit {
 		return false
 	}
 	defer c.queue.Done(key)
-	needRequeue, err := c.sync(key.(string))
+	needRequeue, err := c.sync(ctx, key.(string))
 	if err == nil {
 		c.queue.Forget(key)
 	} else {
@@ -163,7 +223,7 @@ func (c *Controller) processNextItem() bool {
 	return true
 }
 
-func (c *Controller) sync(key string) (bool, error) {
+func (c *Controller) sync(ctx context.Context, key string) (bool, error) {
 	glog.V(2).Infof("sync() key:%s", key)
 	startTime := metav1.Now()
 	defer func() {
@@ -177,6 +237,9 @@ func (c *Controller) sync(key string) (bool, error) {
 	sharedRedisCluster, err := c.redisClusterLister.RedisClusters(namespace).Get(name)
 	if err != nil {
 		glog.Errorf("unable to get RedisCluster %s/%s: %v. Maybe deleted", namespace, name, err)
+		c.adminPool.Close(key)
+		c.adminPool.Close(getStandbySourceAdminKey(key))
+		c.adminPool.Close(getSentinelAdminKey(key))
 		return false, nil
 	}
 
@@ -194,6 +257,9 @@ func (c *Controller) sync(key string) (bool, error) {
 
 	// TODO: add test the case of graceful deletion
 	if sharedRedisCluster.DeletionTimestamp != nil {
+		c.adminPool.Close(key)
+		c.adminPool.Close(getStandbySourceAdminKey(key))
+		c.adminPool.Close(getSentinelAdminKey(key))
 		return false, nil
 	}
 
@@ -209,76 +275,24 @@ func (c *Controller) sync(key string) (bool, error) {
 		glog.V(4).Infof("RedisCluster %s/%s: startTime updated", namespace, name)
 		return false, nil
 	}
-	return c.syncCluster(rediscluster)
+	return c.syncCluster(ctx, rediscluster)
 }
 
-func (c *Controller) getRedisClusterService(redisCluster *rapi.RedisCluster) (*apiv1.Service, error) {
-	serviceName := getServiceName(redisCluster)
-	labels, err := pod.GetLabelsSet(redisCluster)
-	if err != nil {
-		return nil, fmt.Errorf("couldn't get cluster label, err: %v ", err)
-	}
-
-	svcList, err := c.serviceLister.Services(redisCluster.Namespace).List(labels.AsSelector())
-	if err != nil {
-		return nil, fmt.Errorf("couldn't list service with label:%s, err:%v ", labels.String(), err)
-	}
-	var svc *apiv1.Service
-	for i, s := range svcList {
-		if s.Name == serviceName {
-			svc = svcList[i]
-		}
-	}
-	return svc, nil
-}
-
-func (c *Controller) getRedisClusterPodDisruptionBudget(redisCluster *rapi.RedisCluster) (*policyv1.PodDisruptionBudget, error) {
-	podDisruptionBudgetName := redisCluster.Name
-	labels, err := pod.GetLabelsSet(redisCluster)
-	if err != nil {
-		return nil, fmt.Errorf("couldn't get cluster label, err: %v ", err)
-	}
-
-	pdbList, err := c.podDisruptionBudgetLister.PodDisruptionBudgets(redisCluster.Namespace).List(labels.AsSelector())
-	if err != nil {
-		return nil, fmt.Errorf("couldn't list PodDisruptionBudget with label:%s, err:%v ", labels.String(), err)
-	}
-	var pdb *policyv1.PodDisruptionBudget
-	for i, p := range pdbList {
-		if p.Name == podDisruptionBudgetName {
-			pdb = pdbList[i]
-		}
-	}
-	return pdb, nil
-}
-
-func (c *Controller) syncCluster(rediscluster *rapi.RedisCluster) (forceRequeue bool, err error) {
+func (c *Controller) syncCluster(ctx context.Context, rediscluster *rapi.RedisCluster) (forceRequeue bool, err error) {
 	glog.V(6).Info("syncCluster START")
 	defer glog.V(6).Info("syncCluster STOP")
 	forceRequeue = false
-	redisClusterService, err := c.getRedisClusterService(rediscluster)
-	if err != nil {
-		glog.Errorf("RedisCluster-Operator.sync unable to retrieves service associated to the RedisCluster: %s/%s", rediscluster.Namespace, rediscluster.Name)
-		return forceRequeue, err
-	}
-	if redisClusterService == nil {
-		if _, err = c.serviceControl.CreateRedisClusterService(rediscluster); err != nil {
-			glog.Errorf("RedisCluster-Operator.sync unable to create service associated to the RedisCluster: %s/%s", rediscluster.Namespace, rediscluster.Name)
-			return forceRequeue, err
+	// Label and own the pods of the existing cluster to adopt
+	if isAdoptionPending(rediscluster) && !isSentinelMode(rediscluster) {
+		adopted, err := c.adoptPods(rediscluster)
+		if err != nil {
+			glog.Errorf("unable to adopt the pods of cluster %s/%s, err:%v", rediscluster.Namespace, rediscluster.Name, err)
 		}
-	}
-
-	redisClusterPodDisruptionBudget, err := c.getRedisClusterPodDisruptionBudget(rediscluster)
-	if err != nil {
-		glog.Errorf("RedisCluster-Operator.sync unable to retrieves podDisruptionBudget associated to the RedisCluster: %s/%s", rediscluster.Namespace, rediscluster.Name)
-		return forceRequeue, err
-	}
-	if redisClusterPodDisruptionBudget == nil {
-		if _, err = c.podDisruptionBudgetControl.CreateRedisClusterPodDisruptionBudget(rediscluster); err != nil {
-			glog.Errorf("RedisCluster-Operator.sync unable to create podDisruptionBudget associated to the RedisCluster: %s/%s", rediscluster.Namespace, rediscluster.Name)
-			return forceRequeue, err
+		if adopted {
+			return true, nil
 		}
 	}
+
 	redisClusterPods, err := c.podControl.GetRedisClusterPods(rediscluster)
 	if err != nil {
 		glog.Errorf("RedisCluster-Operator.sync unable to retrieves pod associated to the RedisCluster: %s/%s", rediscluster.Namespace, rediscluster.Name)
@@ -294,17 +308,47 @@ func (c *Controller) syncCluster(rediscluster *rapi.RedisCluster) (forceRequeue
 		redisClusterPods = Pods
 	}
 
-	// RedisAdmin is used access the Redis process in the different pods.
-	admin, err := NewRedisAdmin(redisClusterPods, &c.config.redis)
-	if err != nil {
-		return forceRequeue, fmt.Errorf("unable to create the redis.Admin, err:%v", err)
+	// the Services follow the spec and the pods, the manual edits of the fields owned by the operator are reverted
+	if err = c.syncServices(rediscluster, redisClusterPods); err != nil {
+		glog.Errorf("RedisCluster-Operator.sync unable to reconcile the services associated to the RedisCluster: %s/%s, err:%v", rediscluster.Namespace, rediscluster.Name, err)
+		return forceRequeue, err
+	}
+	// the PodDisruptionBudgets follow the shards of the last status
+	if err = c.syncPodDisruptionBudgets(rediscluster, redisClusterPods); err != nil {
+		glog.Errorf("RedisCluster-Operator.sync unable to reconcile the podDisruptionBudgets associated to the RedisCluster: %s/%s, err:%v", rediscluster.Namespace, rediscluster.Name, err)
+		return forceRequeue, err
 	}
-	defer admin.Close()
+
+	// the mutating commands sent to the redis nodes are recorded in the audit log
+	ctx = c.auditContext(ctx, rediscluster)
+	if isSentinelMode(rediscluster) {
+		return c.syncReplication(ctx, rediscluster, redisClusterPods)
+	}
+	if rapi.IsStandbyRedisCluster(rediscluster) {
+		return c.syncStandby(ctx, rediscluster, redisClusterPods)
+	}
+
+	// RedisAdmin is used access the Redis process in the different pods, its connections are kept across the syncs.
+	adminKey := rediscluster.Namespace + "/" + rediscluster.Name
+	// the destructive commands are checked against the guard rails of the cluster
+	admin := redis.NewGuardedAdmin(c.adminPool.Get(adminKey, redisClusterPods).WithContext(ctx), c.newGuardRails(rediscluster))
+	defer c.adminPool.Release(adminKey)
 
 	clusterInfos, errGetInfos := admin.GetClusterInfos()
 	if errGetInfos != nil {
 		glog.Errorf("Error when get cluster infos to rebuild bom : %v", errGetInfos)
 		if clusterInfos.Status == redis.ClusterInfosPartial {
+			previousStatus := rediscluster.Status.DeepCopy()
+			// the nodes still answering can promote the slaves of the lost masters, and make the cluster answer again
+			if _, err = c.fixLostQuorum(admin, rediscluster, clusterInfos); err != nil {
+				glog.Errorf("unable to check the quorum of cluster %s/%s, err:%v", rediscluster.Namespace, rediscluster.Name, err)
+			}
+			setHealthConditions(&rediscluster.Status, buildHealthConditions(rediscluster, redisClusterPods, clusterInfos))
+			if !reflect.DeepEqual(*previousStatus, rediscluster.Status) {
+				if _, err = c.updateHandler(rediscluster); err != nil {
+					glog.Errorf("unable to update the conditions of cluster %s/%s, err:%v", rediscluster.Namespace, rediscluster.Name, err)
+				}
+			}
 			return false, fmt.Errorf("partial Cluster infos")
 		}
 	}
@@ -328,6 +372,35 @@ func (c *Controller) syncCluster(rediscluster *rapi.RedisCluster) (forceRequeue
 		return forceRequeue, nil
 	}
 
+	// Report the problems seen in the view of the redis nodes, the conditions are saved with the next status update
+	// to not delay the remediation
+	pruned := pruneGuardRailRefusals(&rediscluster.Status, clusterInfos)
+	healthChanged := setHealthConditions(&rediscluster.Status, buildHealthConditions(rediscluster, redisClusterPods, clusterInfos)) || pruned
+
+	// Don't run any action on an adopted cluster until its topology matches the spec
+	if isAdoptionPending(rediscluster) {
+		return c.manageAdoption(rediscluster, redisClusterPods, clusterInfos)
+	}
+
+	// Move the masters away from the kubernetes nodes that are cordoned, tainted or not ready before they are drained
+	failover, err := c.manageUnavailableKubeNodes(admin, rediscluster, clusterInfos, redisClusterPods)
+	if err != nil {
+		glog.Errorf("unable to failover masters hosted on unavailable nodes for cluster %s/%s, err:%v", rediscluster.Namespace, rediscluster.Name, err)
+	}
+	if failover {
+		return true, nil
+	}
+
+	// Execute the on-demand operations requested in the spec
+	operationDone, err := c.manageOperations(admin, rediscluster, clusterInfos)
+	if err != nil {
+		glog.Errorf("unable to run the operations for cluster %s/%s, err:%v", rediscluster.Namespace, rediscluster.Name, err)
+		return false, err
+	}
+	if operationDone {
+		return true, nil
+	}
+
 	allPodsNotReady := true
 	if (clusterStatus.NbPods - clusterStatus.NbRedisRunning) != 0 {
 		glog.V(3).Infof("All pods not ready wait to be ready, nbPods: %d, nbPodsReady: %d", clusterStatus.NbPods, clusterStatus.NbRedisRunning)
@@ -335,11 +408,19 @@ func (c *Controller) syncCluster(rediscluster *rapi.RedisCluster) (forceRequeue
 	}
 
 	// Now check if the Operator need to execute some operation the redis cluster. if yes run the clusterAction(...) method.
+	previousSanityChecks := append([]rapi.RedisClusterSanityCheckStatus(nil), rediscluster.Status.SanityChecks...)
 	needSanitize, err := c.checkSanityCheck(rediscluster, admin, clusterInfos)
 	if err != nil {
 		glog.Errorf("checkSanityCheck, error happened in dryrun mode, err:%v", err)
 		return false, err
 	}
+	if !needSanitize && !reflect.DeepEqual(previousSanityChecks, rediscluster.Status.SanityChecks) {
+		// report the results of the sanity checks
+		if _, err = c.updateHandler(rediscluster); err != nil {
+			return forceRequeue, err
+		}
+		return true, nil
+	}
 
 	if (allPodsNotReady && needClusterOperation(rediscluster)) || needSanitize {
 		var requeue bool
@@ -354,10 +435,14 @@ func (c *Controller) syncCluster(rediscluster *rapi.RedisCluster) (forceRequeue
 		return forceRequeue, err
 	}
 
+	// no disruptive action waits for a maintenance window or an approval anymore
+	maintenanceDone := rediscluster.Status.Maintenance != nil
+	rediscluster.Status.Maintenance = nil
+	approvalDone := clearApproval(&rediscluster.Status)
 	if setRebalancingCondition(&rediscluster.Status, false) ||
 		setRollingUpdategCondition(&rediscluster.Status, false) ||
 		setScalingCondition(&rediscluster.Status, false) ||
-		setClusterStatusCondition(&rediscluster.Status, true) {
+		setClusterStatusCondition(&rediscluster.Status, !hasHealthIssues(&rediscluster.Status)) || maintenanceDone || approvalDone || healthChanged {
 		_, err = c.updateHandler(rediscluster)
 		return forceRequeue, err
 	}
@@ -366,7 +451,17 @@ func (c *Controller) syncCluster(rediscluster *rapi.RedisCluster) (forceRequeue
 }
 
 func (c *Controller) checkSanityCheck(cluster *rapi.RedisCluster, admin redis.AdminInterface, infos *redis.ClusterInfos) (bool, error) {
-	return sanitycheck.RunSanityChecks(admin, &c.config.redis, c.podControl, cluster, infos, true)
+	return sanitycheck.RunSanityChecks(admin, &c.config.redis, &c.config.sanityChecks, c.podControl, c.recorder, cluster, infos, true)
+}
+
+// fixLostQuorum runs the lost quorum sanity check on the partial cluster infos, returns true if the slaves of the lost
+// masters have been promoted
+func (c *Controller) fixLostQuorum(admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos) (bool, error) {
+	found, err := sanitycheck.RunPartialInfosSanityChecks(admin, &c.config.redis, &c.config.sanityChecks, c.podControl, c.recorder, cluster, infos, true)
+	if err != nil || !found {
+		return false, err
+	}
+	return sanitycheck.RunPartialInfosSanityChecks(admin, &c.config.redis, &c.config.sanityChecks, c.podControl, c.recorder, cluster, infos, false)
 }
 
 func (c *Controller) updateClusterIfNeed(cluster *rapi.RedisCluster, newStatus *rapi.RedisClusterClusterStatus) (bool, error) {
@@ -379,6 +474,7 @@ func (c *Controller) updateClusterIfNeed(cluster *rapi.RedisCluster, newStatus *
 	}
 	// TODO improve this by checking properly the kapi.Pod informations inside each Node
 	cluster.Status.Cluster.Nodes = newStatus.Nodes
+	cluster.Status.Cluster.MetricsTime = newStatus.MetricsTime
 	return false, nil
 }
 
@@ -521,6 +617,8 @@ func (c *Controller) buildClusterStatus(admin redis.AdminInterface, clusterInfos
 	clusterStatus.MaxReplicationFactor = int32(maxReplicationFactor)
 	clusterStatus.MinReplicationFactor = int32(minReplicationFactor)
 
+	c.setNodesDetails(admin, clusterInfos, cluster, clusterStatus)
+
 	glog.V(3).Infof("Build Bom, current Node list : %s ", clusterStatus.String())
 
 	return clusterStatus, nil
@@ -612,7 +710,7 @@ func (c *Controller) onAddPod(obj interface{}) {
 		glog.Errorf("adding Pod, expected Pod object. Got: %+v", obj)
 		return
 	}
-	if _, ok := pod.GetObjectMeta().GetLabels()[rapi.ClusterNameLabelKey]; !ok {
+	if !isRedisClusterPod(pod) {
 		return
 	}
 	redisCluster, err := c.getRedisClusterFromPod(pod)
@@ -630,7 +728,7 @@ func (c *Controller) onAddPod(obj interface{}) {
 
 func (c *Controller) onDeletePod(obj interface{}) {
 	pod, ok := obj.(*apiv1.Pod)
-	if _, ok := pod.GetObjectMeta().GetLabels()[rapi.ClusterNameLabelKey]; !ok {
+	if !isRedisClusterPod(pod) {
 		return
 	}
 	glog.V(6).Infof("onDeletePod old=%v", pod.Name)
@@ -666,7 +764,7 @@ func (c *Controller) onUpdatePod(oldObj, newObj interface{}) {
 	if oldPod.ResourceVersion == newPod.ResourceVersion { // Since periodic resync will send update events for all known Pods.
 		return
 	}
-	if _, ok := newPod.GetObjectMeta().GetLabels()[rapi.ClusterNameLabelKey]; !ok {
+	if !isRedisClusterPod(newPod) {
 		return
 	}
 	glog.V(6).Infof("onUpdatePod old=%v, cur=%v ", oldPod.Name, newPod.Name)
@@ -696,8 +794,22 @@ func (c *Controller) getRedisClusterFromPod(pod *apiv1.Pod) (*rapi.RedisCluster,
 	}
 
 	clusterName, ok := pod.Labels[rapi.ClusterNameLabelKey]
+	if !ok {
+		// sentinel pods of a RedisCluster in Sentinel mode
+		clusterName, ok = pod.Labels[rapi.SentinelNameLabelKey]
+	}
 	if !ok {
 		return nil, fmt.Errorf("no rediscluster name found for pod. Pod %s/%s has no labels %s", pod.Namespace, pod.Name, rapi.ClusterNameLabelKey)
 	}
 	return c.redisClusterLister.RedisClusters(pod.Namespace).Get(clusterName)
 }
+
+// isRedisClusterPod returns true if the pod is a redis node or a sentinel managed by a RedisCluster
+func isRedisClusterPod(pod *apiv1.Pod) bool {
+	labels := pod.GetObjectMeta().GetLabels()
+	if _, ok := labels[rapi.ClusterNameLabelKey]; ok {
+		return true
+	}
+	_, ok := labels[rapi.SentinelNameLabelKey]
+	return ok
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// GuardRailRefusedEventReason is the reason of the event emitted when a destructive command is refused by a guard rail
+	GuardRailRefusedEventReason = "GuardRailRefused"
+)
+
+// guardRailRules are the guard rails applied to the destructive commands
+var guardRailRules = []rapi.RedisClusterGuardRailRule{
+	rapi.GuardRailFlushKeys,
+	rapi.GuardRailForgetNodeWithSlots,
+	rapi.GuardRailMasterRemoval,
+}
+
+// newGuardRails returns the guard rails of the cluster: its spec and its override annotations. The refusals are
+// reported in the status of the cluster and with an event.
+func (c *Controller) newGuardRails(cluster *rapi.RedisCluster) redis.GuardRails {
+	rails := redis.GuardRails{
+		MaxFlushKeys: -1,
+		Overrides:    map[rapi.RedisClusterGuardRailRule][]string{},
+	}
+	if cluster.Spec.GuardRails != nil && cluster.Spec.GuardRails.MaxFlushKeys != nil {
+		rails.MaxFlushKeys = *cluster.Spec.GuardRails.MaxFlushKeys
+	}
+	for _, rule := range guardRailRules {
+		if value, ok := cluster.Annotations[rapi.GuardRailOverrideAnnotationKey(rule)]; ok {
+			rails.Overrides[rule] = parseGuardRailOverride(value)
+		}
+	}
+	rails.Report = func(rule rapi.RedisClusterGuardRailRule, target string, err error) {
+		if setGuardRailRefusal(&cluster.Status, rule, target, err) && err != nil {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, GuardRailRefusedEventReason, "Command refused by the %s guard rail: %v", rule, err)
+		}
+	}
+	return rails
+}
+
+// parseGuardRailOverride returns the targets listed in the value of an override annotation
+func parseGuardRailOverride(value string) []string {
+	targets := []string{}
+	for _, target := range strings.Split(value, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// setGuardRailRefusal records the refusal of the command in the status, or removes it if the command is allowed.
+// Returns true if the refusals changed.
+func setGuardRailRefusal(status *rapi.RedisClusterStatus, rule rapi.RedisClusterGuardRailRule, target string, err error) bool {
+	for i, refusal := range status.GuardRailRefusals {
+		if refusal.Rule != rule || refusal.Target != target {
+			continue
+		}
+		if err == nil {
+			status.GuardRailRefusals = append(status.GuardRailRefusals[:i], status.GuardRailRefusals[i+1:]...)
+			return true
+		}
+		status.GuardRailRefusals[i].LastRefusalTime = metav1.Now()
+		if refusal.Message == err.Error() {
+			return false
+		}
+		status.GuardRailRefusals[i].Message = err.Error()
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	status.GuardRailRefusals = append(status.GuardRailRefusals, rapi.RedisClusterGuardRailRefusal{
+		Rule:            rule,
+		Target:          target,
+		Message:         err.Error(),
+		LastRefusalTime: metav1.Now(),
+	})
+	return true
+}
+
+// pruneGuardRailRefusals removes the refusals whose target isn't known by the redis nodes anymore, returns true if
+// some refusals have been removed
+func pruneGuardRailRefusals(status *rapi.RedisClusterStatus, infos *redis.ClusterInfos) bool {
+	if len(status.GuardRailRefusals) == 0 || infos == nil {
+		return false
+	}
+	known := map[string]bool{}
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil || nodeinfos.Node == nil {
+			continue
+		}
+		for _, node := range append(redis.Nodes{nodeinfos.Node}, nodeinfos.Friends...) {
+			known[node.ID] = true
+			known[node.IPPort()] = true
+		}
+	}
+	refusals := []rapi.RedisClusterGuardRailRefusal{}
+	for _, refusal := range status.GuardRailRefusals {
+		if known[refusal.Target] {
+			refusals = append(refusals, refusal)
+		}
+	}
+	if len(refusals) == len(status.GuardRailRefusals) {
+		return false
+	}
+	status.GuardRailRefusals = refusals
+	return true
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+func TestController_newGuardRails(t *testing.T) {
+	maxFlushKeys := int64(1000)
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			"redis-operator.k8s.io/allow-forget-node-with-slots": "node1, node2",
+			"redis-operator.k8s.io/allow-master-removal":         "*",
+		}},
+		Spec: rapi.RedisClusterSpec{GuardRails: &rapi.RedisClusterGuardRails{MaxFlushKeys: &maxFlushKeys}},
+	}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{recorder: recorder}
+	rails := c.newGuardRails(cluster)
+
+	if rails.MaxFlushKeys != maxFlushKeys {
+		t.Errorf("newGuardRails() MaxFlushKeys = %d, want %d", rails.MaxFlushKeys, maxFlushKeys)
+	}
+	wantOverrides := map[rapi.RedisClusterGuardRailRule][]string{
+		rapi.GuardRailForgetNodeWithSlots: {"node1", "node2"},
+		rapi.GuardRailMasterRemoval:       {"*"},
+	}
+	if !reflect.DeepEqual(rails.Overrides, wantOverrides) {
+		t.Errorf("newGuardRails() Overrides = %v, want %v", rails.Overrides, wantOverrides)
+	}
+
+	// a refusal is reported once in the status and with an event, then removed when the command is allowed
+	rails.Report(rapi.GuardRailFlushKeys, "10.0.0.1:6379", fmt.Errorf("node 10.0.0.1:6379 not flushed"))
+	rails.Report(rapi.GuardRailFlushKeys, "10.0.0.1:6379", fmt.Errorf("node 10.0.0.1:6379 not flushed"))
+	if len(cluster.Status.GuardRailRefusals) != 1 || len(recorder.Events) != 1 {
+		t.Errorf("Report() refusals = %v, %d events, want one refusal and one event", cluster.Status.GuardRailRefusals, len(recorder.Events))
+	}
+	rails.Report(rapi.GuardRailFlushKeys, "10.0.0.1:6379", nil)
+	if len(cluster.Status.GuardRailRefusals) != 0 {
+		t.Errorf("Report() refusals = %v, want none once allowed", cluster.Status.GuardRailRefusals)
+	}
+	if defaults := c.newGuardRails(&rapi.RedisCluster{}); defaults.MaxFlushKeys != -1 || len(defaults.Overrides) != 0 {
+		t.Errorf("newGuardRails() = %v, want no flush limit and no override by default", defaults)
+	}
+}
+
+func Test_pruneGuardRailRefusals(t *testing.T) {
+	status := &rapi.RedisClusterStatus{GuardRailRefusals: []rapi.RedisClusterGuardRailRefusal{
+		{Rule: rapi.GuardRailFlushKeys, Target: "10.0.0.1:6379"},
+		{Rule: rapi.GuardRailMasterRemoval, Target: "master2"},
+		{Rule: rapi.GuardRailForgetNodeWithSlots, Target: "gone"},
+	}}
+	infos := newHealthInfos(newMasterNode("master1", "10.0.0.1"), newMasterNode("master2", "10.0.0.2"))
+	if !pruneGuardRailRefusals(status, infos) || len(status.GuardRailRefusals) != 2 {
+		t.Errorf("pruneGuardRailRefusals() = %v, want the refusal of the node gone removed", status.GuardRailRefusals)
+	}
+	if pruneGuardRailRefusals(status, infos) {
+		t.Errorf("pruneGuardRailRefusals() should not change the refusals of the known nodes")
+	}
+	if !pruneGuardRailRefusals(status, redis.NewClusterInfos()) || len(status.GuardRailRefusals) != 0 {
+		t.Errorf("pruneGuardRailRefusals() = %v, want all the refusals removed", status.GuardRailRefusals)
+	}
+}
@@ -0,0 +1,292 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/sanitycheck"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// slotsNotServedReason some slots are not owned by a healthy master
+	slotsNotServedReason = "SlotsNotServed"
+	// underReplicatedReason some shards have less slaves than the replication factor
+	underReplicatedReason = "UnderReplicated"
+	// clusterSplitReason the redis nodes are split in several clusters
+	clusterSplitReason = "ClusterSplit"
+	// inconsistentViewReason the redis nodes don't agree on the slots owners
+	inconsistentViewReason = "InconsistentView"
+	// openSlotsReason some slots are in migrating or importing state
+	openSlotsReason = "OpenSlots"
+	// nodesFailingReason some redis nodes are flagged PFAIL or FAIL
+	nodesFailingReason = "NodesFailing"
+	// nodesUnreachableReason some redis nodes can't be reached by the operator
+	nodesUnreachableReason = "NodesUnreachable"
+	// awaitingApprovalReason the losing partitions of a cluster split wait for an approval before being flushed
+	awaitingApprovalReason = "AwaitingApproval"
+	// commandsRefusedReason some destructive commands have been refused by the guard rails
+	commandsRefusedReason = "CommandsRefused"
+	// healthyReason reason of the health conditions when the problem is not detected
+	healthyReason = "Healthy"
+)
+
+// healthConditionTypes are the conditions computed from the redis cluster view, in the order they are reported
+var healthConditionTypes = []rapi.RedisClusterConditionType{
+	rapi.RedisClusterDegraded,
+	rapi.RedisClusterPartitioned,
+	rapi.RedisClusterSlotsMigrating,
+	rapi.RedisClusterUnhealthyNodes,
+	rapi.RedisClusterSplitQuarantined,
+	rapi.RedisClusterGuardRailBlocked,
+}
+
+// healthCondition is the state of a condition computed from the redis cluster view
+type healthCondition struct {
+	conditionType rapi.RedisClusterConditionType
+	status        bool
+	reason        string
+	message       string
+}
+
+// setHealthConditions sets the health conditions in the status, returns true if a condition has changed
+func setHealthConditions(clusterStatus *rapi.RedisClusterStatus, conditions []healthCondition) bool {
+	updated := false
+	now := metav1.Now()
+	for _, condition := range conditions {
+		status := apiv1.ConditionFalse
+		if condition.status {
+			status = apiv1.ConditionTrue
+		}
+		if setCondition(clusterStatus, condition.conditionType, status, now, condition.reason, condition.message) {
+			updated = true
+		}
+	}
+	return updated
+}
+
+// hasHealthIssues returns true if one of the health conditions is true
+func hasHealthIssues(clusterStatus *rapi.RedisClusterStatus) bool {
+	for _, c := range clusterStatus.Conditions {
+		for _, conditionType := range healthConditionTypes {
+			if c.Type == conditionType && c.Status == apiv1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildHealthConditions computes the Degraded, Partitioned, SlotsMigrating and UnhealthyNodes conditions from the view of
+// each redis node and from the pods of the cluster, and the SplitQuarantined and GuardRailBlocked conditions from the status
+func buildHealthConditions(cluster *rapi.RedisCluster, pods []*apiv1.Pod, infos *redis.ClusterInfos) []healthCondition {
+	if infos == nil {
+		infos = redis.NewClusterInfos()
+	}
+	failing := getFailingNodes(infos)
+	return []healthCondition{
+		buildDegradedCondition(cluster, infos, failing),
+		buildPartitionedCondition(infos),
+		buildSlotsMigratingCondition(infos),
+		buildUnhealthyNodesCondition(pods, infos, failing),
+		buildSplitQuarantinedCondition(cluster),
+		buildGuardRailBlockedCondition(cluster),
+	}
+}
+
+// getFailingNodes returns the nodes flagged PFAIL or FAIL by at least one redis node, with the worst flag
+func getFailingNodes(infos *redis.ClusterInfos) map[string]string {
+	failing := map[string]string{}
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil {
+			continue
+		}
+		for _, node := range nodeinfos.Friends {
+			if node.HasStatus(redis.NodeStatusFail) {
+				failing[node.ID] = redis.NodeStatusFail
+			} else if node.HasStatus(redis.NodeStatusPFail) && failing[node.ID] == "" {
+				failing[node.ID] = redis.NodeStatusPFail
+			}
+		}
+	}
+	return failing
+}
+
+func buildDegradedCondition(cluster *rapi.RedisCluster, infos *redis.ClusterInfos, failing map[string]string) healthCondition {
+	condition := healthCondition{conditionType: rapi.RedisClusterDegraded, reason: healthyReason, message: "all the slots are served and all the shards are replicated"}
+	nodes := infos.GetNodes()
+	replicationFactor := int32(0)
+	if cluster.Spec.ReplicationFactor != nil {
+		replicationFactor = *cluster.Spec.ReplicationFactor
+	}
+
+	servedSlots := map[redis.Slot]bool{}
+	underReplicated := 0
+	for _, master := range nodes.FilterByFunc(redis.IsMasterWithSlot).SortNodes() {
+		if failing[master.ID] == redis.NodeStatusFail {
+			continue
+		}
+		for _, slot := range master.Slots {
+			servedSlots[slot] = true
+		}
+		nbSlaves := int32(nodes.CountByFunc(func(n *redis.Node) bool {
+			return redis.IsSlave(n) && n.MasterReferent == master.ID && failing[n.ID] == ""
+		}))
+		if nbSlaves < replicationFactor {
+			underReplicated++
+		}
+	}
+
+	messages := []string{}
+	missing := 0
+	for slot := redis.Slot(0); slot <= redis.HashMaxSlots; slot++ {
+		if !servedSlots[slot] {
+			missing++
+		}
+	}
+	if len(nodes) > 0 && missing > 0 {
+		condition.status = true
+		condition.reason = slotsNotServedReason
+		messages = append(messages, fmt.Sprintf("%d slots not served", missing))
+	}
+	if underReplicated > 0 {
+		if !condition.status {
+			condition.status = true
+			condition.reason = underReplicatedReason
+		}
+		messages = append(messages, fmt.Sprintf("%d shards under-replicated", underReplicated))
+	}
+	if condition.status {
+		condition.message = strings.Join(messages, "; ")
+	}
+	return condition
+}
+
+func buildPartitionedCondition(infos *redis.ClusterInfos) healthCondition {
+	condition := healthCondition{conditionType: rapi.RedisClusterPartitioned, reason: healthyReason, message: "all the nodes agree on the cluster configuration"}
+	if nbPartitions := sanitycheck.NbPartitions(infos); nbPartitions > 1 {
+		condition.status = true
+		condition.reason = clusterSplitReason
+		condition.message = fmt.Sprintf("the redis nodes are split in %d clusters", nbPartitions)
+		return condition
+	}
+	if infos.Status == redis.ClusterInfosInconsistent {
+		condition.status = true
+		condition.reason = inconsistentViewReason
+		condition.message = fmt.Sprintf("the nodes don't agree on the owner of %d slots", countInconsistentSlots(infos))
+	}
+	return condition
+}
+
+func buildSplitQuarantinedCondition(cluster *rapi.RedisCluster) healthCondition {
+	condition := healthCondition{conditionType: rapi.RedisClusterSplitQuarantined, reason: healthyReason, message: "no cluster split waiting for an approval"}
+	if q := cluster.Status.SplitQuarantine; q != nil {
+		condition.status = true
+		condition.reason = awaitingApprovalReason
+		condition.message = fmt.Sprintf("cluster split %s: nodes %s will be flushed once the RedisCluster is annotated with %s=%s, main partition: %s", q.ID, strings.Join(q.QuarantinedNodes, ","), rapi.ApproveSplitResolutionAnnotationKey, q.ID, strings.Join(q.MainPartition, ","))
+	}
+	return condition
+}
+
+func buildGuardRailBlockedCondition(cluster *rapi.RedisCluster) healthCondition {
+	condition := healthCondition{conditionType: rapi.RedisClusterGuardRailBlocked, reason: healthyReason, message: "no command refused by the guard rails"}
+	if len(cluster.Status.GuardRailRefusals) > 0 {
+		messages := []string{}
+		for _, refusal := range cluster.Status.GuardRailRefusals {
+			messages = append(messages, refusal.Message)
+		}
+		condition.status = true
+		condition.reason = commandsRefusedReason
+		condition.message = strings.Join(messages, "; ")
+	}
+	return condition
+}
+
+// countInconsistentSlots returns the number of slots for which the owner seen by a node differs from the owner
+// announced by the masters themselves
+func countInconsistentSlots(infos *redis.ClusterInfos) int {
+	slotOwners := func(nodes redis.Nodes) []string {
+		owners := make([]string, redis.HashMaxSlots+1)
+		for _, node := range nodes.FilterByFunc(redis.IsMasterWithSlot) {
+			for _, slot := range node.Slots {
+				if slot <= redis.HashMaxSlots {
+					owners[slot] = node.ID
+				}
+			}
+		}
+		return owners
+	}
+	consolidated := slotOwners(infos.GetNodes())
+	inconsistent := map[int]bool{}
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil || nodeinfos.Node == nil {
+			continue
+		}
+		for slot, owner := range slotOwners(append(redis.Nodes{nodeinfos.Node}, nodeinfos.Friends...)) {
+			if owner != consolidated[slot] {
+				inconsistent[slot] = true
+			}
+		}
+	}
+	return len(inconsistent)
+}
+
+func buildSlotsMigratingCondition(infos *redis.ClusterInfos) healthCondition {
+	condition := healthCondition{conditionType: rapi.RedisClusterSlotsMigrating, reason: healthyReason, message: "no slot in migrating or importing state"}
+	openSlots := map[redis.Slot]bool{}
+	for _, node := range infos.GetNodes() {
+		for slot := range node.MigratingSlots {
+			openSlots[slot] = true
+		}
+		for slot := range node.ImportingSlots {
+			openSlots[slot] = true
+		}
+	}
+	if len(openSlots) == 0 {
+		return condition
+	}
+	condition.status = true
+	condition.reason = openSlotsReason
+	condition.message = fmt.Sprintf("%d slots in migrating or importing state", len(openSlots))
+	return condition
+}
+
+func buildUnhealthyNodesCondition(pods []*apiv1.Pod, infos *redis.ClusterInfos, failing map[string]string) healthCondition {
+	condition := healthCondition{conditionType: rapi.RedisClusterUnhealthyNodes, reason: healthyReason, message: "all the nodes are reachable"}
+	unreachable := 0
+	for _, pod := range pods {
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := infos.Infos[net.JoinHostPort(pod.Status.PodIP, getRedisPort(pod))]; !ok {
+			unreachable++
+		}
+	}
+	nbFlagged := map[string]int{}
+	for _, flag := range failing {
+		nbFlagged[flag]++
+	}
+
+	messages := []string{}
+	if len(failing) > 0 {
+		condition.status = true
+		condition.reason = nodesFailingReason
+		messages = append(messages, fmt.Sprintf("%d nodes flagged FAIL and %d nodes flagged PFAIL by the cluster", nbFlagged[redis.NodeStatusFail], nbFlagged[redis.NodeStatusPFail]))
+	}
+	if unreachable > 0 {
+		if !condition.status {
+			condition.status = true
+			condition.reason = nodesUnreachableReason
+		}
+		messages = append(messages, fmt.Sprintf("%d pods not reachable by the operator", unreachable))
+	}
+	if condition.status {
+		condition.message = strings.Join(messages, "; ")
+	}
+	return condition
+}
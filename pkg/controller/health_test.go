@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+// newHealthInfos returns the cluster infos where each node sees itself and the other nodes as the given nodes
+func newHealthInfos(nodes ...*redis.Node) *redis.ClusterInfos {
+	infos := redis.NewClusterInfos()
+	infos.Status = redis.ClusterInfosConsistent
+	for _, node := range nodes {
+		nodeinfos := &redis.NodeInfos{Node: node}
+		for _, friend := range nodes {
+			if friend.ID != node.ID {
+				view := *friend
+				nodeinfos.Friends = append(nodeinfos.Friends, &view)
+			}
+		}
+		infos.Infos[node.IPPort()] = nodeinfos
+	}
+	return infos
+}
+
+func newHealthSlave(id, ip, masterID string) *redis.Node {
+	slave := newMasterNode(id, ip)
+	slave.Role = "slave"
+	slave.MasterReferent = masterID
+	return slave
+}
+
+func Test_buildHealthConditions(t *testing.T) {
+	cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(2), ReplicationFactor: rapi.NewInt32(1)}}
+	newMasters := func() (*redis.Node, *redis.Node) {
+		return newMasterNode("master1", "10.0.0.1", redis.BuildSlotSlice(0, 8191)...), newMasterNode("master2", "10.0.0.2", redis.BuildSlotSlice(8192, redis.HashMaxSlots)...)
+	}
+	newPod := func(name, ip string) *kapiv1.Pod {
+		return &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: kapiv1.PodStatus{PodIP: ip}}
+	}
+
+	tests := []struct {
+		name        string
+		pods        []*kapiv1.Pod
+		infos       func() *redis.ClusterInfos
+		wantTrue    map[rapi.RedisClusterConditionType]string
+		wantMessage map[rapi.RedisClusterConditionType]string
+	}{
+		{
+			name: "healthy cluster",
+			pods: []*kapiv1.Pod{newPod("pod1", "10.0.0.1")},
+			infos: func() *redis.ClusterInfos {
+				master1, master2 := newMasters()
+				return newHealthInfos(master1, master2, newHealthSlave("slave1", "10.0.0.3", "master1"), newHealthSlave("slave2", "10.0.0.4", "master2"))
+			},
+			wantTrue: map[rapi.RedisClusterConditionType]string{},
+		},
+		{
+			name: "shard without slave",
+			infos: func() *redis.ClusterInfos {
+				master1, master2 := newMasters()
+				return newHealthInfos(master1, master2, newHealthSlave("slave1", "10.0.0.3", "master1"))
+			},
+			wantTrue:    map[rapi.RedisClusterConditionType]string{rapi.RedisClusterDegraded: underReplicatedReason},
+			wantMessage: map[rapi.RedisClusterConditionType]string{rapi.RedisClusterDegraded: "1 shards under-replicated"},
+		},
+		{
+			name: "failed master",
+			infos: func() *redis.ClusterInfos {
+				master1, master2 := newMasters()
+				infos := newHealthInfos(master1, master2, newHealthSlave("slave1", "10.0.0.3", "master1"), newHealthSlave("slave2", "10.0.0.4", "master2"))
+				for _, friend := range infos.Infos["10.0.0.1:6379"].Friends {
+					if friend.ID == "master2" {
+						friend.FailStatus = []string{redis.NodeStatusFail}
+					}
+				}
+				return infos
+			},
+			wantTrue: map[rapi.RedisClusterConditionType]string{rapi.RedisClusterDegraded: slotsNotServedReason, rapi.RedisClusterUnhealthyNodes: nodesFailingReason},
+			wantMessage: map[rapi.RedisClusterConditionType]string{
+				rapi.RedisClusterDegraded:       "8192 slots not served",
+				rapi.RedisClusterUnhealthyNodes: "1 nodes flagged FAIL and 0 nodes flagged PFAIL by the cluster",
+			},
+		},
+		{
+			name: "open slots",
+			infos: func() *redis.ClusterInfos {
+				master1, master2 := newMasters()
+				master1.MigratingSlots[5] = "master2"
+				master2.ImportingSlots[5] = "master1"
+				master2.ImportingSlots[6] = "master1"
+				return newHealthInfos(master1, master2, newHealthSlave("slave1", "10.0.0.3", "master1"), newHealthSlave("slave2", "10.0.0.4", "master2"))
+			},
+			wantTrue:    map[rapi.RedisClusterConditionType]string{rapi.RedisClusterSlotsMigrating: openSlotsReason},
+			wantMessage: map[rapi.RedisClusterConditionType]string{rapi.RedisClusterSlotsMigrating: "2 slots in migrating or importing state"},
+		},
+		{
+			name: "inconsistent view",
+			infos: func() *redis.ClusterInfos {
+				master1, master2 := newMasters()
+				infos := newHealthInfos(master1, master2, newHealthSlave("slave1", "10.0.0.3", "master1"), newHealthSlave("slave2", "10.0.0.4", "master2"))
+				infos.Status = redis.ClusterInfosInconsistent
+				for _, friend := range infos.Infos["10.0.0.3:6379"].Friends {
+					if friend.ID == "master2" {
+						friend.Slots = redis.BuildSlotSlice(8202, redis.HashMaxSlots)
+					}
+				}
+				return infos
+			},
+			wantTrue:    map[rapi.RedisClusterConditionType]string{rapi.RedisClusterPartitioned: inconsistentViewReason},
+			wantMessage: map[rapi.RedisClusterConditionType]string{rapi.RedisClusterPartitioned: "the nodes don't agree on the owner of 10 slots"},
+		},
+		{
+			name: "cluster split",
+			infos: func() *redis.ClusterInfos {
+				master1, master2 := newMasters()
+				infos := newHealthInfos(master1, newHealthSlave("slave1", "10.0.0.3", "master1"))
+				for addr, nodeinfos := range newHealthInfos(master2, newHealthSlave("slave2", "10.0.0.4", "master2")).Infos {
+					infos.Infos[addr] = nodeinfos
+				}
+				return infos
+			},
+			wantTrue: map[rapi.RedisClusterConditionType]string{rapi.RedisClusterPartitioned: clusterSplitReason},
+		},
+		{
+			name: "unreachable pod",
+			pods: []*kapiv1.Pod{newPod("pod1", "10.0.0.1"), newPod("pod9", "10.0.0.9"), newPod("pending", "")},
+			infos: func() *redis.ClusterInfos {
+				master1, master2 := newMasters()
+				return newHealthInfos(master1, master2, newHealthSlave("slave1", "10.0.0.3", "master1"), newHealthSlave("slave2", "10.0.0.4", "master2"))
+			},
+			wantTrue:    map[rapi.RedisClusterConditionType]string{rapi.RedisClusterUnhealthyNodes: nodesUnreachableReason},
+			wantMessage: map[rapi.RedisClusterConditionType]string{rapi.RedisClusterUnhealthyNodes: "1 pods not reachable by the operator"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, condition := range buildHealthConditions(cluster, tt.pods, tt.infos()) {
+				wantReason, wantTrue := tt.wantTrue[condition.conditionType]
+				if condition.status != wantTrue {
+					t.Errorf("condition %s status = %v, want %v (%s: %s)", condition.conditionType, condition.status, wantTrue, condition.reason, condition.message)
+					continue
+				}
+				if wantTrue && condition.reason != wantReason {
+					t.Errorf("condition %s reason = %s, want %s", condition.conditionType, condition.reason, wantReason)
+				}
+				if wantMessage, ok := tt.wantMessage[condition.conditionType]; ok && condition.message != wantMessage {
+					t.Errorf("condition %s message = %q, want %q", condition.conditionType, condition.message, wantMessage)
+				}
+			}
+		})
+	}
+}
+
+func Test_buildSplitQuarantinedCondition(t *testing.T) {
+	cluster := &rapi.RedisCluster{}
+	if condition := buildSplitQuarantinedCondition(cluster); condition.status {
+		t.Errorf("buildSplitQuarantinedCondition() = %v, want false without quarantine", condition)
+	}
+	cluster.Status.SplitQuarantine = &rapi.RedisClusterSplitQuarantineStatus{ID: "0123456789", MainPartition: []string{"10.0.0.1:6379"}, QuarantinedNodes: []string{"10.0.0.2:6379", "10.0.0.3:6379"}}
+	condition := buildSplitQuarantinedCondition(cluster)
+	wantMessage := "cluster split 0123456789: nodes 10.0.0.2:6379,10.0.0.3:6379 will be flushed once the RedisCluster is annotated with redis-operator.k8s.io/approve-split-resolution=0123456789, main partition: 10.0.0.1:6379"
+	if !condition.status || condition.reason != awaitingApprovalReason || condition.message != wantMessage {
+		t.Errorf("buildSplitQuarantinedCondition() = %v, want true with message %q", condition, wantMessage)
+	}
+}
+
+func Test_setHealthConditions(t *testing.T) {
+	status := &rapi.RedisClusterStatus{}
+	conditions := []healthCondition{{conditionType: rapi.RedisClusterDegraded, status: true, reason: underReplicatedReason, message: "1 shards under-replicated"}}
+	if !setHealthConditions(status, conditions) || !hasHealthIssues(status) {
+		t.Errorf("setHealthConditions() should add the Degraded condition")
+	}
+	if setHealthConditions(status, conditions) {
+		t.Errorf("setHealthConditions() should not update an unchanged condition")
+	}
+	conditions[0].message = "2 shards under-replicated"
+	if !setHealthConditions(status, conditions) || status.Conditions[0].Message != "2 shards under-replicated" {
+		t.Errorf("setHealthConditions() should update the message of the condition")
+	}
+	conditions[0] = healthCondition{conditionType: rapi.RedisClusterDegraded, reason: healthyReason}
+	if !setHealthConditions(status, conditions) || hasHealthIssues(status) {
+		t.Errorf("setHealthConditions() should clear the Degraded condition")
+	}
+}
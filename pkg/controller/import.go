@@ -0,0 +1,515 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// importStartedEventReason is the reason of the event emitted when the copy of the source keys starts
+	importStartedEventReason = "ImportStarted"
+	// importRestartedEventReason is the reason of the event emitted when the copy restarts because modified keys may have been missed
+	importRestartedEventReason = "ImportRestarted"
+	// importCompletedEventReason is the reason of the event emitted when the import is completed
+	importCompletedEventReason = "ImportCompleted"
+	// importFailedEventReason is the reason of the event emitted when keys can't be copied
+	importFailedEventReason = "ImportFailed"
+
+	// importMaxBatchesPerSync maximum number of key batches copied during a sync of the RedisClusterImport
+	importMaxBatchesPerSync = 10
+	// importCopyInterval delay between two syncs during the copy
+	importCopyInterval = time.Second
+	// importTailInterval delay between two copies of the modified keys
+	importTailInterval = 5 * time.Second
+	// importPendingInterval delay before checking again the RedisCluster of a pending import
+	importPendingInterval = 10 * time.Second
+)
+
+// keyspaceWatcher collects the keys modified on a source node, implemented by redis.KeyspaceWatcher
+type keyspaceWatcher interface {
+	ModifiedKeys(max int) []string
+	MarkModified(keys []string)
+	Len() int
+	Err() error
+	Close()
+}
+
+// importWatchers stores the keyspace watchers of the source nodes of each RedisClusterImport
+type importWatchers struct {
+	mutex    sync.Mutex
+	watchers map[string]map[string]keyspaceWatcher
+}
+
+func newImportWatchers() *importWatchers {
+	return &importWatchers{watchers: map[string]map[string]keyspaceWatcher{}}
+}
+
+func (i *importWatchers) get(key string) map[string]keyspaceWatcher {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.watchers[key]
+}
+
+func (i *importWatchers) set(key string, watchers map[string]keyspaceWatcher) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.watchers[key] = watchers
+}
+
+func (i *importWatchers) stop(key string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for _, w := range i.watchers[key] {
+		w.Close()
+	}
+	delete(i.watchers, key)
+}
+
+// healthy returns true if a watcher is running without error for each source node of the import
+func (i *importWatchers) healthy(key string, status *rapi.RedisClusterImportStatus) bool {
+	watchers := i.get(key)
+	for _, source := range status.Sources {
+		w, ok := watchers[source.Addr]
+		if !ok || w.Err() != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Controller) onAddRedisClusterImport(obj interface{}) {
+	c.enqueueImport(obj)
+}
+
+func (c *Controller) onUpdateRedisClusterImport(oldObj, newObj interface{}) {
+	c.enqueueImport(newObj)
+}
+
+func (c *Controller) onDeleteRedisClusterImport(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		glog.Errorf("onDeleteRedisClusterImport: unable to get key for %#v: %v", obj, err)
+		return
+	}
+	c.importWatchers.stop(key)
+}
+
+func (c *Controller) enqueueImport(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		glog.Errorf("enqueueImport: unable to get key for %#v: %v", obj, err)
+		return
+	}
+	c.importQueue.Add(key)
+}
+
+func (c *Controller) runImportWorker(ctx context.Context) {
+	for c.processNextImport(ctx) {
+	}
+}
+
+func (c *Controller) processNextImport(ctx context.Context) bool {
+	key, quit := c.importQueue.Get()
+	if quit {
+		return false
+	}
+	defer c.importQueue.Done(key)
+	requeueAfter, err := c.syncImport(ctx, key.(string))
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Error syncing redisclusterimport: %v", err))
+		c.importQueue.AddRateLimited(key)
+		return true
+	}
+	c.importQueue.Forget(key)
+	if requeueAfter > 0 {
+		c.importQueue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+// syncImport runs one step of the import, returns the delay before the next step, 0 if the import is over
+func (c *Controller) syncImport(ctx context.Context, key string) (time.Duration, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return 0, err
+	}
+	sharedImport, err := c.redisClusterImportLister.RedisClusterImports(namespace).Get(name)
+	if err != nil {
+		glog.V(3).Infof("unable to get RedisClusterImport %s/%s: %v. Maybe deleted", namespace, name, err)
+		c.stopImport(key)
+		return 0, nil
+	}
+	if sharedImport.DeletionTimestamp != nil {
+		c.stopImport(key)
+		return 0, nil
+	}
+	if sharedImport.Status.Phase == rapi.ImportPhaseCompleted || sharedImport.Status.Phase == rapi.ImportPhaseFailed {
+		c.stopImport(key)
+		return 0, nil
+	}
+
+	imp := sharedImport.DeepCopy()
+	requeueAfter, syncErr := c.runImport(ctx, key, imp)
+	if !reflect.DeepEqual(sharedImport.Status, imp.Status) {
+		if _, err = c.updateImportHandler(imp); err != nil {
+			return requeueAfter, errors.NewAggregate([]error{syncErr, err})
+		}
+	}
+	return requeueAfter, syncErr
+}
+
+// runImport runs one step of the import. The commands sent to the cluster are audited and checked against its guard
+// rails like the ones of its reconcile, the refusals are saved in the status of the cluster.
+func (c *Controller) runImport(ctx context.Context, key string, imp *rapi.RedisClusterImport) (time.Duration, error) {
+	if len(imp.Spec.Source.Addrs) == 0 {
+		setImportPhase(imp, rapi.ImportPhaseFailed, "spec.source.addrs is empty")
+		c.recorder.Event(imp, apiv1.EventTypeWarning, importFailedEventReason, imp.Status.Reason)
+		return 0, nil
+	}
+	sharedCluster, err := c.redisClusterLister.RedisClusters(imp.Namespace).Get(imp.Spec.ClusterName)
+	if err != nil {
+		setImportPending(imp, fmt.Sprintf("RedisCluster %s not found", imp.Spec.ClusterName))
+		return importPendingInterval, nil
+	}
+	cluster := sharedCluster.DeepCopy()
+	defer func() {
+		if !reflect.DeepEqual(sharedCluster.Status.GuardRailRefusals, cluster.Status.GuardRailRefusals) {
+			if _, updateErr := c.updateHandler(cluster); updateErr != nil {
+				glog.Errorf("Unable to save the guard rail refusals of RedisCluster %s/%s: %v", cluster.Namespace, cluster.Name, updateErr)
+			}
+		}
+	}()
+	pods, err := c.podControl.GetRedisClusterPods(cluster)
+	if err != nil {
+		return 0, err
+	}
+	// the admin of the cluster is shared with the sync of the cluster, the import waits for the end of a running sync
+	adminKey := cluster.Namespace + "/" + cluster.Name
+	admin := redis.NewGuardedAdmin(c.adminPool.Get(adminKey, pods).WithContext(c.auditContext(ctx, cluster)), c.newGuardRails(cluster))
+	defer c.adminPool.Release(adminKey)
+	infos, err := admin.GetClusterInfos()
+	if infos == nil {
+		return 0, fmt.Errorf("unable to retrieve the infos of RedisCluster %s: %v", imp.Spec.ClusterName, err)
+	}
+	owners, err := getSlotOwners(infos.GetNodes())
+	if err != nil {
+		setImportPending(imp, err.Error())
+		return importPendingInterval, nil
+	}
+
+	sourceAdminKey := getImportSourceAdminKey(key)
+	sourceAdmin := c.adminPool.GetExternal(sourceAdminKey, imp.Spec.Source.Addrs).WithContext(ctx)
+	defer c.adminPool.Release(sourceAdminKey)
+
+	if imp.Status.Phase == "" || imp.Status.Phase == rapi.ImportPhasePending {
+		if err = c.startImport(key, imp, sourceAdmin); err != nil {
+			setImportPending(imp, err.Error())
+			return importPendingInterval, nil
+		}
+		c.recorder.Eventf(imp, apiv1.EventTypeNormal, importStartedEventReason, "Copy of %d source nodes to RedisCluster %s started", len(imp.Status.Sources), imp.Spec.ClusterName)
+	}
+
+	if imp.Spec.Tail && !c.importWatchers.healthy(key, &imp.Status) {
+		// modified keys may have been missed (watcher error or operator restart): copy all the keys again
+		if err = c.startImport(key, imp, sourceAdmin); err != nil {
+			setImportPending(imp, err.Error())
+			return importPendingInterval, nil
+		}
+		c.recorder.Eventf(imp, apiv1.EventTypeWarning, importRestartedEventReason, "Keyspace notifications interrupted, copy of %d source nodes restarted", len(imp.Status.Sources))
+	}
+
+	batchSize := getImportBatchSize(imp)
+	switch imp.Status.Phase {
+	case rapi.ImportPhaseCopying:
+		done, err := copyImportSources(sourceAdmin, admin, owners, &imp.Status, batchSize)
+		if err != nil {
+			c.recorder.Eventf(imp, apiv1.EventTypeWarning, importFailedEventReason, "Unable to copy the keys: %v", err)
+			return 0, err
+		}
+		if !done {
+			return importCopyInterval, nil
+		}
+		if imp.Spec.Tail {
+			setImportPhase(imp, rapi.ImportPhaseTailing, "")
+			return importTailInterval, nil
+		}
+		c.completeImport(key, imp)
+		return 0, nil
+	case rapi.ImportPhaseTailing:
+		pending, err := tailImportSources(sourceAdmin, admin, owners, c.importWatchers.get(key), &imp.Status, batchSize)
+		if err != nil {
+			c.recorder.Eventf(imp, apiv1.EventTypeWarning, importFailedEventReason, "Unable to copy the modified keys: %v", err)
+			return 0, err
+		}
+		if imp.Spec.Cutover && !pending {
+			c.completeImport(key, imp)
+			return 0, nil
+		}
+		if pending {
+			return importCopyInterval, nil
+		}
+		return importTailInterval, nil
+	}
+	return 0, nil
+}
+
+// startImport lists the source nodes, starts their keyspace watchers if needed and resets the copy progress
+func (c *Controller) startImport(key string, imp *rapi.RedisClusterImport, sourceAdmin redis.AdminInterface) error {
+	addrs, err := getImportSourceAddrs(sourceAdmin, imp)
+	if err != nil {
+		return err
+	}
+	c.importWatchers.stop(key)
+	if imp.Spec.Tail {
+		// the watchers are started before the copy to not miss the keys modified during the copy
+		watchers := map[string]keyspaceWatcher{}
+		for _, addr := range addrs {
+			w, err := redis.NewKeyspaceWatcher(addr, time.Duration(c.config.redis.DialTimeout)*time.Millisecond, nil)
+			if err != nil {
+				for _, started := range watchers {
+					started.Close()
+				}
+				return err
+			}
+			watchers[addr] = w
+		}
+		c.importWatchers.set(key, watchers)
+	}
+
+	now := metav1.Now()
+	setImportPhase(imp, rapi.ImportPhaseCopying, "")
+	imp.Status.StartTime = &now
+	imp.Status.Sources = nil
+	for _, addr := range addrs {
+		imp.Status.Sources = append(imp.Status.Sources, rapi.RedisClusterImportSourceStatus{Addr: addr})
+	}
+	return nil
+}
+
+// getImportSourceAdminKey returns the key of the admin of the source of an import in the admin pool
+func getImportSourceAdminKey(importKey string) string {
+	return "import/" + importKey + "/source"
+}
+
+// stopImport stops the keyspace watchers and closes the connections to the source of an import
+func (c *Controller) stopImport(key string) {
+	c.importWatchers.stop(key)
+	c.adminPool.Close(getImportSourceAdminKey(key))
+}
+
+// completeImport stops the keyspace watchers, the connections to the source are closed by the next sync because
+// the source admin is in use
+func (c *Controller) completeImport(key string, imp *rapi.RedisClusterImport) {
+	c.importWatchers.stop(key)
+	now := metav1.Now()
+	setImportPhase(imp, rapi.ImportPhaseCompleted, "")
+	imp.Status.CompletionTime = &now
+	c.recorder.Eventf(imp, apiv1.EventTypeNormal, importCompletedEventReason, "%d keys imported in RedisCluster %s", imp.Status.KeysImported, imp.Spec.ClusterName)
+}
+
+func (c *Controller) updateRedisClusterImport(imp *rapi.RedisClusterImport) (*rapi.RedisClusterImport, error) {
+	return c.redisClient.RedisoperatorV1().RedisClusterImports(imp.Namespace).Update(imp)
+}
+
+func getImportBatchSize(imp *rapi.RedisClusterImport) int {
+	if imp.Spec.BatchSize == nil || *imp.Spec.BatchSize <= 0 {
+		return 100
+	}
+	return int(*imp.Spec.BatchSize)
+}
+
+func setImportPhase(imp *rapi.RedisClusterImport, phase rapi.RedisClusterImportPhase, reason string) {
+	imp.Status.Phase = phase
+	imp.Status.Reason = reason
+}
+
+// setImportPending keeps the progress of a running import, only the reason is updated
+func setImportPending(imp *rapi.RedisClusterImport, reason string) {
+	if imp.Status.Phase == "" {
+		imp.Status.Phase = rapi.ImportPhasePending
+	}
+	imp.Status.Reason = reason
+}
+
+// getImportSourceAddrs returns the address of the source node, or the addresses of the masters if the source is a redis cluster
+func getImportSourceAddrs(sourceAdmin redis.AdminInterface, imp *rapi.RedisClusterImport) ([]string, error) {
+	if !imp.Spec.Source.Cluster {
+		return []string{imp.Spec.Source.Addrs[0]}, nil
+	}
+	infos, err := sourceAdmin.GetClusterInfos()
+	if err != nil {
+		glog.Warningf("import %s/%s, unable to retrieve all the source cluster infos: %v", imp.Namespace, imp.Name, err)
+	}
+	addrs := []string{}
+	for _, master := range getSourceMasters(infos) {
+		addrs = append(addrs, master.IPPort())
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no master found in the source cluster %v", imp.Spec.Source.Addrs)
+	}
+	return addrs, nil
+}
+
+// getSlotOwners returns the address of the master owning each slot, an error if a slot is not assigned
+func getSlotOwners(nodes redis.Nodes) ([]string, error) {
+	owners := make([]string, redis.HashMaxSlots+1)
+	for _, node := range nodes {
+		if !redis.IsMasterWithSlot(node) {
+			continue
+		}
+		for _, slot := range node.Slots {
+			owners[slot] = node.IPPort()
+		}
+	}
+	for slot, owner := range owners {
+		if owner == "" {
+			return nil, fmt.Errorf("slot %d not assigned in the RedisCluster", slot)
+		}
+	}
+	return owners, nil
+}
+
+// copyImportSources copies up to importMaxBatchesPerSync batches of keys of the first source node not completely copied.
+// Returns true once all the source nodes are copied.
+func copyImportSources(sourceAdmin, admin redis.AdminInterface, owners []string, status *rapi.RedisClusterImportStatus, batchSize int) (bool, error) {
+	for i := range status.Sources {
+		source := &status.Sources[i]
+		if source.Done {
+			continue
+		}
+		for b := 0; b < importMaxBatchesPerSync && !source.Done; b++ {
+			next, keys, err := sourceAdmin.ScanKeys(source.Addr, source.Cursor, batchSize)
+			if err != nil {
+				return false, err
+			}
+			dumps, err := sourceAdmin.DumpKeys(source.Addr, keys)
+			if err != nil {
+				return false, err
+			}
+			if err = restoreImportedKeys(admin, owners, dumps); err != nil {
+				return false, err
+			}
+			source.KeysScanned += int64(len(keys))
+			status.KeysImported += int64(len(dumps))
+			source.Cursor = next
+			if next == "0" {
+				source.Cursor = ""
+				source.Done = true
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// tailImportSources copies up to importMaxBatchesPerSync batches of modified keys of each source node, the keys
+// removed from the source are deleted. Returns true if modified keys remain to be copied.
+func tailImportSources(sourceAdmin, admin redis.AdminInterface, owners []string, watchers map[string]keyspaceWatcher, status *rapi.RedisClusterImportStatus, batchSize int) (bool, error) {
+	pending := false
+	copied := false
+	for _, source := range status.Sources {
+		w, ok := watchers[source.Addr]
+		if !ok {
+			continue
+		}
+		for b := 0; b < importMaxBatchesPerSync; b++ {
+			keys := w.ModifiedKeys(batchSize)
+			if len(keys) == 0 {
+				break
+			}
+			copied = true
+			dumps, err := sourceAdmin.DumpKeys(source.Addr, keys)
+			if err == nil {
+				err = restoreImportedKeys(admin, owners, dumps)
+			}
+			deleted := getRemovedKeys(keys, dumps)
+			if err == nil {
+				err = deleteImportedKeys(admin, owners, deleted)
+			}
+			if err != nil {
+				w.MarkModified(keys)
+				return true, err
+			}
+			status.KeysImported += int64(len(dumps))
+			status.KeysDeleted += int64(len(deleted))
+		}
+		pending = pending || w.Len() > 0
+	}
+	if copied {
+		now := metav1.Now()
+		status.LastTailTime = &now
+	}
+	return pending, nil
+}
+
+// restoreImportedKeys restores each key on the master owning its slot
+func restoreImportedKeys(admin redis.AdminInterface, owners []string, dumps []redis.KeyDump) error {
+	byAddr := map[string][]redis.KeyDump{}
+	for _, dump := range dumps {
+		addr := owners[redis.KeySlot(dump.Key)]
+		byAddr[addr] = append(byAddr[addr], dump)
+	}
+	addrs := []string{}
+	for addr := range byAddr {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	var errs []error
+	for _, addr := range addrs {
+		if err := admin.RestoreKeys(addr, byAddr[addr]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// deleteImportedKeys deletes each key on the master owning its slot
+func deleteImportedKeys(admin redis.AdminInterface, owners []string, keys []string) error {
+	byAddr := map[string][]string{}
+	for _, key := range keys {
+		addr := owners[redis.KeySlot(key)]
+		byAddr[addr] = append(byAddr[addr], key)
+	}
+	addrs := []string{}
+	for addr := range byAddr {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	var errs []error
+	for _, addr := range addrs {
+		if err := admin.DeleteKeys(addr, byAddr[addr]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// getRemovedKeys returns the keys without dump: removed or expired in the source
+func getRemovedKeys(keys []string, dumps []redis.KeyDump) []string {
+	dumped := map[string]bool{}
+	for _, dump := range dumps {
+		dumped[dump.Key] = true
+	}
+	removed := []string{}
+	for _, key := range keys {
+		if !dumped[key] {
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake/admin"
+)
+
+type fakeKeyspaceWatcher struct {
+	keys []string
+	err  error
+}
+
+func (w *fakeKeyspaceWatcher) ModifiedKeys(max int) []string {
+	if max > len(w.keys) {
+		max = len(w.keys)
+	}
+	keys := w.keys[:max]
+	w.keys = w.keys[max:]
+	return keys
+}
+
+func (w *fakeKeyspaceWatcher) MarkModified(keys []string) { w.keys = append(w.keys, keys...) }
+func (w *fakeKeyspaceWatcher) Len() int                   { return len(w.keys) }
+func (w *fakeKeyspaceWatcher) Err() error                 { return w.err }
+func (w *fakeKeyspaceWatcher) Close()                     {}
+
+// newImportOwners returns slot owners: the slots lower than 8192 on 10.1.0.1, the others on 10.1.0.2
+func newImportOwners(t *testing.T) []string {
+	master1 := newMasterNode("master1", "10.1.0.1")
+	master2 := newMasterNode("master2", "10.1.0.2")
+	for slot := redis.Slot(0); slot <= redis.HashMaxSlots; slot++ {
+		if slot < 8192 {
+			master1.Slots = append(master1.Slots, slot)
+		} else {
+			master2.Slots = append(master2.Slots, slot)
+		}
+	}
+	owners, err := getSlotOwners(redis.Nodes{master1, master2})
+	if err != nil {
+		t.Fatalf("getSlotOwners() unexpected error: %v", err)
+	}
+	return owners
+}
+
+func Test_getSlotOwners(t *testing.T) {
+	owners := newImportOwners(t)
+	if owners[0] != "10.1.0.1:6379" || owners[redis.HashMaxSlots] != "10.1.0.2:6379" {
+		t.Errorf("getSlotOwners() unexpected owners %s, %s", owners[0], owners[redis.HashMaxSlots])
+	}
+
+	if _, err := getSlotOwners(redis.Nodes{newMasterNode("master1", "10.1.0.1", 0, 1)}); err == nil {
+		t.Errorf("getSlotOwners() should fail if slots are not assigned")
+	}
+}
+
+func Test_restoreImportedKeys(t *testing.T) {
+	owners := newImportOwners(t)
+	// "foo" is in slot 12182, "bar" in slot 5061
+	tests := []struct {
+		name    string
+		dumps   []redis.KeyDump
+		wantErr bool
+	}{
+		{name: "keys of the reachable master", dumps: []redis.KeyDump{{Key: "bar"}}, wantErr: false},
+		{name: "keys of the unreachable master", dumps: []redis.KeyDump{{Key: "bar"}, {Key: "foo"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAdmin := admin.NewFakeAdmin([]string{"10.1.0.1:6379", "10.1.0.2:6379"})
+			fakeAdmin.RestoreKeysRet["10.1.0.2:6379"] = fmt.Errorf("unreachable")
+			if err := restoreImportedKeys(fakeAdmin, owners, tt.dumps); (err != nil) != tt.wantErr {
+				t.Errorf("restoreImportedKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_copyImportSources(t *testing.T) {
+	owners := newImportOwners(t)
+	sourceAdmin := admin.NewFakeAdmin([]string{"10.0.0.1:6379", "10.0.0.2:6379"})
+	sourceAdmin.ScanKeysRet["10.0.0.1:6379"] = admin.ScanKeysRetType{Cursor: "0", Keys: []string{"foo", "bar", "removed"}}
+	sourceAdmin.DumpKeysRet["10.0.0.1:6379"] = admin.DumpKeysRetType{Dumps: []redis.KeyDump{{Key: "foo"}, {Key: "bar"}}}
+	sourceAdmin.ScanKeysRet["10.0.0.2:6379"] = admin.ScanKeysRetType{Cursor: "12", Keys: []string{"zap"}}
+	sourceAdmin.DumpKeysRet["10.0.0.2:6379"] = admin.DumpKeysRetType{Dumps: []redis.KeyDump{{Key: "zap"}}}
+	clusterAdmin := admin.NewFakeAdmin([]string{"10.1.0.1:6379", "10.1.0.2:6379"})
+
+	status := &rapi.RedisClusterImportStatus{Sources: []rapi.RedisClusterImportSourceStatus{{Addr: "10.0.0.1:6379"}, {Addr: "10.0.0.2:6379"}}}
+	done, err := copyImportSources(sourceAdmin, clusterAdmin, owners, status, 100)
+	if err != nil || done {
+		t.Fatalf("copyImportSources() = %v, %v, want false, nil", done, err)
+	}
+	if !status.Sources[0].Done || status.Sources[0].KeysScanned != 3 || status.KeysImported != 2 {
+		t.Errorf("copyImportSources() unexpected status after the first source %v", status)
+	}
+
+	done, err = copyImportSources(sourceAdmin, clusterAdmin, owners, status, 100)
+	if err != nil || done {
+		t.Fatalf("copyImportSources() = %v, %v, want false, nil", done, err)
+	}
+	if status.Sources[1].Done || status.Sources[1].Cursor != "12" || status.Sources[1].KeysScanned != importMaxBatchesPerSync {
+		t.Errorf("copyImportSources() unexpected status of the second source %v", status.Sources[1])
+	}
+
+	sourceAdmin.ScanKeysRet["10.0.0.2:6379"] = admin.ScanKeysRetType{Cursor: "0"}
+	sourceAdmin.DumpKeysRet["10.0.0.2:6379"] = admin.DumpKeysRetType{}
+	if _, err = copyImportSources(sourceAdmin, clusterAdmin, owners, status, 100); err != nil {
+		t.Fatalf("copyImportSources() unexpected error: %v", err)
+	}
+	if done, _ = copyImportSources(sourceAdmin, clusterAdmin, owners, status, 100); !done {
+		t.Errorf("copyImportSources() should be done")
+	}
+}
+
+func Test_tailImportSources(t *testing.T) {
+	owners := newImportOwners(t)
+	sourceAdmin := admin.NewFakeAdmin([]string{"10.0.0.1:6379"})
+	sourceAdmin.DumpKeysRet["10.0.0.1:6379"] = admin.DumpKeysRetType{Dumps: []redis.KeyDump{{Key: "foo"}}}
+	clusterAdmin := admin.NewFakeAdmin([]string{"10.1.0.1:6379", "10.1.0.2:6379"})
+	status := &rapi.RedisClusterImportStatus{Sources: []rapi.RedisClusterImportSourceStatus{{Addr: "10.0.0.1:6379", Done: true}}}
+
+	watcher := &fakeKeyspaceWatcher{keys: []string{"foo", "bar"}}
+	watchers := map[string]keyspaceWatcher{"10.0.0.1:6379": watcher}
+	pending, err := tailImportSources(sourceAdmin, clusterAdmin, owners, watchers, status, 100)
+	if err != nil || pending {
+		t.Fatalf("tailImportSources() = %v, %v, want false, nil", pending, err)
+	}
+	if status.KeysImported != 1 || status.KeysDeleted != 1 || status.LastTailTime == nil {
+		t.Errorf("tailImportSources() unexpected status %v", status)
+	}
+
+	// the modified keys are kept if they can't be deleted
+	clusterAdmin.DeleteKeysRet["10.1.0.1:6379"] = fmt.Errorf("unreachable")
+	watcher.keys = []string{"foo", "bar"}
+	if _, err = tailImportSources(sourceAdmin, clusterAdmin, owners, watchers, status, 100); err == nil {
+		t.Errorf("tailImportSources() should fail")
+	}
+	if watcher.Len() != 2 {
+		t.Errorf("tailImportSources() modified keys lost, remaining %v", watcher.keys)
+	}
+}
+
+func Test_getRemovedKeys(t *testing.T) {
+	got := getRemovedKeys([]string{"a", "b", "c"}, []redis.KeyDump{{Key: "b"}})
+	if want := []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getRemovedKeys() = %v, want %v", got, want)
+	}
+}
+
+func Test_importWatchers_healthy(t *testing.T) {
+	watchers := newImportWatchers()
+	status := &rapi.RedisClusterImportStatus{Sources: []rapi.RedisClusterImportSourceStatus{{Addr: "10.0.0.1:6379"}}}
+	if watchers.healthy("ns/import", status) {
+		t.Errorf("healthy() should be false without watcher")
+	}
+	watcher := &fakeKeyspaceWatcher{}
+	watchers.set("ns/import", map[string]keyspaceWatcher{"10.0.0.1:6379": watcher})
+	if !watchers.healthy("ns/import", status) {
+		t.Errorf("healthy() should be true")
+	}
+	watcher.err = fmt.Errorf("connection reset")
+	if watchers.healthy("ns/import", status) {
+		t.Errorf("healthy() should be false with a watcher error")
+	}
+}
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// MaintenanceWindowWaitingEventReason is the reason of the event emitted when a disruptive action waits for a maintenance window
+	MaintenanceWindowWaitingEventReason = "WaitingMaintenanceWindow"
+
+	// cronScheduleHorizonYears is how far the next start of a schedule is searched
+	cronScheduleHorizonYears = 5
+)
+
+// cronField describes a field of a cron expression
+type cronField struct {
+	name     string
+	min, max uint
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// cronSchedule is a parsed cron expression, each field is the set of its allowed values
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are true if the day of month, or the day of week, is not restricted
+	domStar, dowStar bool
+}
+
+// parseCronSchedule parses a cron expression: minute hour day-of-month month day-of-week, with the *, lists, ranges
+// and steps syntaxes. Like cron, a day matches if it matches the day of month or the day of week when both are restricted.
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("schedule %q has %d fields, expected 5: minute hour day-of-month month day-of-week", spec, len(fields))
+	}
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseCronField(field, cronFields[i]); err != nil {
+			return nil, fmt.Errorf("schedule %q: %v", spec, err)
+		}
+	}
+	// sunday is 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField returns the set of values allowed by a field of a cron expression
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, uint64(1)
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.ParseUint(part[i+1:], 10, 8); err != nil || step == 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, part)
+			}
+			rangePart = part[:i]
+		}
+		lo, hi := uint64(f.min), uint64(f.max)
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = strconv.ParseUint(bounds[0], 10, 8); err != nil {
+				return 0, fmt.Errorf("invalid %s field %q", f.name, part)
+			}
+			switch {
+			case len(bounds) == 2:
+				if hi, err = strconv.ParseUint(bounds[1], 10, 8); err != nil {
+					return 0, fmt.Errorf("invalid %s field %q", f.name, part)
+				}
+			case step == 1:
+				hi = lo
+			}
+		}
+		if lo < uint64(f.min) || hi > uint64(f.max) || lo > hi {
+			return 0, fmt.Errorf("%s field %q out of the range %d-%d", f.name, part, f.min, f.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns the first time matching the schedule at or after from, in the location of from.
+// Returns false if the schedule doesn't match in the next years.
+func (s *cronSchedule) next(from time.Time) (time.Time, bool) {
+	t := from.Truncate(time.Minute)
+	if t.Before(from) {
+		t = t.Add(time.Minute)
+	}
+	loc := t.Location()
+	for t.Year() <= from.Year()+cronScheduleHorizonYears {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// maintenanceWindow is a parsed RedisClusterMaintenanceWindow
+type maintenanceWindow struct {
+	schedule *cronSchedule
+	duration time.Duration
+	location *time.Location
+}
+
+// parseMaintenanceWindows parses the maintenance windows of the spec
+func parseMaintenanceWindows(windows []rapi.RedisClusterMaintenanceWindow) ([]maintenanceWindow, error) {
+	parsed := []maintenanceWindow{}
+	for _, w := range windows {
+		schedule, err := parseCronSchedule(w.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		if w.DurationMinutes <= 0 {
+			return nil, fmt.Errorf("window %q: durationMinutes must be positive", w.Schedule)
+		}
+		location, err := time.LoadLocation(w.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("window %q: unknown time zone %q: %v", w.Schedule, w.TimeZone, err)
+		}
+		parsed = append(parsed, maintenanceWindow{schedule: schedule, duration: time.Duration(w.DurationMinutes) * time.Minute, location: location})
+	}
+	return parsed, nil
+}
+
+// maintenanceWindowOpen returns true if one of the windows is open at now, otherwise the start of the next window.
+// found is false if no window opens in the next years.
+func maintenanceWindowOpen(windows []maintenanceWindow, now time.Time) (open bool, next time.Time, found bool) {
+	for _, w := range windows {
+		// the window is open if it started during the last duration
+		if start, ok := w.schedule.next(now.Add(-w.duration).In(w.location)); ok && !start.After(now) && start.Add(w.duration).After(now) {
+			return true, time.Time{}, true
+		}
+		if start, ok := w.schedule.next(now.In(w.location)); ok && (!found || start.Before(next)) {
+			next, found = start, true
+		}
+	}
+	return false, next, found
+}
+
+// waitMaintenanceWindow returns true if the disruptive action has to wait for the next maintenance window of the
+// cluster, the pending action and the next window are then reported in the status
+func (c *Controller) waitMaintenanceWindow(cluster *rapi.RedisCluster, action rapi.RedisClusterDisruptiveAction, now time.Time) (bool, error) {
+	if len(cluster.Spec.MaintenanceWindows) == 0 {
+		return false, nil
+	}
+	pending := &rapi.RedisClusterMaintenanceStatus{PendingAction: action}
+	windows, err := parseMaintenanceWindows(cluster.Spec.MaintenanceWindows)
+	if err != nil {
+		pending.Message = fmt.Sprintf("%s waits until the maintenance windows are fixed: %v", action, err)
+	} else {
+		open, next, found := maintenanceWindowOpen(windows, now)
+		if open {
+			cluster.Status.Maintenance = nil
+			return false, nil
+		}
+		if found {
+			pending.NextWindow = &metav1.Time{Time: next}
+			pending.Message = fmt.Sprintf("%s waits for the maintenance window starting at %s", action, next.Format(time.RFC3339))
+		} else {
+			pending.Message = fmt.Sprintf("%s waits for a maintenance window, none opens in the next %d years", action, cronScheduleHorizonYears)
+		}
+	}
+	glog.V(3).Infof("cluster %s/%s: %s", cluster.Namespace, cluster.Name, pending.Message)
+	if equalMaintenanceStatus(cluster.Status.Maintenance, pending) {
+		return true, nil
+	}
+	cluster.Status.Maintenance = pending
+	c.recorder.Event(cluster, apiv1.EventTypeNormal, MaintenanceWindowWaitingEventReason, pending.Message)
+	_, err = c.updateHandler(cluster)
+	return true, err
+}
+
+// equalMaintenanceStatus returns true if the pending actions are the same, the times being compared as instants
+func equalMaintenanceStatus(a, b *rapi.RedisClusterMaintenanceStatus) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.PendingAction != b.PendingAction || a.Message != b.Message || (a.NextWindow == nil) != (b.NextWindow == nil) {
+		return false
+	}
+	return a.NextWindow == nil || a.NextWindow.Time.Equal(b.NextWindow.Time)
+}
+
+// isRebalance returns true if slots move from the current masters to other masters, the first slots allocation of
+// a new cluster is not a rebalance
+func isRebalance(newMasters, curMasters redis.Nodes) bool {
+	if len(curMasters) == 0 {
+		return false
+	}
+	if len(newMasters) != len(curMasters) {
+		return true
+	}
+	for _, master := range newMasters {
+		if _, err := curMasters.GetNodeByID(master.ID); err != nil {
+			return true
+		}
+	}
+	return false
+}
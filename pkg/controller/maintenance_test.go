@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+func Test_cronSchedule_next(t *testing.T) {
+	// 2018-05-02 is a wednesday
+	from := time.Date(2018, 5, 2, 10, 30, 20, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule string
+		want     time.Time
+	}{
+		{name: "every minute", schedule: "* * * * *", want: time.Date(2018, 5, 2, 10, 31, 0, 0, time.UTC)},
+		{name: "later today", schedule: "0 22 * * *", want: time.Date(2018, 5, 2, 22, 0, 0, 0, time.UTC)},
+		{name: "tomorrow", schedule: "0 2 * * *", want: time.Date(2018, 5, 3, 2, 0, 0, 0, time.UTC)},
+		{name: "week-end", schedule: "30 1 * * 6,7", want: time.Date(2018, 5, 5, 1, 30, 0, 0, time.UTC)},
+		{name: "working days range", schedule: "0 2 * * 1-5", want: time.Date(2018, 5, 3, 2, 0, 0, 0, time.UTC)},
+		{name: "step", schedule: "*/20 * * * *", want: time.Date(2018, 5, 2, 10, 40, 0, 0, time.UTC)},
+		{name: "day of month or day of week", schedule: "0 0 15 * 1", want: time.Date(2018, 5, 7, 0, 0, 0, 0, time.UTC)},
+		{name: "next year", schedule: "0 0 1 1 *", want: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.schedule)
+			if err != nil {
+				t.Fatalf("parseCronSchedule() error = %v", err)
+			}
+			if got, ok := schedule.next(from); !ok || !got.Equal(tt.want) {
+				t.Errorf("next() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+
+	if schedule, _ := parseCronSchedule("0 0 31 2 *"); schedule != nil {
+		if _, ok := schedule.next(from); ok {
+			t.Errorf("next() should not find the 31st of february")
+		}
+	}
+}
+
+func Test_parseCronSchedule_errors(t *testing.T) {
+	for _, schedule := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := parseCronSchedule(schedule); err == nil {
+			t.Errorf("parseCronSchedule(%q) should fail", schedule)
+		}
+	}
+}
+
+func Test_maintenanceWindowOpen(t *testing.T) {
+	windows, err := parseMaintenanceWindows([]rapi.RedisClusterMaintenanceWindow{
+		{Schedule: "0 2 * * *", DurationMinutes: 120, TimeZone: "Europe/Paris"},
+		{Schedule: "0 22 * * 6", DurationMinutes: 60},
+	})
+	if err != nil {
+		t.Fatalf("parseMaintenanceWindows() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		now      time.Time
+		wantOpen bool
+		wantNext time.Time
+	}{
+		{
+			// 2:30 in Paris, summer time
+			name:     "open in the time zone of the window",
+			now:      time.Date(2018, 5, 2, 0, 30, 0, 0, time.UTC),
+			wantOpen: true,
+		},
+		{
+			name:     "closed at the end of the window",
+			now:      time.Date(2018, 5, 2, 2, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2018, 5, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "earliest window",
+			now:      time.Date(2018, 5, 5, 21, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2018, 5, 5, 22, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, next, found := maintenanceWindowOpen(windows, tt.now)
+			if open != tt.wantOpen {
+				t.Errorf("maintenanceWindowOpen() open = %v, want %v", open, tt.wantOpen)
+			}
+			if !tt.wantOpen && (!found || !next.Equal(tt.wantNext)) {
+				t.Errorf("maintenanceWindowOpen() next = %v, want %v", next, tt.wantNext)
+			}
+		})
+	}
+
+	if _, err := parseMaintenanceWindows([]rapi.RedisClusterMaintenanceWindow{{Schedule: "0 2 * * *", DurationMinutes: 60, TimeZone: "Mars/Olympus"}}); err == nil {
+		t.Errorf("parseMaintenanceWindows() should refuse an unknown time zone")
+	}
+}
+
+func TestController_waitMaintenanceWindow(t *testing.T) {
+	updates := 0
+	c := &Controller{
+		updateHandler: func(rc *rapi.RedisCluster) (*rapi.RedisCluster, error) {
+			updates++
+			return rc, nil
+		},
+		recorder: record.NewFakeRecorder(10),
+	}
+	cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	now := time.Date(2018, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	if wait, _ := c.waitMaintenanceWindow(cluster, rapi.DisruptiveActionScaleDown, now); wait {
+		t.Errorf("waitMaintenanceWindow() should not wait without maintenance window")
+	}
+
+	cluster.Spec.MaintenanceWindows = []rapi.RedisClusterMaintenanceWindow{{Schedule: "0 2 * * *", DurationMinutes: 60}}
+	for i := 0; i < 2; i++ {
+		if wait, err := c.waitMaintenanceWindow(cluster, rapi.DisruptiveActionScaleDown, now); !wait || err != nil {
+			t.Errorf("waitMaintenanceWindow() = %v, %v, want to wait outside of the window", wait, err)
+		}
+	}
+	maintenance := cluster.Status.Maintenance
+	if maintenance == nil || maintenance.PendingAction != rapi.DisruptiveActionScaleDown || maintenance.NextWindow == nil || !maintenance.NextWindow.Time.Equal(time.Date(2018, 5, 3, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("status.maintenance = %+v, want the pending scale-down and the next window", maintenance)
+	}
+	if updates != 1 {
+		t.Errorf("the status should be updated once, got %d updates", updates)
+	}
+
+	if wait, _ := c.waitMaintenanceWindow(cluster, rapi.DisruptiveActionScaleDown, now.Add(16*time.Hour+30*time.Minute)); wait || cluster.Status.Maintenance != nil {
+		t.Errorf("waitMaintenanceWindow() should not wait during the window, status.maintenance = %+v", cluster.Status.Maintenance)
+	}
+
+	cluster.Spec.MaintenanceWindows[0].Schedule = "0 25 * * *"
+	if wait, _ := c.waitMaintenanceWindow(cluster, rapi.DisruptiveActionRollingUpdate, now); !wait || cluster.Status.Maintenance == nil || cluster.Status.Maintenance.Message == "" {
+		t.Errorf("waitMaintenanceWindow() should wait and report an invalid window, status.maintenance = %+v", cluster.Status.Maintenance)
+	}
+}
+
+func TestController_waitMaintenanceWindow_timeZone(t *testing.T) {
+	c := &Controller{
+		updateHandler: func(rc *rapi.RedisCluster) (*rapi.RedisCluster, error) { return rc, nil },
+		recorder:      record.NewFakeRecorder(10),
+	}
+	// 2:00 in Kolkata is 20:30 UTC the day before
+	cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	cluster.Spec.MaintenanceWindows = []rapi.RedisClusterMaintenanceWindow{{Schedule: "0 2 * * *", DurationMinutes: 60, TimeZone: "Asia/Kolkata"}}
+	now := time.Date(2018, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	if wait, err := c.waitMaintenanceWindow(cluster, rapi.DisruptiveActionRebalance, now); !wait || err != nil {
+		t.Fatalf("waitMaintenanceWindow() = %v, %v, want to wait outside of the window", wait, err)
+	}
+	if maintenance := cluster.Status.Maintenance; maintenance == nil || maintenance.NextWindow == nil || !maintenance.NextWindow.Time.Equal(time.Date(2018, 5, 2, 20, 30, 0, 0, time.UTC)) {
+		t.Errorf("status.maintenance = %+v, want the next window at 2:00 in Kolkata", maintenance)
+	}
+	if wait, _ := c.waitMaintenanceWindow(cluster, rapi.DisruptiveActionRebalance, time.Date(2018, 5, 2, 21, 0, 0, 0, time.UTC)); wait {
+		t.Errorf("waitMaintenanceWindow() should not wait at 2:30 in Kolkata")
+	}
+}
+
+func Test_isRebalance(t *testing.T) {
+	a, b, c := &redis.Node{ID: "a"}, &redis.Node{ID: "b"}, &redis.Node{ID: "c"}
+	tests := []struct {
+		name       string
+		newMasters redis.Nodes
+		curMasters redis.Nodes
+		want       bool
+	}{
+		{name: "new cluster", newMasters: redis.Nodes{a, b}, want: false},
+		{name: "same masters", newMasters: redis.Nodes{b, a}, curMasters: redis.Nodes{a, b}, want: false},
+		{name: "scale up", newMasters: redis.Nodes{a, b, c}, curMasters: redis.Nodes{a, b}, want: true},
+		{name: "master replaced", newMasters: redis.Nodes{a, c}, curMasters: redis.Nodes{a, b}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRebalance(tt.newMasters, tt.curMasters); got != tt.want {
+				t.Errorf("isRebalance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
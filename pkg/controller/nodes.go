@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/errors"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// masterFailoverEventReason is the reason of the event emitted when a master hosted on an unavailable kubernetes node is failed over
+	masterFailoverEventReason = "MasterFailover"
+	// masterFailoverFailedEventReason is the reason of the event emitted when the failover of a master hosted on an unavailable kubernetes node failed
+	masterFailoverFailedEventReason = "MasterFailoverFailed"
+)
+
+// kubeNodeUnavailableTaints are the taints set by kubernetes on a cordoned, NotReady or unreachable node: the pods
+// tolerating them are still evicted once the toleration seconds are elapsed
+var kubeNodeUnavailableTaints = map[string]bool{
+	"node.kubernetes.io/unschedulable": true,
+	"node.kubernetes.io/not-ready":     true,
+	"node.kubernetes.io/unreachable":   true,
+}
+
+// isKubeNodeUnavailable returns true if the kubernetes node is cordoned, NotReady, or tainted with a NoSchedule or
+// NoExecute effect by kubernetes or by a taint not tolerated by the pods. The taints of the dedicated nodes tolerated
+// by the pods are ignored.
+func isKubeNodeUnavailable(node *apiv1.Node, tolerations []apiv1.Toleration) bool {
+	if node == nil {
+		return false
+	}
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != apiv1.TaintEffectNoSchedule && taint.Effect != apiv1.TaintEffectNoExecute {
+			continue
+		}
+		if kubeNodeUnavailableTaints[taint.Key] || !isTaintTolerated(taint, tolerations) {
+			return true
+		}
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == apiv1.NodeReady && cond.Status != apiv1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// isTaintTolerated returns true if one of the tolerations tolerates the taint
+func isTaintTolerated(taint *apiv1.Taint, tolerations []apiv1.Toleration) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// getUnavailableKubeNodes returns the names of the unavailable kubernetes nodes hosting the pods
+func (c *Controller) getUnavailableKubeNodes(pods []*apiv1.Pod) map[string]bool {
+	unavailableNodes := map[string]bool{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if _, ok := unavailableNodes[pod.Spec.NodeName]; ok {
+			continue
+		}
+		node, err := c.nodeLister.Get(pod.Spec.NodeName)
+		if err != nil {
+			glog.V(4).Infof("unable to get the kubernetes node %s, err:%v", pod.Spec.NodeName, err)
+			continue
+		}
+		unavailableNodes[pod.Spec.NodeName] = isKubeNodeUnavailable(node, pod.Spec.Tolerations)
+	}
+	for name, unavailable := range unavailableNodes {
+		if !unavailable {
+			delete(unavailableNodes, name)
+		}
+	}
+	return unavailableNodes
+}
+
+// selectMastersToFailover returns the masters with slots hosted on an unavailable kubernetes node
+// that have at least one slave hosted on an available kubernetes node
+func selectMastersToFailover(nodes redis.Nodes, unavailableNodes map[string]bool) redis.Nodes {
+	isOnUnavailableNode := func(n *redis.Node) bool {
+		return n.Pod != nil && unavailableNodes[n.Pod.Spec.NodeName]
+	}
+	masters := redis.Nodes{}
+	for _, master := range nodes.FilterByFunc(redis.IsMasterWithSlot) {
+		if !isOnUnavailableNode(master) {
+			continue
+		}
+		nbHealthySlaves := nodes.CountByFunc(func(n *redis.Node) bool {
+			return redis.IsSlave(n) && n.MasterReferent == master.ID && n.Pod != nil && !isOnUnavailableNode(n)
+		})
+		if nbHealthySlaves == 0 {
+			glog.Warningf("master %s hosted on an unavailable kubernetes node has no slave on an available node", master.ID)
+			continue
+		}
+		masters = append(masters, master)
+	}
+	return masters.SortNodes()
+}
+
+// manageUnavailableKubeNodes fails over the masters hosted on unavailable kubernetes nodes to their slaves,
+// in order to move them before the kubernetes node is drained. Returns true if a failover has been triggered.
+func (c *Controller) manageUnavailableKubeNodes(admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, pods []*apiv1.Pod) (bool, error) {
+	if infos == nil {
+		return false, nil
+	}
+	unavailableNodes := c.getUnavailableKubeNodes(pods)
+	if len(unavailableNodes) == 0 {
+		return false, nil
+	}
+
+	nodes := infos.GetNodes()
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if rNode, err := nodes.GetNodeByID(node.ID); err == nil {
+			rNode.Pod = node.Pod
+		}
+	}
+
+	failover := false
+	var errs []error
+	for _, master := range selectMastersToFailover(nodes, unavailableNodes) {
+		glog.Infof("failover of master %s, pod %s hosted on unavailable node %s", master.ID, master.Pod.Name, master.Pod.Spec.NodeName)
+		// the slaves hosted on unavailable nodes are promoted only if no other slave is available
+		options := &redis.FailoverOptions{}
+		for _, slave := range nodes {
+			if redis.IsSlave(slave) && slave.MasterReferent == master.ID && slave.Pod != nil && unavailableNodes[slave.Pod.Spec.NodeName] {
+				options.AvoidedSlaves = append(options.AvoidedSlaves, slave.IPPort())
+			}
+		}
+		if err := admin.StartFailover(master.IPPort(), options); err != nil {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, masterFailoverFailedEventReason, "Unable to failover master %s (pod %s) hosted on unavailable node %s: %v", master.ID, master.Pod.Name, master.Pod.Spec.NodeName, err)
+			errs = append(errs, err)
+			continue
+		}
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, masterFailoverEventReason, "Master %s (pod %s) failed over, node %s is unavailable", master.ID, master.Pod.Name, master.Pod.Spec.NodeName)
+		failover = true
+	}
+	return failover, errors.NewAggregate(errs)
+}
+
+func (c *Controller) onAddNode(obj interface{}) {
+	node, ok := obj.(*apiv1.Node)
+	if !ok {
+		glog.Errorf("adding Node, expected Node object. Got: %+v", obj)
+		return
+	}
+	if isKubeNodeUnavailable(node, nil) {
+		c.enqueueRedisClustersOnNode(nil, node)
+	}
+}
+
+func (c *Controller) onUpdateNode(oldObj, newObj interface{}) {
+	oldNode := oldObj.(*apiv1.Node)
+	newNode := newObj.(*apiv1.Node)
+	if oldNode.ResourceVersion == newNode.ResourceVersion { // Since periodic resync will send update events for all known Nodes.
+		return
+	}
+	// without toleration, a node is unavailable as soon as it is unavailable for one of the pods
+	if isKubeNodeUnavailable(newNode, nil) {
+		c.enqueueRedisClustersOnNode(oldNode, newNode)
+	}
+}
+
+// enqueueRedisClustersOnNode adds in the controller queue the RedisClusters with a pod hosted on the kubernetes node
+// that became unavailable for this pod, the taints tolerated by the pod being ignored. oldNode is nil for a new node.
+func (c *Controller) enqueueRedisClustersOnNode(oldNode, node *apiv1.Node) {
+	pods, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("unable to list the pods hosted on node %s: %v", node.Name, err)
+		return
+	}
+	enqueued := map[string]bool{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node.Name {
+			continue
+		}
+		if !isKubeNodeUnavailable(node, pod.Spec.Tolerations) || isKubeNodeUnavailable(oldNode, pod.Spec.Tolerations) {
+			continue
+		}
+		clusterName, ok := pod.Labels[rapi.ClusterNameLabelKey]
+		if !ok || enqueued[pod.Namespace+"/"+clusterName] {
+			continue
+		}
+		redisCluster, err := c.getRedisClusterFromPod(pod)
+		if err != nil || redisCluster == nil {
+			glog.Errorf("unable to retrieve the associated rediscluster for pod %s/%s:%v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		enqueued[pod.Namespace+"/"+clusterName] = true
+		c.enqueue(redisCluster)
+	}
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+func Test_isKubeNodeUnavailable(t *testing.T) {
+	tests := []struct {
+		name        string
+		node        *kapiv1.Node
+		tolerations []kapiv1.Toleration
+		want        bool
+	}{
+		{
+			name: "nil node",
+			node: nil,
+			want: false,
+		},
+		{
+			name: "ready node",
+			node: &kapiv1.Node{
+				Status: kapiv1.NodeStatus{Conditions: []kapiv1.NodeCondition{{Type: kapiv1.NodeReady, Status: kapiv1.ConditionTrue}}},
+			},
+			want: false,
+		},
+		{
+			name: "cordoned node",
+			node: &kapiv1.Node{
+				Spec:   kapiv1.NodeSpec{Unschedulable: true},
+				Status: kapiv1.NodeStatus{Conditions: []kapiv1.NodeCondition{{Type: kapiv1.NodeReady, Status: kapiv1.ConditionTrue}}},
+			},
+			want: true,
+		},
+		{
+			name: "NoExecute taint",
+			node: &kapiv1.Node{
+				Spec: kapiv1.NodeSpec{Taints: []kapiv1.Taint{{Key: "maintenance", Effect: kapiv1.TaintEffectNoExecute}}},
+			},
+			want: true,
+		},
+		{
+			name: "NoSchedule taint tolerated by the pods",
+			node: &kapiv1.Node{
+				Spec: kapiv1.NodeSpec{Taints: []kapiv1.Taint{{Key: "dedicated", Value: "redis", Effect: kapiv1.TaintEffectNoSchedule}}},
+			},
+			tolerations: []kapiv1.Toleration{{Key: "dedicated", Operator: kapiv1.TolerationOpEqual, Value: "redis", Effect: kapiv1.TaintEffectNoSchedule}},
+			want:        false,
+		},
+		{
+			name: "unreachable taint tolerated by the pods",
+			node: &kapiv1.Node{
+				Spec: kapiv1.NodeSpec{Taints: []kapiv1.Taint{{Key: "node.kubernetes.io/unreachable", Effect: kapiv1.TaintEffectNoExecute}}},
+			},
+			tolerations: []kapiv1.Toleration{{Key: "node.kubernetes.io/unreachable", Operator: kapiv1.TolerationOpExists, Effect: kapiv1.TaintEffectNoExecute}},
+			want:        true,
+		},
+		{
+			name: "PreferNoSchedule taint",
+			node: &kapiv1.Node{
+				Spec: kapiv1.NodeSpec{Taints: []kapiv1.Taint{{Key: "maintenance", Effect: kapiv1.TaintEffectPreferNoSchedule}}},
+			},
+			want: false,
+		},
+		{
+			name: "not ready node",
+			node: &kapiv1.Node{
+				Status: kapiv1.NodeStatus{Conditions: []kapiv1.NodeCondition{{Type: kapiv1.NodeReady, Status: kapiv1.ConditionUnknown}}},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isKubeNodeUnavailable(tt.node, tt.tolerations); got != tt.want {
+				t.Errorf("isKubeNodeUnavailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_selectMastersToFailover(t *testing.T) {
+	master1 := &redis.Node{ID: "master1", Role: "master", Pod: newPod("pod1", "node1"), Slots: []redis.Slot{1}}
+	slave1 := &redis.Node{ID: "slave1", Role: "slave", MasterReferent: "master1", Pod: newPod("pod2", "node2")}
+	master2 := &redis.Node{ID: "master2", Role: "master", Pod: newPod("pod3", "node2"), Slots: []redis.Slot{2}}
+	slave2 := &redis.Node{ID: "slave2", Role: "slave", MasterReferent: "master2", Pod: newPod("pod4", "node1")}
+	master3 := &redis.Node{ID: "master3", Role: "master", Pod: newPod("pod5", "node1"), Slots: []redis.Slot{3}}
+	slave3 := &redis.Node{ID: "slave3", Role: "slave", MasterReferent: "master3", Pod: newPod("pod6", "node1")}
+
+	tests := []struct {
+		name             string
+		nodes            redis.Nodes
+		unavailableNodes map[string]bool
+		want             redis.Nodes
+	}{
+		{
+			name:             "no unavailable node",
+			nodes:            redis.Nodes{master1, slave1, master2, slave2},
+			unavailableNodes: map[string]bool{},
+			want:             redis.Nodes{},
+		},
+		{
+			name:             "master on unavailable node",
+			nodes:            redis.Nodes{master1, slave1, master2, slave2},
+			unavailableNodes: map[string]bool{"node1": true},
+			want:             redis.Nodes{master1},
+		},
+		{
+			name:             "master and slave on the same unavailable node",
+			nodes:            redis.Nodes{master1, slave1, master3, slave3},
+			unavailableNodes: map[string]bool{"node1": true},
+			want:             redis.Nodes{master1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectMastersToFailover(tt.nodes, tt.unavailableNodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectMastersToFailover() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
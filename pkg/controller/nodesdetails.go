@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// nodesMetricsRefreshInterval is the minimum interval between two updates of the RedisCluster status caused only
+	// by a change of the nodes metrics
+	nodesMetricsRefreshInterval = time.Minute
+
+	// zoneLabelKey is the label of the kubernetes node containing its zone
+	zoneLabelKey = "topology.kubernetes.io/zone"
+	// zoneBetaLabelKey is the deprecated label of the kubernetes node containing its zone
+	zoneBetaLabelKey = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// setNodesDetails completes the nodes of the cluster status with the kubernetes node and zone hosting their pod,
+// and with the runtime details retrieved with the INFO command
+func (c *Controller) setNodesDetails(admin redis.AdminInterface, clusterInfos *redis.ClusterInfos, cluster *rapi.RedisCluster, clusterStatus *rapi.RedisClusterClusterStatus) {
+	now := metav1.Now()
+	redisNodes := clusterInfos.GetNodes()
+	previousNodes := map[string]*rapi.RedisClusterNode{}
+	for i, node := range cluster.Status.Cluster.Nodes {
+		previousNodes[node.ID] = &cluster.Status.Cluster.Nodes[i]
+	}
+
+	serverInfos := map[string]*redis.ServerInfo{}
+	for _, node := range clusterStatus.Nodes {
+		if node.ID == "" {
+			continue
+		}
+		redisNode, err := redisNodes.GetNodeByID(node.ID)
+		if err != nil {
+			continue
+		}
+		info, err := admin.GetServerInfo(redisNode.IPPort())
+		if err != nil {
+			glog.V(3).Infof("unable to retrieve the server info of node %s, err:%v", redisNode.IPPort(), err)
+			continue
+		}
+		serverInfos[node.ID] = info
+	}
+
+	kubeNodeZones := map[string]string{}
+	for i := range clusterStatus.Nodes {
+		node := &clusterStatus.Nodes[i]
+		if node.Pod != nil && node.Pod.Spec.NodeName != "" {
+			node.KubeNode = node.Pod.Spec.NodeName
+			zone, ok := kubeNodeZones[node.KubeNode]
+			if !ok {
+				zone = c.getKubeNodeZone(node.KubeNode)
+				kubeNodeZones[node.KubeNode] = zone
+			}
+			node.Zone = zone
+		}
+		if node.ID == "" {
+			continue
+		}
+		previous := previousNodes[node.ID]
+		node.LinkState = getLinkState(clusterInfos, node.ID)
+		node.LastFailoverTime = getLastFailoverTime(previous, node, now)
+
+		info, ok := serverInfos[node.ID]
+		if !ok {
+			// keep the last known details, the node may be temporarily unreachable
+			if previous != nil {
+				node.RedisVersion = previous.RedisVersion
+				node.Metrics = previous.Metrics
+			}
+			continue
+		}
+		node.RedisVersion = info.RedisVersion
+		node.Metrics = &rapi.RedisClusterNodeMetrics{
+			UsedMemory:        info.UsedMemory,
+			MaxMemory:         info.MaxMemory,
+			Keys:              info.Keys,
+			ConnectedClients:  info.ConnectedClients,
+			ReplicationOffset: info.Replication.MasterReplOffset,
+		}
+		if node.Role == rapi.RedisClusterNodeRoleSlave {
+			node.Metrics.ReplicationOffset = info.Replication.SlaveReplOffset
+			if masterInfo, ok := serverInfos[node.MasterRef]; ok {
+				node.Metrics.ReplicationLag = info.Replication.Lag(&masterInfo.Replication)
+			}
+		}
+	}
+	clusterStatus.MetricsTime = &now
+}
+
+// getKubeNodeZone returns the zone of the kubernetes node, empty if unknown
+func (c *Controller) getKubeNodeZone(name string) string {
+	node, err := c.nodeLister.Get(name)
+	if err != nil {
+		glog.V(4).Infof("unable to get the kubernetes node %s, err:%v", name, err)
+		return ""
+	}
+	return getZone(node)
+}
+
+// getZone returns the zone label of the kubernetes node
+func getZone(node *apiv1.Node) string {
+	if zone, ok := node.Labels[zoneLabelKey]; ok {
+		return zone
+	}
+	return node.Labels[zoneBetaLabelKey]
+}
+
+// getLinkState returns disconnected if at least one redis node sees its cluster bus link with the node disconnected
+func getLinkState(clusterInfos *redis.ClusterInfos, id string) string {
+	linkState := ""
+	for _, nodeinfos := range clusterInfos.Infos {
+		if nodeinfos == nil {
+			continue
+		}
+		for _, friend := range nodeinfos.Friends {
+			if friend.ID != id || friend.LinkState == "" {
+				continue
+			}
+			if friend.LinkState == redis.RedisLinkStateDisconnected {
+				return redis.RedisLinkStateDisconnected
+			}
+			linkState = friend.LinkState
+		}
+	}
+	return linkState
+}
+
+// getLastFailoverTime returns now if the node was a slave and is now a master, otherwise the last failover time
+// already stored in the status
+func getLastFailoverTime(previous, node *rapi.RedisClusterNode, now metav1.Time) *metav1.Time {
+	if previous == nil {
+		return nil
+	}
+	if previous.Role == rapi.RedisClusterNodeRoleSlave && node.Role == rapi.RedisClusterNodeRoleMaster {
+		return &now
+	}
+	return previous.LastFailoverTime
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake/admin"
+)
+
+func TestController_setNodesDetails(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&kapiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{zoneLabelKey: "zone-a"}}})
+	indexer.Add(&kapiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2", Labels: map[string]string{zoneBetaLabelKey: "zone-b"}}})
+	c := &Controller{nodeLister: corev1listers.NewNodeLister(indexer)}
+
+	master := newMasterNode("master", "10.0.0.1", redis.BuildSlotSlice(0, redis.HashMaxSlots)...)
+	slave := newHealthSlave("slave", "10.0.0.2", "master")
+	infos := newHealthInfos(master, slave)
+	for _, friend := range infos.Infos["10.0.0.1:6379"].Friends {
+		friend.LinkState = redis.RedisLinkStateDisconnected
+	}
+	for _, friend := range infos.Infos["10.0.0.2:6379"].Friends {
+		friend.LinkState = redis.RedisLinkStateConnected
+	}
+
+	fakeAdmin := admin.NewFakeAdmin([]string{})
+	fakeAdmin.GetServerInfoRet["10.0.0.1:6379"] = admin.ServerInfoRetType{Info: &redis.ServerInfo{
+		RedisVersion: "4.0.9", UsedMemory: 2048, MaxMemory: 4096, Keys: 12, ConnectedClients: 3,
+		Replication: redis.ReplicationInfo{Role: "master", MasterReplOffset: 1500},
+	}}
+	fakeAdmin.GetServerInfoRet["10.0.0.2:6379"] = admin.ServerInfoRetType{Err: fmt.Errorf("timeout")}
+
+	lastFailover := metav1.Unix(1000, 0)
+	cluster := &rapi.RedisCluster{Status: rapi.RedisClusterStatus{Cluster: rapi.RedisClusterClusterStatus{Nodes: []rapi.RedisClusterNode{
+		{ID: "master", Role: rapi.RedisClusterNodeRoleSlave},
+		{ID: "slave", Role: rapi.RedisClusterNodeRoleSlave, RedisVersion: "4.0.8", LastFailoverTime: &lastFailover, Metrics: &rapi.RedisClusterNodeMetrics{Keys: 11}},
+	}}}}
+	newPod := func(kubeNode string) *kapiv1.Pod {
+		return &kapiv1.Pod{Spec: kapiv1.PodSpec{NodeName: kubeNode}}
+	}
+	status := &rapi.RedisClusterClusterStatus{Nodes: []rapi.RedisClusterNode{
+		{ID: "master", Role: rapi.RedisClusterNodeRoleMaster, Pod: newPod("node1")},
+		{ID: "slave", Role: rapi.RedisClusterNodeRoleSlave, MasterRef: "master", Pod: newPod("node2")},
+		{Pod: newPod("node3")},
+	}}
+
+	c.setNodesDetails(fakeAdmin, infos, cluster, status)
+
+	if status.MetricsTime == nil {
+		t.Errorf("setNodesDetails() should set the metrics time")
+	}
+	masterNode := status.Nodes[0]
+	if masterNode.KubeNode != "node1" || masterNode.Zone != "zone-a" || masterNode.RedisVersion != "4.0.9" || masterNode.LinkState != redis.RedisLinkStateConnected {
+		t.Errorf("unexpected master details: %+v", masterNode)
+	}
+	if masterNode.LastFailoverTime == nil {
+		t.Errorf("the master previously slave should have a last failover time")
+	}
+	wantMetrics := &rapi.RedisClusterNodeMetrics{UsedMemory: 2048, MaxMemory: 4096, Keys: 12, ConnectedClients: 3, ReplicationOffset: 1500}
+	if !reflect.DeepEqual(masterNode.Metrics, wantMetrics) {
+		t.Errorf("master metrics = %+v, want %+v", masterNode.Metrics, wantMetrics)
+	}
+	slaveNode := status.Nodes[1]
+	if slaveNode.Zone != "zone-b" || slaveNode.LinkState != redis.RedisLinkStateDisconnected {
+		t.Errorf("unexpected slave details: %+v", slaveNode)
+	}
+	if slaveNode.RedisVersion != "4.0.8" || slaveNode.Metrics == nil || slaveNode.Metrics.Keys != 11 {
+		t.Errorf("the last known details of the unreachable slave should be kept: %+v", slaveNode)
+	}
+	if slaveNode.LastFailoverTime != &lastFailover {
+		t.Errorf("the last failover time of the slave should be kept")
+	}
+	if unknown := status.Nodes[2]; unknown.KubeNode != "node3" || unknown.Zone != "" || unknown.Metrics != nil {
+		t.Errorf("unexpected details for a pod without redis node: %+v", unknown)
+	}
+}
+
+func TestController_setNodesDetailsReplicationLag(t *testing.T) {
+	c := &Controller{nodeLister: corev1listers.NewNodeLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))}
+	master := newMasterNode("master", "10.0.0.1", redis.BuildSlotSlice(0, redis.HashMaxSlots)...)
+	slave := newHealthSlave("slave", "10.0.0.2", "master")
+	fakeAdmin := admin.NewFakeAdmin([]string{})
+	fakeAdmin.GetServerInfoRet["10.0.0.1:6379"] = admin.ServerInfoRetType{Info: &redis.ServerInfo{Replication: redis.ReplicationInfo{Role: "master", MasterReplOffset: 1500}}}
+	fakeAdmin.GetServerInfoRet["10.0.0.2:6379"] = admin.ServerInfoRetType{Info: &redis.ServerInfo{Replication: redis.ReplicationInfo{Role: "slave", SlaveReplOffset: 1200}}}
+	status := &rapi.RedisClusterClusterStatus{Nodes: []rapi.RedisClusterNode{
+		{ID: "master", Role: rapi.RedisClusterNodeRoleMaster},
+		{ID: "slave", Role: rapi.RedisClusterNodeRoleSlave, MasterRef: "master"},
+	}}
+
+	c.setNodesDetails(fakeAdmin, newHealthInfos(master, slave), &rapi.RedisCluster{}, status)
+
+	if got := status.Nodes[1].Metrics; got == nil || got.ReplicationOffset != 1200 || got.ReplicationLag != 300 {
+		t.Errorf("slave metrics = %+v, want offset 1200 and lag 300", got)
+	}
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/clustering"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// operationSucceededEventReason is the reason of the event emitted when an on-demand operation succeeded
+	operationSucceededEventReason = "OperationSucceeded"
+	// operationFailedEventReason is the reason of the event emitted when an on-demand operation failed
+	operationFailedEventReason = "OperationFailed"
+)
+
+// manageOperations executes the first on-demand operation of the spec that doesn't have a result in the status yet.
+// An operation is executed only once, even if it failed: its Running phase is saved before it is executed, and an
+// operation found Running at the next sync, whose result couldn't be saved, is marked Failed instead of being executed
+// again. The operations with a duplicate name are rejected. The operations are not held by the maintenance windows nor
+// by the approvals. Returns true if the RedisCluster has been updated.
+func (c *Controller) manageOperations(admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos) (bool, error) {
+	statuses, pruned := pruneOperationStatuses(cluster)
+	cluster.Status.Operations = statuses
+	failed := append(failInterruptedOperations(cluster), rejectDuplicateOperations(cluster)...)
+	for _, status := range failed {
+		c.recorder.Eventf(cluster, apiv1.EventTypeWarning, operationFailedEventReason, "Operation %s (%s) failed: %s", status.Name, status.Type, status.Message)
+	}
+	if pruned || len(failed) > 0 {
+		if _, err := c.updateHandler(cluster); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	op := nextOperation(cluster)
+	if op == nil {
+		return false, nil
+	}
+
+	glog.Infof("cluster %s/%s, running operation %s (%s)", cluster.Namespace, cluster.Name, op.Name, op.Type)
+	cluster.Status.Operations = append(cluster.Status.Operations, rapi.RedisClusterOperationStatus{
+		Name:  op.Name,
+		Type:  op.Type,
+		Phase: rapi.OperationPhaseRunning,
+	})
+	running, err := c.updateHandler(cluster)
+	if err != nil {
+		return false, err
+	}
+	// the operation pointer refers to the spec of the cluster before its update
+	operation := *op
+
+	status := getOperationStatus(running, operation.Name)
+	status.Phase = rapi.OperationPhaseSucceeded
+	if err := c.runOperation(admin, running, infos, &operation); err != nil {
+		glog.Errorf("cluster %s/%s, operation %s (%s) failed: %v", cluster.Namespace, cluster.Name, operation.Name, operation.Type, err)
+		status.Phase = rapi.OperationPhaseFailed
+		status.Message = err.Error()
+		c.recorder.Eventf(running, apiv1.EventTypeWarning, operationFailedEventReason, "Operation %s (%s%s) failed: %v", operation.Name, operation.Type, describeOperationTarget(&operation), err)
+	} else {
+		c.recorder.Eventf(running, apiv1.EventTypeNormal, operationSucceededEventReason, "Operation %s (%s%s) succeeded", operation.Name, operation.Type, describeOperationTarget(&operation))
+	}
+	status.CompletionTime = metav1.Now()
+
+	if _, err := c.updateHandler(running); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// failInterruptedOperations marks Failed the operations still Running: their result couldn't be saved, and they may
+// have been executed. Returns the statuses of the marked operations.
+func failInterruptedOperations(cluster *rapi.RedisCluster) []rapi.RedisClusterOperationStatus {
+	var failed []rapi.RedisClusterOperationStatus
+	for i := range cluster.Status.Operations {
+		status := &cluster.Status.Operations[i]
+		if status.Phase != rapi.OperationPhaseRunning {
+			continue
+		}
+		status.Phase = rapi.OperationPhaseFailed
+		status.Message = "interrupted before its result was saved, it is not executed again"
+		status.CompletionTime = metav1.Now()
+		failed = append(failed, *status)
+	}
+	return failed
+}
+
+// rejectDuplicateOperations marks Failed the operations whose name is used several times in the spec, the result of
+// each of them couldn't be told apart. Returns the statuses of the rejected operations.
+func rejectDuplicateOperations(cluster *rapi.RedisCluster) []rapi.RedisClusterOperationStatus {
+	count := map[string]int{}
+	for _, op := range cluster.Spec.Operations {
+		count[op.Name]++
+	}
+	var rejected []rapi.RedisClusterOperationStatus
+	for _, op := range cluster.Spec.Operations {
+		if count[op.Name] < 2 || getOperationStatus(cluster, op.Name) != nil {
+			continue
+		}
+		status := rapi.RedisClusterOperationStatus{
+			Name:           op.Name,
+			Type:           op.Type,
+			Phase:          rapi.OperationPhaseFailed,
+			Message:        fmt.Sprintf("%d operations are named %s, they are not executed", count[op.Name], op.Name),
+			CompletionTime: metav1.Now(),
+		}
+		cluster.Status.Operations = append(cluster.Status.Operations, status)
+		rejected = append(rejected, status)
+	}
+	return rejected
+}
+
+func (c *Controller) runOperation(admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, op *rapi.RedisClusterOperation) error {
+	if rapi.IsStandbyRedisCluster(cluster) && op.Type != rapi.OperationPromote {
+		return fmt.Errorf("the redis nodes of a standby cluster run standalone, only the promote operation is allowed")
+	}
+	switch op.Type {
+	case rapi.OperationFailover:
+		node, err := getOperationTargetNode(cluster, infos, op)
+		if err != nil {
+			return err
+		}
+		if !redis.IsMasterWithSlot(node) {
+			return fmt.Errorf("node %s is not a master with slots", node.ID)
+		}
+		return admin.StartFailover(node.IPPort(), nil)
+	case rapi.OperationRebalance:
+		rCluster, nodes, err := newRedisCluster(admin, cluster)
+		if err != nil {
+			return err
+		}
+		masters := nodes.FilterByFunc(redis.IsMasterWithSlot)
+		if len(masters) == 0 {
+			return fmt.Errorf("no master with slots to rebalance")
+		}
+		defer clustering.RecordActions(c.recorder, cluster, rCluster)
+		return clustering.DispatchSlotToNewMasters(rCluster, admin, masters, masters, masters)
+	case rapi.OperationForgetNode:
+		nodeID, err := getOperationTargetNodeID(cluster, op)
+		if err != nil {
+			return err
+		}
+		return admin.ForgetNode(nodeID)
+	case rapi.OperationResetNode:
+		node, err := getOperationTargetNode(cluster, infos, op)
+		if err != nil {
+			return err
+		}
+		if redis.IsMasterWithSlot(node) {
+			return fmt.Errorf("node %s is a master with slots, it can't be reset", node.ID)
+		}
+		return admin.FlushAndReset(node.IPPort(), redis.ResetHard)
+	case rapi.OperationPromote:
+		return promoteStandby(cluster)
+	}
+	return fmt.Errorf("unknown operation type %q", op.Type)
+}
+
+// describeOperationTarget returns the node or the pod targeted by the operation, formatted to follow its type
+func describeOperationTarget(op *rapi.RedisClusterOperation) string {
+	switch {
+	case op.NodeID != "":
+		return " on node " + op.NodeID
+	case op.PodName != "":
+		return " on pod " + op.PodName
+	}
+	return ""
+}
+
+// nextOperation returns the first operation of the spec without result in the status
+func nextOperation(cluster *rapi.RedisCluster) *rapi.RedisClusterOperation {
+	for i, op := range cluster.Spec.Operations {
+		if getOperationStatus(cluster, op.Name) == nil {
+			return &cluster.Spec.Operations[i]
+		}
+	}
+	return nil
+}
+
+// getOperationStatus returns the status of the operation corresponding to the name, nil if not found
+func getOperationStatus(cluster *rapi.RedisCluster, name string) *rapi.RedisClusterOperationStatus {
+	for i, status := range cluster.Status.Operations {
+		if status.Name == name {
+			return &cluster.Status.Operations[i]
+		}
+	}
+	return nil
+}
+
+// pruneOperationStatuses returns the operation statuses still referenced in the spec, and true if some statuses have been removed
+func pruneOperationStatuses(cluster *rapi.RedisCluster) ([]rapi.RedisClusterOperationStatus, bool) {
+	var statuses []rapi.RedisClusterOperationStatus
+	for _, status := range cluster.Status.Operations {
+		for _, op := range cluster.Spec.Operations {
+			if op.Name == status.Name {
+				statuses = append(statuses, status)
+				break
+			}
+		}
+	}
+	return statuses, len(statuses) != len(cluster.Status.Operations)
+}
+
+// getOperationTargetNodeID returns the redis node ID targeted by the operation, from the NodeID or the PodName
+func getOperationTargetNodeID(cluster *rapi.RedisCluster, op *rapi.RedisClusterOperation) (string, error) {
+	if op.NodeID != "" {
+		return op.NodeID, nil
+	}
+	if op.PodName == "" {
+		return "", fmt.Errorf("operation %s requires a nodeID or a podName", op.Name)
+	}
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.PodName == op.PodName && node.ID != "" {
+			return node.ID, nil
+		}
+	}
+	return "", fmt.Errorf("no redis node found for pod %s", op.PodName)
+}
+
+// getOperationTargetNode returns the redis node targeted by the operation
+func getOperationTargetNode(cluster *rapi.RedisCluster, infos *redis.ClusterInfos, op *rapi.RedisClusterOperation) (*redis.Node, error) {
+	nodeID, err := getOperationTargetNodeID(cluster, op)
+	if err != nil {
+		return nil, err
+	}
+	if infos == nil {
+		return nil, fmt.Errorf("no cluster infos available")
+	}
+	return infos.GetNodes().GetNodeByID(nodeID)
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+)
+
+func Test_nextOperation(t *testing.T) {
+	failover := rapi.RedisClusterOperation{Name: "op1", Type: rapi.OperationFailover, PodName: "pod1"}
+	rebalance := rapi.RedisClusterOperation{Name: "op2", Type: rapi.OperationRebalance}
+
+	tests := []struct {
+		name     string
+		spec     []rapi.RedisClusterOperation
+		statuses []rapi.RedisClusterOperationStatus
+		want     *rapi.RedisClusterOperation
+	}{
+		{
+			name: "no operation",
+			want: nil,
+		},
+		{
+			name: "first operation",
+			spec: []rapi.RedisClusterOperation{failover, rebalance},
+			want: &failover,
+		},
+		{
+			name:     "first operation already done",
+			spec:     []rapi.RedisClusterOperation{failover, rebalance},
+			statuses: []rapi.RedisClusterOperationStatus{{Name: "op1", Type: rapi.OperationFailover, Phase: rapi.OperationPhaseFailed}},
+			want:     &rebalance,
+		},
+		{
+			name: "all operations done",
+			spec: []rapi.RedisClusterOperation{failover, rebalance},
+			statuses: []rapi.RedisClusterOperationStatus{
+				{Name: "op1", Type: rapi.OperationFailover, Phase: rapi.OperationPhaseSucceeded},
+				{Name: "op2", Type: rapi.OperationRebalance, Phase: rapi.OperationPhaseSucceeded},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &rapi.RedisCluster{
+				Spec:   rapi.RedisClusterSpec{Operations: tt.spec},
+				Status: rapi.RedisClusterStatus{Operations: tt.statuses},
+			}
+			if got := nextOperation(cluster); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nextOperation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pruneOperationStatuses(t *testing.T) {
+	cluster := &rapi.RedisCluster{
+		Spec: rapi.RedisClusterSpec{Operations: []rapi.RedisClusterOperation{{Name: "op2", Type: rapi.OperationRebalance}}},
+		Status: rapi.RedisClusterStatus{Operations: []rapi.RedisClusterOperationStatus{
+			{Name: "op1", Type: rapi.OperationFailover},
+			{Name: "op2", Type: rapi.OperationRebalance},
+		}},
+	}
+	got, pruned := pruneOperationStatuses(cluster)
+	if !pruned {
+		t.Errorf("pruneOperationStatuses() pruned = false, want true")
+	}
+	want := []rapi.RedisClusterOperationStatus{{Name: "op2", Type: rapi.OperationRebalance}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pruneOperationStatuses() = %v, want %v", got, want)
+	}
+}
+
+func Test_getOperationTargetNodeID(t *testing.T) {
+	cluster := &rapi.RedisCluster{
+		Status: rapi.RedisClusterStatus{
+			Cluster: rapi.RedisClusterClusterStatus{
+				Nodes: []rapi.RedisClusterNode{{ID: "id1", PodName: "pod1"}, {ID: "id2", PodName: "pod2"}},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		op      rapi.RedisClusterOperation
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "by node id",
+			op:   rapi.RedisClusterOperation{Name: "op", NodeID: "id3", PodName: "pod1"},
+			want: "id3",
+		},
+		{
+			name: "by pod name",
+			op:   rapi.RedisClusterOperation{Name: "op", PodName: "pod2"},
+			want: "id2",
+		},
+		{
+			name:    "unknown pod",
+			op:      rapi.RedisClusterOperation{Name: "op", PodName: "pod3"},
+			wantErr: true,
+		},
+		{
+			name:    "no target",
+			op:      rapi.RedisClusterOperation{Name: "op"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getOperationTargetNodeID(cluster, &tt.op)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getOperationTargetNodeID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("getOperationTargetNodeID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_manageOperations(t *testing.T) {
+	cluster := &rapi.RedisCluster{
+		Spec: rapi.RedisClusterSpec{Operations: []rapi.RedisClusterOperation{
+			{Name: "op1", Type: "unknown"},
+			{Name: "op2", Type: rapi.OperationRebalance},
+		}},
+	}
+	var saved []rapi.RedisClusterOperationPhase
+	c := &Controller{
+		recorder: record.NewFakeRecorder(10),
+		updateHandler: func(rc *rapi.RedisCluster) (*rapi.RedisCluster, error) {
+			saved = append(saved, getOperationStatus(rc, "op1").Phase)
+			return rc.DeepCopy(), nil
+		},
+	}
+	updated, err := c.manageOperations(nil, cluster, nil)
+	if err != nil || !updated {
+		t.Fatalf("manageOperations() = %v, %v, want true, nil", updated, err)
+	}
+	if want := []rapi.RedisClusterOperationPhase{rapi.OperationPhaseRunning, rapi.OperationPhaseFailed}; !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved phases = %v, want %v", saved, want)
+	}
+}
+
+func Test_failInterruptedOperations(t *testing.T) {
+	cluster := &rapi.RedisCluster{
+		Status: rapi.RedisClusterStatus{Operations: []rapi.RedisClusterOperationStatus{
+			{Name: "op1", Type: rapi.OperationFailover, Phase: rapi.OperationPhaseSucceeded},
+			{Name: "op2", Type: rapi.OperationForgetNode, Phase: rapi.OperationPhaseRunning},
+		}},
+	}
+	failed := failInterruptedOperations(cluster)
+	if len(failed) != 1 || failed[0].Name != "op2" {
+		t.Errorf("failInterruptedOperations() = %v, want op2", failed)
+	}
+	if phase := getOperationStatus(cluster, "op2").Phase; phase != rapi.OperationPhaseFailed {
+		t.Errorf("op2 phase = %s, want %s", phase, rapi.OperationPhaseFailed)
+	}
+	if phase := getOperationStatus(cluster, "op1").Phase; phase != rapi.OperationPhaseSucceeded {
+		t.Errorf("op1 phase = %s, want %s", phase, rapi.OperationPhaseSucceeded)
+	}
+}
+
+func Test_rejectDuplicateOperations(t *testing.T) {
+	cluster := &rapi.RedisCluster{
+		Spec: rapi.RedisClusterSpec{Operations: []rapi.RedisClusterOperation{
+			{Name: "op1", Type: rapi.OperationFailover, PodName: "pod1"},
+			{Name: "op2", Type: rapi.OperationRebalance},
+			{Name: "op1", Type: rapi.OperationResetNode, PodName: "pod2"},
+		}},
+	}
+	rejected := rejectDuplicateOperations(cluster)
+	if len(rejected) != 1 || rejected[0].Name != "op1" || rejected[0].Phase != rapi.OperationPhaseFailed {
+		t.Errorf("rejectDuplicateOperations() = %v, want op1 failed once", rejected)
+	}
+	if got := nextOperation(cluster); got == nil || got.Name != "op2" {
+		t.Errorf("nextOperation() = %v, want op2", got)
+	}
+}
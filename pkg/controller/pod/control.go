@@ -7,17 +7,34 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
 	kapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clientset "k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/config"
 	"github.com/golang/glog"
 )
 
+const (
+	// RedisNodeContainerName is the name of the container running the redis node in the redis pods
+	RedisNodeContainerName = "redis-node"
+	// SuccessfulCreatePodReason is the reason of the event emitted when a pod has been created
+	SuccessfulCreatePodReason = "SuccessfulCreate"
+	// FailedCreatePodReason is the reason of the event emitted when a pod can't be created
+	FailedCreatePodReason = "FailedCreate"
+	// SuccessfulDeletePodReason is the reason of the event emitted when a pod has been deleted
+	SuccessfulDeletePodReason = "SuccessfulDelete"
+	// FailedDeletePodReason is the reason of the event emitted when a pod can't be deleted
+	FailedDeletePodReason = "FailedDelete"
+)
+
 // RedisClusterControlInteface interface for the RedisClusterPodControl
 type RedisClusterControlInteface interface {
 	// GetRedisClusterPods return list of Pod attached to a RedisCluster
@@ -28,6 +45,18 @@ type RedisClusterControlInteface interface {
 	DeletePod(redisCluster *rapi.RedisCluster, podName string) error
 	// DeletePodNow used to delete now (force) a pod from its name
 	DeletePodNow(redisCluster *rapi.RedisCluster, podName string) error
+	// GetRedisSentinelPods return list of sentinel Pod attached to a RedisCluster in Sentinel mode
+	GetRedisSentinelPods(redisCluster *rapi.RedisCluster) ([]*kapiv1.Pod, error)
+	// CreateSentinelPod used to create a sentinel Pod for a RedisCluster in Sentinel mode
+	CreateSentinelPod(redisCluster *rapi.RedisCluster) (*kapiv1.Pod, error)
+	// AdoptPod labels an existing pod and sets the RedisCluster as its controller
+	AdoptPod(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, podNo int32) (*kapiv1.Pod, error)
+	// SetPodShard labels the pod with the shard it belongs to, an empty shard removes the label
+	SetPodShard(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, shard string) (*kapiv1.Pod, error)
+	// SetPodNo labels the pod with its pod number
+	SetPodNo(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, podNo int32) (*kapiv1.Pod, error)
+	// SetStandbyRole annotates the pod of a promoted standby cluster with the role of its redis node
+	SetStandbyRole(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, role string) (*kapiv1.Pod, error)
 }
 
 var _ RedisClusterControlInteface = &RedisClusterControl{}
@@ -65,7 +94,95 @@ func (p *RedisClusterControl) CreatePod(redisCluster *rapi.RedisCluster, current
 		return pod, err
 	}
 	glog.V(6).Infof("CreatePod: %s/%s", redisCluster.Namespace, pod.Name)
-	return p.KubeClient.CoreV1().Pods(redisCluster.Namespace).Create(pod)
+	return p.createPod(redisCluster, pod)
+}
+
+// GetRedisSentinelPods return list of sentinel Pod attached to a RedisCluster in Sentinel mode
+func (p *RedisClusterControl) GetRedisSentinelPods(redisCluster *rapi.RedisCluster) ([]*kapiv1.Pod, error) {
+	selector, err := CreateRedisSentinelLabelSelector(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+	return p.PodLister.Pods(redisCluster.Namespace).List(selector)
+}
+
+// CreateSentinelPod used to create a sentinel Pod for a RedisCluster in Sentinel mode
+func (p *RedisClusterControl) CreateSentinelPod(redisCluster *rapi.RedisCluster) (*kapiv1.Pod, error) {
+	pod, err := initSentinelPod(redisCluster)
+	if err != nil {
+		return pod, err
+	}
+	glog.V(6).Infof("CreateSentinelPod: %s/%s", redisCluster.Namespace, pod.GenerateName)
+	return p.createPod(redisCluster, pod)
+}
+
+// createPod creates the pod and emits an event on the RedisCluster
+func (p *RedisClusterControl) createPod(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod) (*kapiv1.Pod, error) {
+	newPod, err := p.KubeClient.CoreV1().Pods(redisCluster.Namespace).Create(pod)
+	if err != nil {
+		p.Recorder.Eventf(redisCluster, kapiv1.EventTypeWarning, FailedCreatePodReason, "Error creating pod: %v", err)
+		return nil, err
+	}
+	p.Recorder.Eventf(redisCluster, kapiv1.EventTypeNormal, SuccessfulCreatePodReason, "Created pod: %s", newPod.Name)
+	return newPod, nil
+}
+
+// AdoptPod labels an existing pod and sets the RedisCluster as its controller
+func (p *RedisClusterControl) AdoptPod(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, podNo int32) (*kapiv1.Pod, error) {
+	adoptedPod, err := initAdoptedPod(redisCluster, pod, podNo)
+	if err != nil {
+		return nil, err
+	}
+	glog.V(6).Infof("AdoptPod: %s/%s", redisCluster.Namespace, pod.Name)
+	return p.KubeClient.CoreV1().Pods(redisCluster.Namespace).Update(adoptedPod)
+}
+
+// SetPodShard labels the pod with the shard it belongs to, an empty shard removes the label
+func (p *RedisClusterControl) SetPodShard(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, shard string) (*kapiv1.Pod, error) {
+	if pod.Labels[rapi.ShardLabelKey] == shard {
+		return pod, nil
+	}
+	labeledPod := pod.DeepCopy()
+	if shard == "" {
+		delete(labeledPod.Labels, rapi.ShardLabelKey)
+	} else {
+		if labeledPod.Labels == nil {
+			labeledPod.Labels = map[string]string{}
+		}
+		labeledPod.Labels[rapi.ShardLabelKey] = shard
+	}
+	glog.V(6).Infof("SetPodShard: %s/%s shard:%q", redisCluster.Namespace, pod.Name, shard)
+	return p.KubeClient.CoreV1().Pods(redisCluster.Namespace).Update(labeledPod)
+}
+
+// SetPodNo labels the pod with its pod number
+func (p *RedisClusterControl) SetPodNo(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, podNo int32) (*kapiv1.Pod, error) {
+	value := strconv.Itoa(int(podNo))
+	if pod.Labels[rapi.PodNoLabelKey] == value {
+		return pod, nil
+	}
+	labeledPod := pod.DeepCopy()
+	if labeledPod.Labels == nil {
+		labeledPod.Labels = map[string]string{}
+	}
+	labeledPod.Labels[rapi.PodNoLabelKey] = value
+	glog.V(6).Infof("SetPodNo: %s/%s pod-no:%s", redisCluster.Namespace, pod.Name, value)
+	return p.KubeClient.CoreV1().Pods(redisCluster.Namespace).Update(labeledPod)
+}
+
+// SetStandbyRole annotates the pod of a promoted standby cluster with the role of its redis node, the redis node
+// restarts in cluster mode once annotated
+func (p *RedisClusterControl) SetStandbyRole(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, role string) (*kapiv1.Pod, error) {
+	if pod.Annotations[rapi.StandbyRoleAnnotationKey] == role {
+		return pod, nil
+	}
+	annotatedPod := pod.DeepCopy()
+	if annotatedPod.Annotations == nil {
+		annotatedPod.Annotations = map[string]string{}
+	}
+	annotatedPod.Annotations[rapi.StandbyRoleAnnotationKey] = role
+	glog.V(6).Infof("SetStandbyRole: %s/%s role:%s", redisCluster.Namespace, pod.Name, role)
+	return p.KubeClient.CoreV1().Pods(redisCluster.Namespace).Update(annotatedPod)
 }
 
 // DeletePod used to delete a pod from its name
@@ -83,7 +200,16 @@ func (p *RedisClusterControl) DeletePodNow(redisCluster *rapi.RedisCluster, podN
 
 // DeletePodNow used to delete now (force) a pod from its name
 func (p *RedisClusterControl) deletePodGracefullperiode(redisCluster *rapi.RedisCluster, podName string, period *int64) error {
-	return p.KubeClient.CoreV1().Pods(redisCluster.Namespace).Delete(podName, &metav1.DeleteOptions{GracePeriodSeconds: period})
+	if err := p.KubeClient.CoreV1().Pods(redisCluster.Namespace).Delete(podName, &metav1.DeleteOptions{GracePeriodSeconds: period}); err != nil {
+		p.Recorder.Eventf(redisCluster, kapiv1.EventTypeWarning, FailedDeletePodReason, "Error deleting pod %s: %v", podName, err)
+		return err
+	}
+	if period != nil && *period == 0 {
+		p.Recorder.Eventf(redisCluster, kapiv1.EventTypeNormal, SuccessfulDeletePodReason, "Deleted pod: %s (forced)", podName)
+	} else {
+		p.Recorder.Eventf(redisCluster, kapiv1.EventTypeNormal, SuccessfulDeletePodReason, "Deleted pod: %s", podName)
+	}
+	return nil
 }
 
 func initPod(redisCluster *rapi.RedisCluster, currentPods int32) (*kapiv1.Pod, error) {
@@ -115,12 +241,150 @@ func initPod(redisCluster *rapi.RedisCluster, currentPods int32) (*kapiv1.Pod, e
 	}
 	pod.Spec = *redisCluster.Spec.PodTemplate.Spec.DeepCopy()
 
-	// Generate a MD5 representing the PodSpec send
+	// Generate a MD5 representing the PodSpec send, before the injection of the mode: it is compared to the hash of
+	// the PodTemplate
 	hash, err := GenerateMD5Spec(&pod.Spec)
 	if err != nil {
 		return nil, err
 	}
 	pod.Annotations[rapi.PodSpecMD5LabelKey] = hash
+	mode := string(redisCluster.Spec.Mode)
+	if rapi.IsStandbyRedisCluster(redisCluster) {
+		// the redis nodes of a standby cluster run standalone to replicate the source masters until the promotion
+		mode = config.StandbyMode
+	}
+	setRedisNodeMode(&pod.Spec, mode)
+
+	return pod, nil
+}
+
+// setRedisNodeMode sets the --mode argument of the redis-node container from the mode of the RedisCluster, replacing
+// the one of the PodTemplate: in Sentinel and Standby modes the redis nodes must not start with the cluster mode enabled
+func setRedisNodeMode(spec *kapiv1.PodSpec, mode string) {
+	if mode == "" {
+		return
+	}
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if container.Name != RedisNodeContainerName {
+			continue
+		}
+		args := []string{}
+		for j := 0; j < len(container.Args); j++ {
+			switch arg := container.Args[j]; {
+			case arg == "--mode":
+				j++ // the value follows the flag
+			case !strings.HasPrefix(arg, "--mode="):
+				args = append(args, arg)
+			}
+		}
+		container.Args = append(args, fmt.Sprintf("--mode=%s", mode))
+	}
+}
+
+// initAdoptedPod returns a copy of the pod with the RedisCluster labels and owner reference. The pod is annotated
+// with the hash of the current PodTemplate: the adoption doesn't trigger a rolling update.
+func initAdoptedPod(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, podNo int32) (*kapiv1.Pod, error) {
+	if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil && controllerRef.UID != redisCluster.UID {
+		return nil, fmt.Errorf("pod %s/%s is already controlled by %s %s", pod.Namespace, pod.Name, controllerRef.Kind, controllerRef.Name)
+	}
+	if redisCluster.Spec.PodTemplate == nil {
+		return nil, fmt.Errorf("rediscluster[%s/%s] PodTemplate missing", redisCluster.Namespace, redisCluster.Name)
+	}
+	desiredPodLabels, err := GetPodLabelsSet(redisCluster, podNo)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := GenerateMD5Spec(&redisCluster.Spec.PodTemplate.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	adoptedPod := pod.DeepCopy()
+	if adoptedPod.Labels == nil {
+		adoptedPod.Labels = map[string]string{}
+	}
+	for k, v := range desiredPodLabels {
+		if _, ok := adoptedPod.Labels[k]; k == rapi.PodNoLabelKey && ok {
+			continue
+		}
+		adoptedPod.Labels[k] = v
+	}
+	if adoptedPod.Annotations == nil {
+		adoptedPod.Annotations = map[string]string{}
+	}
+	adoptedPod.Annotations[rapi.PodSpecMD5LabelKey] = hash
+	if metav1.GetControllerOf(adoptedPod) == nil {
+		adoptedPod.OwnerReferences = append(adoptedPod.OwnerReferences, BuildOwnerReference(redisCluster))
+	}
+	return adoptedPod, nil
+}
+
+// initSentinelPod builds the sentinel pod: the sentinel starts with a minimal configuration, the master
+// to monitor is configured by the operator with the SENTINEL MONITOR command
+func initSentinelPod(redisCluster *rapi.RedisCluster) (*kapiv1.Pod, error) {
+	if redisCluster == nil {
+		return nil, fmt.Errorf("rediscluster nil pointer")
+	}
+	if redisCluster.Spec.PodTemplate == nil {
+		return nil, fmt.Errorf("rediscluster[%s/%s] PodTemplate missing", redisCluster.Namespace, redisCluster.Name)
+	}
+
+	desiredLabels, err := GetSentinelLabelsSet(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+	desiredAnnotations, err := GetAnnotationsSet(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	image := ""
+	if redisCluster.Spec.Sentinel != nil {
+		image = redisCluster.Spec.Sentinel.Image
+	}
+	if image == "" && len(redisCluster.Spec.PodTemplate.Spec.Containers) > 0 {
+		image = redisCluster.Spec.PodTemplate.Spec.Containers[0].Image
+	}
+	if image == "" {
+		return nil, fmt.Errorf("rediscluster[%s/%s] sentinel image missing", redisCluster.Namespace, redisCluster.Name)
+	}
+
+	templateSpec := redisCluster.Spec.PodTemplate.Spec.DeepCopy()
+	pod := &kapiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       redisCluster.Namespace,
+			Labels:          desiredLabels,
+			Annotations:     desiredAnnotations,
+			GenerateName:    fmt.Sprintf("redissentinel-%s-", redisCluster.Name),
+			OwnerReferences: []metav1.OwnerReference{BuildOwnerReference(redisCluster)},
+		},
+		Spec: kapiv1.PodSpec{
+			NodeSelector:     templateSpec.NodeSelector,
+			Tolerations:      templateSpec.Tolerations,
+			ImagePullSecrets: templateSpec.ImagePullSecrets,
+			Volumes:          []kapiv1.Volume{{Name: "conf", VolumeSource: kapiv1.VolumeSource{EmptyDir: &kapiv1.EmptyDirVolumeSource{}}}},
+			Containers: []kapiv1.Container{
+				{
+					Name:            "redis-sentinel",
+					Image:           image,
+					ImagePullPolicy: kapiv1.PullIfNotPresent,
+					// the sentinel rewrites its configuration file, it has to be writable
+					Command: []string{"sh", "-c", "echo 'port 26379' > /sentinel-conf/sentinel.conf && exec redis-server /sentinel-conf/sentinel.conf --sentinel"},
+					Ports:   []kapiv1.ContainerPort{{Name: "sentinel", ContainerPort: 26379}},
+					VolumeMounts: []kapiv1.VolumeMount{
+						{Name: "conf", MountPath: "/sentinel-conf"},
+					},
+					ReadinessProbe: &kapiv1.Probe{
+						Handler: kapiv1.Handler{
+							TCPSocket: &kapiv1.TCPSocketAction{Port: intstr.FromInt(26379)},
+						},
+						PeriodSeconds: 10,
+					},
+				},
+			},
+		},
+	}
 
 	return pod, nil
 }
@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/config"
 )
 
 func Test_initPod(t *testing.T) {
@@ -54,7 +55,7 @@ func Test_initPod(t *testing.T) {
 						Kind:       rapi.ResourceKind,
 						Controller: boolPtr(true),
 					}},
-					Labels:      map[string]string{rapi.ClusterNameLabelKey: "testcluster"},
+					Labels:      map[string]string{rapi.ClusterNameLabelKey: "testcluster", rapi.PodNoLabelKey: "0"},
 					Annotations: map[string]string{rapi.PodSpecMD5LabelKey: string(emptyPodSpecMD5)},
 				},
 			},
@@ -76,3 +77,165 @@ func Test_initPod(t *testing.T) {
 		})
 	}
 }
+
+func Test_initSentinelPod(t *testing.T) {
+	tests := []struct {
+		name      string
+		sentinel  *rapi.RedisSentinelSpec
+		podSpec   kapiv1.PodSpec
+		wantImage string
+		wantErr   bool
+	}{
+		{
+			name:    "no image",
+			wantErr: true,
+		},
+		{
+			name:      "image from the pod template",
+			podSpec:   kapiv1.PodSpec{Containers: []kapiv1.Container{{Name: "redis-node", Image: "redisnode:1"}}},
+			wantImage: "redisnode:1",
+		},
+		{
+			name:      "image from the sentinel spec",
+			sentinel:  &rapi.RedisSentinelSpec{Image: "redis:4.0-alpine"},
+			podSpec:   kapiv1.PodSpec{Containers: []kapiv1.Container{{Name: "redis-node", Image: "redisnode:1"}}},
+			wantImage: "redis:4.0-alpine",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &rapi.RedisCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "testcluster", Namespace: "foo"},
+				Spec: rapi.RedisClusterSpec{
+					Mode:        rapi.SentinelMode,
+					Sentinel:    tt.sentinel,
+					PodTemplate: &kapiv1.PodTemplateSpec{Spec: tt.podSpec},
+				},
+			}
+			got, err := initSentinelPod(cluster)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("initSentinelPod() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Spec.Containers[0].Image != tt.wantImage {
+				t.Errorf("initSentinelPod() image = %s, want %s", got.Spec.Containers[0].Image, tt.wantImage)
+			}
+			wantLabels := map[string]string{rapi.SentinelNameLabelKey: "testcluster"}
+			if !reflect.DeepEqual(map[string]string(got.Labels), wantLabels) {
+				t.Errorf("initSentinelPod() labels = %v, want %v", got.Labels, wantLabels)
+			}
+			if got.GenerateName != "redissentinel-testcluster-" {
+				t.Errorf("initSentinelPod() generateName = %s", got.GenerateName)
+			}
+		})
+	}
+}
+
+func Test_initAdoptedPod(t *testing.T) {
+	podSpec := kapiv1.PodSpec{Containers: []kapiv1.Container{{Name: "redis", Image: "redis:4.0"}}}
+	hash, _ := GenerateMD5Spec(&podSpec)
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "testcluster", Namespace: "foo", UID: "cluster-uid"},
+		Spec:       rapi.RedisClusterSpec{PodTemplate: &kapiv1.PodTemplateSpec{Spec: podSpec}},
+	}
+	tests := []struct {
+		name       string
+		pod        *kapiv1.Pod
+		wantOwners int
+		wantErr    bool
+	}{
+		{
+			name:       "unowned pod",
+			pod:        &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "foo", Labels: map[string]string{"app": "redis"}}},
+			wantOwners: 1,
+		},
+		{
+			name:       "pod already adopted",
+			pod:        &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "foo", OwnerReferences: []metav1.OwnerReference{BuildOwnerReference(cluster)}}},
+			wantOwners: 1,
+		},
+		{
+			name:    "pod controlled by a statefulset",
+			pod:     &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "foo", OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "redis", UID: "sts-uid", Controller: boolPtr(true)}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := initAdoptedPod(cluster, tt.pod, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("initAdoptedPod() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Labels[rapi.ClusterNameLabelKey] != "testcluster" {
+				t.Errorf("initAdoptedPod() labels = %v", got.Labels)
+			}
+			if tt.pod.Labels != nil && got.Labels["app"] != "redis" {
+				t.Errorf("initAdoptedPod() existing labels not kept: %v", got.Labels)
+			}
+			if got.Annotations[rapi.PodSpecMD5LabelKey] != hash {
+				t.Errorf("initAdoptedPod() podspec hash = %s, want %s", got.Annotations[rapi.PodSpecMD5LabelKey], hash)
+			}
+			if len(got.OwnerReferences) != tt.wantOwners {
+				t.Errorf("initAdoptedPod() ownerReferences = %v", got.OwnerReferences)
+			}
+			if _, ok := tt.pod.Labels[rapi.ClusterNameLabelKey]; ok {
+				t.Errorf("initAdoptedPod() the pod from the cache has been modified")
+			}
+		})
+	}
+}
+
+func Test_setRedisNodeMode(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+		args []string
+		want []string
+	}{
+		{
+			name: "no mode",
+			args: []string{"--v=2"},
+			want: []string{"--v=2"},
+		},
+		{
+			name: "sentinel mode",
+			mode: string(rapi.SentinelMode),
+			args: []string{"--v=2"},
+			want: []string{"--v=2", "--mode=Sentinel"},
+		},
+		{
+			name: "standby mode",
+			mode: config.StandbyMode,
+			args: []string{"--v=2"},
+			want: []string{"--v=2", "--mode=Standby"},
+		},
+		{
+			name: "mode of the template replaced",
+			mode: string(rapi.SentinelMode),
+			args: []string{"--mode=Cluster", "--v=2", "--mode", "Cluster"},
+			want: []string{"--v=2", "--mode=Sentinel"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &kapiv1.PodSpec{Containers: []kapiv1.Container{
+				{Name: RedisNodeContainerName, Args: tt.args},
+				{Name: "sidecar", Args: []string{"--v=2"}},
+			}}
+			setRedisNodeMode(spec, tt.mode)
+			if !reflect.DeepEqual(spec.Containers[0].Args, tt.want) {
+				t.Errorf("setRedisNodeMode() args = %v, want %v", spec.Containers[0].Args, tt.want)
+			}
+			if !reflect.DeepEqual(spec.Containers[1].Args, []string{"--v=2"}) {
+				t.Errorf("setRedisNodeMode() changed the args of another container: %v", spec.Containers[1].Args)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package pod
 
 import (
 	"fmt"
+	"strconv"
+	"unicode/utf8"
+
 	"k8s.io/apimachinery/pkg/labels"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
@@ -31,10 +34,23 @@ func GetPodLabelsSet(rediscluster *rapi.RedisCluster, currentPods int32) (labels
 	if err!=nil{
 		return desiredLabels,err
 	}
-	desiredLabels[rapi.PodNoLabelKey] = string(currentPods)
+	desiredLabels[rapi.PodNoLabelKey] = strconv.Itoa(int(currentPods))
 	return desiredLabels, nil
 }
 
+// ParsePodNo returns the pod number of a pod-no label. The previous versions encoded the number as a single rune:
+// legacy is true if the label has this format. A legacy label of a digit can't be told apart from the decimal format,
+// it is read as a decimal number.
+func ParsePodNo(value string) (podNo int32, legacy bool, err error) {
+	if n, err := strconv.ParseInt(value, 10, 32); err == nil {
+		return int32(n), false, nil
+	}
+	if r, size := utf8.DecodeRuneInString(value); r != utf8.RuneError && size == len(value) {
+		return int32(r), true, nil
+	}
+	return 0, false, fmt.Errorf("invalid %s label %q", rapi.PodNoLabelKey, value)
+}
+
 // CreateRedisClusterLabelSelector creates label selector to select the jobs related to a rediscluster, stepName
 func CreateRedisClusterLabelSelector(rediscluster *rapi.RedisCluster) (labels.Selector, error) {
 	set, err := GetLabelsSet(rediscluster)
@@ -44,6 +60,30 @@ func CreateRedisClusterLabelSelector(rediscluster *rapi.RedisCluster) (labels.Se
 	return labels.SelectorFromSet(set), nil
 }
 
+// GetSentinelLabelsSet return labels associated to the sentinel pods of a RedisCluster in Sentinel mode.
+// The cluster name label is not set, the sentinel pods are not selected with the redis nodes.
+func GetSentinelLabelsSet(rediscluster *rapi.RedisCluster) (labels.Set, error) {
+	desiredLabels := labels.Set{}
+	if rediscluster == nil {
+		return desiredLabels, fmt.Errorf("redisluster nil pointer")
+	}
+	for k, v := range rediscluster.Spec.AdditionalLabels {
+		desiredLabels[k] = v
+	}
+	delete(desiredLabels, rapi.ClusterNameLabelKey)
+	desiredLabels[rapi.SentinelNameLabelKey] = rediscluster.Name
+	return desiredLabels, nil
+}
+
+// CreateRedisSentinelLabelSelector creates label selector to select the sentinel pods related to a rediscluster
+func CreateRedisSentinelLabelSelector(rediscluster *rapi.RedisCluster) (labels.Selector, error) {
+	set, err := GetSentinelLabelsSet(rediscluster)
+	if err != nil {
+		return nil, err
+	}
+	return labels.SelectorFromSet(set), nil
+}
+
 // GetAnnotationsSet return a labels.Set of annotation from the RedisCluster
 func GetAnnotationsSet(rediscluster *rapi.RedisCluster) (labels.Set, error) {
 	desiredAnnotations := make(labels.Set)
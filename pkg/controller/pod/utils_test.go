@@ -0,0 +1,29 @@
+package pod
+
+import "testing"
+
+func TestParsePodNo(t *testing.T) {
+	tests := []struct {
+		value      string
+		wantPodNo  int32
+		wantLegacy bool
+		wantErr    bool
+	}{
+		{value: "0", wantPodNo: 0},
+		{value: "12", wantPodNo: 12},
+		{value: string(rune(12)), wantPodNo: 12, wantLegacy: true},
+		{value: string(rune(300)), wantPodNo: 300, wantLegacy: true},
+		{value: "", wantErr: true},
+		{value: "ab", wantErr: true},
+	}
+	for _, tt := range tests {
+		podNo, legacy, err := ParsePodNo(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePodNo(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if podNo != tt.wantPodNo || legacy != tt.wantLegacy {
+			t.Errorf("ParsePodNo(%q) = %d, %v, want %d, %v", tt.value, podNo, legacy, tt.wantPodNo, tt.wantLegacy)
+		}
+	}
+}
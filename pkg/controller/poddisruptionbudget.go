@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/errors"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+)
+
+// shardIDLength is the number of characters of the master ID identifying a shard
+const shardIDLength = 10
+
+// getPodDisruptionBudgetPolicy returns the PodDisruptionBudget policy of the cluster, a cluster in Sentinel mode
+// having a single shard is protected by the Cluster policy instead of PerShard
+func getPodDisruptionBudgetPolicy(cluster *rapi.RedisCluster) rapi.RedisClusterPodDisruptionBudgetPolicy {
+	if cluster.Spec.PodDisruptionBudget == nil || cluster.Spec.PodDisruptionBudget.Policy == "" {
+		return rapi.PodDisruptionBudgetPolicyCluster
+	}
+	if cluster.Spec.PodDisruptionBudget.Policy == rapi.PodDisruptionBudgetPolicyPerShard && isSentinelMode(cluster) {
+		return rapi.PodDisruptionBudgetPolicyCluster
+	}
+	return cluster.Spec.PodDisruptionBudget.Policy
+}
+
+// getShardID returns the shard led by the master
+func getShardID(masterID string) string {
+	if len(masterID) > shardIDLength {
+		return masterID[:shardIDLength]
+	}
+	return masterID
+}
+
+// getPodShards returns the shard of each pod hosting a redis node of the status: a master and its slaves form a shard
+// named after the ID of the master. The pods of the unknown nodes, or of the slaves without master, have no shard.
+func getPodShards(cluster *rapi.RedisCluster) map[string]string {
+	shards := map[string]string{}
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.PodName == "" {
+			continue
+		}
+		switch {
+		case node.Role == rapi.RedisClusterNodeRoleMaster:
+			shards[node.PodName] = getShardID(node.ID)
+		case node.Role == rapi.RedisClusterNodeRoleSlave && node.MasterRef != "":
+			shards[node.PodName] = getShardID(node.MasterRef)
+		}
+	}
+	return shards
+}
+
+// syncPodDisruptionBudgets reconciles the PodDisruptionBudgets of the redis pods with the policy of the cluster: the
+// pods are labeled with their shard, the PodDisruptionBudgets are created or updated, recreated if their spec changed,
+// then the ones not needed anymore, like the PodDisruptionBudgets of the former shards, are deleted.
+func (c *Controller) syncPodDisruptionBudgets(cluster *rapi.RedisCluster, pods []*apiv1.Pod) error {
+	policy := getPodDisruptionBudgetPolicy(cluster)
+	shards := map[string]string{}
+	if policy == rapi.PodDisruptionBudgetPolicyPerShard {
+		shards = getPodShards(cluster)
+	}
+
+	var errs []error
+	desired := map[string]string{} // PodDisruptionBudget name to shard, empty for the cluster PodDisruptionBudget
+	for _, pod := range pods {
+		shard := shards[pod.Name]
+		if _, err := c.podControl.SetPodShard(cluster, pod, shard); err != nil {
+			errs = append(errs, fmt.Errorf("unable to set the shard of pod %s: %v", pod.Name, err))
+			continue
+		}
+		if shard != "" {
+			desired[getShardPodDisruptionBudgetName(cluster, shard)] = shard
+		}
+	}
+	if policy == rapi.PodDisruptionBudgetPolicyCluster {
+		desired[cluster.Name] = ""
+	}
+
+	existing, err := c.listRedisClusterPodDisruptionBudgets(cluster)
+	if err != nil {
+		return err
+	}
+	live := map[string]*policyv1.PodDisruptionBudget{}
+	for _, pdb := range existing {
+		live[pdb.Name] = pdb
+	}
+
+	// the new PodDisruptionBudgets are created before the former ones are deleted, the pods are always protected
+	names := []string{}
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want, err := newRedisClusterPodDisruptionBudget(cluster, desired[name])
+		if err != nil {
+			return err
+		}
+		if err := c.applyPodDisruptionBudget(cluster, live[name], want); err != nil {
+			errs = append(errs, fmt.Errorf("unable to apply PodDisruptionBudget %s: %v", name, err))
+		}
+	}
+	for _, pdb := range existing {
+		if _, ok := desired[pdb.Name]; ok {
+			continue
+		}
+		glog.V(3).Infof("cluster %s/%s: delete PodDisruptionBudget %s", cluster.Namespace, cluster.Name, pdb.Name)
+		if err := c.podDisruptionBudgetControl.DeletePodDisruptionBudget(cluster, pdb.Name); err != nil {
+			errs = append(errs, fmt.Errorf("unable to delete PodDisruptionBudget %s: %v", pdb.Name, err))
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// listRedisClusterPodDisruptionBudgets returns the PodDisruptionBudgets of the redis pods controlled by the cluster,
+// the PodDisruptionBudget of the sentinels excluded. They are not selected by label: their labels may have been edited.
+func (c *Controller) listRedisClusterPodDisruptionBudgets(cluster *rapi.RedisCluster) ([]*policyv1.PodDisruptionBudget, error) {
+	pdbList, err := c.podDisruptionBudgetLister.PodDisruptionBudgets(cluster.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list PodDisruptionBudget, err:%v ", err)
+	}
+	pdbs := []*policyv1.PodDisruptionBudget{}
+	for _, pdb := range pdbList {
+		if pdb.Name == getSentinelPodDisruptionBudgetName(cluster) {
+			continue
+		}
+		if controllerRef := metav1.GetControllerOf(pdb); controllerRef == nil || controllerRef.UID != cluster.UID {
+			continue
+		}
+		pdbs = append(pdbs, pdb)
+	}
+	return pdbs, nil
+}
@@ -6,6 +6,7 @@ import (
 	policyv1 "k8s.io/api/policy/v1beta1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	clientset "k8s.io/client-go/kubernetes"
@@ -23,6 +24,14 @@ type PodDisruptionBudgetsControlInterface interface {
 	DeleteRedisClusterPodDisruptionBudget(redisCluster *rapi.RedisCluster) error
 	// GetRedisClusterPodDisruptionBudget used to retrieve the Kubernetes PodDisruptionBudget associated to the RedisCluster
 	GetRedisClusterPodDisruptionBudget(redisCluster *rapi.RedisCluster) (*policyv1.PodDisruptionBudget, error)
+	// CreatePodDisruptionBudget used to create a Kubernetes PodDisruptionBudget of the Redis Cluster
+	CreatePodDisruptionBudget(redisCluster *rapi.RedisCluster, pdb *policyv1.PodDisruptionBudget) (*policyv1.PodDisruptionBudget, error)
+	// UpdatePodDisruptionBudget used to update a Kubernetes PodDisruptionBudget of the Redis Cluster
+	UpdatePodDisruptionBudget(redisCluster *rapi.RedisCluster, pdb *policyv1.PodDisruptionBudget) (*policyv1.PodDisruptionBudget, error)
+	// DeletePodDisruptionBudget used to delete a Kubernetes PodDisruptionBudget of the Redis Cluster from its name
+	DeletePodDisruptionBudget(redisCluster *rapi.RedisCluster, name string) error
+	// CreateRedisSentinelPodDisruptionBudget used to create the Kubernetes PodDisruptionBudget of the sentinels in Sentinel mode
+	CreateRedisSentinelPodDisruptionBudget(redisCluster *rapi.RedisCluster) (*policyv1.PodDisruptionBudget, error)
 }
 
 // PodDisruptionBudgetsControl contains all information for managing Kube PodDisruptionBudgets
@@ -59,34 +68,120 @@ func (s *PodDisruptionBudgetsControl) DeleteRedisClusterPodDisruptionBudget(redi
 	return s.KubeClient.PolicyV1beta1().PodDisruptionBudgets(redisCluster.Namespace).Delete(redisCluster.Name, nil)
 }
 
+// DeletePodDisruptionBudget used to delete a Kubernetes PodDisruptionBudget of the Redis Cluster from its name
+func (s *PodDisruptionBudgetsControl) DeletePodDisruptionBudget(redisCluster *rapi.RedisCluster, name string) error {
+	return s.KubeClient.PolicyV1beta1().PodDisruptionBudgets(redisCluster.Namespace).Delete(name, nil)
+}
+
 // CreateRedisClusterPodDisruptionBudget used to create the Kubernetes PodDisruptionBudget needed to access the Redis Cluster
 func (s *PodDisruptionBudgetsControl) CreateRedisClusterPodDisruptionBudget(redisCluster *rapi.RedisCluster) (*policyv1.PodDisruptionBudget, error) {
-	PodDisruptionBudgetName := redisCluster.Name
-	desiredlabels, err := pod.GetLabelsSet(redisCluster)
+	newPodDisruptionBudget, err := newRedisClusterPodDisruptionBudget(redisCluster, "")
 	if err != nil {
 		return nil, err
+	}
+	return s.KubeClient.PolicyV1beta1().PodDisruptionBudgets(redisCluster.Namespace).Create(newPodDisruptionBudget)
+}
 
+// CreatePodDisruptionBudget used to create a Kubernetes PodDisruptionBudget of the Redis Cluster
+func (s *PodDisruptionBudgetsControl) CreatePodDisruptionBudget(redisCluster *rapi.RedisCluster, pdb *policyv1.PodDisruptionBudget) (*policyv1.PodDisruptionBudget, error) {
+	return s.KubeClient.PolicyV1beta1().PodDisruptionBudgets(redisCluster.Namespace).Create(pdb)
+}
+
+// UpdatePodDisruptionBudget used to update a Kubernetes PodDisruptionBudget of the Redis Cluster
+func (s *PodDisruptionBudgetsControl) UpdatePodDisruptionBudget(redisCluster *rapi.RedisCluster, pdb *policyv1.PodDisruptionBudget) (*policyv1.PodDisruptionBudget, error) {
+	return s.KubeClient.PolicyV1beta1().PodDisruptionBudgets(redisCluster.Namespace).Update(pdb)
+}
+
+// newRedisClusterPodDisruptionBudget builds the PodDisruptionBudget selecting the pods of the shard, or all the
+// redis pods of the cluster if shard is empty
+func newRedisClusterPodDisruptionBudget(redisCluster *rapi.RedisCluster, shard string) (*policyv1.PodDisruptionBudget, error) {
+	clusterLabels, err := pod.GetLabelsSet(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+	desiredlabels := labels.Merge(clusterLabels, nil)
+	name := redisCluster.Name
+	if shard != "" {
+		desiredlabels[rapi.ShardLabelKey] = shard
+		name = getShardPodDisruptionBudgetName(redisCluster, shard)
 	}
 
 	desiredAnnotations, err := pod.GetAnnotationsSet(redisCluster)
 	if err != nil {
 		return nil, err
 	}
-	maxUnavailable := intstr.FromInt(1)
-	labelSelector := metav1.LabelSelector{
-		MatchLabels: desiredlabels,
+	newPodDisruptionBudget := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:          desiredlabels,
+			Annotations:     desiredAnnotations,
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{pod.BuildOwnerReference(redisCluster)},
+		},
+		Spec: newPodDisruptionBudgetSpec(redisCluster.Spec.PodDisruptionBudget, desiredlabels),
+	}
+	recordLastApplied(&newPodDisruptionBudget.ObjectMeta, appliedFields{Labels: desiredlabels, Annotations: desiredAnnotations})
+	return newPodDisruptionBudget, nil
+}
+
+// newPodDisruptionBudgetSpec returns the spec of a PodDisruptionBudget selecting the pods with the labels: MinAvailable
+// if set, otherwise MaxUnavailable defaulted to 1
+func newPodDisruptionBudgetSpec(budget *rapi.RedisClusterPodDisruptionBudget, selector labels.Set) policyv1.PodDisruptionBudgetSpec {
+	spec := policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: selector}}
+	switch {
+	case budget != nil && budget.MinAvailable != nil:
+		minAvailable := *budget.MinAvailable
+		spec.MinAvailable = &minAvailable
+	case budget != nil && budget.MaxUnavailable != nil:
+		maxUnavailable := *budget.MaxUnavailable
+		spec.MaxUnavailable = &maxUnavailable
+	default:
+		maxUnavailable := intstr.FromInt(1)
+		spec.MaxUnavailable = &maxUnavailable
 	}
+	return spec
+}
+
+// CreateRedisSentinelPodDisruptionBudget used to create the Kubernetes PodDisruptionBudget of the sentinels in Sentinel mode
+func (s *PodDisruptionBudgetsControl) CreateRedisSentinelPodDisruptionBudget(redisCluster *rapi.RedisCluster) (*policyv1.PodDisruptionBudget, error) {
+	newPodDisruptionBudget, err := newRedisSentinelPodDisruptionBudget(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+	return s.KubeClient.PolicyV1beta1().PodDisruptionBudgets(redisCluster.Namespace).Create(newPodDisruptionBudget)
+}
+
+// newRedisSentinelPodDisruptionBudget builds the PodDisruptionBudget of the sentinels in Sentinel mode
+func newRedisSentinelPodDisruptionBudget(redisCluster *rapi.RedisCluster) (*policyv1.PodDisruptionBudget, error) {
+	desiredlabels, err := pod.GetSentinelLabelsSet(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredAnnotations, err := pod.GetAnnotationsSet(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+	maxUnavailable := intstr.FromInt(1)
 	newPodDisruptionBudget := &policyv1.PodDisruptionBudget{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:          desiredlabels,
 			Annotations:     desiredAnnotations,
-			Name:            PodDisruptionBudgetName,
+			Name:            getSentinelPodDisruptionBudgetName(redisCluster),
 			OwnerReferences: []metav1.OwnerReference{pod.BuildOwnerReference(redisCluster)},
 		},
 		Spec: policyv1.PodDisruptionBudgetSpec{
 			MaxUnavailable: &maxUnavailable,
-			Selector:       &labelSelector,
+			Selector:       &metav1.LabelSelector{MatchLabels: desiredlabels},
 		},
 	}
-	return s.KubeClient.PolicyV1beta1().PodDisruptionBudgets(redisCluster.Namespace).Create(newPodDisruptionBudget)
+	recordLastApplied(&newPodDisruptionBudget.ObjectMeta, appliedFields{Labels: desiredlabels, Annotations: desiredAnnotations})
+	return newPodDisruptionBudget, nil
+}
+
+func getShardPodDisruptionBudgetName(redisCluster *rapi.RedisCluster, shard string) string {
+	return redisCluster.Name + "-shard-" + shard
+}
+
+func getSentinelPodDisruptionBudgetName(redisCluster *rapi.RedisCluster) string {
+	return redisCluster.Name + "-sentinel"
 }
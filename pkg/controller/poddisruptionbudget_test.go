@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"sort"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	policyv1listers "k8s.io/client-go/listers/policy/v1beta1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
+)
+
+func TestController_syncPodDisruptionBudgets(t *testing.T) {
+	masterA, masterB := "aaaaaaaaaa0123456789", "bbbbbbbbbb0123456789"
+	maxUnavailable2 := intstr.FromInt(2)
+	newCluster := func(budget *rapi.RedisClusterPodDisruptionBudget) *rapi.RedisCluster {
+		cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns", UID: "uid"}}
+		cluster.Spec.PodDisruptionBudget = budget
+		cluster.Status.Cluster.Nodes = []rapi.RedisClusterNode{
+			{ID: masterA, Role: rapi.RedisClusterNodeRoleMaster, PodName: "pod1"},
+			{ID: "c", Role: rapi.RedisClusterNodeRoleSlave, MasterRef: masterA, PodName: "pod2"},
+			{ID: masterB, Role: rapi.RedisClusterNodeRoleMaster, PodName: "pod3"},
+			{ID: "d", Role: rapi.RedisClusterNodeRoleSlave, MasterRef: masterB, PodName: "pod4"},
+		}
+		return cluster
+	}
+	newPDB := func(cluster *rapi.RedisCluster, shard string) *policyv1.PodDisruptionBudget {
+		pdb, _ := newRedisClusterPodDisruptionBudget(cluster, shard)
+		pdb.Namespace = cluster.Namespace
+		return pdb
+	}
+
+	tests := []struct {
+		name      string
+		budget    *rapi.RedisClusterPodDisruptionBudget
+		existing  func(cluster *rapi.RedisCluster) []*policyv1.PodDisruptionBudget
+		wantPDBs  []string
+		wantShard string // shard label expected on pod1
+		check     func(t *testing.T, pdbs map[string]policyv1.PodDisruptionBudget)
+	}{
+		{
+			name:     "default cluster policy",
+			wantPDBs: []string{"foo"},
+			check: func(t *testing.T, pdbs map[string]policyv1.PodDisruptionBudget) {
+				if spec := pdbs["foo"].Spec; spec.MaxUnavailable == nil || spec.MaxUnavailable.IntValue() != 1 {
+					t.Errorf("spec = %+v, want maxUnavailable 1", spec)
+				}
+			},
+		},
+		{
+			name:   "per shard replaces the cluster and the former shards PodDisruptionBudgets",
+			budget: &rapi.RedisClusterPodDisruptionBudget{Policy: rapi.PodDisruptionBudgetPolicyPerShard},
+			existing: func(cluster *rapi.RedisCluster) []*policyv1.PodDisruptionBudget {
+				return []*policyv1.PodDisruptionBudget{newPDB(cluster, ""), newPDB(cluster, "cccccccccc")}
+			},
+			wantPDBs:  []string{"foo-shard-aaaaaaaaaa", "foo-shard-bbbbbbbbbb"},
+			wantShard: "aaaaaaaaaa",
+			check: func(t *testing.T, pdbs map[string]policyv1.PodDisruptionBudget) {
+				if selector := pdbs["foo-shard-aaaaaaaaaa"].Spec.Selector; selector == nil || selector.MatchLabels[rapi.ShardLabelKey] != "aaaaaaaaaa" {
+					t.Errorf("selector = %+v, want the pods of the shard", selector)
+				}
+			},
+		},
+		{
+			name:   "changed budget is recreated",
+			budget: &rapi.RedisClusterPodDisruptionBudget{MaxUnavailable: &maxUnavailable2},
+			existing: func(cluster *rapi.RedisCluster) []*policyv1.PodDisruptionBudget {
+				return []*policyv1.PodDisruptionBudget{newPDB(newCluster(nil), "")}
+			},
+			wantPDBs: []string{"foo"},
+			check: func(t *testing.T, pdbs map[string]policyv1.PodDisruptionBudget) {
+				if spec := pdbs["foo"].Spec; spec.MaxUnavailable == nil || spec.MaxUnavailable.IntValue() != 2 {
+					t.Errorf("spec = %+v, want maxUnavailable 2", spec)
+				}
+			},
+		},
+		{
+			name:   "none",
+			budget: &rapi.RedisClusterPodDisruptionBudget{Policy: rapi.PodDisruptionBudgetPolicyNone},
+			existing: func(cluster *rapi.RedisCluster) []*policyv1.PodDisruptionBudget {
+				return []*policyv1.PodDisruptionBudget{newPDB(cluster, "")}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newCluster(tt.budget)
+			kubeClient := kubefake.NewSimpleClientset()
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if tt.existing != nil {
+				for _, pdb := range tt.existing(cluster) {
+					kubeClient.PolicyV1beta1().PodDisruptionBudgets("ns").Create(pdb)
+					indexer.Add(pdb)
+				}
+			}
+			pods := []*apiv1.Pod{}
+			for _, name := range []string{"pod1", "pod2", "pod3", "pod4"} {
+				p := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: map[string]string{rapi.ClusterNameLabelKey: "foo"}}}
+				kubeClient.CoreV1().Pods("ns").Create(p)
+				pods = append(pods, p)
+			}
+			recorder := record.NewFakeRecorder(10)
+			c := &Controller{
+				podDisruptionBudgetLister:  policyv1listers.NewPodDisruptionBudgetLister(indexer),
+				podControl:                 pod.NewRedisClusterControl(nil, kubeClient, recorder),
+				podDisruptionBudgetControl: NewPodDisruptionBudgetsControl(kubeClient, recorder),
+				recorder:                   recorder,
+			}
+
+			if err := c.syncPodDisruptionBudgets(cluster, pods); err != nil {
+				t.Fatalf("syncPodDisruptionBudgets() error = %v", err)
+			}
+
+			pdbList, _ := kubeClient.PolicyV1beta1().PodDisruptionBudgets("ns").List(metav1.ListOptions{})
+			pdbs := map[string]policyv1.PodDisruptionBudget{}
+			names := []string{}
+			for _, pdb := range pdbList.Items {
+				pdbs[pdb.Name] = pdb
+				names = append(names, pdb.Name)
+			}
+			sort.Strings(names)
+			if !equalStrings(names, tt.wantPDBs) {
+				t.Errorf("PodDisruptionBudgets = %v, want %v", names, tt.wantPDBs)
+			}
+			if p, _ := kubeClient.CoreV1().Pods("ns").Get("pod1", metav1.GetOptions{}); p.Labels[rapi.ShardLabelKey] != tt.wantShard {
+				t.Errorf("shard label of pod1 = %q, want %q", p.Labels[rapi.ShardLabelKey], tt.wantShard)
+			}
+			if tt.check != nil {
+				tt.check(t, pdbs)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
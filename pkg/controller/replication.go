@@ -0,0 +1,392 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/errors"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// replicationMasterEventReason is the reason of the event emitted when a redis node is promoted as the replication master
+	replicationMasterEventReason = "ReplicationMaster"
+	// replicaAttachedEventReason is the reason of the event emitted when a redis node is attached to the master
+	replicaAttachedEventReason = "ReplicaAttached"
+	// sentinelMonitorEventReason is the reason of the event emitted when a sentinel is configured to monitor the master
+	sentinelMonitorEventReason = "SentinelMonitor"
+	// replicationFailedEventReason is the reason of the event emitted when the replication topology can't be configured
+	replicationFailedEventReason = "ReplicationFailed"
+)
+
+// replicationNode is a redis node of a RedisCluster in Sentinel mode with its replication info
+type replicationNode struct {
+	pod  *apiv1.Pod
+	addr string
+	info *redis.ReplicationInfo
+}
+
+func isSentinelMode(cluster *rapi.RedisCluster) bool {
+	return cluster.Spec.Mode == rapi.SentinelMode
+}
+
+// getNumberOfReplicationPods returns the number of redis pods in Sentinel mode: the master and its replicas
+func getNumberOfReplicationPods(cluster *rapi.RedisCluster) int32 {
+	return 1 + *cluster.Spec.ReplicationFactor
+}
+
+func getSentinelReplicas(cluster *rapi.RedisCluster) int32 {
+	if cluster.Spec.Sentinel == nil || cluster.Spec.Sentinel.Replicas == nil {
+		return 3
+	}
+	return *cluster.Spec.Sentinel.Replicas
+}
+
+func getSentinelQuorum(cluster *rapi.RedisCluster) int32 {
+	if cluster.Spec.Sentinel != nil && cluster.Spec.Sentinel.Quorum != nil {
+		return *cluster.Spec.Sentinel.Quorum
+	}
+	return getSentinelReplicas(cluster)/2 + 1
+}
+
+// syncReplication reconciles a RedisCluster in Sentinel mode: one master, ReplicationFactor replicas and the sentinels
+// monitoring the master. Once the sentinels monitor the master, they are the reference for the current master,
+// the operator only attaches the replicas and reconfigures the sentinels that monitor another address.
+func (c *Controller) syncReplication(ctx context.Context, cluster *rapi.RedisCluster, pods []*apiv1.Pod) (bool, error) {
+	if err := c.ensureSentinelResources(cluster); err != nil {
+		return false, err
+	}
+	sentinelPods, err := c.podControl.GetRedisSentinelPods(cluster)
+	if err != nil {
+		glog.Errorf("RedisCluster-Operator.sync unable to retrieves sentinel pods associated to the RedisCluster: %s/%s", cluster.Namespace, cluster.Name)
+		return false, err
+	}
+
+	// create the missing pods one by one, the redis nodes first
+	if int32(len(pods)) < getNumberOfReplicationPods(cluster) {
+		_, err = c.podControl.CreatePod(cluster, int32(len(pods)))
+		return true, err
+	}
+	if int32(len(sentinelPods)) < getSentinelReplicas(cluster) {
+		_, err = c.podControl.CreateSentinelPod(cluster)
+		return true, err
+	}
+
+	// the connections to the redis nodes are kept across the syncs
+	adminKey := cluster.Namespace + "/" + cluster.Name
+	admin := c.adminPool.Get(adminKey, pods).WithContext(ctx)
+	defer c.adminPool.Release(adminKey)
+	nodes := getReplicationNodes(admin, pods)
+
+	sentinelAdminKey := getSentinelAdminKey(adminKey)
+	sentinelAdmin := c.adminPool.GetExternal(sentinelAdminKey, getSentinelAddrs(sentinelPods)).WithContext(ctx)
+	defer c.adminPool.Release(sentinelAdminKey)
+	sentinelMasters := getSentinelMasters(sentinelAdmin, sentinelPods, cluster.Name)
+
+	configured := false
+	var errs []error
+	master := selectReplicationMaster(nodes, electSentinelMaster(sentinelMasters))
+	if master == nil {
+		glog.Infof("cluster %s/%s, waiting for the sentinels to failover the master", cluster.Namespace, cluster.Name)
+	} else {
+		configured, errs = c.configureReplication(sentinelAdmin, admin, cluster, master, nodes, sentinelMasters)
+	}
+
+	if master != nil && int32(len(pods)) > getNumberOfReplicationPods(cluster) {
+		if replica := selectReplicaToDelete(master, pods); replica != nil {
+			glog.Infof("cluster %s/%s, scale down, deleting replica pod %s", cluster.Namespace, cluster.Name, replica.Name)
+			if err = c.podControl.DeletePod(cluster, replica.Name); err != nil {
+				errs = append(errs, err)
+			}
+			configured = true
+		}
+	}
+
+	status := buildReplicationStatus(master, nodes, sentinelMasters, int32(len(sentinelPods)))
+	updated := !reflect.DeepEqual(status, cluster.Status.Replication)
+	cluster.Status.Replication = status
+	cluster.Status.Cluster.NbPods = int32(len(pods))
+	cluster.Status.Cluster.NbRedisRunning = int32(len(nodes))
+	ok := isReplicationHealthy(cluster, status)
+	clusterStatus := rapi.ClusterStatusKO
+	if ok {
+		clusterStatus = rapi.ClusterStatusOK
+	}
+	if cluster.Status.Cluster.Status != clusterStatus {
+		cluster.Status.Cluster.Status = clusterStatus
+		updated = true
+	}
+	if setClusterStatusCondition(&cluster.Status, ok) {
+		updated = true
+	}
+	if updated {
+		if _, err = c.updateHandler(cluster); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return configured || updated, errors.NewAggregate(errs)
+}
+
+// configureReplication promotes the master if needed, attaches the replicas to the master and makes the sentinels
+// monitor it. Returns true if a redis node or a sentinel has been reconfigured.
+func (c *Controller) configureReplication(sentinelAdmin, admin redis.AdminInterface, cluster *rapi.RedisCluster, master *replicationNode, nodes []*replicationNode, sentinelMasters map[string]string) (bool, []error) {
+	configured := false
+	var errs []error
+	masterIP, masterPort, _ := net.SplitHostPort(master.addr)
+
+	if !master.info.IsMaster() {
+		glog.Infof("cluster %s/%s, promoting %s (pod %s) as master", cluster.Namespace, cluster.Name, master.addr, master.pod.Name)
+		if err := admin.SetReplicaOf(master.addr, "", ""); err != nil {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, replicationFailedEventReason, "Unable to promote pod %s as master: %v", master.pod.Name, err)
+			return false, []error{err}
+		}
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, replicationMasterEventReason, "Pod %s (%s) promoted as master", master.pod.Name, master.addr)
+		configured = true
+	}
+
+	for _, node := range nodes {
+		if node == master || node.info.IsReplicaOf(master.addr) {
+			continue
+		}
+		glog.Infof("cluster %s/%s, attaching %s (pod %s) to master %s", cluster.Namespace, cluster.Name, node.addr, node.pod.Name, master.addr)
+		if err := admin.SetReplicaOf(node.addr, masterIP, masterPort); err != nil {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, replicationFailedEventReason, "Unable to attach pod %s to master %s: %v", node.pod.Name, master.pod.Name, err)
+			errs = append(errs, err)
+			continue
+		}
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, replicaAttachedEventReason, "Pod %s attached to master %s (pod %s)", node.pod.Name, master.addr, master.pod.Name)
+		configured = true
+	}
+
+	quorum := getSentinelQuorum(cluster)
+	for addr, monitored := range sentinelMasters {
+		if monitored == master.addr {
+			continue
+		}
+		glog.Infof("cluster %s/%s, sentinel %s monitors %q instead of %s", cluster.Namespace, cluster.Name, addr, monitored, master.addr)
+		if err := sentinelAdmin.SentinelMonitor(addr, cluster.Name, masterIP, masterPort, quorum); err != nil {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, replicationFailedEventReason, "Unable to configure sentinel %s: %v", addr, err)
+			errs = append(errs, err)
+			continue
+		}
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, sentinelMonitorEventReason, "Sentinel %s monitors master %s (pod %s) with quorum %d", addr, master.addr, master.pod.Name, quorum)
+		sentinelMasters[addr] = master.addr
+		configured = true
+	}
+
+	return configured, errs
+}
+
+// ensureSentinelResources creates or updates the sentinels service and PodDisruptionBudget
+func (c *Controller) ensureSentinelResources(cluster *rapi.RedisCluster) error {
+	svc, err := newRedisSentinelService(cluster)
+	if err != nil {
+		return err
+	}
+	liveSvc, err := c.serviceLister.Services(cluster.Namespace).Get(svc.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("couldn't get service %s, err:%v ", svc.Name, err)
+	}
+	if err = c.applyService(cluster, liveSvc, svc); err != nil {
+		glog.Errorf("RedisCluster-Operator.sync unable to apply sentinel service associated to the RedisCluster: %s/%s", cluster.Namespace, cluster.Name)
+		return err
+	}
+
+	pdb, err := newRedisSentinelPodDisruptionBudget(cluster)
+	if err != nil {
+		return err
+	}
+	livePDB, err := c.podDisruptionBudgetLister.PodDisruptionBudgets(cluster.Namespace).Get(pdb.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("couldn't get PodDisruptionBudget %s, err:%v ", pdb.Name, err)
+	}
+	if err = c.applyPodDisruptionBudget(cluster, livePDB, pdb); err != nil {
+		glog.Errorf("RedisCluster-Operator.sync unable to apply sentinel podDisruptionBudget associated to the RedisCluster: %s/%s", cluster.Namespace, cluster.Name)
+		return err
+	}
+	return nil
+}
+
+// getSentinelAdminKey returns the key of the admin of the sentinels of the cluster in the admin pool, the sentinels
+// don't use the renamed commands of the redis nodes
+func getSentinelAdminKey(clusterKey string) string {
+	return clusterKey + "/sentinels"
+}
+
+// getSentinelAddrs returns the addresses of the sentinel pods having an IP
+func getSentinelAddrs(pods []*apiv1.Pod) []string {
+	addrs := []string{}
+	for _, p := range pods {
+		if p.Status.PodIP != "" {
+			addrs = append(addrs, net.JoinHostPort(p.Status.PodIP, redis.DefaultSentinelPort))
+		}
+	}
+	return addrs
+}
+
+// getReplicationNodes returns the redis nodes that answer to the INFO REPLICATION command
+func getReplicationNodes(admin redis.AdminInterface, pods []*apiv1.Pod) []*replicationNode {
+	nodes := []*replicationNode{}
+	for _, p := range pods {
+		if p.Status.PodIP == "" {
+			continue
+		}
+		addr := net.JoinHostPort(p.Status.PodIP, getRedisPort(p))
+		info, err := admin.GetReplicationInfo(addr)
+		if err != nil {
+			glog.V(3).Infof("unable to retrieve the replication info of pod %s: %v", p.Name, err)
+			continue
+		}
+		nodes = append(nodes, &replicationNode{pod: p, addr: addr, info: info})
+	}
+	return nodes
+}
+
+// getSentinelMasters returns for each reachable sentinel the address of the master it monitors, empty if none
+func getSentinelMasters(admin redis.AdminInterface, pods []*apiv1.Pod, name string) map[string]string {
+	masters := map[string]string{}
+	for _, p := range pods {
+		if p.Status.PodIP == "" {
+			continue
+		}
+		addr := net.JoinHostPort(p.Status.PodIP, redis.DefaultSentinelPort)
+		master, err := admin.SentinelGetMasterAddr(addr, name)
+		if err != nil {
+			glog.V(3).Infof("unable to retrieve the master monitored by sentinel %s: %v", p.Name, err)
+			continue
+		}
+		masters[addr] = master
+	}
+	return masters
+}
+
+// electSentinelMaster returns the master address monitored by the largest number of sentinels, empty if none
+func electSentinelMaster(sentinelMasters map[string]string) string {
+	votes := map[string]int{}
+	for _, master := range sentinelMasters {
+		if master != "" {
+			votes[master]++
+		}
+	}
+	elected := ""
+	for master, nb := range votes {
+		if nb > votes[elected] || (nb == votes[elected] && master < elected) {
+			elected = master
+		}
+	}
+	return elected
+}
+
+// selectReplicationMaster returns the node that has to be the master. The master monitored by the sentinels is kept
+// if it is reachable. If it is unreachable but still has replicas, nil is returned: the sentinels are in charge of
+// the failover. Otherwise (bootstrap, or all the nodes have been replaced), the master with the most replicas,
+// then the most up-to-date node, is selected.
+func selectReplicationMaster(nodes []*replicationNode, sentinelMaster string) *replicationNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	if sentinelMaster != "" {
+		for _, node := range nodes {
+			if node.addr == sentinelMaster {
+				return node
+			}
+		}
+		for _, node := range nodes {
+			if node.info.IsReplicaOf(sentinelMaster) {
+				return nil
+			}
+		}
+	}
+
+	candidates := []*replicationNode{}
+	for _, node := range nodes {
+		if node.info.IsMaster() {
+			candidates = append(candidates, node)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = append(candidates, nodes...)
+	}
+	offset := func(n *replicationNode) int64 {
+		if n.info.IsMaster() {
+			return n.info.MasterReplOffset
+		}
+		return n.info.SlaveReplOffset
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.info.ConnectedSlaves != cj.info.ConnectedSlaves {
+			return ci.info.ConnectedSlaves > cj.info.ConnectedSlaves
+		}
+		if offset(ci) != offset(cj) {
+			return offset(ci) > offset(cj)
+		}
+		return ci.pod.Name < cj.pod.Name
+	})
+	return candidates[0]
+}
+
+// selectReplicaToDelete returns the last replica pod by name, never the master
+func selectReplicaToDelete(master *replicationNode, pods []*apiv1.Pod) *apiv1.Pod {
+	var selected *apiv1.Pod
+	for _, p := range pods {
+		if p.Name == master.pod.Name {
+			continue
+		}
+		if selected == nil || p.Name > selected.Name {
+			selected = p
+		}
+	}
+	return selected
+}
+
+// buildReplicationStatus builds the replication status from the current master, its replicas and the sentinels
+func buildReplicationStatus(master *replicationNode, nodes []*replicationNode, sentinelMasters map[string]string, nbSentinels int32) *rapi.RedisReplicationStatus {
+	status := &rapi.RedisReplicationStatus{NbSentinels: nbSentinels}
+	if master == nil {
+		return status
+	}
+	status.MasterPodName = master.pod.Name
+	status.MasterAddr = master.addr
+	for _, node := range nodes {
+		if node == master {
+			continue
+		}
+		status.Replicas = append(status.Replicas, rapi.RedisReplicaStatus{
+			PodName:          node.pod.Name,
+			Addr:             node.addr,
+			MasterLinkStatus: node.info.MasterLinkStatus,
+			Lag:              node.info.Lag(master.info),
+		})
+	}
+	sort.Slice(status.Replicas, func(i, j int) bool { return status.Replicas[i].PodName < status.Replicas[j].PodName })
+	for _, monitored := range sentinelMasters {
+		if monitored == master.addr {
+			status.NbSentinelsReady++
+		}
+	}
+	return status
+}
+
+// isReplicationHealthy returns true if all the replicas are attached to the master and enough sentinels monitor it
+func isReplicationHealthy(cluster *rapi.RedisCluster, status *rapi.RedisReplicationStatus) bool {
+	if status.MasterAddr == "" || int32(len(status.Replicas)) < *cluster.Spec.ReplicationFactor {
+		return false
+	}
+	for _, replica := range status.Replicas {
+		if replica.MasterLinkStatus != redis.RedisMasterLinkStatusUp {
+			return false
+		}
+	}
+	return status.NbSentinelsReady >= getSentinelQuorum(cluster)
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+func newReplicationNode(podName, addr string, info *redis.ReplicationInfo) *replicationNode {
+	return &replicationNode{
+		pod:  &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName}},
+		addr: addr,
+		info: info,
+	}
+}
+
+func Test_selectReplicationMaster(t *testing.T) {
+	master := &redis.ReplicationInfo{Role: "master", ConnectedSlaves: 1, MasterReplOffset: 100}
+	slaveOfA := &redis.ReplicationInfo{Role: "slave", MasterHost: "10.0.0.1", MasterPort: "6379", MasterLinkStatus: "up", SlaveReplOffset: 90}
+	slaveOfDead := &redis.ReplicationInfo{Role: "slave", MasterHost: "10.0.0.9", MasterPort: "6379", SlaveReplOffset: 80}
+	emptyMaster := &redis.ReplicationInfo{Role: "master"}
+
+	tests := []struct {
+		name           string
+		nodes          []*replicationNode
+		sentinelMaster string
+		want           string
+	}{
+		{
+			name: "no nodes",
+			want: "",
+		},
+		{
+			name: "bootstrap, first pod by name",
+			nodes: []*replicationNode{
+				newReplicationNode("pod-b", "10.0.0.2:6379", emptyMaster),
+				newReplicationNode("pod-a", "10.0.0.1:6379", emptyMaster),
+			},
+			want: "pod-a",
+		},
+		{
+			name: "master with replicas kept",
+			nodes: []*replicationNode{
+				newReplicationNode("pod-a", "10.0.0.1:6379", master),
+				newReplicationNode("pod-b", "10.0.0.2:6379", slaveOfA),
+				newReplicationNode("pod-0", "10.0.0.3:6379", emptyMaster),
+			},
+			want: "pod-a",
+		},
+		{
+			name: "sentinel master",
+			nodes: []*replicationNode{
+				newReplicationNode("pod-a", "10.0.0.1:6379", master),
+				newReplicationNode("pod-b", "10.0.0.2:6379", slaveOfA),
+			},
+			sentinelMaster: "10.0.0.2:6379",
+			want:           "pod-b",
+		},
+		{
+			name: "sentinel master unreachable with replicas, wait for the failover",
+			nodes: []*replicationNode{
+				newReplicationNode("pod-b", "10.0.0.2:6379", slaveOfDead),
+				newReplicationNode("pod-c", "10.0.0.3:6379", emptyMaster),
+			},
+			sentinelMaster: "10.0.0.9:6379",
+			want:           "",
+		},
+		{
+			name: "all replicas of a lost master, most up-to-date promoted",
+			nodes: []*replicationNode{
+				newReplicationNode("pod-b", "10.0.0.2:6379", slaveOfDead),
+				newReplicationNode("pod-c", "10.0.0.3:6379", slaveOfA),
+			},
+			want: "pod-c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectReplicationMaster(tt.nodes, tt.sentinelMaster)
+			gotName := ""
+			if got != nil {
+				gotName = got.pod.Name
+			}
+			if gotName != tt.want {
+				t.Errorf("selectReplicationMaster() = %q, want %q", gotName, tt.want)
+			}
+		})
+	}
+}
+
+func Test_electSentinelMaster(t *testing.T) {
+	tests := []struct {
+		name            string
+		sentinelMasters map[string]string
+		want            string
+	}{
+		{
+			name:            "no sentinel",
+			sentinelMasters: map[string]string{},
+			want:            "",
+		},
+		{
+			name:            "not monitored",
+			sentinelMasters: map[string]string{"s1": "", "s2": ""},
+			want:            "",
+		},
+		{
+			name:            "majority",
+			sentinelMasters: map[string]string{"s1": "10.0.0.1:6379", "s2": "10.0.0.2:6379", "s3": "10.0.0.2:6379"},
+			want:            "10.0.0.2:6379",
+		},
+		{
+			name:            "partially configured",
+			sentinelMasters: map[string]string{"s1": "10.0.0.1:6379", "s2": "", "s3": ""},
+			want:            "10.0.0.1:6379",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := electSentinelMaster(tt.sentinelMasters); got != tt.want {
+				t.Errorf("electSentinelMaster() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buildReplicationStatus(t *testing.T) {
+	cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{ReplicationFactor: rapi.NewInt32(2)}}
+	master := newReplicationNode("pod-a", "10.0.0.1:6379", &redis.ReplicationInfo{Role: "master", ConnectedSlaves: 2, MasterReplOffset: 100})
+	nodes := []*replicationNode{
+		newReplicationNode("pod-c", "10.0.0.3:6379", &redis.ReplicationInfo{Role: "slave", MasterHost: "10.0.0.1", MasterPort: "6379", MasterLinkStatus: "up", SlaveReplOffset: 60}),
+		master,
+		newReplicationNode("pod-b", "10.0.0.2:6379", &redis.ReplicationInfo{Role: "slave", MasterHost: "10.0.0.1", MasterPort: "6379", MasterLinkStatus: "up", SlaveReplOffset: 100}),
+	}
+	sentinelMasters := map[string]string{"s1": "10.0.0.1:6379", "s2": "10.0.0.1:6379", "s3": ""}
+
+	status := buildReplicationStatus(master, nodes, sentinelMasters, 3)
+	if status.MasterPodName != "pod-a" || status.MasterAddr != "10.0.0.1:6379" {
+		t.Errorf("buildReplicationStatus() master = %s/%s", status.MasterPodName, status.MasterAddr)
+	}
+	if len(status.Replicas) != 2 || status.Replicas[0].PodName != "pod-b" || status.Replicas[1].Lag != 40 {
+		t.Errorf("buildReplicationStatus() replicas = %v", status.Replicas)
+	}
+	if status.NbSentinels != 3 || status.NbSentinelsReady != 2 {
+		t.Errorf("buildReplicationStatus() sentinels = %d/%d", status.NbSentinelsReady, status.NbSentinels)
+	}
+	if !isReplicationHealthy(cluster, status) {
+		t.Errorf("isReplicationHealthy() should be true")
+	}
+
+	status.Replicas[1].MasterLinkStatus = redis.RedisMasterLinkStatusDown
+	if isReplicationHealthy(cluster, status) {
+		t.Errorf("isReplicationHealthy() should be false with a replication link down")
+	}
+
+	if status := buildReplicationStatus(nil, nodes, sentinelMasters, 3); isReplicationHealthy(cluster, status) {
+		t.Errorf("isReplicationHealthy() should be false without master")
+	}
+}
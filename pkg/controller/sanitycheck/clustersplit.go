@@ -1,42 +1,193 @@
 package sanitycheck
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
 
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
 	"github.com/zh168654/Redis-Operator/pkg/config"
+	"github.com/zh168654/Redis-Operator/pkg/controller/clustering"
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 )
 
-// FixClusterSplit use to detect and fix Cluster split
-func FixClusterSplit(admin redis.AdminInterface, config *config.Redis, infos *redis.ClusterInfos, dryRun bool) (bool, error) {
+const (
+	// defaultSplitBackupTimeout is the default time given to the backup of a node of a losing partition
+	defaultSplitBackupTimeout = 10 * time.Minute
+)
+
+// FixClusterSplit use to detect and fix Cluster split.
+// With the Quarantine policy, an ApprovalRequiredError is returned until the split is approved with the
+// approve-split-resolution annotation, and the nodes of the losing partitions are left untouched.
+func FixClusterSplit(admin redis.AdminInterface, config *config.Redis, recorder record.EventRecorder, rCluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (bool, error) {
 	clusters := buildClustersLists(infos)
 
 	if len(clusters) > 1 {
+		id := splitID(clusters)
+		if getSplitResolutionPolicy(rCluster) == rapi.SplitResolutionQuarantine && rCluster.Annotations[rapi.ApproveSplitResolutionAnnotationKey] != id {
+			quarantineSplit(admin, rCluster, infos, clusters, id)
+			return true, &ApprovalRequiredError{Message: fmt.Sprintf("cluster split %s quarantined, %d nodes of the losing partitions will be flushed once the RedisCluster is annotated with %s=%s", id, len(rCluster.Status.SplitQuarantine.QuarantinedNodes), rapi.ApproveSplitResolutionAnnotationKey, id)}
+		}
 		if dryRun {
 			return true, nil
 		}
-		return true, reassignClusters(admin, config, clusters)
+		return true, reassignClusters(admin, config, recorder, rCluster, infos, clusters, id)
 	}
+	// the split has disappeared, or was not a split
+	rCluster.Status.SplitQuarantine = nil
 	glog.V(3).Info("[SanityChecks] No split cluster detected")
 	return false, nil
 }
 
 type cluster []string
 
-func reassignClusters(admin redis.AdminInterface, config *config.Redis, clusters []cluster) error {
+// partitionScore is used to elect the main partition of a split cluster: the partition owning the most slots,
+// then storing the most keys, then having the most nodes
+type partitionScore struct {
+	slots int
+	keys  int64
+	nodes int
+}
+
+func (s partitionScore) greaterThan(o partitionScore) bool {
+	if s.slots != o.slots {
+		return s.slots > o.slots
+	}
+	if s.keys != o.keys {
+		return s.keys > o.keys
+	}
+	return s.nodes > o.nodes
+}
+
+// NbPartitions returns the number of independant clusters formed by the redis nodes, more than one means a cluster split
+func NbPartitions(infos *redis.ClusterInfos) int {
+	if infos == nil {
+		return 0
+	}
+	return len(buildClustersLists(infos))
+}
+
+// getSplitResolutionPolicy returns the split resolution policy of the cluster, Flush by default
+func getSplitResolutionPolicy(rCluster *rapi.RedisCluster) rapi.RedisClusterSplitResolutionPolicy {
+	if rCluster.Spec.SplitResolution == nil || rCluster.Spec.SplitResolution.Policy == "" {
+		return rapi.SplitResolutionFlush
+	}
+	return rCluster.Spec.SplitResolution.Policy
+}
+
+// getSplitBackupTimeout returns the time given to the backup of a node, 0 if the losing partitions are not backed up
+func getSplitBackupTimeout(rCluster *rapi.RedisCluster) time.Duration {
+	resolution := rCluster.Spec.SplitResolution
+	if resolution == nil || !resolution.Backup {
+		return 0
+	}
+	if resolution.BackupTimeoutSeconds == nil || *resolution.BackupTimeoutSeconds <= 0 {
+		return defaultSplitBackupTimeout
+	}
+	return time.Duration(*resolution.BackupTimeoutSeconds) * time.Second
+}
+
+// splitID identifies a split by its partitions, whatever the order in which they are listed
+func splitID(clusters []cluster) string {
+	partitions := []string{}
+	for _, c := range clusters {
+		addrs := append([]string{}, c...)
+		sort.Strings(addrs)
+		partitions = append(partitions, strings.Join(addrs, ","))
+	}
+	sort.Strings(partitions)
+	sum := sha1.Sum([]byte(strings.Join(partitions, ";")))
+	return hex.EncodeToString(sum[:])[:10]
+}
+
+// quarantineSplit reports the split in the status of the cluster, the main partition is elected once per split so
+// that the partition approved is the one kept
+func quarantineSplit(admin redis.AdminInterface, rCluster *rapi.RedisCluster, infos *redis.ClusterInfos, clusters []cluster, id string) {
+	if q := rCluster.Status.SplitQuarantine; q != nil && q.ID == id {
+		return
+	}
+	mainCluster, badClusters := splitMainCluster(clusters, scorePartitions(admin, infos, clusters))
+	quarantined := []string{}
+	for _, c := range badClusters {
+		quarantined = append(quarantined, c...)
+	}
+	sort.Strings(quarantined)
+	glog.Warningf("[SanityChecks] Cluster split %s quarantined, main partition: %s, waiting for the approval to flush the nodes %s", id, mainCluster, quarantined)
+	rCluster.Status.SplitQuarantine = &rapi.RedisClusterSplitQuarantineStatus{
+		ID:               id,
+		MainPartition:    mainCluster,
+		QuarantinedNodes: quarantined,
+		StartTime:        metav1.Now(),
+	}
+}
+
+// electMainCluster returns the main partition and the losing partitions, the main partition of an approved
+// quarantine is kept
+func electMainCluster(admin redis.AdminInterface, rCluster *rapi.RedisCluster, infos *redis.ClusterInfos, clusters []cluster, id string) (cluster, []cluster) {
+	if q := rCluster.Status.SplitQuarantine; q != nil && q.ID == id && len(q.MainPartition) > 0 {
+		for i, c := range clusters {
+			if findInCluster(q.MainPartition[0], []cluster{c}) {
+				others := append(append([]cluster{}, clusters[:i]...), clusters[i+1:]...)
+				return c, others
+			}
+		}
+	}
+	return splitMainCluster(clusters, scorePartitions(admin, infos, clusters))
+}
+
+// scorePartitions returns the score of each partition: the slots owned by its masters, the keys they store and
+// its number of nodes
+func scorePartitions(admin redis.AdminInterface, infos *redis.ClusterInfos, clusters []cluster) []partitionScore {
+	scores := make([]partitionScore, len(clusters))
+	for i, c := range clusters {
+		scores[i].nodes = len(c)
+		slots := map[redis.Slot]bool{}
+		for _, addr := range c {
+			nodeinfos, ok := infos.Infos[addr]
+			if !ok || nodeinfos == nil || nodeinfos.Node == nil || nodeinfos.Node.GetRole() == rapi.RedisClusterNodeRoleSlave {
+				continue
+			}
+			for _, slot := range nodeinfos.Node.Slots {
+				slots[slot] = true
+			}
+			serverInfo, err := admin.GetServerInfo(addr)
+			if err != nil {
+				glog.Warningf("[SanityChecks] unable to count the keys of node %s, err:%v", addr, err)
+				continue
+			}
+			scores[i].keys += serverInfo.Keys
+		}
+		scores[i].slots = len(slots)
+	}
+	return scores
+}
+
+func reassignClusters(admin redis.AdminInterface, config *config.Redis, recorder record.EventRecorder, rCluster *rapi.RedisCluster, infos *redis.ClusterInfos, clusters []cluster, id string) error {
 	glog.Error("[SanityChecks] Cluster split detected, the Redis manager will recover from the issue, but data may be lost")
 	var errs []error
 	// only one cluster may remain
-	mainCluster, badClusters := splitMainCluster(clusters)
+	mainCluster, badClusters := electMainCluster(admin, rCluster, infos, clusters, id)
 	if len(mainCluster) == 0 {
 		glog.Error("[SanityChecks] Impossible to fix cluster split, cannot elect main cluster")
 		return fmt.Errorf("Impossible to fix cluster split, cannot elect main cluster")
 	}
+	if timeout := getSplitBackupTimeout(rCluster); timeout > 0 {
+		// no node is flushed until all the masters of the losing partitions are backed up
+		if err := backupClusters(admin, recorder, rCluster, infos, badClusters, id, timeout); err != nil {
+			return err
+		}
+	}
+	recorder.Eventf(rCluster, kapiv1.EventTypeWarning, clustering.NodeResetEventReason, "Cluster split detected, %d nodes of %d other partitions will be flushed and reset, main partition: %s", countNodes(badClusters), len(badClusters), strings.Join(mainCluster, ","))
 	glog.Infof("[SanityChecks] Cluster '%s' is elected as main cluster", mainCluster)
 	// reset admin to connect to the correct cluster
 	admin.Connections().ReplaceAll(mainCluster)
@@ -44,16 +195,21 @@ func reassignClusters(admin redis.AdminInterface, config *config.Redis, clusters
 	// reconfigure bad clusters
 	for _, cluster := range badClusters {
 		glog.Warningf("[SanityChecks] All keys stored in redis cluster '%s' will be lost", cluster)
-		clusterAdmin := redis.NewAdmin(cluster,
+		// the nodes of the bad cluster are flushed under the same guard rails and audit log as the main cluster
+		clusterAdmin := redis.GuardAs(admin, redis.NewAdmin(cluster,
 			&redis.AdminOptions{
 				ConnectionTimeout:  time.Duration(config.DialTimeout) * time.Millisecond,
 				RenameCommandsFile: config.GetRenameCommandsFile(),
-			})
+			}).WithContext(admin.Context()))
 		for _, nodeAddr := range cluster {
 			if err := clusterAdmin.FlushAndReset(nodeAddr, redis.ResetHard); err != nil {
 				glog.Errorf("unable to flush the node: %s, err:%v", nodeAddr, err)
+				recorder.Eventf(rCluster, kapiv1.EventTypeWarning, clustering.NodeResetFailedEventReason, "Unable to flush and reset node %s of a split partition: %v", nodeAddr, err)
 				errs = append(errs, err)
+				// a node still member of its partition is not attached to the main cluster
+				continue
 			}
+			recorder.Eventf(rCluster, kapiv1.EventTypeNormal, clustering.NodeResetEventReason, "Node %s of a split partition flushed and reset, its keys are lost", nodeAddr)
 			if err := admin.AttachNodeToCluster(nodeAddr); err != nil {
 				glog.Errorf("unable to attach the node: %s, err:%v", nodeAddr, err)
 				errs = append(errs, err)
@@ -62,20 +218,55 @@ func reassignClusters(admin redis.AdminInterface, config *config.Redis, clusters
 		}
 		clusterAdmin.Close()
 	}
+	if len(errs) == 0 {
+		rCluster.Status.SplitQuarantine = nil
+	}
 
 	return errors.NewAggregate(errs)
 }
 
-func splitMainCluster(clusters []cluster) (cluster, []cluster) {
+// backupClusters dumps the keys of the masters of the clusters with BGSAVE, in a file of their data directory
+func backupClusters(admin redis.AdminInterface, recorder record.EventRecorder, rCluster *rapi.RedisCluster, infos *redis.ClusterInfos, clusters []cluster, id string, timeout time.Duration) error {
+	filename := fmt.Sprintf("split-%s-%s.rdb", id, time.Now().UTC().Format("20060102150405"))
+	var errs []error
+	for _, c := range clusters {
+		for _, addr := range c {
+			if nodeinfos, ok := infos.Infos[addr]; ok && nodeinfos != nil && nodeinfos.Node != nil && nodeinfos.Node.GetRole() == rapi.RedisClusterNodeRoleSlave {
+				// the keys of a slave are also stored by its master
+				continue
+			}
+			if err := admin.BackupNode(addr, filename, timeout); err != nil {
+				glog.Errorf("unable to backup the node: %s, err:%v", addr, err)
+				recorder.Eventf(rCluster, kapiv1.EventTypeWarning, clustering.NodeBackupFailedEventReason, "Unable to backup node %s of a split partition, the partition is not flushed: %v", addr, err)
+				errs = append(errs, err)
+				continue
+			}
+			recorder.Eventf(rCluster, kapiv1.EventTypeNormal, clustering.NodeBackedUpEventReason, "Node %s of a split partition backed up in %s", addr, filename)
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// countNodes returns the number of nodes in the clusters
+func countNodes(clusters []cluster) int {
+	nb := 0
+	for _, c := range clusters {
+		nb += len(c)
+	}
+	return nb
+}
+
+// splitMainCluster returns the partition with the greatest score, or the first one if several partitions have the
+// same score, and the other partitions
+func splitMainCluster(clusters []cluster, scores []partitionScore) (cluster, []cluster) {
 	if len(clusters) == 0 {
 		return cluster{}, []cluster{}
 	}
-	// only the bigger cluster is kept, or the first one if several cluster have the same size
 	maincluster := -1
-	maxSize := 0
+	var maxScore partitionScore
 	for i, c := range clusters {
-		if len(c) > maxSize {
-			maxSize = len(c)
+		if len(c) > 0 && (maincluster == -1 || scores[i].greaterThan(maxScore)) {
+			maxScore = scores[i]
 			maincluster = i
 		}
 	}
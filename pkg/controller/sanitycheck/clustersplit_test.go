@@ -10,11 +10,13 @@ import (
 
 	//kapi "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
 	"github.com/zh168654/Redis-Operator/pkg/config"
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 	"github.com/zh168654/Redis-Operator/pkg/redis/fake"
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake/admin"
 )
 
 func TestFixClusterSplit(t *testing.T) {
@@ -70,7 +72,7 @@ func TestFixClusterSplit(t *testing.T) {
 	}
 
 	// First run, should return an inconsitent error
-	if action, err := FixClusterSplit(admin, cfg, infos, false); err != nil && action {
+	if action, err := FixClusterSplit(admin, cfg, record.NewFakeRecorder(10), &rapi.RedisCluster{}, infos, false); err != nil && action {
 		t.Errorf("FixClusterSplit should not return an error and action==true. action[%v] error[%v]", action, err)
 	}
 }
@@ -163,7 +165,11 @@ func TestSplitMainCluster(t *testing.T) {
 	}
 
 	for i, tc := range testCases {
-		main, other := splitMainCluster(tc.inputClusters)
+		scores := []partitionScore{}
+		for _, c := range tc.inputClusters {
+			scores = append(scores, partitionScore{nodes: len(c)})
+		}
+		main, other := splitMainCluster(tc.inputClusters, scores)
 		if !reflect.DeepEqual(main, tc.keptCluster) {
 			t.Errorf("[Case %d] Unexpected result for main cluster, expected %v, got %v", i, tc.keptCluster, main)
 		}
@@ -173,6 +179,112 @@ func TestSplitMainCluster(t *testing.T) {
 	}
 }
 
+func TestSplitMainClusterByScore(t *testing.T) {
+	testCases := []struct {
+		name          string
+		scores        []partitionScore
+		keptCluster   cluster
+		toFixClusters []cluster
+	}{
+		{
+			name:          "most slots",
+			scores:        []partitionScore{{slots: 100, keys: 1000, nodes: 3}, {slots: 16384, keys: 10, nodes: 1}},
+			keptCluster:   cluster{"ip4"},
+			toFixClusters: []cluster{{"ip1", "ip2", "ip3"}},
+		},
+		{
+			name:          "most keys",
+			scores:        []partitionScore{{slots: 16384, keys: 10, nodes: 3}, {slots: 16384, keys: 1000, nodes: 1}},
+			keptCluster:   cluster{"ip4"},
+			toFixClusters: []cluster{{"ip1", "ip2", "ip3"}},
+		},
+		{
+			name:          "most nodes",
+			scores:        []partitionScore{{slots: 16384, keys: 10, nodes: 3}, {slots: 16384, keys: 10, nodes: 1}},
+			keptCluster:   cluster{"ip1", "ip2", "ip3"},
+			toFixClusters: []cluster{{"ip4"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			main, other := splitMainCluster([]cluster{{"ip1", "ip2", "ip3"}, {"ip4"}}, tc.scores)
+			if !reflect.DeepEqual(main, tc.keptCluster) || !reflect.DeepEqual(other, tc.toFixClusters) {
+				t.Errorf("splitMainCluster() = %v, %v, want %v, %v", main, other, tc.keptCluster, tc.toFixClusters)
+			}
+		})
+	}
+}
+
+func TestSplitID(t *testing.T) {
+	id := splitID([]cluster{{"ip1", "ip2"}, {"ip3"}})
+	if len(id) != 10 {
+		t.Errorf("splitID() = %s, want 10 characters", id)
+	}
+	if other := splitID([]cluster{{"ip3"}, {"ip2", "ip1"}}); other != id {
+		t.Errorf("splitID() = %s, want %s whatever the order of the nodes", other, id)
+	}
+	if other := splitID([]cluster{{"ip1"}, {"ip2", "ip3"}}); other == id {
+		t.Errorf("splitID() should differ for other partitions")
+	}
+}
+
+func TestFixClusterSplit_quarantine(t *testing.T) {
+	// ip1 and ip2 form the biggest partition, but ip3 is the only master owning slots
+	nodes := map[string]*redis.Node{
+		"ip1:1234": {ID: "id1", IP: "ip1", Port: "1234", Role: "master"},
+		"ip2:1234": {ID: "id2", IP: "ip2", Port: "1234", Role: "slave", MasterReferent: "id1"},
+		"ip3:1234": {ID: "id3", IP: "ip3", Port: "1234", Role: "master", Slots: redis.BuildSlotSlice(0, 16383)},
+	}
+	infos := &redis.ClusterInfos{
+		Infos: map[string]*redis.NodeInfos{
+			"ip1:1234": {Node: nodes["ip1:1234"], Friends: redis.Nodes{nodes["ip2:1234"]}},
+			"ip2:1234": {Node: nodes["ip2:1234"], Friends: redis.Nodes{nodes["ip1:1234"]}},
+			"ip3:1234": {Node: nodes["ip3:1234"], Friends: redis.Nodes{}},
+		},
+		Status: redis.ClusterInfosInconsistent,
+	}
+	id := splitID(buildClustersLists(infos))
+	fakeAdmin := admin.NewFakeAdmin([]string{"ip1:1234", "ip2:1234", "ip3:1234"})
+	fakeAdmin.BackupNodeRet["ip1:1234"] = fmt.Errorf("Background save already in progress")
+	rCluster := newCluster(1, 1)
+	rCluster.Spec.SplitResolution = &rapi.RedisClusterSplitResolution{Policy: rapi.SplitResolutionQuarantine, Backup: true}
+
+	for _, dryRun := range []bool{true, false} {
+		actionDone, err := FixClusterSplit(fakeAdmin, &config.Redis{}, record.NewFakeRecorder(10), rCluster, infos, dryRun)
+		if !actionDone || !IsApprovalRequiredError(err) {
+			t.Fatalf("FixClusterSplit(dryRun:%v) = %v, %v, want an approval required", dryRun, actionDone, err)
+		}
+	}
+	want := &rapi.RedisClusterSplitQuarantineStatus{ID: id, MainPartition: []string{"ip3:1234"}, QuarantinedNodes: []string{"ip1:1234", "ip2:1234"}, StartTime: rCluster.Status.SplitQuarantine.StartTime}
+	if !reflect.DeepEqual(rCluster.Status.SplitQuarantine, want) {
+		t.Errorf("FixClusterSplit() quarantine = %v, want %v", rCluster.Status.SplitQuarantine, want)
+	}
+
+	// a stale approval doesn't flush the nodes
+	rCluster.Annotations = map[string]string{rapi.ApproveSplitResolutionAnnotationKey: "0123456789"}
+	if _, err := FixClusterSplit(fakeAdmin, &config.Redis{}, record.NewFakeRecorder(10), rCluster, infos, false); !IsApprovalRequiredError(err) {
+		t.Errorf("FixClusterSplit() error = %v, want an approval required with a stale approval", err)
+	}
+
+	// approved, but the nodes are not flushed since the backup of the master failed
+	rCluster.Annotations[rapi.ApproveSplitResolutionAnnotationKey] = id
+	if actionDone, err := FixClusterSplit(fakeAdmin, &config.Redis{}, record.NewFakeRecorder(10), rCluster, infos, true); !actionDone || err != nil {
+		t.Errorf("FixClusterSplit(dryRun:true) = %v, %v, want a problem found once approved", actionDone, err)
+	}
+	if _, err := FixClusterSplit(fakeAdmin, &config.Redis{}, record.NewFakeRecorder(10), rCluster, infos, false); err == nil || err.Error() != "Background save already in progress" {
+		t.Errorf("FixClusterSplit() error = %v, want the backup error", err)
+	}
+	if rCluster.Status.SplitQuarantine == nil {
+		t.Errorf("FixClusterSplit() should keep the quarantine until the nodes are flushed")
+	}
+
+	// the split has disappeared
+	delete(infos.Infos, "ip3:1234")
+	if actionDone, err := FixClusterSplit(fakeAdmin, &config.Redis{}, record.NewFakeRecorder(10), rCluster, infos, true); actionDone || err != nil || rCluster.Status.SplitQuarantine != nil {
+		t.Errorf("FixClusterSplit() = %v, %v, quarantine %v, want no split", actionDone, err, rCluster.Status.SplitQuarantine)
+	}
+}
+
 // newCluster generate a new Cluster struct
 func newCluster(replicaFactor int32, nbMaster int32) *rapi.RedisCluster {
 	return &rapi.RedisCluster{
@@ -3,14 +3,17 @@ package sanitycheck
 import (
 	"github.com/golang/glog"
 
+	kapiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/clustering"
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 )
 
 // FixFailedNodes fix failed nodes: in some cases (cluster without enough master after crash or scale down), some nodes may still know about fail nodes
-func FixFailedNodes(admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (bool, error) {
+func FixFailedNodes(admin redis.AdminInterface, recorder record.EventRecorder, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (bool, error) {
 	forgetSet := listGhostNodes(cluster, infos)
 	var errs []error
 	doneAnAction := false
@@ -19,8 +22,11 @@ func FixFailedNodes(admin redis.AdminInterface, cluster *rapi.RedisCluster, info
 		glog.Infof("Sanitychecks: Forgetting failed node %s, this command might fail, this is not an error", id)
 		if !dryRun {
 			if err := admin.ForgetNode(id); err != nil {
+				recorder.Eventf(cluster, kapiv1.EventTypeWarning, clustering.ForgetNodeFailedEventReason, "Unable to forget the failed node %s: %v", id, err)
 				errs = append(errs, err)
+				continue
 			}
+			recorder.Eventf(cluster, kapiv1.EventTypeNormal, clustering.NodeForgottenEventReason, "Failed node %s forgotten, it is not hosted by a pod anymore", id)
 		}
 	}
 
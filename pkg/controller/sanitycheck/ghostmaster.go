@@ -3,25 +3,34 @@ package sanitycheck
 import (
 	"github.com/golang/glog"
 
+	kapiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/clustering"
 	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 )
 
 // FixGhostMasterNodes used to removed gost redis nodes
-func FixGhostMasterNodes(admin redis.AdminInterface, podControl pod.RedisClusterControlInteface, cluster *rapi.RedisCluster, info *redis.ClusterInfos) (bool, error) {
+func FixGhostMasterNodes(admin redis.AdminInterface, podControl pod.RedisClusterControlInteface, recorder record.EventRecorder, cluster *rapi.RedisCluster, info *redis.ClusterInfos, dryRun bool) (bool, error) {
 	ghosts := listGhostMasterNodes(podControl, cluster, info)
 	var errs []error
 	doneAnAction := false
 	for _, nodeID := range ghosts {
 		doneAnAction = true
+		if dryRun {
+			continue
+		}
 		glog.Infof("forget ghost master nodes with no slot, id:%s", nodeID)
 
 		if err := admin.ForgetNode(nodeID); err != nil {
+			recorder.Eventf(cluster, kapiv1.EventTypeWarning, clustering.ForgetNodeFailedEventReason, "Unable to forget the ghost master %s: %v", nodeID, err)
 			errs = append(errs, err)
+			continue
 		}
+		recorder.Eventf(cluster, kapiv1.EventTypeNormal, clustering.NodeForgottenEventReason, "Ghost master %s without slot forgotten, its pod doesn't exist anymore", nodeID)
 	}
 
 	return doneAnAction, errors.NewAggregate(errs)
@@ -5,6 +5,7 @@ import (
 
 	kapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
 	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
@@ -85,7 +86,7 @@ func TestFixGhostMasterNodes(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			admin := tt.args.adminFunc()
-			got, err := FixGhostMasterNodes(admin, tt.args.podControl, tt.args.cluster, tt.args.info)
+			got, err := FixGhostMasterNodes(admin, tt.args.podControl, record.NewFakeRecorder(10), tt.args.cluster, tt.args.info, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FixGhostMasterNodes() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1,9 +1,7 @@
 package sanitycheck
 
 import (
-	"time"
-
-	"github.com/golang/glog"
+	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
 	"github.com/zh168654/Redis-Operator/pkg/config"
@@ -11,40 +9,38 @@ import (
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 )
 
-// RunSanityChecks function used to run all the sanity check on the current cluster
+// RunSanityChecks function used to run all the sanity check on the current cluster, the checks of the DefaultRegistry
+// are configured by the operator defaults and the RedisCluster spec, their results are reported in the status.
 // Return actionDone = true if a modification has been made on the cluster
-func RunSanityChecks(admin redis.AdminInterface, config *config.Redis, podControl pod.RedisClusterControlInteface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (actionDone bool, err error) {
-	// * fix failed nodes: in some cases (cluster without enough master after crash or scale down), some nodes may still know about fail nodes
-	if actionDone, err = FixFailedNodes(admin, cluster, infos, dryRun); err != nil {
-		return actionDone, err
-	} else if actionDone {
-		glog.V(2).Infof("FixFailedNodes done an action on the cluster (dryRun:%v)", dryRun)
-		return actionDone, nil
-	}
-
-	// forget nodes and delete pods when a redis node is untrusted.
-	if actionDone, err = FixUntrustedNodes(admin, podControl, cluster, infos, dryRun); err != nil {
-		return actionDone, err
-	} else if actionDone {
-		glog.V(2).Infof("FixUntrustedNodes done an action on the cluster (dryRun:%v)", dryRun)
-		return actionDone, nil
+func RunSanityChecks(admin redis.AdminInterface, config *config.Redis, checks *config.SanityChecks, podControl pod.RedisClusterControlInteface, recorder record.EventRecorder, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (actionDone bool, err error) {
+	ctx := &CheckContext{
+		Admin:      admin,
+		Config:     config,
+		PodControl: podControl,
+		Recorder:   recorder,
+		Cluster:    cluster,
+		Infos:      infos,
 	}
+	return DefaultRegistry.Run(ctx, DefaultRegistry.Settings(checks, cluster), dryRun)
+}
 
-	// forget nodes and delete pods when a redis node is untrusted.
-	if actionDone, err = FixTerminatingPods(cluster, podControl, 5*time.Minute, dryRun); err != nil {
-		return actionDone, err
-	} else if actionDone {
-		glog.V(2).Infof("FixTerminatingPods done an action on the cluster (dryRun:%v)", dryRun)
-		return actionDone, nil
+// RunPartialInfosSanityChecks runs the sanity checks able to work with the partial cluster infos returned when some
+// redis nodes don't answer, like the nodes of deleted pods: only the lost quorum check, whose slaves promotion makes
+// the cluster answer again. The check is configured like in RunSanityChecks.
+func RunPartialInfosSanityChecks(admin redis.AdminInterface, config *config.Redis, checks *config.SanityChecks, podControl pod.RedisClusterControlInteface, recorder record.EventRecorder, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (actionDone bool, err error) {
+	ctx := &CheckContext{
+		Admin:      admin,
+		Config:     config,
+		PodControl: podControl,
+		Recorder:   recorder,
+		Cluster:    cluster,
+		Infos:      infos,
 	}
-
-	// forget nodes and delete pods when a redis node is untrusted.
-	if actionDone, err = FixClusterSplit(admin, config, infos, dryRun); err != nil {
-		return actionDone, err
-	} else if actionDone {
-		glog.V(2).Infof("FixClusterSplit done an action on the cluster (dryRun:%v)", dryRun)
-		return actionDone, nil
+	settings := []CheckSettings{}
+	for _, s := range DefaultRegistry.Settings(checks, cluster) {
+		if s.Name == rapi.SanityCheckLostQuorum {
+			settings = append(settings, s)
+		}
 	}
-
-	return actionDone, err
+	return DefaultRegistry.Run(ctx, settings, dryRun)
 }
@@ -0,0 +1,269 @@
+package sanitycheck
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// QuorumLostEventReason is the reason of the event emitted when the majority of the masters is lost
+	QuorumLostEventReason = "QuorumLost"
+	// QuorumRecoveryDisabledEventReason is the reason of the event emitted when the quorum is lost but the recovery is not allowed by the spec
+	QuorumRecoveryDisabledEventReason = "QuorumRecoveryDisabled"
+	// ShardTakeoverEventReason is the reason of the event emitted when a slave of a lost master has been promoted
+	ShardTakeoverEventReason = "ShardTakeover"
+	// ShardTakeoverFailedEventReason is the reason of the event emitted when no slave of a lost master can be promoted
+	ShardTakeoverFailedEventReason = "ShardTakeoverFailed"
+	// ConfigEpochBumpedEventReason is the reason of the event emitted when the config epoch of a master is bumped to
+	// resolve a collision after the takeovers
+	ConfigEpochBumpedEventReason = "ConfigEpochBumped"
+	// ShardTakeoverNotAgreedEventReason is the reason of the event emitted when the reachable nodes don't agree on the
+	// owner of the slots of a lost master after its takeover
+	ShardTakeoverNotAgreedEventReason = "ShardTakeoverNotAgreed"
+)
+
+var (
+	// quorumAgreementTimeout is the time given to the reachable nodes to agree on the new slots owners after the takeovers
+	quorumAgreementTimeout = 10 * time.Second
+	// quorumAgreementInterval is the delay between two checks of the agreement
+	quorumAgreementInterval = 500 * time.Millisecond
+)
+
+// FixLostQuorum detects when the majority of the masters with slots is lost, in this case redis can't elect new masters.
+// If the RedisCluster spec allows it, the best slave of each lost master is promoted with a CLUSTER FAILOVER TAKEOVER:
+// the promoted slave bumps its config epoch without the agreement of the other masters. The epoch agreement is then
+// rebuilt: the masters whose config epoch collides with another master bump it again, and the check waits for the
+// reachable nodes to agree on the new slots owners before reporting the takeovers.
+// Otherwise a ProblemNotFixedError reports the lost quorum.
+func FixLostQuorum(admin redis.AdminInterface, recorder record.EventRecorder, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, pods []*kapiv1.Pod, dryRun bool) (bool, error) {
+	lostMasters, nbMasters := listLostMasters(infos, pods)
+	if !isQuorumLost(len(lostMasters), nbMasters) {
+		glog.V(3).Info("[SanityChecks] Masters quorum is reached")
+		return false, nil
+	}
+
+	if !cluster.Spec.QuorumLossRecovery {
+		glog.Warningf("[SanityChecks] %d/%d masters lost, quorum lost, recovery disabled", len(lostMasters), nbMasters)
+		return false, &ProblemNotFixedError{
+			Reason:  QuorumRecoveryDisabledEventReason,
+			Message: fmt.Sprintf("%d/%d masters lost (%s), set spec.quorumLossRecovery to promote their slaves", len(lostMasters), nbMasters, nodeIDs(lostMasters)),
+		}
+	}
+	if dryRun {
+		return true, nil
+	}
+
+	glog.Errorf("[SanityChecks] %d/%d masters lost, quorum lost, the slaves of the lost masters will be promoted with a takeover", len(lostMasters), nbMasters)
+	recorder.Eventf(cluster, kapiv1.EventTypeWarning, QuorumLostEventReason, "%d/%d masters lost (%s), starting the quorum recovery", len(lostMasters), nbMasters, nodeIDs(lostMasters))
+
+	var errs []error
+	tookOver := redis.Nodes{}
+	options := &redis.FailoverOptions{Modes: []string{redis.FailoverTakeover}}
+	for _, master := range lostMasters {
+		if err := admin.StartFailover(master.IPPort(), options); err != nil {
+			glog.Errorf("[SanityChecks] unable to promote a slave of the lost master %s: %v", master.ID, err)
+			recorder.Eventf(cluster, kapiv1.EventTypeWarning, ShardTakeoverFailedEventReason, "Unable to promote a slave of the lost master %s (slots %v): %v", master.ID, redis.SlotRangesFromSlots(master.Slots), err)
+			errs = append(errs, err)
+			continue
+		}
+		tookOver = append(tookOver, master)
+	}
+	if len(tookOver) == 0 {
+		return true, errors.NewAggregate(errs)
+	}
+
+	owners, err := waitSlotsOwnersAgreement(admin, recorder, cluster, tookOver)
+	for _, master := range tookOver {
+		slots := redis.SlotRangesFromSlots(master.Slots)
+		if owner, ok := owners[master.ID]; ok {
+			recorder.Eventf(cluster, kapiv1.EventTypeNormal, ShardTakeoverEventReason, "A slave of the lost master %s took over the slots %v, the reachable nodes agree on the new owner %s", master.ID, slots, owner)
+			continue
+		}
+		glog.Errorf("[SanityChecks] the reachable nodes don't agree on the owner of the slots of the lost master %s: %v", master.ID, err)
+		recorder.Eventf(cluster, kapiv1.EventTypeWarning, ShardTakeoverNotAgreedEventReason, "A slave of the lost master %s took over the slots %v, but the reachable nodes don't agree on the new owner: %v", master.ID, slots, err)
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return true, errors.NewAggregate(errs)
+}
+
+// waitSlotsOwnersAgreement rebuilds the config epoch agreement after the takeovers and waits for the reachable nodes
+// to agree on the new owner of the slots of each lost master. Returns the new owner by lost master ID for the
+// agreed ones, and an error if some are not agreed before the timeout.
+func waitSlotsOwnersAgreement(admin redis.AdminInterface, recorder record.EventRecorder, cluster *rapi.RedisCluster, lostMasters redis.Nodes) (map[string]string, error) {
+	deadline := time.Now().Add(quorumAgreementTimeout)
+	bumped := map[string]bool{}
+	for {
+		infos, err := admin.GetClusterInfos()
+		if infos == nil {
+			return map[string]string{}, fmt.Errorf("unable to retrieve the cluster infos: %v", err)
+		}
+		for _, node := range listConfigEpochCollisions(infos) {
+			if bumped[node.ID] {
+				continue
+			}
+			if err = admin.BumpConfigEpoch(node.IPPort()); err != nil {
+				glog.Errorf("[SanityChecks] unable to bump the config epoch of master %s: %v", node.ID, err)
+				continue
+			}
+			bumped[node.ID] = true
+			recorder.Eventf(cluster, kapiv1.EventTypeNormal, ConfigEpochBumpedEventReason, "Config epoch %d of master %s collides with another master, bumped", node.ConfigEpoch, node.ID)
+		}
+		owners, disagreements := getAgreedSlotsOwners(infos, lostMasters)
+		if len(disagreements) == 0 {
+			return owners, nil
+		}
+		if !time.Now().Before(deadline) {
+			return owners, fmt.Errorf("no agreement after %v: %s", quorumAgreementTimeout, strings.Join(disagreements, ", "))
+		}
+		select {
+		case <-time.After(quorumAgreementInterval):
+		case <-admin.Context().Done():
+			return owners, admin.Context().Err()
+		}
+	}
+}
+
+// listConfigEpochCollisions returns the reachable masters with slots whose config epoch, in their own view, is the
+// same as the one of another master. As redis does, the master with the greatest ID keeps its config epoch.
+func listConfigEpochCollisions(infos *redis.ClusterInfos) redis.Nodes {
+	byEpoch := map[int64]redis.Nodes{}
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil || nodeinfos.Node == nil || !redis.IsMasterWithSlot(nodeinfos.Node) {
+			continue
+		}
+		byEpoch[nodeinfos.Node.ConfigEpoch] = append(byEpoch[nodeinfos.Node.ConfigEpoch], nodeinfos.Node)
+	}
+	collisions := redis.Nodes{}
+	for _, masters := range byEpoch {
+		if len(masters) < 2 {
+			continue
+		}
+		masters = masters.SortNodes()
+		collisions = append(collisions, masters[:len(masters)-1]...)
+	}
+	return collisions.SortNodes()
+}
+
+// getAgreedSlotsOwners returns, by lost master ID, the node that all the reachable nodes see as the owner of all the
+// slots of the lost master, and the description of the lost masters without agreement
+func getAgreedSlotsOwners(infos *redis.ClusterInfos, lostMasters redis.Nodes) (map[string]string, []string) {
+	owners := map[string]string{}
+	disagreements := []string{}
+	for _, lost := range lostMasters {
+		owner := ""
+		agreed := true
+		for addr, nodeinfos := range infos.Infos {
+			if nodeinfos == nil || nodeinfos.Node == nil {
+				continue
+			}
+			for _, slot := range lost.Slots {
+				viewOwner := getSlotOwner(nodeinfos, slot)
+				if owner == "" {
+					owner = viewOwner
+				}
+				if viewOwner == "" || viewOwner == lost.ID || viewOwner != owner {
+					glog.V(3).Infof("[SanityChecks] node %s sees %q as the owner of slot %d of the lost master %s", addr, viewOwner, slot, lost.ID)
+					agreed = false
+					break
+				}
+			}
+			if !agreed {
+				break
+			}
+		}
+		if agreed && owner != "" {
+			owners[lost.ID] = owner
+		} else {
+			disagreements = append(disagreements, fmt.Sprintf("slots %v of the lost master %s", redis.SlotRangesFromSlots(lost.Slots), lost.ID))
+		}
+	}
+	return owners, disagreements
+}
+
+// getSlotOwner returns the ID of the master owning the slot in the view of the node, empty if the slot is not assigned
+func getSlotOwner(nodeinfos *redis.NodeInfos, slot redis.Slot) string {
+	for _, node := range append(redis.Nodes{nodeinfos.Node}, nodeinfos.Friends...) {
+		if redis.IsMasterWithSlot(node) && redis.Contains(node.Slots, slot) {
+			return node.ID
+		}
+	}
+	return ""
+}
+
+// listLostMasters returns the masters with slots not reachable by the operator, and either flagged FAIL by the reachable
+// nodes or whose pod is not running anymore, and the total number of masters with slots. A master only flagged PFAIL
+// with a running pod is not lost: without the majority of the masters, its PFAIL flag never becomes FAIL.
+func listLostMasters(infos *redis.ClusterInfos, pods []*kapiv1.Pod) (redis.Nodes, int) {
+	if infos == nil || infos.Infos == nil {
+		return redis.Nodes{}, 0
+	}
+	runningIPs := map[string]bool{}
+	for _, pod := range pods {
+		if pod.Status.PodIP != "" && pod.Status.Phase == kapiv1.PodRunning && pod.DeletionTimestamp == nil {
+			runningIPs[pod.Status.PodIP] = true
+		}
+	}
+	reachable := map[string]bool{}
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos != nil && nodeinfos.Node != nil {
+			reachable[nodeinfos.Node.ID] = true
+		}
+	}
+
+	masters := map[string]*redis.Node{}
+	lost := map[string]*redis.Node{}
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil || nodeinfos.Node == nil {
+			continue
+		}
+		for _, node := range append(redis.Nodes{nodeinfos.Node}, nodeinfos.Friends...) {
+			if !redis.IsMasterWithSlot(node) {
+				continue
+			}
+			if _, ok := masters[node.ID]; !ok {
+				masters[node.ID] = node
+			}
+			if reachable[node.ID] {
+				continue
+			}
+			if node.HasStatus(redis.NodeStatusFail) || (node.HasStatus(redis.NodeStatusPFail) && !runningIPs[node.IP]) {
+				lost[node.ID] = node
+			}
+		}
+	}
+
+	lostMasters := redis.Nodes{}
+	for _, node := range lost {
+		lostMasters = append(lostMasters, node)
+	}
+	return lostMasters.SortNodes(), len(masters)
+}
+
+// isQuorumLost returns true if the remaining masters are not a majority, redis can't failover the lost masters
+func isQuorumLost(nbLostMasters, nbMasters int) bool {
+	if nbLostMasters == 0 {
+		return false
+	}
+	return 2*(nbMasters-nbLostMasters) <= nbMasters
+}
+
+func nodeIDs(nodes redis.Nodes) string {
+	ids := []string{}
+	for _, node := range nodes {
+		ids = append(ids, node.ID)
+	}
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,185 @@
+package sanitycheck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake/admin"
+)
+
+func Test_listLostMasters(t *testing.T) {
+	master1 := &redis.Node{ID: "master1", Role: "master", IP: "10.0.0.1", Port: "6379", Slots: []redis.Slot{1}}
+	master2 := &redis.Node{ID: "master2", Role: "master", IP: "10.0.0.2", Port: "6379", Slots: []redis.Slot{2}}
+	master3 := &redis.Node{ID: "master3", Role: "master", IP: "10.0.0.3", Port: "6379", Slots: []redis.Slot{3}}
+	lostMaster2 := &redis.Node{ID: "master2", Role: "master", IP: "10.0.0.2", Port: "6379", Slots: []redis.Slot{2}, FailStatus: []string{redis.NodeStatusPFail}}
+	lostMaster3 := &redis.Node{ID: "master3", Role: "master", IP: "10.0.0.3", Port: "6379", Slots: []redis.Slot{3}, FailStatus: []string{redis.NodeStatusFail}}
+	slave1 := &redis.Node{ID: "slave1", Role: "slave", IP: "10.0.0.4", Port: "6379", MasterReferent: "master1"}
+
+	tests := []struct {
+		name          string
+		infos         *redis.ClusterInfos
+		pods          []*kapiv1.Pod
+		wantLost      redis.Nodes
+		wantNbMasters int
+	}{
+		{
+			name:          "nil infos",
+			infos:         nil,
+			wantLost:      redis.Nodes{},
+			wantNbMasters: 0,
+		},
+		{
+			name: "all masters reachable",
+			infos: &redis.ClusterInfos{
+				Infos: map[string]*redis.NodeInfos{
+					"10.0.0.1:6379": {Node: master1, Friends: redis.Nodes{master2, master3, slave1}},
+					"10.0.0.2:6379": {Node: master2, Friends: redis.Nodes{master1, master3, slave1}},
+					"10.0.0.3:6379": {Node: master3, Friends: redis.Nodes{master1, master2, slave1}},
+				},
+			},
+			wantLost:      redis.Nodes{},
+			wantNbMasters: 3,
+		},
+		{
+			name: "two masters lost",
+			infos: &redis.ClusterInfos{
+				Infos: map[string]*redis.NodeInfos{
+					"10.0.0.1:6379": {Node: master1, Friends: redis.Nodes{lostMaster2, lostMaster3, slave1}},
+					"10.0.0.4:6379": {Node: slave1, Friends: redis.Nodes{master1, lostMaster2, master3}},
+				},
+			},
+			wantLost:      redis.Nodes{lostMaster2, lostMaster3},
+			wantNbMasters: 3,
+		},
+		{
+			name: "master flagged PFAIL with a running pod",
+			infos: &redis.ClusterInfos{
+				Infos: map[string]*redis.NodeInfos{
+					"10.0.0.1:6379": {Node: master1, Friends: redis.Nodes{lostMaster2, lostMaster3, slave1}},
+					"10.0.0.4:6379": {Node: slave1, Friends: redis.Nodes{master1, lostMaster2, master3}},
+				},
+			},
+			pods:          []*kapiv1.Pod{{Status: kapiv1.PodStatus{PodIP: "10.0.0.2", Phase: kapiv1.PodRunning}}},
+			wantLost:      redis.Nodes{lostMaster3},
+			wantNbMasters: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLost, gotNbMasters := listLostMasters(tt.infos, tt.pods)
+			if !reflect.DeepEqual(gotLost, tt.wantLost) {
+				t.Errorf("listLostMasters() gotLost = %v, want %v", gotLost, tt.wantLost)
+			}
+			if gotNbMasters != tt.wantNbMasters {
+				t.Errorf("listLostMasters() gotNbMasters = %v, want %v", gotNbMasters, tt.wantNbMasters)
+			}
+		})
+	}
+}
+
+func Test_isQuorumLost(t *testing.T) {
+	tests := []struct {
+		name          string
+		nbLostMasters int
+		nbMasters     int
+		want          bool
+	}{
+		{name: "no master lost", nbLostMasters: 0, nbMasters: 3, want: false},
+		{name: "minority lost", nbLostMasters: 1, nbMasters: 3, want: false},
+		{name: "majority lost", nbLostMasters: 2, nbMasters: 3, want: true},
+		{name: "half lost", nbLostMasters: 2, nbMasters: 4, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isQuorumLost(tt.nbLostMasters, tt.nbMasters); got != tt.want {
+				t.Errorf("isQuorumLost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFixLostQuorum(t *testing.T) {
+	defer func(timeout time.Duration) { quorumAgreementTimeout = timeout }(quorumAgreementTimeout)
+	quorumAgreementTimeout = 0
+
+	master1 := &redis.Node{ID: "master1", Role: "master", IP: "10.0.0.1", Port: "6379", Slots: []redis.Slot{1}, ConfigEpoch: 1}
+	lostMaster2 := &redis.Node{ID: "master2", Role: "master", IP: "10.0.0.2", Port: "6379", Slots: []redis.Slot{2}, ConfigEpoch: 2, FailStatus: []string{redis.NodeStatusFail}}
+	lostMaster3 := &redis.Node{ID: "master3", Role: "master", IP: "10.0.0.3", Port: "6379", Slots: []redis.Slot{3}, ConfigEpoch: 3, FailStatus: []string{redis.NodeStatusFail}}
+	slave4 := &redis.Node{ID: "slave4", Role: "slave", IP: "10.0.0.4", Port: "6379", MasterReferent: "master2"}
+	slave5 := &redis.Node{ID: "slave5", Role: "slave", IP: "10.0.0.5", Port: "6379", MasterReferent: "master3"}
+	infos := &redis.ClusterInfos{
+		Infos: map[string]*redis.NodeInfos{
+			"10.0.0.1:6379": {Node: master1, Friends: redis.Nodes{lostMaster2, lostMaster3, slave4, slave5}},
+			"10.0.0.4:6379": {Node: slave4, Friends: redis.Nodes{master1, lostMaster2, lostMaster3, slave5}},
+			"10.0.0.5:6379": {Node: slave5, Friends: redis.Nodes{master1, lostMaster2, lostMaster3, slave4}},
+		},
+	}
+	// the takeovers give the same config epoch to the promoted slaves
+	promoted4 := &redis.Node{ID: "slave4", Role: "master", IP: "10.0.0.4", Port: "6379", Slots: []redis.Slot{2}, ConfigEpoch: 4}
+	promoted5 := &redis.Node{ID: "slave5", Role: "master", IP: "10.0.0.5", Port: "6379", Slots: []redis.Slot{3}, ConfigEpoch: 4}
+	oldMaster2 := &redis.Node{ID: "master2", Role: "master", IP: "10.0.0.2", Port: "6379", FailStatus: []string{redis.NodeStatusFail}}
+	oldMaster3 := &redis.Node{ID: "master3", Role: "master", IP: "10.0.0.3", Port: "6379", FailStatus: []string{redis.NodeStatusFail}}
+	agreed := &redis.ClusterInfos{
+		Infos: map[string]*redis.NodeInfos{
+			"10.0.0.1:6379": {Node: master1, Friends: redis.Nodes{oldMaster2, oldMaster3, promoted4, promoted5}},
+			"10.0.0.4:6379": {Node: promoted4, Friends: redis.Nodes{master1, oldMaster2, oldMaster3, promoted5}},
+			"10.0.0.5:6379": {Node: promoted5, Friends: redis.Nodes{master1, oldMaster2, oldMaster3, promoted4}},
+		},
+	}
+	notAgreed := &redis.ClusterInfos{
+		Infos: map[string]*redis.NodeInfos{
+			"10.0.0.1:6379": {Node: master1, Friends: redis.Nodes{oldMaster2, lostMaster3, promoted4, slave5}},
+			"10.0.0.4:6379": {Node: promoted4, Friends: redis.Nodes{master1, oldMaster2, oldMaster3, promoted5}},
+			"10.0.0.5:6379": {Node: promoted5, Friends: redis.Nodes{master1, oldMaster2, oldMaster3, promoted4}},
+		},
+	}
+	cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{QuorumLossRecovery: true}}
+
+	tests := []struct {
+		name        string
+		after       *redis.ClusterInfos
+		wantErr     bool
+		wantReasons []string
+	}{
+		{
+			name:        "slots owners agreed",
+			after:       agreed,
+			wantReasons: []string{QuorumLostEventReason, ConfigEpochBumpedEventReason, ShardTakeoverEventReason, ShardTakeoverEventReason},
+		},
+		{
+			name:        "slots owners not agreed",
+			after:       notAgreed,
+			wantErr:     true,
+			wantReasons: []string{QuorumLostEventReason, ConfigEpochBumpedEventReason, ShardTakeoverEventReason, ShardTakeoverNotAgreedEventReason},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAdmin := admin.NewFakeAdmin([]string{"10.0.0.1:6379", "10.0.0.4:6379", "10.0.0.5:6379"})
+			fakeAdmin.GetClusterInfosRet = admin.ClusterInfosRetType{ClusterInfos: tt.after}
+			recorder := record.NewFakeRecorder(10)
+			actionDone, err := FixLostQuorum(fakeAdmin, recorder, cluster, infos, nil, false)
+			if !actionDone || (err != nil) != tt.wantErr {
+				t.Errorf("FixLostQuorum() = %v, %v, want action done and error %v", actionDone, err, tt.wantErr)
+			}
+			reasons := []string{}
+			for len(recorder.Events) > 0 {
+				event := <-recorder.Events
+				reasons = append(reasons, strings.Fields(event)[1])
+				if strings.Contains(event, ConfigEpochBumpedEventReason) && !strings.Contains(event, "slave4") {
+					t.Errorf("the config epoch of the master with the smallest ID should be bumped: %s", event)
+				}
+			}
+			if !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Errorf("FixLostQuorum() events = %v, want %v", reasons, tt.wantReasons)
+			}
+		})
+	}
+}
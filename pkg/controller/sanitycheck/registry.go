@@ -0,0 +1,289 @@
+package sanitycheck
+
+import (
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/config"
+	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// SanityCheckProblemEventReason is the reason of the event emitted when a check in dry-run only mode finds a problem
+	SanityCheckProblemEventReason = "SanityCheckProblemFound"
+	// SanityCheckFailedEventReason is the reason of the event emitted when a check fails
+	SanityCheckFailedEventReason = "SanityCheckFailed"
+	// SanityCheckAwaitingApprovalEventReason is the reason of the event emitted when the fix of a problem waits for an approval
+	SanityCheckAwaitingApprovalEventReason = "SanityCheckAwaitingApproval"
+)
+
+// ApprovalRequiredError is returned by the checks whose fix of the problem found waits for a human approval
+type ApprovalRequiredError struct {
+	Message string
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return e.Message
+}
+
+// IsApprovalRequiredError returns true if the check waits for an approval
+func IsApprovalRequiredError(err error) bool {
+	_, ok := err.(*ApprovalRequiredError)
+	return ok
+}
+
+// ProblemNotFixedError is returned by the checks whose problem found can't be fixed with the current spec: the problem
+// is reported in the status and by an event with the Reason when it is found, and the next checks run
+type ProblemNotFixedError struct {
+	Reason  string
+	Message string
+}
+
+func (e *ProblemNotFixedError) Error() string {
+	return e.Message
+}
+
+// CheckContext contains what a sanity check needs to inspect and fix the cluster
+type CheckContext struct {
+	Admin      redis.AdminInterface
+	Config     *config.Redis
+	PodControl pod.RedisClusterControlInteface
+	Recorder   record.EventRecorder
+	Cluster    *rapi.RedisCluster
+	Infos      *redis.ClusterInfos
+	// Threshold is the threshold of the check, 0 if the check has none
+	Threshold time.Duration
+}
+
+// CheckFunc runs a sanity check, the problem found is only detected if dryRun is true.
+// Returns true if a problem has been found.
+type CheckFunc func(ctx *CheckContext, dryRun bool) (bool, error)
+
+// CheckSettings is the configuration of a sanity check, resolved from the operator defaults and the RedisCluster spec
+type CheckSettings struct {
+	Name       rapi.RedisClusterSanityCheckName
+	Order      int32
+	Enabled    bool
+	DryRunOnly bool
+	Threshold  time.Duration
+}
+
+// Registry contains the sanity checks by name
+type Registry struct {
+	checks map[rapi.RedisClusterSanityCheckName]CheckFunc
+}
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{checks: map[rapi.RedisClusterSanityCheckName]CheckFunc{}}
+}
+
+// Register adds the check to the registry, a check already registered with the same name is replaced
+func (r *Registry) Register(name rapi.RedisClusterSanityCheckName, check CheckFunc) {
+	r.checks[name] = check
+}
+
+// DefaultRegistry contains the built-in sanity checks
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	// fix lost quorum: promote the slaves of the lost masters before they are forgotten by FixFailedNodes
+	r.Register(rapi.SanityCheckLostQuorum, func(ctx *CheckContext, dryRun bool) (bool, error) {
+		pods, err := ctx.PodControl.GetRedisClusterPods(ctx.Cluster)
+		if err != nil {
+			return false, err
+		}
+		return FixLostQuorum(ctx.Admin, ctx.Recorder, ctx.Cluster, ctx.Infos, pods, dryRun)
+	})
+	// fix failed nodes: in some cases (cluster without enough master after crash or scale down), some nodes may still know about fail nodes
+	r.Register(rapi.SanityCheckFailedNodes, func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return FixFailedNodes(ctx.Admin, ctx.Recorder, ctx.Cluster, ctx.Infos, dryRun)
+	})
+	// forget nodes and delete pods when a redis node is untrusted.
+	r.Register(rapi.SanityCheckUntrustedNodes, func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return FixUntrustedNodes(ctx.Admin, ctx.PodControl, ctx.Recorder, ctx.Cluster, ctx.Infos, dryRun)
+	})
+	// delete again the pods blocked in terminating status
+	r.Register(rapi.SanityCheckTerminatingPods, func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return FixTerminatingPods(ctx.Cluster, ctx.PodControl, ctx.Threshold, dryRun)
+	})
+	// merge the partitions of a split cluster
+	r.Register(rapi.SanityCheckClusterSplit, func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return FixClusterSplit(ctx.Admin, ctx.Config, ctx.Recorder, ctx.Cluster, ctx.Infos, dryRun)
+	})
+	// forget the masters without slot whose pod doesn't exist anymore
+	r.Register(rapi.SanityCheckGhostMasters, func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return FixGhostMasterNodes(ctx.Admin, ctx.PodControl, ctx.Recorder, ctx.Cluster, ctx.Infos, dryRun)
+	})
+	// make the nodes that don't know each other meet
+	r.Register(rapi.SanityCheckNodesNotMeet, func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return FixNodesNotMeet(ctx.Admin, ctx.Infos, dryRun)
+	})
+	return r
+}
+
+// Settings returns the settings of the registered checks sorted by order: the operator defaults overridden by the
+// RedisCluster spec. The checks without position in the default order run after the others, by name.
+func (r *Registry) Settings(defaults *config.SanityChecks, cluster *rapi.RedisCluster) []CheckSettings {
+	order := defaults.Order
+	if order == nil {
+		order = config.DefaultSanityChecksOrder
+	}
+	disabled := defaults.Disabled
+	if disabled == nil {
+		disabled = config.DefaultSanityChecksDisabled
+	}
+
+	names := []string{}
+	for name := range r.checks {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	byName := map[rapi.RedisClusterSanityCheckName]*CheckSettings{}
+	settings := []*CheckSettings{}
+	for i, name := range names {
+		s := &CheckSettings{
+			Name:       rapi.RedisClusterSanityCheckName(name),
+			Order:      int32(len(order) + i),
+			Enabled:    !contains(disabled, name),
+			DryRunOnly: contains(defaults.DryRunOnly, name),
+		}
+		for position, orderedName := range order {
+			if orderedName == name {
+				s.Order = int32(position)
+				break
+			}
+		}
+		if s.Name == rapi.SanityCheckTerminatingPods {
+			s.Threshold = defaults.TerminatingPodTimeout
+		}
+		byName[s.Name] = s
+		settings = append(settings, s)
+	}
+
+	for _, check := range cluster.Spec.SanityChecks {
+		s, ok := byName[check.Name]
+		if !ok {
+			glog.Warningf("unknown sanity check %q in the spec of the cluster %s/%s", check.Name, cluster.Namespace, cluster.Name)
+			continue
+		}
+		if check.Enabled != nil {
+			s.Enabled = *check.Enabled
+		}
+		if check.Order != nil {
+			s.Order = *check.Order
+		}
+		if check.DryRunOnly != nil {
+			s.DryRunOnly = *check.DryRunOnly
+		}
+		if check.ThresholdSeconds != nil {
+			s.Threshold = time.Duration(*check.ThresholdSeconds) * time.Second
+		}
+	}
+
+	sort.SliceStable(settings, func(i, j int) bool { return settings[i].Order < settings[j].Order })
+	result := make([]CheckSettings, 0, len(settings))
+	for _, s := range settings {
+		result = append(result, *s)
+	}
+	return result
+}
+
+// Run runs the enabled checks by order until one of them finds a problem, the result of each check is reported in
+// the status of the cluster. A check in dry-run only mode only reports the problem found and the next checks run.
+// A check returning an ApprovalRequiredError stops the run until its fix is approved, a check returning a
+// ProblemNotFixedError only reports the problem.
+// Returns actionDone = true if a problem has been found, and fixed if dryRun is false.
+func (r *Registry) Run(ctx *CheckContext, settings []CheckSettings, dryRun bool) (bool, error) {
+	status := &ctx.Cluster.Status
+	for _, s := range settings {
+		if !s.Enabled {
+			setSanityCheckStatus(status, s.Name, rapi.SanityCheckDisabled, s.DryRunOnly, "")
+			continue
+		}
+		checkCtx := *ctx
+		checkCtx.Threshold = s.Threshold
+		found, err := r.checks[s.Name](&checkCtx, dryRun || s.DryRunOnly)
+		if IsApprovalRequiredError(err) {
+			if !s.DryRunOnly {
+				if setSanityCheckStatus(status, s.Name, rapi.SanityCheckAwaitingApproval, false, err.Error()) {
+					ctx.Recorder.Eventf(ctx.Cluster, kapiv1.EventTypeWarning, SanityCheckAwaitingApprovalEventReason, "Sanity check %s waits for an approval: %v", s.Name, err)
+				}
+				// nothing else runs on the cluster until the problem is fixed
+				return true, nil
+			}
+			found, err = true, nil
+		}
+		if notFixed, ok := err.(*ProblemNotFixedError); ok {
+			if setSanityCheckStatus(status, s.Name, rapi.SanityCheckProblemFound, s.DryRunOnly, notFixed.Message) {
+				ctx.Recorder.Event(ctx.Cluster, kapiv1.EventTypeWarning, notFixed.Reason, notFixed.Message)
+			}
+			continue
+		}
+		if err != nil {
+			if setSanityCheckStatus(status, s.Name, rapi.SanityCheckFailed, s.DryRunOnly, err.Error()) {
+				ctx.Recorder.Eventf(ctx.Cluster, kapiv1.EventTypeWarning, SanityCheckFailedEventReason, "Sanity check %s failed: %v", s.Name, err)
+			}
+			return found, err
+		}
+		if !found {
+			setSanityCheckStatus(status, s.Name, rapi.SanityCheckPassed, s.DryRunOnly, "")
+			continue
+		}
+		if s.DryRunOnly {
+			if setSanityCheckStatus(status, s.Name, rapi.SanityCheckProblemFound, true, "problem not fixed, the check is in dry-run only mode") {
+				ctx.Recorder.Eventf(ctx.Cluster, kapiv1.EventTypeWarning, SanityCheckProblemEventReason, "Sanity check %s found a problem, not fixed since the check is in dry-run only mode", s.Name)
+			}
+			continue
+		}
+		if dryRun {
+			setSanityCheckStatus(status, s.Name, rapi.SanityCheckProblemFound, false, "")
+		} else {
+			setSanityCheckStatus(status, s.Name, rapi.SanityCheckFixed, false, "")
+		}
+		glog.V(2).Infof("sanity check %s done an action on the cluster (dryRun:%v)", s.Name, dryRun)
+		return true, nil
+	}
+	return false, nil
+}
+
+// setSanityCheckStatus sets the result of the check in the status, returns true if the result changed
+func setSanityCheckStatus(status *rapi.RedisClusterStatus, name rapi.RedisClusterSanityCheckName, result rapi.RedisClusterSanityCheckResult, dryRunOnly bool, message string) bool {
+	newStatus := rapi.RedisClusterSanityCheckStatus{
+		Name:               name,
+		Result:             result,
+		DryRunOnly:         dryRunOnly,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	for i, checkStatus := range status.SanityChecks {
+		if checkStatus.Name != name {
+			continue
+		}
+		if checkStatus.Result == result && checkStatus.DryRunOnly == dryRunOnly && checkStatus.Message == message {
+			return false
+		}
+		status.SanityChecks[i] = newStatus
+		return true
+	}
+	status.SanityChecks = append(status.SanityChecks, newStatus)
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package sanitycheck
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/config"
+)
+
+func TestRegistry_Settings(t *testing.T) {
+	enabled := true
+	disabled := false
+	first := int32(-1)
+	threshold := int32(60)
+
+	tests := []struct {
+		name      string
+		defaults  config.SanityChecks
+		spec      []rapi.RedisClusterSanityCheck
+		wantOrder []rapi.RedisClusterSanityCheckName
+		check     func(t *testing.T, settings map[rapi.RedisClusterSanityCheckName]CheckSettings)
+	}{
+		{
+			name:      "built-in defaults",
+			wantOrder: []rapi.RedisClusterSanityCheckName{"lost-quorum", "failed-nodes", "untrusted-nodes", "terminating-pods", "cluster-split", "ghost-masters", "nodes-not-meet"},
+			check: func(t *testing.T, settings map[rapi.RedisClusterSanityCheckName]CheckSettings) {
+				if !settings[rapi.SanityCheckClusterSplit].Enabled || settings[rapi.SanityCheckGhostMasters].Enabled || settings[rapi.SanityCheckNodesNotMeet].Enabled {
+					t.Errorf("ghost-masters and nodes-not-meet should be the only checks disabled by default")
+				}
+			},
+		},
+		{
+			name: "operator defaults",
+			defaults: config.SanityChecks{
+				Order:                 []string{"cluster-split", "lost-quorum"},
+				Disabled:              []string{"failed-nodes"},
+				DryRunOnly:            []string{"untrusted-nodes"},
+				TerminatingPodTimeout: time.Minute,
+			},
+			wantOrder: []rapi.RedisClusterSanityCheckName{"cluster-split", "lost-quorum", "failed-nodes", "ghost-masters", "nodes-not-meet", "terminating-pods", "untrusted-nodes"},
+			check: func(t *testing.T, settings map[rapi.RedisClusterSanityCheckName]CheckSettings) {
+				if settings[rapi.SanityCheckFailedNodes].Enabled || !settings[rapi.SanityCheckGhostMasters].Enabled {
+					t.Errorf("only failed-nodes should be disabled")
+				}
+				if !settings[rapi.SanityCheckUntrustedNodes].DryRunOnly {
+					t.Errorf("untrusted-nodes should be in dry-run only mode")
+				}
+				if settings[rapi.SanityCheckTerminatingPods].Threshold != time.Minute {
+					t.Errorf("terminating-pods threshold = %v, want %v", settings[rapi.SanityCheckTerminatingPods].Threshold, time.Minute)
+				}
+			},
+		},
+		{
+			name: "spec overrides",
+			defaults: config.SanityChecks{
+				TerminatingPodTimeout: time.Minute,
+			},
+			spec: []rapi.RedisClusterSanityCheck{
+				{Name: rapi.SanityCheckClusterSplit, Order: &first, DryRunOnly: &enabled},
+				{Name: rapi.SanityCheckNodesNotMeet, Enabled: &enabled},
+				{Name: rapi.SanityCheckLostQuorum, Enabled: &disabled},
+				{Name: rapi.SanityCheckTerminatingPods, ThresholdSeconds: &threshold},
+				{Name: "unknown", Enabled: &enabled},
+			},
+			wantOrder: []rapi.RedisClusterSanityCheckName{"cluster-split", "lost-quorum", "failed-nodes", "untrusted-nodes", "terminating-pods", "ghost-masters", "nodes-not-meet"},
+			check: func(t *testing.T, settings map[rapi.RedisClusterSanityCheckName]CheckSettings) {
+				if !settings[rapi.SanityCheckClusterSplit].DryRunOnly || !settings[rapi.SanityCheckNodesNotMeet].Enabled || settings[rapi.SanityCheckLostQuorum].Enabled {
+					t.Errorf("the spec should override the defaults, got %v", settings)
+				}
+				if settings[rapi.SanityCheckTerminatingPods].Threshold != time.Minute {
+					t.Errorf("terminating-pods threshold = %v, want %v", settings[rapi.SanityCheckTerminatingPods].Threshold, time.Minute)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{SanityChecks: tt.spec}}
+			settings := DefaultRegistry.Settings(&tt.defaults, cluster)
+			order := []rapi.RedisClusterSanityCheckName{}
+			byName := map[rapi.RedisClusterSanityCheckName]CheckSettings{}
+			for _, s := range settings {
+				order = append(order, s.Name)
+				byName[s.Name] = s
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("Settings() order = %v, want %v", order, tt.wantOrder)
+			}
+			tt.check(t, byName)
+		})
+	}
+}
+
+func TestRegistry_Run(t *testing.T) {
+	var runs []string
+	newCheck := func(name string, found bool, err error) CheckFunc {
+		return func(ctx *CheckContext, dryRun bool) (bool, error) {
+			runs = append(runs, fmt.Sprintf("%s(dryRun:%v)", name, dryRun))
+			return found, err
+		}
+	}
+	registry := NewRegistry()
+	registry.Register("passed", newCheck("passed", false, nil))
+	registry.Register("reported", newCheck("reported", true, nil))
+	registry.Register("fixed", newCheck("fixed", true, nil))
+	registry.Register("failed", newCheck("failed", false, fmt.Errorf("unable to fix")))
+	registry.Register("disabled", newCheck("disabled", true, nil))
+	settings := []CheckSettings{
+		{Name: "disabled"},
+		{Name: "passed", Enabled: true},
+		{Name: "reported", Enabled: true, DryRunOnly: true},
+		{Name: "fixed", Enabled: true},
+		{Name: "failed", Enabled: true},
+	}
+	cluster := &rapi.RedisCluster{}
+	recorder := record.NewFakeRecorder(10)
+	ctx := &CheckContext{Cluster: cluster, Recorder: recorder}
+
+	actionDone, err := registry.Run(ctx, settings, false)
+	if !actionDone || err != nil {
+		t.Errorf("Run() = %v, %v, want an action done", actionDone, err)
+	}
+	wantRuns := []string{"passed(dryRun:false)", "reported(dryRun:true)", "fixed(dryRun:false)"}
+	if !reflect.DeepEqual(runs, wantRuns) {
+		t.Errorf("Run() ran %v, want %v", runs, wantRuns)
+	}
+	wantResults := map[rapi.RedisClusterSanityCheckName]rapi.RedisClusterSanityCheckResult{
+		"disabled": rapi.SanityCheckDisabled,
+		"passed":   rapi.SanityCheckPassed,
+		"reported": rapi.SanityCheckProblemFound,
+		"fixed":    rapi.SanityCheckFixed,
+	}
+	results := map[rapi.RedisClusterSanityCheckName]rapi.RedisClusterSanityCheckResult{}
+	for _, status := range cluster.Status.SanityChecks {
+		results[status.Name] = status.Result
+	}
+	if !reflect.DeepEqual(results, wantResults) {
+		t.Errorf("Run() results = %v, want %v", results, wantResults)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Run() should emit one event for the problem reported by the dry-run only check, got %d", len(recorder.Events))
+	}
+
+	// the problems reported are not reported again, the failure stops the run
+	runs = nil
+	settings[3].Enabled = false
+	actionDone, err = registry.Run(ctx, settings, true)
+	if actionDone || err == nil {
+		t.Errorf("Run() = %v, %v, want the error of the failed check", actionDone, err)
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("Run() should only emit the event of the failed check, got %d events", len(recorder.Events))
+	}
+	if status := cluster.Status.SanityChecks[len(cluster.Status.SanityChecks)-1]; status.Name != "failed" || status.Result != rapi.SanityCheckFailed || status.Message != "unable to fix" {
+		t.Errorf("Run() status of the failed check = %v", status)
+	}
+}
+
+func TestRegistry_RunApprovalRequired(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("quarantined", func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return true, &ApprovalRequiredError{Message: "waiting for the approval"}
+	})
+	registry.Register("next", func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return true, nil
+	})
+	cluster := &rapi.RedisCluster{}
+	recorder := record.NewFakeRecorder(10)
+	ctx := &CheckContext{Cluster: cluster, Recorder: recorder}
+
+	settings := []CheckSettings{{Name: "quarantined", Enabled: true}, {Name: "next", Enabled: true}}
+	for i := 0; i < 2; i++ {
+		if actionDone, err := registry.Run(ctx, settings, true); !actionDone || err != nil {
+			t.Errorf("Run() = %v, %v, want an action done without error", actionDone, err)
+		}
+	}
+	if len(cluster.Status.SanityChecks) != 1 || cluster.Status.SanityChecks[0].Result != rapi.SanityCheckAwaitingApproval || cluster.Status.SanityChecks[0].Message != "waiting for the approval" {
+		t.Errorf("Run() should stop at the check awaiting an approval, got %v", cluster.Status.SanityChecks)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Run() should emit one event for the check awaiting an approval, got %d", len(recorder.Events))
+	}
+
+	// in dry-run only mode, the check only reports the problem and the next checks run
+	settings[0].DryRunOnly = true
+	if actionDone, err := registry.Run(ctx, settings, false); !actionDone || err != nil {
+		t.Errorf("Run() = %v, %v, want an action done without error", actionDone, err)
+	}
+	if cluster.Status.SanityChecks[0].Result != rapi.SanityCheckProblemFound {
+		t.Errorf("Run() result = %s, want %s", cluster.Status.SanityChecks[0].Result, rapi.SanityCheckProblemFound)
+	}
+}
+
+func TestRegistry_RunProblemNotFixed(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("not-fixed", func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return false, &ProblemNotFixedError{Reason: "NotFixed", Message: "problem not fixed"}
+	})
+	registry.Register("next", func(ctx *CheckContext, dryRun bool) (bool, error) {
+		return false, nil
+	})
+	cluster := &rapi.RedisCluster{}
+	recorder := record.NewFakeRecorder(10)
+	ctx := &CheckContext{Cluster: cluster, Recorder: recorder}
+
+	// the dry-run and the real runs of the successive syncs report the problem once, and the next checks run
+	settings := []CheckSettings{{Name: "not-fixed", Enabled: true}, {Name: "next", Enabled: true}}
+	for _, dryRun := range []bool{true, false, true} {
+		if actionDone, err := registry.Run(ctx, settings, dryRun); actionDone || err != nil {
+			t.Errorf("Run() = %v, %v, want no action done and no error", actionDone, err)
+		}
+	}
+	if len(cluster.Status.SanityChecks) != 2 || cluster.Status.SanityChecks[0].Result != rapi.SanityCheckProblemFound || cluster.Status.SanityChecks[1].Result != rapi.SanityCheckPassed {
+		t.Errorf("Run() statuses = %v, want the problem found and the next check passed", cluster.Status.SanityChecks)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Run() should emit one event for the problem not fixed, got %d", len(recorder.Events))
+	}
+}
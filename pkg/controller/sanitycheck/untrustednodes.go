@@ -5,15 +5,17 @@ import (
 
 	kapi "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/clustering"
 	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 )
 
 // FixUntrustedNodes used to remove Nodes that are not trusted by other nodes. It can append when a node
 // are removed from the cluster (with the "forget nodes" command) but try to rejoins the cluster.
-func FixUntrustedNodes(admin redis.AdminInterface, podControl pod.RedisClusterControlInteface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (bool, error) {
+func FixUntrustedNodes(admin redis.AdminInterface, podControl pod.RedisClusterControlInteface, recorder record.EventRecorder, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (bool, error) {
 	untrustedNode := listUntrustedNodes(infos)
 	var errs []error
 	doneAnAction := false
@@ -40,17 +42,20 @@ func FixUntrustedNodes(admin redis.AdminInterface, podControl pod.RedisClusterCo
 			// it means the POD is used by another Redis node ID so we should not delete the pod.
 			continue
 		}
-		exist, reused := checkIfPodNameExistAndIsReused(uNode, currentPods)
-		if exist && !reused {
-			if err := podControl.DeletePod(cluster, uNode.Pod.Name); err != nil {
-				errs = append(errs, err)
-			}
-		}
 		doneAnAction = true
 		if !dryRun {
+			exist, reused := checkIfPodNameExistAndIsReused(uNode, currentPods)
+			if exist && !reused {
+				if err := podControl.DeletePod(cluster, uNode.Pod.Name); err != nil {
+					errs = append(errs, err)
+				}
+			}
 			if err := admin.ForgetNode(id); err != nil {
+				recorder.Eventf(cluster, kapi.EventTypeWarning, clustering.ForgetNodeFailedEventReason, "Unable to forget the untrusted node %s: %v", clustering.NodeDescription(uNode), err)
 				errs = append(errs, err)
+				continue
 			}
+			recorder.Eventf(cluster, kapi.EventTypeNormal, clustering.NodeForgottenEventReason, "Untrusted node %s forgotten, it was trying to rejoin the cluster", clustering.NodeDescription(uNode))
 		}
 	}
 
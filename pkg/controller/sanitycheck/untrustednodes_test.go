@@ -6,6 +6,7 @@ import (
 
 	kapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
 	"github.com/zh168654/Redis-Operator/pkg/redis"
@@ -27,6 +28,7 @@ func TestFixUntrustedNodes(t *testing.T) {
 		podControl *Fakecontrol
 		cluster    *rapi.RedisCluster
 		infos      *redis.ClusterInfos
+		dryRun     bool
 	}
 	tests := []struct {
 		name           string
@@ -164,11 +166,37 @@ func TestFixUntrustedNodes(t *testing.T) {
 			wantErr:        false,
 			wantPodDeleted: map[string]bool{"pod3": true},
 		},
+		{
+			name: "dry run",
+			args: args{
+				adminFunc: func() redis.AdminInterface {
+					nodesAddr := []string{redis1.IPPort(), redis2.IPPort()}
+					fakeAdmin := admin.NewFakeAdmin(nodesAddr)
+
+					return fakeAdmin
+				},
+				podControl: newFakecontrol([]*kapiv1.Pod{pod1, pod2, pod4}),
+				cluster: &rapi.RedisCluster{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "test-ns"},
+				},
+				infos: &redis.ClusterInfos{
+					Infos: map[string]*redis.NodeInfos{
+						redis1.ID: {Node: &redis1, Friends: redis.Nodes{&redis2, &redisUntrusted}},
+						redis2.ID: {Node: &redis2, Friends: redis.Nodes{&redis1}},
+					},
+					Status: redis.ClusterInfosConsistent,
+				},
+				dryRun: true,
+			},
+			want:           true,
+			wantErr:        false,
+			wantPodDeleted: map[string]bool{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			admin := tt.args.adminFunc()
-			got, err := FixUntrustedNodes(admin, tt.args.podControl, tt.args.cluster, tt.args.infos, false)
+			got, err := FixUntrustedNodes(admin, tt.args.podControl, record.NewFakeRecorder(10), tt.args.cluster, tt.args.infos, tt.args.dryRun)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FixUntrustedNodes() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -206,7 +234,7 @@ func (f *Fakecontrol) GetRedisClusterPods(redisCluster *rapi.RedisCluster) ([]*k
 }
 
 // CreatePod used to create a Pod from the RedisCluster pod template
-func (f *Fakecontrol) CreatePod(redisCluster *rapi.RedisCluster) (*kapiv1.Pod, error) {
+func (f *Fakecontrol) CreatePod(redisCluster *rapi.RedisCluster, currentPods int32) (*kapiv1.Pod, error) {
 	return f.pod, nil
 }
 
@@ -222,6 +250,36 @@ func (f *Fakecontrol) DeletePodNow(redisCluster *rapi.RedisCluster, podName stri
 	return nil
 }
 
+// GetRedisSentinelPods return list of sentinel Pod attached to a RedisCluster
+func (f *Fakecontrol) GetRedisSentinelPods(redisCluster *rapi.RedisCluster) ([]*kapiv1.Pod, error) {
+	return nil, nil
+}
+
+// CreateSentinelPod used to create a sentinel Pod
+func (f *Fakecontrol) CreateSentinelPod(redisCluster *rapi.RedisCluster) (*kapiv1.Pod, error) {
+	return f.pod, nil
+}
+
+// AdoptPod labels an existing pod and sets the RedisCluster as its controller
+func (f *Fakecontrol) AdoptPod(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, podNo int32) (*kapiv1.Pod, error) {
+	return pod, nil
+}
+
+// SetPodShard labels the pod with the shard it belongs to
+func (f *Fakecontrol) SetPodShard(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, shard string) (*kapiv1.Pod, error) {
+	return pod, nil
+}
+
+// SetPodNo labels the pod with its pod number
+func (f *Fakecontrol) SetPodNo(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, podNo int32) (*kapiv1.Pod, error) {
+	return pod, nil
+}
+
+// SetStandbyRole annotates the pod with the role of its redis node
+func (f *Fakecontrol) SetStandbyRole(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, role string) (*kapiv1.Pod, error) {
+	return pod, nil
+}
+
 func newPod(name, vmName, ip string) *kapiv1.Pod {
 	return &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: kapiv1.PodSpec{NodeName: vmName}, Status: kapiv1.PodStatus{PodIP: ip}}
 }
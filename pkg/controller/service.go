@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/errors"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
+)
+
+// newRedisClusterServices returns the Services of the cluster: the headless Service selecting the redis pods and,
+// with the External service type and a ServiceNodePortStart, one NodePort Service per pod. complete is false if a pod
+// has no valid pod-no label: its NodePort Service is unknown.
+func newRedisClusterServices(cluster *rapi.RedisCluster, pods []*apiv1.Pod) (services []*apiv1.Service, complete bool, err error) {
+	svc, err := newRedisClusterService(cluster)
+	if err != nil {
+		return nil, false, err
+	}
+	services = []*apiv1.Service{svc}
+	if !strings.EqualFold(getServiceType(cluster), string(rapi.ServiceTypeExternal)) || getServiceNodePortStart(cluster) == "" {
+		return services, true, nil
+	}
+	nodePortStart, err := strconv.ParseInt(getServiceNodePortStart(cluster), 10, 32)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid serviceNodePortStart %q: %v", getServiceNodePortStart(cluster), err)
+	}
+
+	// pods with the same number share their NodePort Service
+	complete = true
+	seen := map[int]bool{}
+	podNos := []int{}
+	for _, p := range pods {
+		podNo, _, err := pod.ParsePodNo(p.Labels[rapi.PodNoLabelKey])
+		if err != nil {
+			glog.Warningf("pod %s/%s has no valid %s label, it is not exposed by a NodePort service: %v", p.Namespace, p.Name, rapi.PodNoLabelKey, err)
+			complete = false
+			continue
+		}
+		if !seen[int(podNo)] {
+			seen[int(podNo)] = true
+			podNos = append(podNos, int(podNo))
+		}
+	}
+	sort.Ints(podNos)
+	for _, podNo := range podNos {
+		podSvc, err := newRedisPodService(cluster, int32(podNo), int32(nodePortStart))
+		if err != nil {
+			return nil, false, err
+		}
+		services = append(services, podSvc)
+	}
+	return services, complete, nil
+}
+
+// relabelLegacyPods labels with the decimal pod number the pods labeled by the previous versions with the number
+// encoded as a rune, so that they are selected by their NodePort Service
+func (c *Controller) relabelLegacyPods(cluster *rapi.RedisCluster, pods []*apiv1.Pod) error {
+	var errs []error
+	for _, p := range pods {
+		podNo, legacy, err := pod.ParsePodNo(p.Labels[rapi.PodNoLabelKey])
+		if err != nil || !legacy {
+			continue
+		}
+		glog.V(3).Infof("cluster %s/%s: relabel pod %s with pod number %d", cluster.Namespace, cluster.Name, p.Name, podNo)
+		if _, err := c.podControl.SetPodNo(cluster, p, podNo); err != nil {
+			errs = append(errs, fmt.Errorf("unable to relabel pod %s: %v", p.Name, err))
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// syncServices reconciles the Services of the cluster: the pods with a legacy pod-no label are relabeled, the desired
+// Services are created or updated, then the Services controlled by the cluster and not desired anymore, like the
+// NodePort Services of the deleted pods or the Services of a former ServiceName, are deleted. Nothing is deleted while
+// a pod has no valid pod-no label.
+func (c *Controller) syncServices(cluster *rapi.RedisCluster, pods []*apiv1.Pod) error {
+	var errs []error
+	if err := c.relabelLegacyPods(cluster, pods); err != nil {
+		errs = append(errs, err)
+	}
+	desired, complete, err := newRedisClusterServices(cluster, pods)
+	if err != nil {
+		return err
+	}
+	existing, err := c.listRedisClusterServices(cluster)
+	if err != nil {
+		return err
+	}
+	live := map[string]*apiv1.Service{}
+	for _, svc := range existing {
+		live[svc.Name] = svc
+	}
+
+	desiredNames := map[string]bool{}
+	for _, svc := range desired {
+		desiredNames[svc.Name] = true
+		if err := c.applyService(cluster, live[svc.Name], svc); err != nil {
+			errs = append(errs, fmt.Errorf("unable to apply service %s: %v", svc.Name, err))
+		}
+	}
+	if !complete {
+		glog.Warningf("cluster %s/%s: some pods have no valid %s label, the services are not pruned", cluster.Namespace, cluster.Name, rapi.PodNoLabelKey)
+		return errors.NewAggregate(errs)
+	}
+	for _, svc := range existing {
+		if desiredNames[svc.Name] {
+			continue
+		}
+		glog.V(3).Infof("cluster %s/%s: delete service %s", cluster.Namespace, cluster.Name, svc.Name)
+		if err := c.serviceControl.DeleteService(cluster, svc.Name); err != nil {
+			errs = append(errs, fmt.Errorf("unable to delete service %s: %v", svc.Name, err))
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// listRedisClusterServices returns the Services of the redis pods controlled by the cluster, the Service of the
+// sentinels excluded. They are not selected by label: their labels may have been edited.
+func (c *Controller) listRedisClusterServices(cluster *rapi.RedisCluster) ([]*apiv1.Service, error) {
+	svcList, err := c.serviceLister.Services(cluster.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list service, err:%v ", err)
+	}
+	services := []*apiv1.Service{}
+	for _, svc := range svcList {
+		if svc.Name == getSentinelServiceName(cluster) {
+			continue
+		}
+		if controllerRef := metav1.GetControllerOf(svc); controllerRef == nil || controllerRef.UID != cluster.UID {
+			continue
+		}
+		services = append(services, svc)
+	}
+	return services, nil
+}
@@ -3,30 +3,35 @@ package controller
 import (
 	"fmt"
 
+	"strconv"
+	"strings"
+
 	kapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
 	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
-	"strconv"
-	"strings"
 )
 
 // ServicesControlInterface inferface for the ServicesControl
 type ServicesControlInterface interface {
-	// CreateRedisClusterService used to create the Kubernetes Services needed to access the Redis Cluster
-	CreateRedisClusterService(redisCluster *rapi.RedisCluster) ([]*kapiv1.Service, error)
+	// CreateService used to create a Kubernetes Service of the Redis Cluster
+	CreateService(redisCluster *rapi.RedisCluster, svc *kapiv1.Service) (*kapiv1.Service, error)
+	// UpdateService used to update a Kubernetes Service of the Redis Cluster
+	UpdateService(redisCluster *rapi.RedisCluster, svc *kapiv1.Service) (*kapiv1.Service, error)
+	// DeleteService used to delete a Kubernetes Service of the Redis Cluster from its name
+	DeleteService(redisCluster *rapi.RedisCluster, name string) error
 	// DeleteRedisClusterService used to delete the Kubernetes Services linked to the Redis Cluster
 	DeleteRedisClusterService(redisCluster *rapi.RedisCluster) error
 	// GetRedisClusterService used to retrieve the Kubernetes Services associated to the RedisCluster
 	GetRedisClusterService(redisCluster *rapi.RedisCluster) ([]*kapiv1.Service, error)
 
-	// AddRedisPodService used to add a nodePort service for the redis pod
-	AddRedisPodService(redisCluster *rapi.RedisCluster, currentPods int32) (*kapiv1.Service, error)
-	// RemoveRedisPodService used to remove a nodePort service for the redis pod
-	RemoveRedisPodService(redisCluster *rapi.RedisCluster, currentPods int32) error
+	// CreateRedisSentinelService used to create the Kubernetes Service needed to access the sentinels in Sentinel mode
+	CreateRedisSentinelService(redisCluster *rapi.RedisCluster) (*kapiv1.Service, error)
 }
 
 // ServicesControl contains all information for managing Kube Services
@@ -62,7 +67,7 @@ func (s *ServicesControl) GetRedisClusterService(redisCluster *rapi.RedisCluster
 	replicationFactor := getReplicationFactor(redisCluster)
 	if strings.EqualFold(getServiceType(redisCluster), string(rapi.ServiceTypeExternal)) {
 		for i := 0; i < int(numberOfMaster*replicationFactor); i++ {
-			podServiceName := serviceName + "-external-" + strconv.Itoa(i)
+			podServiceName := getPodServiceName(redisCluster, int32(i))
 			pod_svc, err := s.KubeClient.CoreV1().Services(redisCluster.Namespace).Get(podServiceName, metav1.GetOptions{})
 			if err != nil {
 				return nil, err
@@ -76,83 +81,92 @@ func (s *ServicesControl) GetRedisClusterService(redisCluster *rapi.RedisCluster
 	return svcList, nil
 }
 
-// CreateRedisClusterService used to create the Kubernetes Service needed to access the Redis Cluster
-func (s *ServicesControl) CreateRedisClusterService(redisCluster *rapi.RedisCluster) ([]*kapiv1.Service, error) {
-	defer func() {
-		if err := recover(); err != nil {
-			if createdServiceList, ok := err.([]*kapiv1.Service); ok {
-				for _, createdService := range createdServiceList {
-					s.KubeClient.CoreV1().Services(redisCluster.Namespace).Delete(createdService.Name, nil)
-				}
-			}
-		}
-	}()
-	serviceName := getServiceName(redisCluster)
+// CreateService used to create a Kubernetes Service of the Redis Cluster
+func (s *ServicesControl) CreateService(redisCluster *rapi.RedisCluster, svc *kapiv1.Service) (*kapiv1.Service, error) {
+	return s.KubeClient.CoreV1().Services(redisCluster.Namespace).Create(svc)
+}
+
+// UpdateService used to update a Kubernetes Service of the Redis Cluster
+func (s *ServicesControl) UpdateService(redisCluster *rapi.RedisCluster, svc *kapiv1.Service) (*kapiv1.Service, error) {
+	return s.KubeClient.CoreV1().Services(redisCluster.Namespace).Update(svc)
+}
+
+// DeleteService used to delete a Kubernetes Service of the Redis Cluster from its name
+func (s *ServicesControl) DeleteService(redisCluster *rapi.RedisCluster, name string) error {
+	return s.KubeClient.CoreV1().Services(redisCluster.Namespace).Delete(name, nil)
+}
+
+// newRedisClusterService builds the headless Service selecting the redis pods of the cluster
+func newRedisClusterService(redisCluster *rapi.RedisCluster) (*kapiv1.Service, error) {
 	desiredlabels, err := pod.GetLabelsSet(redisCluster)
 	if err != nil {
 		return nil, err
 	}
+	desiredlabels = labels.Merge(desiredlabels, nil)
 
 	desiredAnnotations, err := pod.GetAnnotationsSet(redisCluster)
 	if err != nil {
 		return nil, err
 	}
 
-	var serviceList []*kapiv1.Service
 	newService := &kapiv1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:          desiredlabels,
 			Annotations:     desiredAnnotations,
-			Name:            serviceName,
+			Name:            getServiceName(redisCluster),
 			OwnerReferences: []metav1.OwnerReference{pod.BuildOwnerReference(redisCluster)},
 		},
 		Spec: kapiv1.ServiceSpec{
-			ClusterIP: "None",
-			Ports:     []kapiv1.ServicePort{{Port: 6379, Name: "redis"}},
+			Type:      kapiv1.ServiceTypeClusterIP,
+			ClusterIP: kapiv1.ClusterIPNone,
+			Ports:     []kapiv1.ServicePort{newServicePort("redis", 6379, 0)},
 			Selector:  desiredlabels,
 		},
 	}
+	recordLastApplied(&newService.ObjectMeta, appliedFields{Labels: desiredlabels, Annotations: desiredAnnotations, Selector: desiredlabels, Ports: servicePortNames(newService.Spec.Ports)})
+	return newService, nil
+}
 
-	numberOfMaster := getNumberOfMaster(redisCluster)
-	replicationFactor := getReplicationFactor(redisCluster)
-	serviceNodePortStart := getServiceNodePortStart(redisCluster)
+// newRedisPodService builds the NodePort Service exposing the redis pod labeled with the pod number, on the node
+// port ServiceNodePortStart + pod number
+func newRedisPodService(redisCluster *rapi.RedisCluster, podNo int32, nodePortStart int32) (*kapiv1.Service, error) {
+	desiredPodlabels, err := pod.GetPodLabelsSet(redisCluster, podNo)
+	if err != nil {
+		return nil, err
+	}
+	desiredPodlabels = labels.Merge(desiredPodlabels, nil)
 
-	// Create nodePort services for each pod
-	if serviceNodePortStart != "" && strings.EqualFold(getServiceType(redisCluster), string(rapi.ServiceTypeExternal)) {
-		for i := 0; i < int(numberOfMaster*replicationFactor); i++ {
-			if nodePortStart, err := strconv.ParseInt(serviceNodePortStart, 10, 32); err == nil {
-				desiredPodlabels, err := pod.GetPodLabelsSet(redisCluster, int32(i))
-				if err != nil {
-					return nil, err
-				}
-				newPodService := &kapiv1.Service{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels:          desiredPodlabels,
-						Annotations:     desiredAnnotations,
-						Name:            serviceName + "-external-" + strconv.Itoa(i),
-						OwnerReferences: []metav1.OwnerReference{pod.BuildOwnerReference(redisCluster)},
-					},
-					Spec: kapiv1.ServiceSpec{
-						Ports:    []kapiv1.ServicePort{{Port: 6379, Name: "redis", NodePort: int32(nodePortStart)+int32(i)}},
-						Selector: desiredPodlabels,
-					},
-				}
-
-				if _, err := s.KubeClient.CoreV1().Services(redisCluster.Namespace).Create(newService); err != nil {
-					panic(serviceList)
-					return serviceList, err
-				}
-				serviceList = append(serviceList, newPodService)
-			}
-		}
+	desiredAnnotations, err := pod.GetAnnotationsSet(redisCluster)
+	if err != nil {
+		return nil, err
 	}
-	if _, err := s.KubeClient.CoreV1().Services(redisCluster.Namespace).Create(newService); err != nil {
-		panic(serviceList)
-		return serviceList, err
+
+	newPodService := &kapiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:          desiredPodlabels,
+			Annotations:     desiredAnnotations,
+			Name:            getPodServiceName(redisCluster, podNo),
+			OwnerReferences: []metav1.OwnerReference{pod.BuildOwnerReference(redisCluster)},
+		},
+		Spec: kapiv1.ServiceSpec{
+			Type:     kapiv1.ServiceTypeNodePort,
+			Ports:    []kapiv1.ServicePort{newServicePort("redis", 6379, nodePortStart+podNo)},
+			Selector: desiredPodlabels,
+		},
 	}
-	serviceList = append(serviceList, newService)
+	recordLastApplied(&newPodService.ObjectMeta, appliedFields{Labels: desiredPodlabels, Annotations: desiredAnnotations, Selector: desiredPodlabels, Ports: servicePortNames(newPodService.Spec.Ports)})
+	return newPodService, nil
+}
 
-	return serviceList, err
+// newServicePort returns a TCP service port with the fields defaulted by kubernetes, to compare it with the live ports
+func newServicePort(name string, port, nodePort int32) kapiv1.ServicePort {
+	return kapiv1.ServicePort{
+		Name:       name,
+		Protocol:   kapiv1.ProtocolTCP,
+		Port:       port,
+		TargetPort: intstr.FromInt(int(port)),
+		NodePort:   nodePort,
+	}
 }
 
 // DeleteRedisClusterService used to delete the Kubernetes Service linked to the Redis Cluster
@@ -167,7 +181,7 @@ func (s *ServicesControl) DeleteRedisClusterService(redisCluster *rapi.RedisClus
 
 	if strings.EqualFold(getServiceType(redisCluster), string(rapi.ServiceTypeExternal)) {
 		for i := 0; i < int(numberOfMaster*replicationFactor); i++ {
-			podServiceName := serviceName + "-external-" + strconv.Itoa(i)
+			podServiceName := getPodServiceName(redisCluster, int32(i))
 			err := s.KubeClient.CoreV1().Services(redisCluster.Namespace).Delete(podServiceName, nil)
 			if err != nil {
 				return err
@@ -177,51 +191,42 @@ func (s *ServicesControl) DeleteRedisClusterService(redisCluster *rapi.RedisClus
 	return nil
 }
 
-// AddRedisPodService used to add a nodePort service for the redis pod
-func (s *ServicesControl) AddRedisPodService(redisCluster *rapi.RedisCluster, currentPods int32) (*kapiv1.Service, error) {
-	serviceNodePortStart := getServiceNodePortStart(redisCluster)
-	serviceName := getServiceName(redisCluster)
-	if serviceNodePortStart != "" && strings.EqualFold(getServiceType(redisCluster), string(rapi.ServiceTypeExternal)) {
-		desiredPodlabels, err := pod.GetPodLabelsSet(redisCluster, currentPods)
-		if err != nil {
-			return nil, err
-		}
-		desiredAnnotations, err := pod.GetAnnotationsSet(redisCluster)
-		if err != nil {
-			return nil, err
-		}
-		if nodePortStart, err := strconv.ParseInt(serviceNodePortStart, 10, 32); err == nil {
-			newPodService := &kapiv1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels:          desiredPodlabels,
-					Annotations:     desiredAnnotations,
-					Name:            serviceName + "-external-" + string(currentPods),
-					OwnerReferences: []metav1.OwnerReference{pod.BuildOwnerReference(redisCluster)},
-				},
-				Spec: kapiv1.ServiceSpec{
-					Ports:    []kapiv1.ServicePort{{Port: 6379, Name: "redis", NodePort: int32(nodePortStart)+currentPods}},
-					Selector: desiredPodlabels,
-				},
-			}
-			if svc, err := s.KubeClient.CoreV1().Services(redisCluster.Namespace).Create(newPodService); err != nil {
-				return svc, err
-			}
-		}
+// CreateRedisSentinelService used to create the Kubernetes Service needed to access the sentinels in Sentinel mode
+func (s *ServicesControl) CreateRedisSentinelService(redisCluster *rapi.RedisCluster) (*kapiv1.Service, error) {
+	newService, err := newRedisSentinelService(redisCluster)
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return s.KubeClient.CoreV1().Services(redisCluster.Namespace).Create(newService)
 }
 
-// RemoveRedisPodService used to remove a nodePort service for the redis pod
-func (s *ServicesControl) RemoveRedisPodService(redisCluster *rapi.RedisCluster, currentPods int32) error {
-	if strings.EqualFold(getServiceType(redisCluster), string(rapi.ServiceTypeExternal)) {
-		serviceName := getServiceName(redisCluster)
-		podServiceName := serviceName + "-external-" + string(currentPods)
-		err := s.KubeClient.CoreV1().Services(redisCluster.Namespace).Delete(podServiceName, nil)
-		if err != nil {
-			return err
-		}
+// newRedisSentinelService builds the Service of the sentinels in Sentinel mode
+func newRedisSentinelService(redisCluster *rapi.RedisCluster) (*kapiv1.Service, error) {
+	desiredlabels, err := pod.GetSentinelLabelsSet(redisCluster)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	desiredAnnotations, err := pod.GetAnnotationsSet(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	newService := &kapiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:          desiredlabels,
+			Annotations:     desiredAnnotations,
+			Name:            getSentinelServiceName(redisCluster),
+			OwnerReferences: []metav1.OwnerReference{pod.BuildOwnerReference(redisCluster)},
+		},
+		Spec: kapiv1.ServiceSpec{
+			Type:     kapiv1.ServiceTypeClusterIP,
+			Ports:    []kapiv1.ServicePort{newServicePort("sentinel", 26379, 0)},
+			Selector: desiredlabels,
+		},
+	}
+	recordLastApplied(&newService.ObjectMeta, appliedFields{Labels: desiredlabels, Annotations: desiredAnnotations, Selector: desiredlabels, Ports: servicePortNames(newService.Spec.Ports)})
+	return newService, nil
 }
 
 func getServiceName(redisCluster *rapi.RedisCluster) string {
@@ -232,6 +237,15 @@ func getServiceName(redisCluster *rapi.RedisCluster) string {
 	return serviceName
 }
 
+// getPodServiceName returns the name of the NodePort Service exposing the redis pod labeled with the pod number
+func getPodServiceName(redisCluster *rapi.RedisCluster, podNo int32) string {
+	return getServiceName(redisCluster) + "-external-" + strconv.Itoa(int(podNo))
+}
+
+func getSentinelServiceName(redisCluster *rapi.RedisCluster) string {
+	return getServiceName(redisCluster) + "-sentinel"
+}
+
 func getServiceType(redisCluster *rapi.RedisCluster) string {
 	serviceType := redisCluster.Name
 	if redisCluster.Spec.ServiceType != "" {
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"sort"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/controller/pod"
+)
+
+func TestController_syncServices(t *testing.T) {
+	cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns", UID: "uid"}}
+	cluster.Spec.ServiceType = string(rapi.ServiceTypeExternal)
+	cluster.Spec.ServiceNodePortStart = "30000"
+
+	// the headless service was edited, the service of pod 5 has no pod anymore, another service is not owned
+	headless, _ := newRedisClusterService(cluster)
+	headless.Namespace = "ns"
+	headless.Spec.Ports[0].Port = 7000
+	headless.Labels["team"] = "a"
+	orphan, _ := newRedisPodService(cluster, 5, 30000)
+	orphan.Namespace = "ns"
+	foreign := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "foo-external-9", Namespace: "ns", Labels: map[string]string{rapi.ClusterNameLabelKey: "foo"}}}
+
+	kubeClient := kubefake.NewSimpleClientset()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, svc := range []*apiv1.Service{headless, orphan, foreign} {
+		kubeClient.CoreV1().Services("ns").Create(svc)
+		indexer.Add(svc)
+	}
+	pods := []*apiv1.Pod{}
+	for i, name := range []string{"pod0", "pod1"} {
+		labels, _ := pod.GetPodLabelsSet(cluster, int32(i))
+		pods = append(pods, &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels}})
+	}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{
+		serviceLister:  corev1listers.NewServiceLister(indexer),
+		serviceControl: NewServicesControl(kubeClient, recorder),
+		recorder:       recorder,
+	}
+
+	if err := c.syncServices(cluster, pods); err != nil {
+		t.Fatalf("syncServices() error = %v", err)
+	}
+
+	svcList, _ := kubeClient.CoreV1().Services("ns").List(metav1.ListOptions{})
+	services := map[string]apiv1.Service{}
+	names := []string{}
+	for _, svc := range svcList.Items {
+		services[svc.Name] = svc
+		names = append(names, svc.Name)
+	}
+	sort.Strings(names)
+	if want := []string{"foo", "foo-external-0", "foo-external-1", "foo-external-9"}; !equalStrings(names, want) {
+		t.Errorf("services = %v, want %v", names, want)
+	}
+	if svc := services["foo"]; svc.Spec.Ports[0].Port != 6379 || svc.Labels["team"] != "a" {
+		t.Errorf("headless service = %+v, want the port reverted and the label of the user kept", svc)
+	}
+	if svc := services["foo-external-1"]; svc.Spec.Type != apiv1.ServiceTypeNodePort || svc.Spec.Ports[0].NodePort != 30001 || svc.Spec.Selector[rapi.PodNoLabelKey] != "1" {
+		t.Errorf("pod service = %+v, want the node port 30001 selecting the pod 1", svc.Spec)
+	}
+}
+
+func TestController_syncServices_podNoLabels(t *testing.T) {
+	cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns", UID: "uid"}}
+	cluster.Spec.ServiceType = string(rapi.ServiceTypeExternal)
+	cluster.Spec.ServiceNodePortStart = "30000"
+
+	orphan, _ := newRedisPodService(cluster, 5, 30000)
+	orphan.Namespace = "ns"
+	kubeClient := kubefake.NewSimpleClientset(orphan)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(orphan)
+
+	// pod0 has the label of the previous versions, the label of pod1 has been edited
+	pods := []*apiv1.Pod{}
+	for name, podNo := range map[string]string{"pod0": string(rune(12)), "pod1": "none"} {
+		labels, _ := pod.GetLabelsSet(cluster)
+		labels[rapi.PodNoLabelKey] = podNo
+		p := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels}}
+		kubeClient.CoreV1().Pods("ns").Create(p)
+		pods = append(pods, p)
+	}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{
+		serviceLister:  corev1listers.NewServiceLister(indexer),
+		serviceControl: NewServicesControl(kubeClient, recorder),
+		podControl:     pod.NewRedisClusterControl(nil, kubeClient, recorder),
+		recorder:       recorder,
+	}
+
+	if err := c.syncServices(cluster, pods); err != nil {
+		t.Fatalf("syncServices() error = %v", err)
+	}
+	if p, _ := kubeClient.CoreV1().Pods("ns").Get("pod0", metav1.GetOptions{}); p.Labels[rapi.PodNoLabelKey] != "12" {
+		t.Errorf("pod0 labels = %v, want the decimal pod number", p.Labels)
+	}
+	if _, err := kubeClient.CoreV1().Services("ns").Get("foo-external-12", metav1.GetOptions{}); err != nil {
+		t.Errorf("the service of the relabeled pod should be created: %v", err)
+	}
+	if _, err := kubeClient.CoreV1().Services("ns").Get(orphan.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("the services should not be pruned while a pod-no label is invalid: %v", err)
+	}
+}
@@ -0,0 +1,552 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+)
+
+const (
+	// standbyMisalignedEventReason is the reason of the event emitted when the standby cluster and the source cluster
+	// don't have the same number of masters
+	standbyMisalignedEventReason = "StandbyMisaligned"
+	// standbyReplicatingEventReason is the reason of the event emitted when a standby master replicates a source master
+	standbyReplicatingEventReason = "StandbyReplicating"
+	// standbyReplicationFailedEventReason is the reason of the event emitted when a standby pod can't be configured
+	standbyReplicationFailedEventReason = "StandbyReplicationFailed"
+	// standbyDetachedEventReason is the reason of the event emitted when a standby master is detached from its source
+	// master by the promotion
+	standbyDetachedEventReason = "StandbyDetached"
+	// standbyPromotionFailedEventReason is the reason of the event emitted when a step of the promotion fails
+	standbyPromotionFailedEventReason = "StandbyPromotionFailed"
+	// standbyPromotedEventReason is the reason of the event emitted when the promoted standby cluster is formed
+	standbyPromotedEventReason = "StandbyPromoted"
+)
+
+// standbyShard associates a master of the source cluster with the pods replicating it: the standby master
+// replicates the source master, the replicas replicate the standby master
+type standbyShard struct {
+	source   *redis.Node
+	master   *apiv1.Pod
+	replicas []*apiv1.Pod
+}
+
+// getStandbySourceAdminKey returns the key of the admin of the source cluster in the admin pool
+func getStandbySourceAdminKey(clusterKey string) string {
+	return clusterKey + "/standby-source"
+}
+
+// getNumberOfStandbyPods returns the number of redis pods of a standby cluster: a standby master and its replicas
+// for each master of the source cluster
+func getNumberOfStandbyPods(cluster *rapi.RedisCluster) int32 {
+	return *cluster.Spec.NumberOfMaster * (1 + *cluster.Spec.ReplicationFactor)
+}
+
+// isStandbyPromoting returns true if the standby cluster has been detached from its source cluster by a promote
+// operation and the promoted cluster is not formed yet
+func isStandbyPromoting(cluster *rapi.RedisCluster) bool {
+	return cluster.Status.ReplicaOf != nil && cluster.Status.ReplicaOf.Phase == rapi.StandbyPhasePromoting
+}
+
+// syncStandby reconciles a standby cluster. Its redis nodes run standalone: a standby master replicates each master
+// of the source cluster with REPLICAOF and the other pods of the shard replicate the standby master. The cluster
+// stays read-only until a promote operation detaches it from the source cluster, then formPromotedCluster restarts
+// the redis nodes in cluster mode and forms the cluster from the shards.
+func (c *Controller) syncStandby(ctx context.Context, cluster *rapi.RedisCluster, pods []*apiv1.Pod) (bool, error) {
+	// the connections to the redis nodes are kept across the syncs
+	adminKey := cluster.Namespace + "/" + cluster.Name
+	admin := c.adminPool.Get(adminKey, pods).WithContext(ctx)
+	defer c.adminPool.Release(adminKey)
+
+	if isStandbyPromoting(cluster) {
+		return c.formPromotedCluster(admin, cluster, pods)
+	}
+
+	// the promote operation is the only one allowed on a standby cluster
+	operationDone, err := c.manageOperations(admin, cluster, nil)
+	if err != nil || operationDone {
+		return operationDone, err
+	}
+
+	// create the missing pods one by one
+	if int32(len(pods)) < getNumberOfStandbyPods(cluster) {
+		_, err = c.podControl.CreatePod(cluster, int32(len(pods)))
+		return true, err
+	}
+
+	sourceAdminKey := getStandbySourceAdminKey(adminKey)
+	sourceAdmin := c.adminPool.GetExternal(sourceAdminKey, cluster.Spec.ReplicaOf.Addrs).WithContext(ctx)
+	defer c.adminPool.Release(sourceAdminKey)
+	sourceInfos, err := sourceAdmin.GetClusterInfos()
+	if err != nil {
+		glog.Warningf("cluster %s/%s, partial view of the source cluster: %v", cluster.Namespace, cluster.Name, err)
+	}
+	sourceMasters := getSourceMasters(sourceInfos)
+	if len(sourceMasters) == 0 {
+		return false, fmt.Errorf("no master found in the source cluster %v", cluster.Spec.ReplicaOf.Addrs)
+	}
+	if int32(len(sourceMasters)) != *cluster.Spec.NumberOfMaster {
+		c.recorder.Eventf(cluster, apiv1.EventTypeWarning, standbyMisalignedEventReason, "The cluster has %d masters, the source cluster %d: the standby masters are not configured", *cluster.Spec.NumberOfMaster, len(sourceMasters))
+		return false, fmt.Errorf("the source cluster has %d masters, spec.numberOfMaster is %d", len(sourceMasters), *cluster.Spec.NumberOfMaster)
+	}
+
+	var previous []rapi.RedisClusterStandbyShardStatus
+	if cluster.Status.ReplicaOf != nil {
+		previous = cluster.Status.ReplicaOf.Shards
+	}
+	shards := assignStandbyShards(sourceMasters, pods, previous, *cluster.Spec.ReplicationFactor)
+	nodes := getReplicationNodes(admin, pods)
+	configured, errs := c.configureStandbyShards(admin, cluster, shards, nodes)
+
+	if int32(len(pods)) > getNumberOfStandbyPods(cluster) {
+		if pod := selectUnassignedStandbyPod(shards, pods); pod != nil {
+			glog.Infof("cluster %s/%s, scale down, deleting standby pod %s", cluster.Namespace, cluster.Name, pod.Name)
+			if err = c.podControl.DeletePod(cluster, pod.Name); err != nil {
+				errs = append(errs, err)
+			}
+			configured = true
+		}
+	}
+
+	status := &rapi.RedisClusterReplicaOfStatus{
+		Phase:  rapi.StandbyPhaseReplicating,
+		Shards: buildStandbyShardStatuses(shards, nodes, getSourceReplicationInfos(sourceAdmin, shards)),
+	}
+	updated := !reflect.DeepEqual(status, cluster.Status.ReplicaOf)
+	cluster.Status.ReplicaOf = status
+	cluster.Status.Cluster.NbPods = int32(len(pods))
+	cluster.Status.Cluster.NbRedisRunning = int32(len(nodes))
+	ok := isStandbyHealthy(cluster, status)
+	clusterStatus := rapi.ClusterStatusKO
+	if ok {
+		clusterStatus = rapi.ClusterStatusOK
+	}
+	if cluster.Status.Cluster.Status != clusterStatus {
+		cluster.Status.Cluster.Status = clusterStatus
+		updated = true
+	}
+	if setClusterStatusCondition(&cluster.Status, ok) {
+		updated = true
+	}
+	if updated {
+		if _, err = c.updateHandler(cluster); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return configured || updated, errors.NewAggregate(errs)
+}
+
+// configureStandbyShards makes each standby master replicate its source master and the replicas of the shard
+// replicate the standby master. Returns true if a redis node has been reconfigured.
+func (c *Controller) configureStandbyShards(admin redis.AdminInterface, cluster *rapi.RedisCluster, shards []standbyShard, nodes []*replicationNode) (bool, []error) {
+	configured := false
+	var errs []error
+	nodesByPod := getReplicationNodesByPod(nodes)
+	for _, shard := range shards {
+		if shard.master == nil {
+			continue
+		}
+		master := nodesByPod[shard.master.Name]
+		if master == nil {
+			continue
+		}
+		sourceAddr := shard.source.IPPort()
+		if !master.info.IsReplicaOf(sourceAddr) {
+			glog.Infof("cluster %s/%s, %s (pod %s) replicates source master %s", cluster.Namespace, cluster.Name, master.addr, master.pod.Name, sourceAddr)
+			if err := admin.SetReplicaOf(master.addr, shard.source.IP, shard.source.Port); err != nil {
+				c.recorder.Eventf(cluster, apiv1.EventTypeWarning, standbyReplicationFailedEventReason, "Unable to make pod %s replicate source master %s: %v", master.pod.Name, sourceAddr, err)
+				errs = append(errs, err)
+				continue
+			}
+			c.recorder.Eventf(cluster, apiv1.EventTypeNormal, standbyReplicatingEventReason, "Pod %s replicates source master %s (slots %s)", master.pod.Name, sourceAddr, formatSlotRanges(shard.source.Slots))
+			configured = true
+		}
+
+		masterIP, masterPort, _ := net.SplitHostPort(master.addr)
+		for _, pod := range shard.replicas {
+			replica := nodesByPod[pod.Name]
+			if replica == nil || replica.info.IsReplicaOf(master.addr) {
+				continue
+			}
+			glog.Infof("cluster %s/%s, attaching %s (pod %s) to standby master %s", cluster.Namespace, cluster.Name, replica.addr, pod.Name, master.addr)
+			if err := admin.SetReplicaOf(replica.addr, masterIP, masterPort); err != nil {
+				c.recorder.Eventf(cluster, apiv1.EventTypeWarning, standbyReplicationFailedEventReason, "Unable to attach pod %s to standby master %s: %v", pod.Name, master.pod.Name, err)
+				errs = append(errs, err)
+				continue
+			}
+			c.recorder.Eventf(cluster, apiv1.EventTypeNormal, replicaAttachedEventReason, "Pod %s attached to standby master %s (pod %s)", pod.Name, master.addr, master.pod.Name)
+			configured = true
+		}
+	}
+	return configured, errs
+}
+
+// promoteStandby starts the promotion of the standby cluster, executed by the promote operation: formPromotedCluster
+// detaches the standby masters from the source masters and restarts the redis nodes in cluster mode
+func promoteStandby(cluster *rapi.RedisCluster) error {
+	if !rapi.IsStandbyRedisCluster(cluster) {
+		return fmt.Errorf("the cluster is not a standby cluster, spec.replicaOf is not set or already promoted")
+	}
+	if isStandbyPromoting(cluster) {
+		return fmt.Errorf("the standby cluster is already being promoted")
+	}
+	if cluster.Status.ReplicaOf == nil || int32(len(cluster.Status.ReplicaOf.Shards)) != *cluster.Spec.NumberOfMaster {
+		return fmt.Errorf("the shards of the standby cluster are not configured yet")
+	}
+	for _, shard := range cluster.Status.ReplicaOf.Shards {
+		if shard.MasterPodName == "" {
+			return fmt.Errorf("no standby master replicates source master %s", shard.SourceMaster)
+		}
+	}
+	now := metav1.Now()
+	cluster.Status.ReplicaOf.Phase = rapi.StandbyPhasePromoting
+	cluster.Status.ReplicaOf.PromotionTime = &now
+	return nil
+}
+
+// formPromotedCluster forms the redis cluster from the shards of a promoted standby cluster, the source cluster may
+// be unreachable. Each step is checked at each sync, so that the promotion resumes after an error. The standby
+// masters are detached from the source masters: they keep their keys and accept the writes. Then the pods are
+// annotated with their role and the redis nodes restart in cluster mode, the standby masters with their keys and the
+// replicas empty. Then the redis nodes meet, each standby master owns the slots of its source master and the replicas
+// replicate it. Once formed the standby cluster is Promoted and managed as any cluster.
+func (c *Controller) formPromotedCluster(admin redis.AdminInterface, cluster *rapi.RedisCluster, pods []*apiv1.Pod) (bool, error) {
+	podsByName := map[string]*apiv1.Pod{}
+	for _, pod := range pods {
+		podsByName[pod.Name] = pod
+	}
+	shards := cluster.Status.ReplicaOf.Shards
+	masters := map[string]bool{}
+	for _, shard := range shards {
+		pod := podsByName[shard.MasterPodName]
+		if pod == nil || pod.Status.PodIP == "" {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, standbyPromotionFailedEventReason, "Standby master pod %s of source master %s not found", shard.MasterPodName, shard.SourceMaster)
+			return false, fmt.Errorf("standby master pod %s of source master %s not found", shard.MasterPodName, shard.SourceMaster)
+		}
+		masters[pod.Name] = true
+	}
+
+	// the standby masters are detached before any redis node restarts
+	for _, shard := range shards {
+		pod := podsByName[shard.MasterPodName]
+		if pod.Annotations[rapi.StandbyRoleAnnotationKey] != "" {
+			continue
+		}
+		addr := net.JoinHostPort(pod.Status.PodIP, getRedisPort(pod))
+		info, err := admin.GetReplicationInfo(addr)
+		if err != nil {
+			return false, err
+		}
+		if info.IsMaster() {
+			continue
+		}
+		if err = admin.SetReplicaOf(addr, "", ""); err != nil {
+			c.recorder.Eventf(cluster, apiv1.EventTypeWarning, standbyPromotionFailedEventReason, "Unable to detach pod %s from source master %s: %v", pod.Name, shard.SourceMaster, err)
+			return false, err
+		}
+		c.recorder.Eventf(cluster, apiv1.EventTypeNormal, standbyDetachedEventReason, "Pod %s detached from source master %s (slots %s)", pod.Name, shard.SourceMaster, strings.Join(shard.Slots, ","))
+	}
+
+	// the pods that are not a standby master restart empty
+	for _, pod := range pods {
+		role := rapi.StandbyRoleReplica
+		if masters[pod.Name] {
+			role = rapi.StandbyRoleMaster
+		}
+		if _, err := c.podControl.SetStandbyRole(cluster, pod, role); err != nil {
+			return false, err
+		}
+	}
+
+	formed, err := formPromotedShards(admin, shards, podsByName)
+	if err != nil {
+		c.recorder.Eventf(cluster, apiv1.EventTypeWarning, standbyPromotionFailedEventReason, "Unable to form the promoted cluster: %v", err)
+		return false, err
+	}
+	if !formed {
+		glog.Infof("cluster %s/%s, waiting for the promoted cluster to be formed", cluster.Namespace, cluster.Name)
+		return true, nil
+	}
+
+	cluster.Status.ReplicaOf.Phase = rapi.StandbyPhasePromoted
+	c.recorder.Eventf(cluster, apiv1.EventTypeNormal, standbyPromotedEventReason, "Standby cluster promoted: %d masters own the slots of the source masters", len(shards))
+	if _, err = c.updateHandler(cluster); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// formPromotedShards makes the redis nodes restarted in cluster mode meet, assigns to each standby master the slots
+// of its source master and attaches the replicas to it. Returns true once the cluster is formed.
+func formPromotedShards(admin redis.AdminInterface, shards []rapi.RedisClusterStandbyShardStatus, podsByName map[string]*apiv1.Pod) (bool, error) {
+	infos, _ := admin.GetClusterInfos()
+	nodesByPod := map[string]*redis.NodeInfos{}
+	for name, pod := range podsByName {
+		if pod.Status.PodIP == "" {
+			return false, nil
+		}
+		nodeInfos := infos.Infos[net.JoinHostPort(pod.Status.PodIP, getRedisPort(pod))]
+		if nodeInfos == nil || nodeInfos.Node == nil {
+			// still running standalone
+			return false, nil
+		}
+		nodesByPod[name] = nodeInfos
+	}
+
+	met := true
+	for _, nodeInfos := range nodesByPod {
+		if len(nodeInfos.Friends) >= len(nodesByPod)-1 {
+			continue
+		}
+		met = false
+		if err := admin.AttachNodeToCluster(nodeInfos.Node.IPPort()); err != nil {
+			return false, err
+		}
+	}
+	if !met {
+		return false, nil
+	}
+
+	formed := true
+	for _, shard := range shards {
+		master := nodesByPod[shard.MasterPodName].Node
+		slots, err := decodeSlotRanges(shard.Slots)
+		if err != nil {
+			return false, err
+		}
+		if missing := redis.RemoveSlots(slots, master.Slots); len(missing) > 0 {
+			formed = false
+			if err = admin.AddSlots(master.IPPort(), missing); err != nil {
+				return false, err
+			}
+		}
+		for _, name := range shard.ReplicaPodNames {
+			replicaInfos := nodesByPod[name]
+			if replicaInfos == nil || replicaInfos.Node.MasterReferent == master.ID {
+				continue
+			}
+			formed = false
+			if err = admin.AttachSlaveToMaster(replicaInfos.Node, master); err != nil {
+				return false, err
+			}
+		}
+	}
+	return formed, nil
+}
+
+// assignStandbyShards assigns a standby master and ReplicationFactor replicas to each source master. The pods keep
+// the shard recorded in the status while it replicates the same slots, a replica replaces a lost standby master
+// since it has the keys, and the other pods are assigned by name.
+func assignStandbyShards(sourceMasters redis.Nodes, pods []*apiv1.Pod, previous []rapi.RedisClusterStandbyShardStatus, replicationFactor int32) []standbyShard {
+	sorted := append([]*apiv1.Pod(nil), pods...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	podsByName := map[string]*apiv1.Pod{}
+	for _, pod := range sorted {
+		podsByName[pod.Name] = pod
+	}
+	assigned := map[string]bool{}
+	take := func(name string) *apiv1.Pod {
+		pod := podsByName[name]
+		if pod == nil || assigned[name] {
+			return nil
+		}
+		assigned[name] = true
+		return pod
+	}
+
+	sources := append(redis.Nodes(nil), sourceMasters...)
+	sort.SliceStable(sources, func(i, j int) bool { return firstSlot(sources[i]) < firstSlot(sources[j]) })
+	shards := make([]standbyShard, len(sources))
+	for i, source := range sources {
+		shards[i].source = source
+		slots := formatSlotRanges(source.Slots)
+		for _, prev := range previous {
+			if strings.Join(prev.Slots, ",") != slots {
+				continue
+			}
+			shards[i].master = take(prev.MasterPodName)
+			for _, name := range prev.ReplicaPodNames {
+				if pod := take(name); pod != nil && int32(len(shards[i].replicas)) < replicationFactor {
+					shards[i].replicas = append(shards[i].replicas, pod)
+				}
+			}
+			break
+		}
+	}
+
+	next := func() *apiv1.Pod {
+		for _, pod := range sorted {
+			if p := take(pod.Name); p != nil {
+				return p
+			}
+		}
+		return nil
+	}
+	for i := range shards {
+		if shards[i].master != nil {
+			continue
+		}
+		if len(shards[i].replicas) > 0 {
+			shards[i].master, shards[i].replicas = shards[i].replicas[0], shards[i].replicas[1:]
+		} else {
+			shards[i].master = next()
+		}
+	}
+	for i := range shards {
+		for int32(len(shards[i].replicas)) < replicationFactor {
+			pod := next()
+			if pod == nil {
+				break
+			}
+			shards[i].replicas = append(shards[i].replicas, pod)
+		}
+	}
+	return shards
+}
+
+// selectUnassignedStandbyPod returns the last pod by name that is not assigned to a shard
+func selectUnassignedStandbyPod(shards []standbyShard, pods []*apiv1.Pod) *apiv1.Pod {
+	assigned := map[string]bool{}
+	for _, shard := range shards {
+		if shard.master != nil {
+			assigned[shard.master.Name] = true
+		}
+		for _, pod := range shard.replicas {
+			assigned[pod.Name] = true
+		}
+	}
+	var selected *apiv1.Pod
+	for _, pod := range pods {
+		if !assigned[pod.Name] && (selected == nil || pod.Name > selected.Name) {
+			selected = pod
+		}
+	}
+	return selected
+}
+
+// getSourceReplicationInfos returns the replication info of the source masters that answer, by address
+func getSourceReplicationInfos(sourceAdmin redis.AdminInterface, shards []standbyShard) map[string]*redis.ReplicationInfo {
+	infos := map[string]*redis.ReplicationInfo{}
+	for _, shard := range shards {
+		addr := shard.source.IPPort()
+		info, err := sourceAdmin.GetReplicationInfo(addr)
+		if err != nil {
+			glog.V(3).Infof("unable to retrieve the replication info of source master %s: %v", addr, err)
+			continue
+		}
+		infos[addr] = info
+	}
+	return infos
+}
+
+// buildStandbyShardStatuses builds the status of each shard with the replication link of its standby master
+func buildStandbyShardStatuses(shards []standbyShard, nodes []*replicationNode, sourceInfos map[string]*redis.ReplicationInfo) []rapi.RedisClusterStandbyShardStatus {
+	nodesByPod := getReplicationNodesByPod(nodes)
+	statuses := []rapi.RedisClusterStandbyShardStatus{}
+	for _, shard := range shards {
+		status := rapi.RedisClusterStandbyShardStatus{SourceMaster: shard.source.IPPort()}
+		for _, slot := range redis.SlotRangesFromSlots(shard.source.Slots) {
+			status.Slots = append(status.Slots, slot.String())
+		}
+		if shard.master != nil {
+			status.MasterPodName = shard.master.Name
+			if master := nodesByPod[shard.master.Name]; master != nil && master.info.IsReplicaOf(status.SourceMaster) {
+				status.MasterLinkStatus = master.info.MasterLinkStatus
+				if sourceInfo := sourceInfos[status.SourceMaster]; sourceInfo != nil {
+					status.Lag = master.info.Lag(sourceInfo)
+				}
+			}
+		}
+		for _, pod := range shard.replicas {
+			status.ReplicaPodNames = append(status.ReplicaPodNames, pod.Name)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// isStandbyHealthy returns true if each standby master replicates its source master and has all its replicas
+func isStandbyHealthy(cluster *rapi.RedisCluster, status *rapi.RedisClusterReplicaOfStatus) bool {
+	if int32(len(status.Shards)) != *cluster.Spec.NumberOfMaster {
+		return false
+	}
+	for _, shard := range status.Shards {
+		if shard.MasterLinkStatus != redis.RedisMasterLinkStatusUp || int32(len(shard.ReplicaPodNames)) < *cluster.Spec.ReplicationFactor {
+			return false
+		}
+	}
+	return true
+}
+
+func getReplicationNodesByPod(nodes []*replicationNode) map[string]*replicationNode {
+	nodesByPod := map[string]*replicationNode{}
+	for _, node := range nodes {
+		nodesByPod[node.pod.Name] = node
+	}
+	return nodesByPod
+}
+
+// getSourceMasters returns the masters with slots known by the reachable nodes of the source cluster
+func getSourceMasters(infos *redis.ClusterInfos) redis.Nodes {
+	masters := redis.Nodes{}
+	if infos == nil {
+		return masters
+	}
+	found := map[string]bool{}
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil || nodeinfos.Node == nil {
+			continue
+		}
+		for _, node := range append(redis.Nodes{nodeinfos.Node}, nodeinfos.Friends...) {
+			if found[node.ID] || !redis.IsMasterWithSlot(node) || node.HasStatus(redis.NodeStatusFail) {
+				continue
+			}
+			found[node.ID] = true
+			masters = append(masters, node)
+		}
+	}
+	return masters.SortNodes()
+}
+
+func formatSlotRanges(slots []redis.Slot) string {
+	ranges := []string{}
+	for _, slot := range redis.SlotRangesFromSlots(slots) {
+		ranges = append(ranges, slot.String())
+	}
+	return strings.Join(ranges, ",")
+}
+
+func decodeSlotRanges(ranges []string) ([]redis.Slot, error) {
+	slots := []redis.Slot{}
+	for _, r := range ranges {
+		decoded, _, _, err := redis.DecodeSlotRange(r)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, decoded...)
+	}
+	return slots, nil
+}
+
+func firstSlot(node *redis.Node) redis.Slot {
+	first := redis.Slot(0)
+	for i, slot := range node.Slots {
+		if i == 0 || slot < first {
+			first = slot
+		}
+	}
+	return first
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+	"github.com/zh168654/Redis-Operator/pkg/redis"
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake/admin"
+)
+
+func newMasterNode(id, ip string, slots ...redis.Slot) *redis.Node {
+	node := redis.NewNode(id, ip, nil)
+	node.Port = "6379"
+	node.Role = "master"
+	node.Slots = slots
+	return node
+}
+
+func newStandbyPod(name, ip string) *kapiv1.Pod {
+	return &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: kapiv1.PodStatus{PodIP: ip}}
+}
+
+func Test_assignStandbyShards(t *testing.T) {
+	source1 := newMasterNode("source1", "10.0.0.1", 0, 1, 2)
+	source2 := newMasterNode("source2", "10.0.0.2", 3, 4, 5)
+	pods := []*kapiv1.Pod{newStandbyPod("pod-d", ""), newStandbyPod("pod-c", ""), newStandbyPod("pod-b", ""), newStandbyPod("pod-a", "")}
+	names := func(shards []standbyShard) [][]string {
+		got := [][]string{}
+		for _, shard := range shards {
+			shardNames := []string{shard.source.ID, shard.master.Name}
+			for _, pod := range shard.replicas {
+				shardNames = append(shardNames, pod.Name)
+			}
+			got = append(got, shardNames)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name     string
+		pods     []*kapiv1.Pod
+		previous []rapi.RedisClusterStandbyShardStatus
+		want     [][]string
+	}{
+		{
+			name: "standby masters assigned first, by name",
+			pods: pods,
+			want: [][]string{{"source1", "pod-a", "pod-c"}, {"source2", "pod-b", "pod-d"}},
+		},
+		{
+			name: "previous assignment kept",
+			pods: pods,
+			previous: []rapi.RedisClusterStandbyShardStatus{
+				{Slots: []string{"0-2"}, MasterPodName: "pod-d", ReplicaPodNames: []string{"pod-a"}},
+			},
+			want: [][]string{{"source1", "pod-d", "pod-a"}, {"source2", "pod-b", "pod-c"}},
+		},
+		{
+			name: "replica replaces the lost standby master",
+			pods: pods[1:],
+			previous: []rapi.RedisClusterStandbyShardStatus{
+				{Slots: []string{"3-5"}, MasterPodName: "pod-d", ReplicaPodNames: []string{"pod-c"}},
+			},
+			want: [][]string{{"source1", "pod-a", "pod-b"}, {"source2", "pod-c"}},
+		},
+		{
+			name: "assignment of other slots not kept",
+			pods: pods,
+			previous: []rapi.RedisClusterStandbyShardStatus{
+				{Slots: []string{"0-1"}, MasterPodName: "pod-d", ReplicaPodNames: []string{"pod-c"}},
+			},
+			want: [][]string{{"source1", "pod-a", "pod-c"}, {"source2", "pod-b", "pod-d"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shards := assignStandbyShards(redis.Nodes{source2, source1}, tt.pods, tt.previous, 1)
+			if got := names(shards); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("assignStandbyShards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buildStandbyShardStatuses(t *testing.T) {
+	shards := []standbyShard{
+		{source: newMasterNode("source1", "10.0.0.1", 0, 1, 2), master: newStandbyPod("pod-a", "10.1.0.1"), replicas: []*kapiv1.Pod{newStandbyPod("pod-b", "10.1.0.2")}},
+		{source: newMasterNode("source2", "10.0.0.2", 3, 4, 5), master: newStandbyPod("pod-c", "10.1.0.3")},
+	}
+	nodes := []*replicationNode{
+		newReplicationNode("pod-a", "10.1.0.1:6379", &redis.ReplicationInfo{Role: "slave", MasterHost: "10.0.0.1", MasterPort: "6379", MasterLinkStatus: "up", SlaveReplOffset: 90}),
+		newReplicationNode("pod-c", "10.1.0.3:6379", &redis.ReplicationInfo{Role: "master"}),
+	}
+	sourceInfos := map[string]*redis.ReplicationInfo{"10.0.0.1:6379": {Role: "master", MasterReplOffset: 100}}
+
+	statuses := buildStandbyShardStatuses(shards, nodes, sourceInfos)
+	want := []rapi.RedisClusterStandbyShardStatus{
+		{SourceMaster: "10.0.0.1:6379", Slots: []string{"0-2"}, MasterPodName: "pod-a", ReplicaPodNames: []string{"pod-b"}, MasterLinkStatus: "up", Lag: 10},
+		{SourceMaster: "10.0.0.2:6379", Slots: []string{"3-5"}, MasterPodName: "pod-c"},
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("buildStandbyShardStatuses() = %v, want %v", statuses, want)
+	}
+
+	cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(2), ReplicationFactor: rapi.NewInt32(1)}}
+	if isStandbyHealthy(cluster, &rapi.RedisClusterReplicaOfStatus{Shards: statuses}) {
+		t.Errorf("isStandbyHealthy() should be false, the second standby master doesn't replicate its source master")
+	}
+	statuses[1].MasterLinkStatus = "up"
+	statuses[1].ReplicaPodNames = []string{"pod-d"}
+	if !isStandbyHealthy(cluster, &rapi.RedisClusterReplicaOfStatus{Shards: statuses}) {
+		t.Errorf("isStandbyHealthy() should be true")
+	}
+}
+
+func Test_promoteStandby(t *testing.T) {
+	cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{NumberOfMaster: rapi.NewInt32(1)}}
+	if err := promoteStandby(cluster); err == nil {
+		t.Errorf("promoteStandby() should fail without spec.replicaOf")
+	}
+
+	cluster.Spec.ReplicaOf = &rapi.RedisClusterReplicaOf{Addrs: []string{"10.0.0.1:6379"}}
+	cluster.Status.ReplicaOf = &rapi.RedisClusterReplicaOfStatus{Phase: rapi.StandbyPhaseReplicating}
+	if err := promoteStandby(cluster); err == nil {
+		t.Errorf("promoteStandby() should fail if the shards are not configured")
+	}
+
+	cluster.Status.ReplicaOf.Shards = []rapi.RedisClusterStandbyShardStatus{{SourceMaster: "10.0.0.1:6379", MasterPodName: "pod-a"}}
+	if err := promoteStandby(cluster); err != nil {
+		t.Fatalf("promoteStandby() unexpected error: %v", err)
+	}
+	if !isStandbyPromoting(cluster) || cluster.Status.ReplicaOf.PromotionTime == nil {
+		t.Errorf("promoteStandby() unexpected status %v", cluster.Status.ReplicaOf)
+	}
+	if err := promoteStandby(cluster); err == nil {
+		t.Errorf("promoteStandby() should fail if already promoting")
+	}
+
+	cluster.Status.ReplicaOf.Phase = rapi.StandbyPhasePromoted
+	if rapi.IsStandbyRedisCluster(cluster) {
+		t.Errorf("IsStandbyRedisCluster() should be false once promoted")
+	}
+}
+
+func Test_formPromotedShards(t *testing.T) {
+	podsByName := map[string]*kapiv1.Pod{
+		"pod-a": newStandbyPod("pod-a", "10.1.0.1"),
+		"pod-b": newStandbyPod("pod-b", "10.1.0.2"),
+	}
+	shards := []rapi.RedisClusterStandbyShardStatus{{SourceMaster: "10.0.0.1:6379", Slots: []string{"0-2"}, MasterPodName: "pod-a", ReplicaPodNames: []string{"pod-b"}}}
+	newInfos := func(master, replica *redis.Node) *redis.ClusterInfos {
+		infos := redis.NewClusterInfos()
+		infos.Infos["10.1.0.1:6379"] = &redis.NodeInfos{Node: master, Friends: redis.Nodes{replica}}
+		infos.Infos["10.1.0.2:6379"] = &redis.NodeInfos{Node: replica, Friends: redis.Nodes{master}}
+		return infos
+	}
+	master := newMasterNode("master", "10.1.0.1", 0, 1)
+	replica := newMasterNode("replica", "10.1.0.2")
+
+	tests := []struct {
+		name  string
+		infos *redis.ClusterInfos
+		want  bool
+	}{
+		{
+			name: "redis node still standalone",
+			infos: &redis.ClusterInfos{Infos: map[string]*redis.NodeInfos{
+				"10.1.0.1:6379": {Node: master},
+				"10.1.0.2:6379": nil,
+			}},
+			want: false,
+		},
+		{
+			name: "redis nodes not met",
+			infos: &redis.ClusterInfos{Infos: map[string]*redis.NodeInfos{
+				"10.1.0.1:6379": {Node: master},
+				"10.1.0.2:6379": {Node: replica},
+			}},
+			want: false,
+		},
+		{
+			name:  "slots missing and replica not attached",
+			infos: newInfos(master, replica),
+			want:  false,
+		},
+		{
+			name: "cluster formed",
+			infos: newInfos(newMasterNode("master", "10.1.0.1", 0, 1, 2), &redis.Node{
+				ID: "replica", IP: "10.1.0.2", Port: "6379", Role: "slave", MasterReferent: "master",
+			}),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAdmin := admin.NewFakeAdmin([]string{"10.1.0.1:6379", "10.1.0.2:6379"})
+			fakeAdmin.GetClusterInfosRet = admin.ClusterInfosRetType{ClusterInfos: tt.infos}
+			got, err := formPromotedShards(fakeAdmin, shards, podsByName)
+			if err != nil {
+				t.Fatalf("formPromotedShards() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("formPromotedShards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,37 +3,50 @@ package controller
 import (
 	"errors"
 	"fmt"
-	"net"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
 
 	"github.com/zh168654/Redis-Operator/pkg/config"
+	podctrl "github.com/zh168654/Redis-Operator/pkg/controller/pod"
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 )
 
 // NewRedisAdmin builds and returns new redis.Admin from the list of pods
 func NewRedisAdmin(pods []*apiv1.Pod, cfg *config.Redis) (redis.AdminInterface, error) {
-	nodesAddrs := []string{}
-	for _, pod := range pods {
-		redisPort := redis.DefaultRedisPort
-		for _, container := range pod.Spec.Containers {
-			if container.Name == "redis-node" {
-				for _, port := range container.Ports {
-					if port.Name == "redis" {
-						redisPort = fmt.Sprintf("%d", port.ContainerPort)
-					}
-				}
-			}
-		}
-		nodesAddrs = append(nodesAddrs, net.JoinHostPort(pod.Status.PodIP, redisPort))
-	}
-	adminConfig := redis.AdminOptions{
+	return redis.NewAdmin(getPodsAddrs(pods), newAdminOptions(cfg)), nil
+}
+
+// newAdminOptions returns the options of the admins of the redis nodes managed by the operator
+func newAdminOptions(cfg *config.Redis) *redis.AdminOptions {
+	return &redis.AdminOptions{
 		ConnectionTimeout:  time.Duration(cfg.DialTimeout) * time.Millisecond,
 		RenameCommandsFile: cfg.GetRenameCommandsFile(),
+		RetryAttempts:      cfg.RetryAttempts,
+		BreakerThreshold:   cfg.CircuitBreakerThreshold,
+		BreakerCooldown:    time.Duration(cfg.CircuitBreakerCooldown) * time.Millisecond,
 	}
+}
 
-	return redis.NewAdmin(nodesAddrs, &adminConfig), nil
+// newExternalAdminOptions returns the options of the admins of a redis not managed by the operator,
+// its commands are not renamed
+func newExternalAdminOptions(cfg *config.Redis) *redis.AdminOptions {
+	return &redis.AdminOptions{ConnectionTimeout: time.Duration(cfg.DialTimeout) * time.Millisecond}
+}
+
+// getRedisPort returns the redis port declared by the redis-node container of the pod
+func getRedisPort(pod *apiv1.Pod) string {
+	redisPort := redis.DefaultRedisPort
+	for _, container := range pod.Spec.Containers {
+		if container.Name == podctrl.RedisNodeContainerName {
+			for _, port := range container.Ports {
+				if port.Name == "redis" {
+					redisPort = fmt.Sprintf("%d", port.ContainerPort)
+				}
+			}
+		}
+	}
+	return redisPort
 }
 
 // IsPodReady check if pod is in ready condition, return the error message otherwise
@@ -12,6 +12,8 @@ type Config struct {
 	Master         string
 	ListenAddr     string
 	Redis          config.Redis
+	SanityChecks   config.SanityChecks
+	Audit          config.Audit
 }
 
 // NewRedisOperatorConfig builds and returns a redis-operator Config
@@ -26,4 +28,6 @@ func (c *Config) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.Master, "master", c.Master, "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	fs.StringVar(&c.ListenAddr, "addr", "0.0.0.0:8086", "listen address of the http server which serves kubernetes probes and prometheus endpoints")
 	c.Redis.AddFlags(fs)
+	c.SanityChecks.AddFlags(fs)
+	c.Audit.AddFlags(fs)
 }
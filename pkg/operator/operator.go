@@ -55,6 +55,10 @@ func NewRedisOperator(cfg *Config) *RedisOperator {
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		glog.Fatalf("Unable to define RedisCluster resource:%v", err)
 	}
+	_, err = rclient.DefineRedisClusterImportResource(extClient)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		glog.Fatalf("Unable to define RedisClusterImport resource:%v", err)
+	}
 
 	kubeClient, err := clientset.NewForConfig(kubeConfig)
 	if err != nil {
@@ -72,7 +76,7 @@ func NewRedisOperator(cfg *Config) *RedisOperator {
 	op := &RedisOperator{
 		kubeInformerFactory:  kubeInformerFactory,
 		redisInformerFactory: redisInformerFactory,
-		controller:           controller.NewController(controller.NewConfig(1, cfg.Redis), kubeClient, redisClient, kubeInformerFactory, redisInformerFactory),
+		controller:           controller.NewController(controller.NewConfig(1, cfg.Redis, cfg.SanityChecks, cfg.Audit), kubeClient, redisClient, kubeInformerFactory, redisInformerFactory),
 		GC:                   garbagecollector.NewGarbageCollector(redisClient, kubeClient, redisInformerFactory),
 	}
 
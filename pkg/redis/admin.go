@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strconv"
@@ -26,6 +27,10 @@ type AdminInterface interface {
 	Connections() AdminConnectionsInterface
 	// Close the admin connections
 	Close()
+	// WithContext returns an admin sharing the connections, whose operations stop once the context is done
+	WithContext(ctx context.Context) AdminInterface
+	// Context returns the context of the admin, context.Background() if the admin is not bound to a context
+	Context() context.Context
 	// InitRedisCluster used to configure the first node of a cluster
 	InitRedisCluster(addr string) error
 	// GetClusterInfos get node infos for all nodes
@@ -39,8 +44,10 @@ type AdminInterface interface {
 	AttachSlaveToMaster(slave *Node, master *Node) error
 	// DetachSlave dettach a slave to its master
 	DetachSlave(slave *Node) error
-	// StartFailover execute the failover of the Redis Master corresponding to the addr
-	StartFailover(addr string) error
+	// StartFailover execute the failover of the Redis Master corresponding to the addr, options can be nil
+	StartFailover(addr string, options *FailoverOptions) error
+	// BumpConfigEpoch exec the CLUSTER BUMPEPOCH command: the node gets a config epoch greater than the ones it knows
+	BumpConfigEpoch(addr string) error
 	// ForgetNode execute the Redis command to force the cluster to forgot the the Node
 	ForgetNode(id string) error
 	// ForgetNodeByAddr execute the Redis command to force the cluster to forgot the the Node
@@ -56,10 +63,32 @@ type AdminInterface interface {
 	GetKeysInSlot(addr string, slot Slot, batch int, limit bool) ([]string, error)
 	// CountKeysInSlot exec the redis command to count the keys given slot on the node
 	CountKeysInSlot(addr string, slot Slot) (int64, error)
+	// GetReplicationInfo exec the INFO REPLICATION redis command on the node and decode it
+	GetReplicationInfo(addr string) (*ReplicationInfo, error)
+	// GetServerInfo exec the INFO redis command on the node and decode it
+	GetServerInfo(addr string) (*ServerInfo, error)
+	// SetReplicaOf configures the node as a replica of the master, or as a master if masterIP is empty
+	SetReplicaOf(addr, masterIP, masterPort string) error
+	// SentinelGetMasterAddr returns the address of the master monitored by the sentinel, empty if not monitored
+	SentinelGetMasterAddr(addr, name string) (string, error)
+	// SentinelMonitor makes the sentinel monitor the master under the given name
+	SentinelMonitor(addr, name, masterIP, masterPort string, quorum int32) error
+	// ScanKeys runs one iteration of the SCAN command on the node, returns the next cursor and the keys
+	ScanKeys(addr string, cursor string, count int) (string, []string, error)
+	// DumpKeys serializes the keys of the node with the DUMP command
+	DumpKeys(addr string, keys []string) ([]KeyDump, error)
+	// RestoreKeys restores the serialized keys on the node with the RESTORE REPLACE command
+	RestoreKeys(addr string, dumps []KeyDump) error
+	// DeleteKeys deletes the keys on the node
+	DeleteKeys(addr string, keys []string) error
 	// MigrateKeys from addr to destination node. returns number of slot migrated. If replace is true, replace key on busy error
 	MigrateKeys(addr string, dest *Node, slots []Slot, batch, timeout int, replace bool) (int, error)
 	// FlushAndReset reset the cluster configuration of the node, the node is flushed in the same pipe to ensure reset works
 	FlushAndReset(addr string, mode string) error
+	// GetPersistenceInfo exec the INFO PERSISTENCE redis command on the node and decode it
+	GetPersistenceInfo(addr string) (*PersistenceInfo, error)
+	// BackupNode dumps the keys of the node with BGSAVE in the given file of its working directory
+	BackupNode(addr, filename string, timeout time.Duration) error
 	// FlushAll flush all keys in cluster
 	FlushAll()
 	// GetHashMaxSlot get the max slot value
@@ -73,12 +102,30 @@ type AdminOptions struct {
 	ConnectionTimeout  time.Duration
 	ClientName         string
 	RenameCommandsFile string
+	// Parallelism is the maximum number of nodes queried at the same time by the commands sent to all the nodes
+	Parallelism int
+	// CallTimeout is the time given to each node to answer the commands sent to all the nodes
+	CallTimeout time.Duration
+	// RetryAttempts is the number of times an idempotent command is sent to a node while it fails with a
+	// retryable error, 1 disables the retries
+	RetryAttempts int
+	// RetryBackoff is the wait before the first retry, doubled at each retry up to MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// BreakerThreshold is the number of consecutive network failures after which no command is sent to a node
+	// during BreakerCooldown, the circuit breaker is disabled if negative
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
 }
 
 // Admin wraps redis cluster admin logic
 type Admin struct {
 	hashMaxSlots Slot
 	cnx          AdminConnectionsInterface
+	parallelism  int
+	callTimeout  time.Duration
+	retryPolicy  retryPolicy
+	ctx          context.Context
 }
 
 // NewAdmin returns new AdminInterface instance
@@ -86,6 +133,20 @@ type Admin struct {
 func NewAdmin(addrs []string, options *AdminOptions) AdminInterface {
 	a := &Admin{
 		hashMaxSlots: defaultHashMaxSlots,
+		parallelism:  defaultParallelism,
+		callTimeout:  defaultCallTimeout,
+		retryPolicy:  defaultRetryPolicy,
+	}
+	if options != nil {
+		a.retryPolicy = newRetryPolicy(options.RetryAttempts, options.RetryBackoff, options.MaxRetryBackoff)
+		if options.Parallelism > 0 {
+			a.parallelism = options.Parallelism
+		}
+		if options.CallTimeout > 0 {
+			a.callTimeout = options.CallTimeout
+		} else if options.ConnectionTimeout > 0 {
+			a.callTimeout = callTimeoutFactor * options.ConnectionTimeout
+		}
 	}
 
 	// perform initial connections
@@ -96,6 +157,9 @@ func NewAdmin(addrs []string, options *AdminOptions) AdminInterface {
 
 // Connections returns the connection map of all clients
 func (a *Admin) Connections() AdminConnectionsInterface {
+	if a.ctx != nil {
+		return &contextConnections{AdminConnectionsInterface: a.cnx, ctx: a.ctx}
+	}
 	return a.cnx
 }
 
@@ -146,20 +210,7 @@ func (a *Admin) GetClusterInfos() (*ClusterInfos, error) {
 	infos := NewClusterInfos()
 	clusterErr := NewClusterInfosError()
 
-	for addr, c := range a.Connections().GetAll() {
-		nodeinfos, err := a.getInfos(c, addr)
-		if err != nil {
-			infos.Status = ClusterInfosPartial
-			clusterErr.partial = true
-			clusterErr.errs[addr] = err
-			continue
-		}
-		if nodeinfos.Node != nil && nodeinfos.Node.IPPort() == addr {
-			infos.Infos[addr] = nodeinfos
-		} else {
-			glog.Warningf("Bad node info retreived from %s", addr)
-		}
-	}
+	a.collectInfos(a.Connections().GetAll(), infos, &clusterErr)
 
 	if len(clusterErr.errs) == 0 {
 		clusterErr.inconsistent = !infos.ComputeStatus()
@@ -175,20 +226,7 @@ func (a *Admin) GetClusterInfosSelected(addrs []string) (*ClusterInfos, error) {
 	infos := NewClusterInfos()
 	clusterErr := NewClusterInfosError()
 
-	for addr, c := range a.Connections().GetSelected(addrs) {
-		nodeinfos, err := a.getInfos(c, addr)
-		if err != nil {
-			infos.Status = ClusterInfosPartial
-			clusterErr.partial = true
-			clusterErr.errs[addr] = err
-			continue
-		}
-		if nodeinfos.Node != nil && nodeinfos.Node.IPPort() == addr {
-			infos.Infos[addr] = nodeinfos
-		} else {
-			glog.Warningf("Bad node info retreived from %s", addr)
-		}
-	}
+	a.collectInfos(a.Connections().GetSelected(addrs), infos, &clusterErr)
 
 	if len(clusterErr.errs) == 0 {
 		clusterErr.inconsistent = !infos.ComputeStatus()
@@ -199,98 +237,31 @@ func (a *Admin) GetClusterInfosSelected(addrs []string) (*ClusterInfos, error) {
 	return infos, clusterErr
 }
 
-// StartFailover used to force the failover of a specific redis master node
-func (a *Admin) StartFailover(addr string) error {
-	c, err := a.Connections().Get(addr)
-	if err != nil {
-		return err
-	}
-	var me *NodeInfos
-	me, err = a.getInfos(c, addr)
-	if err != nil {
-		return err
-	}
-
-	if me.Node.Role != redisMasterRole {
-		// if not a Master dont failover
-		return nil
-	}
-
-	slaves, err := selectMySlaves(me.Node, me.Friends)
-	if err != nil {
-		return fmt.Errorf("Unable to found associated slaves, err:%s", err)
-	}
-
-	if len(slaves) == 0 {
-		return fmt.Errorf("Master id:%s dont have associated slave", me.Node.ID)
-	}
-
-	if glog.V(3) {
-		for _, slave := range slaves {
-			glog.Info("- Slave: ", slave.ID)
-		}
-	}
-
-	failoverTriggered := false
-	for _, aSlave := range slaves {
-		var slaveClient ClientInterface
-		if slaveClient, err = a.Connections().Get(aSlave.IPPort()); err != nil {
-			continue
-		}
-
-		resp := slaveClient.Cmd("CLUSTER", "FAILOVER")
-		if err = a.Connections().ValidateResp(resp, aSlave.IPPort(), "Unable to execute Failover"); err != nil {
-			continue
-		}
-		failoverTriggered = true
-		break
-	}
-
-	if !failoverTriggered {
-		return fmt.Errorf("Unable to trigger failover for node '%s'", me.Node.IPPort())
-	}
-
-	for {
-		me, err = a.getInfos(c, addr)
-		if err != nil {
-			return err
-		}
-
-		if me.Node.TotalSlots() == 0 {
-			glog.Info("failover completed")
-			break
-		}
-
-		glog.Info("waiting failover to be complete...")
-		time.Sleep(time.Second) // TODO: implement back-off like logic
-		// we should wait that all slots have been moved to the new master
-		// this is the only way to know that we can stop this master with no impact on the cluster
-	}
-
-	return nil
-}
-
 // ForgetNode used to force other redis cluster node to forget a specific node
 func (a *Admin) ForgetNode(id string) error {
 	infos, _ := a.GetClusterInfos()
+	addrs := []string{}
 	for nodeAddr, nodeinfos := range infos.Infos {
 		if nodeinfos.Node.ID == id {
 			continue
 		}
-		c, err := a.Connections().Get(nodeAddr)
-		if err != nil {
-			glog.Errorf("Cannot force a forget on node %s, for node %s: %v", nodeAddr, id, err)
-			continue
-		}
-
+		// the slaves are detached before the fan-out since DetachSlave uses the connections to all the nodes
 		if IsSlave(nodeinfos.Node) && nodeinfos.Node.MasterReferent == id {
 			a.DetachSlave(nodeinfos.Node)
 			glog.V(2).Infof("detach slave id: %s of master: %s", nodeinfos.Node.ID, id)
 		}
+		addrs = append(addrs, nodeAddr)
+	}
+	a.fanOut(addrs, func(admin *Admin, nodeAddr string) (interface{}, error) {
+		c, err := admin.Connections().Get(nodeAddr)
+		if err != nil {
+			glog.Errorf("Cannot force a forget on node %s, for node %s: %v", nodeAddr, id, err)
+			return nil, err
+		}
 
 		resp := c.Cmd("CLUSTER", "FORGET", id)
-		a.Connections().ValidateResp(resp, nodeAddr, "Unable to execute FORGET command")
-	}
+		return nil, admin.Connections().ValidateResp(resp, nodeAddr, "Unable to execute FORGET command")
+	})
 
 	glog.Infof("Forget Node:%s ...done", id)
 	return nil
@@ -325,58 +296,77 @@ func (a *Admin) ForgetNodeByAddr(addr string) error {
 	return a.ForgetNode(me.ID)
 }
 
-// SetSlots use to set SETSLOT command on several slots
+// SetSlots use to set SETSLOT command on several slots, the pipeline is sent again while it fails with a retryable error
 func (a *Admin) SetSlots(addr, action string, slots []Slot, nodeID string) error {
 	if len(slots) == 0 {
 		return nil
 	}
-	c, err := a.Connections().Get(addr)
-	if err != nil {
-		return err
-	}
-	for _, slot := range slots {
-		if nodeID == "" {
-			c.PipeAppend("CLUSTER", "SETSLOT", slot, action)
-		} else {
-			c.PipeAppend("CLUSTER", "SETSLOT", slot, action, nodeID)
+	return a.retry(addr, func() error {
+		c, err := a.Connections().Get(addr)
+		if err != nil {
+			return err
 		}
-	}
-	if !a.Connections().ValidatePipeResp(c, addr, "Cannot SETSLOT") {
-		return fmt.Errorf("Error occured during CLUSTER SETSLOT %s", action)
-	}
-	c.PipeClear()
-
-	return nil
+		for _, slot := range slots {
+			if nodeID == "" {
+				c.PipeAppend("CLUSTER", "SETSLOT", slot, action)
+			} else {
+				c.PipeAppend("CLUSTER", "SETSLOT", slot, action, nodeID)
+			}
+		}
+		err = a.Connections().ValidatePipeRespError(c, addr, fmt.Sprintf("Cannot SETSLOT %s", action))
+		c.PipeClear()
+		return err
+	})
 }
 
-// AddSlots use to ADDSLOT commands on several slots
+// AddSlots use to ADDSLOT commands on several slots. ADDSLOTS is not idempotent: when it is sent again after a
+// retryable error, an "already busy" reply is a success if the node owns all the slots
 func (a *Admin) AddSlots(addr string, slots []Slot) error {
 	if len(slots) == 0 {
 		return nil
 	}
-	c, err := a.Connections().Get(addr)
-	if err != nil {
-		return err
-	}
-
-	resp := c.Cmd("CLUSTER", "ADDSLOTS", slots)
-
-	return a.Connections().ValidateResp(resp, addr, "Unable to run CLUSTER ADDSLOTS")
+	return a.retrySlotsCmd(addr, "ADDSLOTS", slots, true)
 }
 
-// DelSlots exec the redis command to del slots in a pipeline
+// DelSlots exec the redis command to del slots. DELSLOTS is not idempotent: when it is sent again after a
+// retryable error, an "already unassigned" reply is a success if the node owns none of the slots
 func (a *Admin) DelSlots(addr string, slots []Slot) error {
 	if len(slots) == 0 {
 		return nil
 	}
-	c, err := a.Connections().Get(addr)
-	if err != nil {
-		return err
-	}
-
-	resp := c.Cmd("CLUSTER", "DELSLOTS", slots)
+	return a.retrySlotsCmd(addr, "DELSLOTS", slots, false)
+}
 
-	return a.Connections().ValidateResp(resp, addr, "Unable to run CLUSTER DELSLOTS")
+// retrySlotsCmd sends the CLUSTER ADDSLOTS or DELSLOTS command while it fails with a retryable error. The error reply
+// of a retry telling the slots are already assigned or unassigned is checked against the slots owned by the node: the
+// previous attempt may have been applied before its response was lost
+func (a *Admin) retrySlotsCmd(addr, subCmd string, slots []Slot, owned bool) error {
+	errMessage := fmt.Sprintf("Unable to run CLUSTER %s", subCmd)
+	attempt := 0
+	return a.retry(addr, func() error {
+		attempt++
+		c, err := a.Connections().Get(addr)
+		if err != nil {
+			return err
+		}
+		resp := c.Cmd("CLUSTER", subCmd, slots)
+		err = a.Connections().ValidateResp(resp, addr, errMessage)
+		if err == nil || attempt == 1 || !IsSlotsAlreadyAppliedError(err) {
+			return err
+		}
+		nodeInfos, infosErr := a.getInfos(addr)
+		if infosErr != nil {
+			glog.Errorf("Cannot check the slots owned by node %s after CLUSTER %s: %v", addr, subCmd, infosErr)
+			return err
+		}
+		for _, slot := range slots {
+			if Contains(nodeInfos.Node.Slots, slot) != owned {
+				return err
+			}
+		}
+		glog.V(2).Infof("CLUSTER %s already applied on node %s by a previous attempt", subCmd, addr)
+		return nil
+	})
 }
 
 // GetKeysInSlot exec the redis command to get the keys in the given slot on the node we are connected to
@@ -384,14 +374,10 @@ func (a *Admin) DelSlots(addr string, slots []Slot) error {
 func (a *Admin) GetKeysInSlot(addr string, slot Slot, batch int, limit bool) ([]string, error) {
 	keyCount := 0
 	allKeys := []string{}
-	c, err := a.Connections().Get(addr)
-	if err != nil {
-		return allKeys, err
-	}
 
 	for {
-		resp := c.Cmd("CLUSTER", "GETKEYSINSLOT", slot, strconv.Itoa(batch))
-		if err := a.Connections().ValidateResp(resp, addr, "Unable to run command GETKEYSINSLOT"); err != nil {
+		resp, err := a.cmd(addr, "Unable to run command GETKEYSINSLOT", "CLUSTER", "GETKEYSINSLOT", slot, strconv.Itoa(batch))
+		if err != nil {
 			return allKeys, err
 		}
 		keys, err := resp.List()
@@ -412,41 +398,84 @@ func (a *Admin) GetKeysInSlot(addr string, slot Slot, batch int, limit bool) ([]
 
 // CountKeysInSlot exec the redis command to count the number of keys in the given slot on a node
 func (a *Admin) CountKeysInSlot(addr string, slot Slot) (int64, error) {
-	c, err := a.Connections().Get(addr)
+	resp, err := a.cmd(addr, "Unable to run command COUNTKEYSINSLOT", "CLUSTER", "COUNTKEYSINSLOT", slot)
 	if err != nil {
 		return 0, err
 	}
+	return resp.Int64()
+}
+
+// GetReplicationInfo exec the INFO REPLICATION redis command on the node and decode it
+func (a *Admin) GetReplicationInfo(addr string) (*ReplicationInfo, error) {
+	resp, err := a.cmd(addr, "Unable to retrieve replication info", "INFO", "REPLICATION")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := resp.Str()
+	if err != nil {
+		return nil, fmt.Errorf("Wrong format from INFO REPLICATION: %v", err)
+	}
+	return DecodeReplicationInfo(&raw)
+}
 
-	resp := c.Cmd("CLUSTER", "COUNTKEYSINSLOT", slot)
-	if err := a.Connections().ValidateResp(resp, addr, "Unable to run command COUNTKEYSINSLOT"); err != nil {
-		return 0, err
+// GetServerInfo exec the INFO redis command on the node and decode it
+func (a *Admin) GetServerInfo(addr string) (*ServerInfo, error) {
+	resp, err := a.cmd(addr, "Unable to retrieve server info", "INFO")
+	if err != nil {
+		return nil, err
 	}
-	return resp.Int64()
+	raw, err := resp.Str()
+	if err != nil {
+		return nil, fmt.Errorf("Wrong format from INFO: %v", err)
+	}
+	return DecodeServerInfo(&raw)
 }
 
 // MigrateKeys use to migrate keys from slots to other slots. if replace is true, replace key on busy error
-// timeout is in milliseconds
+// timeout is in milliseconds. A batch failing with a retryable error is retried: the keys left in the slot are
+// read again before being migrated.
 func (a *Admin) MigrateKeys(addr string, dest *Node, slots []Slot, batch int, timeout int, replace bool) (int, error) {
 	if len(slots) == 0 {
 		return 0, nil
 	}
 	keyCount := 0
-	c, err := a.Connections().Get(addr)
-	if err != nil {
-		return keyCount, err
-	}
 	timeoutStr := strconv.Itoa(timeout)
 	batchStr := strconv.Itoa(batch)
 
 	for _, slot := range slots {
 		for {
-			resp := c.Cmd("CLUSTER", "GETKEYSINSLOT", slot, batchStr)
-			if err := a.Connections().ValidateResp(resp, addr, "Unable to run command GETKEYSINSLOT"); err != nil {
+			if err := a.Context().Err(); err != nil {
 				return keyCount, err
 			}
-			keys, err := resp.List()
+			var keys []string
+			err := a.retry(addr, func() error {
+				c, err := a.Connections().Get(addr)
+				if err != nil {
+					return err
+				}
+				resp := c.Cmd("CLUSTER", "GETKEYSINSLOT", slot, batchStr)
+				if err = a.Connections().ValidateResp(resp, addr, "Unable to run command GETKEYSINSLOT"); err != nil {
+					return err
+				}
+				if keys, err = resp.List(); err != nil {
+					glog.Errorf("Wrong retured format for CLUSTER GETKEYSINSLOT: %v", err)
+					return err
+				}
+				if len(keys) == 0 {
+					return nil
+				}
+
+				var args []string
+				if replace {
+					args = append([]string{dest.IP, dest.Port, "", "0", timeoutStr, "REPLACE", "KEYS"}, keys...)
+				} else {
+					args = append([]string{dest.IP, dest.Port, "", "0", timeoutStr, "KEYS"}, keys...)
+				}
+
+				resp = c.Cmd("MIGRATE", args)
+				return a.Connections().ValidateResp(resp, addr, "Unable to run command MIGRATE")
+			})
 			if err != nil {
-				glog.Errorf("Wrong retured format for CLUSTER GETKEYSINSLOT: %v", err)
 				return keyCount, err
 			}
 
@@ -454,18 +483,6 @@ func (a *Admin) MigrateKeys(addr string, dest *Node, slots []Slot, batch int, ti
 			if len(keys) == 0 {
 				break
 			}
-
-			var args []string
-			if replace {
-				args = append([]string{dest.IP, dest.Port, "", "0", timeoutStr, "REPLACE", "KEYS"}, keys...)
-			} else {
-				args = append([]string{dest.IP, dest.Port, "", "0", timeoutStr, "KEYS"}, keys...)
-			}
-
-			resp = c.Cmd("MIGRATE", args)
-			if err := a.Connections().ValidateResp(resp, addr, "Unable to run command MIGRATE"); err != nil {
-				return keyCount, err
-			}
 		}
 	}
 
@@ -474,13 +491,7 @@ func (a *Admin) MigrateKeys(addr string, dest *Node, slots []Slot, batch int, ti
 
 // AttachSlaveToMaster attach a slave to a master node
 func (a *Admin) AttachSlaveToMaster(slave *Node, master *Node) error {
-	c, err := a.Connections().Get(slave.IPPort())
-	if err != nil {
-		return err
-	}
-
-	resp := c.Cmd("CLUSTER", "REPLICATE", master.ID)
-	if err := a.Connections().ValidateResp(resp, slave.IPPort(), "Unable to run command REPLICATE"); err != nil {
+	if _, err := a.cmd(slave.IPPort(), "Unable to run command REPLICATE", "CLUSTER", "REPLICATE", master.ID); err != nil {
 		return err
 	}
 
@@ -546,14 +557,13 @@ func selectMySlaves(me *Node, nodes Nodes) (Nodes, error) {
 	})
 }
 
-func (a *Admin) getInfos(c ClientInterface, addr string) (*NodeInfos, error) {
-	resp := c.Cmd("CLUSTER", "NODES")
-	if err := a.Connections().ValidateResp(resp, addr, "Unable to retrieve Node Info"); err != nil {
+func (a *Admin) getInfos(addr string) (*NodeInfos, error) {
+	resp, err := a.cmd(addr, "Unable to retrieve Node Info", "CLUSTER", "NODES")
+	if err != nil {
 		return nil, err
 	}
 
 	var raw string
-	var err error
 	raw, err = resp.Str()
 
 	if err != nil {
@@ -564,8 +574,8 @@ func (a *Admin) getInfos(c ClientInterface, addr string) (*NodeInfos, error) {
 
 	if glog.V(3) {
 		//Retrieve server info for debugging
-		resp = c.Cmd("INFO", "SERVER")
-		if err = a.Connections().ValidateResp(resp, addr, "Unable to retrieve Node Info"); err != nil {
+		resp, err = a.cmd(addr, "Unable to retrieve Node Info", "INFO", "SERVER")
+		if err != nil {
 			return nil, err
 		}
 		raw, err = resp.Str()
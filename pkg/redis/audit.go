@@ -0,0 +1,276 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/mediocregopher/radix.v2/redis"
+)
+
+const (
+	// AuditResultOK is the result of a mutating command accepted by the node
+	AuditResultOK = "ok"
+	// AuditResultError is the result of a mutating command refused by the node, or whose response can't be read
+	AuditResultError = "error"
+	// AuditResultUnknown is the result of a pipelined mutating command whose response has not been read
+	AuditResultUnknown = "unknown"
+
+	// auditRedacted replaces the values of the arguments not written in the audit records
+	auditRedacted = "<redacted>"
+)
+
+// auditedCommands are the mutating commands recorded in the audit log, the CLUSTER and CONFIG commands by subcommand
+var auditedCommands = map[string]bool{
+	"CLUSTER SETSLOT":   true,
+	"CLUSTER FAILOVER":  true,
+	"CLUSTER FORGET":    true,
+	"CLUSTER RESET":     true,
+	"CLUSTER REPLICATE": true,
+	"CLUSTER ADDSLOTS":  true,
+	"CLUSTER DELSLOTS":  true,
+	"CLUSTER MEET":      true,
+	"CLUSTER BUMPEPOCH": true,
+	"MIGRATE":           true,
+	"FLUSHALL":          true,
+	"FLUSHDB":           true,
+	"SLAVEOF":           true,
+	"REPLICAOF":         true,
+	"BGSAVE":            true,
+	"CONFIG SET":        true,
+	"RESTORE":           true,
+	"DEL":               true,
+}
+
+// AuditRecord is the record of a mutating command sent to a redis node
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	Cluster   string    `json:"cluster"`
+	Reconcile string    `json:"reconcile,omitempty"`
+	Addr      string    `json:"addr"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args,omitempty"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AuditSink receives the audit records, it must be safe for concurrent use
+type AuditSink interface {
+	Write(record *AuditRecord) error
+}
+
+// JSONLinesAuditSink writes the audit records as JSON lines
+type JSONLinesAuditSink struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// NewJSONLinesAuditSink returns a sink writing the audit records as JSON lines in w
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{w: w}
+}
+
+// NewFileAuditSink returns a sink appending the audit records as JSON lines to the file, created if needed
+func NewFileAuditSink(path string) (*JSONLinesAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the audit file %s: %v", path, err)
+	}
+	return NewJSONLinesAuditSink(f), nil
+}
+
+// Write writes the record on a line
+func (s *JSONLinesAuditSink) Write(record *AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+type auditContextKey struct{}
+
+// auditInfo is what the records of the commands sent by an admin bound to the context have in common
+type auditInfo struct {
+	sink      AuditSink
+	cluster   string
+	reconcile string
+}
+
+// WithAudit returns a context recording in the sink the mutating commands sent by the admins bound to it,
+// as commands of the cluster issued by the reconcile
+func WithAudit(ctx context.Context, sink AuditSink, cluster, reconcile string) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, &auditInfo{sink: sink, cluster: cluster, reconcile: reconcile})
+}
+
+func auditFromContext(ctx context.Context) *auditInfo {
+	info, _ := ctx.Value(auditContextKey{}).(*auditInfo)
+	return info
+}
+
+// newRecord returns the record of the command if it is audited, nil otherwise
+func (info *auditInfo) newRecord(addr, cmd string, args []interface{}) *AuditRecord {
+	command, rest := auditCommand(cmd, flattenAuditArgs(args))
+	if !auditedCommands[command] {
+		return nil
+	}
+	return &AuditRecord{
+		Time:      time.Now(),
+		Cluster:   info.cluster,
+		Reconcile: info.reconcile,
+		Addr:      addr,
+		Command:   command,
+		Args:      redactAuditArgs(command, rest),
+	}
+}
+
+// write completes the record with the response of the node and writes it in the sink, resp is nil if the response
+// has not been read
+func (info *auditInfo) write(record *AuditRecord, resp *redis.Resp) {
+	if record == nil {
+		return
+	}
+	switch {
+	case resp == nil:
+		record.Result = AuditResultUnknown
+	case resp.Err != nil:
+		record.Result = AuditResultError
+		record.Error = resp.Err.Error()
+	default:
+		record.Result = AuditResultOK
+	}
+	if err := info.sink.Write(record); err != nil {
+		glog.Errorf("unable to write the audit record of %s on node %s: %v", record.Command, record.Addr, err)
+	}
+}
+
+// auditCommand returns the name of the command, with its subcommand for CLUSTER and CONFIG, and its other arguments
+func auditCommand(cmd string, args []string) (string, []string) {
+	command := strings.ToUpper(cmd)
+	if (command == "CLUSTER" || command == "CONFIG") && len(args) > 0 {
+		return command + " " + strings.ToUpper(args[0]), args[1:]
+	}
+	return command, args
+}
+
+// flattenAuditArgs returns the arguments of a command as strings, the slots as ranges
+func flattenAuditArgs(args []interface{}) []string {
+	flat := []string{}
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case []interface{}:
+			flat = append(flat, flattenAuditArgs(v)...)
+		case []string:
+			flat = append(flat, v...)
+		case []Slot:
+			// SlotRangesFromSlots sorts the slots, the caller's slice is kept untouched
+			for _, r := range SlotRangesFromSlots(append([]Slot{}, v...)) {
+				if r.Min == r.Max {
+					flat = append(flat, r.Min.String())
+				} else {
+					flat = append(flat, r.String())
+				}
+			}
+		default:
+			flat = append(flat, fmt.Sprint(v))
+		}
+	}
+	return flat
+}
+
+// redactAuditArgs returns the arguments without the keys, the values and the secrets
+func redactAuditArgs(command string, args []string) []string {
+	redacted := append([]string{}, args...)
+	switch command {
+	case "RESTORE":
+		// RESTORE key ttl serialized-value [REPLACE]
+		for _, i := range []int{0, 2} {
+			if i < len(redacted) {
+				redacted[i] = auditRedacted
+			}
+		}
+	case "DEL":
+		return []string{fmt.Sprintf("<%d keys redacted>", len(redacted))}
+	case "MIGRATE":
+		// MIGRATE host port key|"" destination-db timeout [COPY] [REPLACE] [AUTH password] [KEYS key [key ...]]
+		if len(redacted) > 2 && redacted[2] != "" {
+			redacted[2] = auditRedacted
+		}
+		for i := 5; i < len(redacted); i++ {
+			switch strings.ToUpper(redacted[i]) {
+			case "AUTH":
+				if i+1 < len(redacted) {
+					redacted[i+1] = auditRedacted
+				}
+			case "KEYS":
+				return append(redacted[:i+1], fmt.Sprintf("<%d keys redacted>", len(redacted)-i-1))
+			}
+		}
+	case "CONFIG SET":
+		if len(redacted) > 1 && (strings.EqualFold(redacted[0], "requirepass") || strings.EqualFold(redacted[0], "masterauth")) {
+			redacted[1] = auditRedacted
+		}
+	}
+	return redacted
+}
+
+// auditClient is a client connection recording its mutating commands in the audit sink
+type auditClient struct {
+	ClientInterface
+	addr  string
+	audit *auditInfo
+	// pipe contains the records of the pipelined commands whose response has not been read yet, nil if not audited
+	pipe []*AuditRecord
+}
+
+// Cmd calls the given Redis command and records it if it is a mutating command
+func (c *auditClient) Cmd(cmd string, args ...interface{}) *redis.Resp {
+	record := c.audit.newRecord(c.addr, cmd, args)
+	resp := c.ClientInterface.Cmd(cmd, args...)
+	c.audit.write(record, resp)
+	return resp
+}
+
+// PipeAppend adds the given call to the pipeline queue, it is recorded once its response is read
+func (c *auditClient) PipeAppend(cmd string, args ...interface{}) {
+	c.pipe = append(c.pipe, c.audit.newRecord(c.addr, cmd, args))
+	c.ClientInterface.PipeAppend(cmd, args...)
+}
+
+// PipeResp returns the reply for the next request in the pipeline queue and records the request
+func (c *auditClient) PipeResp() *redis.Resp {
+	resp := c.ClientInterface.PipeResp()
+	switch {
+	case resp == nil:
+		// the connection is lost, the responses of the pending commands won't be read
+		c.flushPipe()
+	case resp.Err == redis.ErrPipelineEmpty:
+	case len(c.pipe) > 0:
+		record := c.pipe[0]
+		c.pipe = c.pipe[1:]
+		c.audit.write(record, resp)
+	}
+	return resp
+}
+
+// PipeClear clears the pipeline queue, the commands whose response has not been read are recorded without result
+func (c *auditClient) PipeClear() (int, int) {
+	c.flushPipe()
+	return c.ClientInterface.PipeClear()
+}
+
+func (c *auditClient) flushPipe() {
+	for _, record := range c.pipe {
+		c.audit.write(record, nil)
+	}
+	c.pipe = nil
+}
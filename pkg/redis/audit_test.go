@@ -0,0 +1,138 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake"
+)
+
+// memoryAuditSink keeps the audit records in memory
+type memoryAuditSink struct {
+	records []*AuditRecord
+}
+
+func (s *memoryAuditSink) Write(record *AuditRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func TestAdmin_audit(t *testing.T) {
+	redisSrv := fake.NewRedisServer(t)
+	defer redisSrv.Close()
+	addr := redisSrv.GetHostPort()
+	a := NewAdmin([]string{addr}, &AdminOptions{RetryAttempts: 1})
+	defer a.Close()
+
+	sink := &memoryAuditSink{}
+	audited := a.WithContext(WithAudit(context.Background(), sink, "default/cluster", "reconcile-1"))
+
+	redisSrv.PushResponse("CLUSTER ADDSLOTS 7 1 2 3", "OK")
+	redisSrv.PushResponse("CLUSTER SETSLOT 1 IMPORTING node", "OK")
+	redisSrv.PushResponse("CLUSTER SETSLOT 2 IMPORTING node", errors.New("ERR I'm already the owner of hash slot 2"))
+	redisSrv.PushResponse("CLUSTER COUNTKEYSINSLOT 1", 10)
+	if err := audited.AddSlots(addr, []Slot{7, 1, 2, 3}); err != nil {
+		t.Fatalf("AddSlots() error = %v", err)
+	}
+	audited.SetSlots(addr, "IMPORTING", []Slot{1, 2}, "node")
+	audited.CountKeysInSlot(addr, 1)
+
+	want := []AuditRecord{
+		{Cluster: "default/cluster", Reconcile: "reconcile-1", Addr: addr, Command: "CLUSTER ADDSLOTS", Args: []string{"1-3", "7"}, Result: AuditResultOK},
+		{Cluster: "default/cluster", Reconcile: "reconcile-1", Addr: addr, Command: "CLUSTER SETSLOT", Args: []string{"1", "IMPORTING", "node"}, Result: AuditResultOK},
+		{Cluster: "default/cluster", Reconcile: "reconcile-1", Addr: addr, Command: "CLUSTER SETSLOT", Args: []string{"2", "IMPORTING", "node"}, Result: AuditResultError, Error: "ERR I'm already the owner of hash slot 2"},
+	}
+	if len(sink.records) != len(want) {
+		t.Fatalf("audit records = %d, want %d, only the mutating commands are recorded", len(sink.records), len(want))
+	}
+	for i, record := range sink.records {
+		if record.Time.IsZero() {
+			t.Errorf("record %d should be timestamped", i)
+		}
+		record.Time = want[i].Time
+		if !reflect.DeepEqual(*record, want[i]) {
+			t.Errorf("record %d = %+v, want %+v", i, *record, want[i])
+		}
+	}
+
+	sink.records = nil
+	redisSrv.PushResponse("CLUSTER ADDSLOTS 8", "OK")
+	a.AddSlots(addr, []Slot{8})
+	if len(sink.records) != 0 {
+		t.Errorf("the commands of an admin not bound to an audited context should not be recorded")
+	}
+}
+
+func Test_redactAuditArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		want    []string
+	}{
+		{
+			name:    "migrate keys",
+			command: "MIGRATE",
+			args:    []string{"10.0.0.1", "6379", "", "0", "10000", "REPLACE", "KEYS", "user:1", "user:2"},
+			want:    []string{"10.0.0.1", "6379", "", "0", "10000", "REPLACE", "KEYS", "<2 keys redacted>"},
+		},
+		{
+			name:    "migrate single key with auth",
+			command: "MIGRATE",
+			args:    []string{"10.0.0.1", "6379", "user:1", "0", "10000", "AUTH", "secret"},
+			want:    []string{"10.0.0.1", "6379", auditRedacted, "0", "10000", "AUTH", auditRedacted},
+		},
+		{
+			name:    "restore key",
+			command: "RESTORE",
+			args:    []string{"user:1", "0", "\x00\x03foo", "REPLACE"},
+			want:    []string{auditRedacted, "0", auditRedacted, "REPLACE"},
+		},
+		{
+			name:    "del keys",
+			command: "DEL",
+			args:    []string{"user:1", "user:2"},
+			want:    []string{"<2 keys redacted>"},
+		},
+		{
+			name:    "config set password",
+			command: "CONFIG SET",
+			args:    []string{"masterauth", "secret"},
+			want:    []string{"masterauth", auditRedacted},
+		},
+		{
+			name:    "cluster command",
+			command: "CLUSTER FORGET",
+			args:    []string{"node"},
+			want:    []string{"node"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactAuditArgs(tt.command, tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactAuditArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLinesAuditSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := NewJSONLinesAuditSink(buf)
+	sink.Write(&AuditRecord{Cluster: "default/cluster", Addr: "10.0.0.1:6379", Command: "FLUSHALL", Result: AuditResultOK})
+	sink.Write(&AuditRecord{Cluster: "default/cluster", Addr: "10.0.0.2:6379", Command: "FLUSHALL", Result: AuditResultUnknown})
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("JSONLinesAuditSink wrote %d lines, want 2", len(lines))
+	}
+	record := AuditRecord{}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil || record.Addr != "10.0.0.2:6379" || record.Result != AuditResultUnknown {
+		t.Errorf("line = %s, err = %v, want the second record", lines[1], err)
+	}
+}
@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// backupPollInterval is the wait between two checks of the progress of a BGSAVE
+	backupPollInterval = time.Second
+)
+
+// PersistenceInfo contains the state of the background saves of a node, from the persistence section of the INFO command
+type PersistenceInfo struct {
+	BgsaveInProgress bool
+	LastBgsaveStatus string
+}
+
+// DecodePersistenceInfo decode from the INFO PERSISTENCE cmd output the state of the background saves
+func DecodePersistenceInfo(input *string) *PersistenceInfo {
+	info := &PersistenceInfo{}
+	for _, line := range strings.Split(*input, "\n") {
+		values := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(values) != 2 {
+			continue
+		}
+		switch values[0] {
+		case "rdb_bgsave_in_progress":
+			info.BgsaveInProgress = values[1] == "1"
+		case "rdb_last_bgsave_status":
+			info.LastBgsaveStatus = values[1]
+		}
+	}
+	return info
+}
+
+// GetPersistenceInfo exec the INFO PERSISTENCE redis command on the node and decode it
+func (a *Admin) GetPersistenceInfo(addr string) (*PersistenceInfo, error) {
+	resp, err := a.cmd(addr, "Unable to retrieve persistence info", "INFO", "PERSISTENCE")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := resp.Str()
+	if err != nil {
+		return nil, fmt.Errorf("Wrong format from INFO PERSISTENCE: %v", err)
+	}
+	return DecodePersistenceInfo(&raw), nil
+}
+
+// BackupNode dumps the keys of the node with BGSAVE in the given file of its working directory, and waits at most
+// timeout for the end of the save. The dbfilename of the node is restored as soon as the save has started.
+func (a *Admin) BackupNode(addr, filename string, timeout time.Duration) error {
+	resp, err := a.cmd(addr, "Unable to retrieve the dbfilename", "CONFIG", "GET", "dbfilename")
+	if err != nil {
+		return err
+	}
+	values, err := resp.List()
+	if err != nil || len(values) != 2 {
+		return fmt.Errorf("Wrong format from CONFIG GET dbfilename on node %s: %v", addr, err)
+	}
+	dbfilename := values[1]
+
+	c, err := a.Connections().Get(addr)
+	if err != nil {
+		return err
+	}
+	if err = a.Connections().ValidateResp(c.Cmd("CONFIG", "SET", "dbfilename", filename), addr, "Unable to set the dbfilename of the backup"); err != nil {
+		return err
+	}
+	saveErr := a.Connections().ValidateResp(c.Cmd("BGSAVE"), addr, "Unable to start the backup")
+	if _, err = a.cmd(addr, "Unable to restore the dbfilename", "CONFIG", "SET", "dbfilename", dbfilename); err != nil {
+		glog.Errorf("the dbfilename of node %s is still %s instead of %s: %v", addr, filename, dbfilename, err)
+		if saveErr == nil {
+			saveErr = err
+		}
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		info, err := a.GetPersistenceInfo(addr)
+		if err != nil {
+			return err
+		}
+		if !info.BgsaveInProgress {
+			if info.LastBgsaveStatus != "ok" {
+				return fmt.Errorf("backup %s of node %s failed, rdb_last_bgsave_status:%s", filename, addr, info.LastBgsaveStatus)
+			}
+			glog.Infof("node %s backed up in %s", addr, filename)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("backup %s of node %s not finished after %v", filename, addr, timeout)
+		}
+		if err = a.sleep(backupPollInterval); err != nil {
+			return err
+		}
+	}
+}
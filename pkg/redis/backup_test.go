@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake"
+)
+
+func TestAdmin_BackupNode(t *testing.T) {
+	redisSrv := fake.NewRedisServer(t)
+	defer redisSrv.Close()
+	addr := redisSrv.GetHostPort()
+	a := NewAdmin([]string{addr}, nil).(*Admin)
+	defer a.Close()
+
+	redisSrv.PushResponse("CONFIG GET dbfilename", []string{"dbfilename", "dump.rdb"})
+	redisSrv.PushResponse("CONFIG SET dbfilename backup.rdb", "OK")
+	redisSrv.PushResponse("BGSAVE", "Background saving started")
+	redisSrv.PushResponse("CONFIG SET dbfilename dump.rdb", "OK")
+	redisSrv.PushResponse("INFO PERSISTENCE", "# Persistence\r\nrdb_bgsave_in_progress:0\r\nrdb_last_bgsave_status:ok\r\n")
+	if err := a.BackupNode(addr, "backup.rdb", time.Minute); err != nil {
+		t.Errorf("BackupNode() error = %v", err)
+	}
+
+	// the dbfilename is restored when the save can't start
+	redisSrv.PushResponse("CONFIG GET dbfilename", []string{"dbfilename", "dump.rdb"})
+	redisSrv.PushResponse("CONFIG SET dbfilename backup.rdb", "OK")
+	redisSrv.PushResponse("BGSAVE", errors.New("ERR Background save already in progress"))
+	redisSrv.PushResponse("CONFIG SET dbfilename dump.rdb", "OK")
+	if err := a.BackupNode(addr, "backup.rdb", time.Minute); err == nil {
+		t.Errorf("BackupNode() should fail when the save can't start")
+	}
+	if len(redisSrv.Responses["CONFIG SET dbfilename dump.rdb"]) != 0 {
+		t.Errorf("BackupNode() should restore the dbfilename")
+	}
+
+	redisSrv.PushResponse("CONFIG GET dbfilename", []string{"dbfilename", "dump.rdb"})
+	redisSrv.PushResponse("CONFIG SET dbfilename backup.rdb", "OK")
+	redisSrv.PushResponse("BGSAVE", "Background saving started")
+	redisSrv.PushResponse("CONFIG SET dbfilename dump.rdb", "OK")
+	redisSrv.PushResponse("INFO PERSISTENCE", "# Persistence\r\nrdb_bgsave_in_progress:0\r\nrdb_last_bgsave_status:err\r\n")
+	if err := a.BackupNode(addr, "backup.rdb", time.Minute); err == nil {
+		t.Errorf("BackupNode() should fail when the save failed")
+	}
+}
+
+func TestDecodePersistenceInfo(t *testing.T) {
+	input := "# Persistence\r\nloading:0\r\nrdb_bgsave_in_progress:1\r\nrdb_last_bgsave_status:ok\r\n"
+	info := DecodePersistenceInfo(&input)
+	if !info.BgsaveInProgress || info.LastBgsaveStatus != "ok" {
+		t.Errorf("DecodePersistenceInfo() = %v", info)
+	}
+}
@@ -0,0 +1,119 @@
+package redis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// defaultBreakerThreshold is the default number of consecutive network failures opening the circuit of a node
+	defaultBreakerThreshold = 5
+	// defaultBreakerCooldown is the default time during which no command is sent to a node once its circuit is open
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// circuitBreaker stops sending commands to the nodes that cannot be reached: once a node failed threshold times
+// in a row, its circuit is open and the connections to the node are refused during the cool down. After the cool
+// down, one probe is let through: a success closes the circuit, a failure opens it again.
+// A nil circuitBreaker never opens.
+type circuitBreaker struct {
+	mutex     sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	states    map[string]*breakerState
+	now       func() time.Time // Added as member for testing
+}
+
+// breakerState is the state of the circuit of a node
+type breakerState struct {
+	failures  int
+	openUntil time.Time
+}
+
+// newCircuitBreaker returns a new circuitBreaker, nil if threshold is negative
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold < 0 {
+		return nil
+	}
+	if threshold == 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		states:    map[string]*breakerState{},
+		now:       time.Now,
+	}
+}
+
+// allow returns a CircuitOpenError if the circuit of the node is open
+func (b *circuitBreaker) allow(addr string) error {
+	if b == nil {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	state, ok := b.states[addr]
+	if !ok || state.failures < b.threshold {
+		return nil
+	}
+	now := b.now()
+	if now.Before(state.openUntil) {
+		return &CircuitOpenError{Addr: addr, Until: state.openUntil}
+	}
+	// half-open: let this probe through, the others wait for its result until the end of a new cool down
+	state.openUntil = now.Add(b.cooldown)
+	return nil
+}
+
+// success closes the circuit of the node
+func (b *circuitBreaker) success(addr string) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if state, ok := b.states[addr]; ok {
+		if state.failures >= b.threshold {
+			glog.Infof("circuit closed for node %s", addr)
+		}
+		delete(b.states, addr)
+	}
+}
+
+// failure records a failure of the node, the circuit opens when the threshold is reached
+func (b *circuitBreaker) failure(addr string) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	state, ok := b.states[addr]
+	if !ok {
+		state = &breakerState{}
+		b.states[addr] = state
+	}
+	state.failures++
+	if state.failures >= b.threshold {
+		state.openUntil = b.now().Add(b.cooldown)
+		glog.Warningf("circuit open for node %s until %s after %d failures", addr, state.openUntil.Format(time.RFC3339), state.failures)
+	}
+}
+
+// record updates the circuit of the node with the result of a command, only the network errors count as failures:
+// a node answering with an error reply is reachable
+func (b *circuitBreaker) record(addr string, err error) {
+	if IsCircuitOpenError(err) {
+		return
+	}
+	if ClassifyError(err) == ErrorClassNetwork {
+		b.failure(addr)
+		return
+	}
+	b.success(addr)
+}
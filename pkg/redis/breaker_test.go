@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	addr := "10.0.0.1:6379"
+
+	b.record(addr, io.EOF)
+	if err := b.allow(addr); err != nil {
+		t.Errorf("allow() error = %v, the circuit should be closed below the threshold", err)
+	}
+	b.record(addr, &NodeError{Addr: addr, Err: io.EOF})
+	if err := b.allow(addr); !IsCircuitOpenError(err) {
+		t.Errorf("allow() error = %v, the circuit should be open once the threshold is reached", err)
+	}
+	if err := b.allow("10.0.0.2:6379"); err != nil {
+		t.Errorf("allow() error = %v, the circuit of the other nodes should be closed", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := b.allow(addr); err != nil {
+		t.Errorf("allow() error = %v, a probe should be let through after the cool down", err)
+	}
+	if err := b.allow(addr); !IsCircuitOpenError(err) {
+		t.Errorf("allow() error = %v, only one probe should be let through", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	b.allow(addr)
+	b.record(addr, &NodeError{Addr: addr, Err: errNoResponse})
+	if err := b.allow(addr); !IsCircuitOpenError(err) {
+		t.Errorf("allow() error = %v, a failed probe should open the circuit again", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	b.allow(addr)
+	b.record(addr, nil)
+	b.record(addr, io.EOF)
+	if err := b.allow(addr); err != nil {
+		t.Errorf("allow() error = %v, a successful probe should close the circuit", err)
+	}
+
+	var disabled *circuitBreaker
+	disabled.record(addr, io.EOF)
+	if err := disabled.allow(addr); err != nil || newCircuitBreaker(-1, 0) != nil {
+		t.Errorf("a negative threshold should disable the circuit breaker")
+	}
+}
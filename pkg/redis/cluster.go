@@ -17,6 +17,26 @@ type Cluster struct {
 // ClusterActionsInfo use to store information about current action on the Cluster
 type ClusterActionsInfo struct {
 	NbslotsToMigrate int32
+	// Migrations are the slots moved (or assigned if From is nil) since the actions have been recorded
+	Migrations []SlotsMigration
+	// Attachments are the slaves attached to a master since the actions have been recorded
+	Attachments []SlaveAttachment
+}
+
+// SlotsMigration describes slots moved from a master to another one
+type SlotsMigration struct {
+	From   *Node
+	To     *Node
+	Slots  []Slot
+	NbKeys int
+	Err    error
+}
+
+// SlaveAttachment describes a node attached as slave of a master
+type SlaveAttachment struct {
+	Slave  *Node
+	Master *Node
+	Err    error
 }
 
 // NewCluster builds and returns new Cluster instance
@@ -3,11 +3,10 @@ package redis
 import (
 	"bufio"
 	"errors"
-	"fmt"
 	"math/rand"
-	"net"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -57,17 +56,23 @@ type AdminConnectionsInterface interface {
 	// in case of network issue clear the pipe and return
 	// in case of error return false
 	ValidatePipeResp(c ClientInterface, addr, errMessage string) bool
+	// ValidatePipeRespError wait for all answers in the pipe and validate the response
+	// in case of network issue clear the pipe and return the error
+	// in case of error, customize the first error, log it and return it
+	ValidatePipeRespError(c ClientInterface, addr, errMessage string) error
 	// Reset close all connections and clear the connection map
 	Reset()
 }
 
 // AdminConnections connection map for redis cluster
-// currently the admin connection is not threadSafe since it is only use in the Events thread.
+// the map is thread safe, but a client connection must not be used by several goroutines at the same time
 type AdminConnections struct {
+	mutex             sync.Mutex
 	clients           map[string]ClientInterface
 	connectionTimeout time.Duration
 	commandsMapping   map[string]string
 	clientName        string
+	breaker           *circuitBreaker
 }
 
 func init() {
@@ -83,6 +88,7 @@ func NewAdminConnections(addrs []string, options *AdminOptions) AdminConnections
 		clientName:        defaultClientName,
 	}
 	if options != nil {
+		cnx.breaker = newCircuitBreaker(options.BreakerThreshold, options.BreakerCooldown)
 		if options.ConnectionTimeout != 0 {
 			cnx.connectionTimeout = options.ConnectionTimeout
 		}
@@ -90,6 +96,8 @@ func NewAdminConnections(addrs []string, options *AdminOptions) AdminConnections
 			cnx.commandsMapping = buildCommandReplaceMapping(options.RenameCommandsFile)
 		}
 		cnx.clientName = options.ClientName
+	} else {
+		cnx.breaker = newCircuitBreaker(0, 0)
 	}
 	cnx.AddAll(addrs)
 	return cnx
@@ -97,6 +105,8 @@ func NewAdminConnections(addrs []string, options *AdminOptions) AdminConnections
 
 // Close used to close all possible resources instanciate by the Connections
 func (cnx *AdminConnections) Close() {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	for _, c := range cnx.clients {
 		c.Close()
 	}
@@ -111,6 +121,8 @@ func (cnx *AdminConnections) Add(addr string) error {
 
 // Remove disconnect and remove the client connection from the map
 func (cnx *AdminConnections) Remove(addr string) {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	if c, ok := cnx.clients[addr]; ok {
 		c.Close()
 		delete(cnx.clients, addr)
@@ -120,14 +132,23 @@ func (cnx *AdminConnections) Remove(addr string) {
 // Update returns a client connection for the given adress,
 // connects if the connection is not in the map yet
 func (cnx *AdminConnections) Update(addr string) (ClientInterface, error) {
+	if err := cnx.breaker.allow(addr); err != nil {
+		glog.V(3).Infof("Cannot connect to %s: %v", addr, err)
+		return nil, err
+	}
 	// if already exist close the current connection
+	cnx.mutex.Lock()
 	if c, ok := cnx.clients[addr]; ok {
 		c.Close()
+		delete(cnx.clients, addr)
 	}
+	cnx.mutex.Unlock()
 
 	c, err := cnx.connect(addr)
 	if err == nil && c != nil {
+		cnx.mutex.Lock()
 		cnx.clients[addr] = c
+		cnx.mutex.Unlock()
 	} else {
 		glog.V(3).Infof("Cannot connect to %s ", addr)
 	}
@@ -136,12 +157,26 @@ func (cnx *AdminConnections) Update(addr string) (ClientInterface, error) {
 
 // Get returns a client connection for the given adress,
 // connects if the connection is not in the map yet
+// returns a CircuitOpenError if the node failed too many times in a row
 func (cnx *AdminConnections) Get(addr string) (ClientInterface, error) {
-	if c, ok := cnx.clients[addr]; ok {
+	if err := cnx.breaker.allow(addr); err != nil {
+		return nil, err
+	}
+	cnx.mutex.Lock()
+	c, ok := cnx.clients[addr]
+	cnx.mutex.Unlock()
+	if ok {
 		return c, nil
 	}
 	c, err := cnx.connect(addr)
 	if err == nil && c != nil {
+		cnx.mutex.Lock()
+		defer cnx.mutex.Unlock()
+		if existing, ok := cnx.clients[addr]; ok {
+			// connected in the meantime by another goroutine
+			c.Close()
+			return existing, nil
+		}
 		cnx.clients[addr] = c
 	}
 	return c, err
@@ -155,14 +190,17 @@ func (cnx *AdminConnections) GetRandom() (ClientInterface, error) {
 
 // GetDifferentFrom returns random a client connection different from given address
 func (cnx *AdminConnections) GetDifferentFrom(addr string) (ClientInterface, error) {
+	cnx.mutex.Lock()
 	if len(cnx.clients) == 1 {
+		defer cnx.mutex.Unlock()
 		for a, c := range cnx.clients {
 			if a != addr {
 				return c, nil
 			}
-			return nil, errors.New(ErrNotFound)
 		}
+		return nil, errors.New(ErrNotFound)
 	}
+	cnx.mutex.Unlock()
 
 	for {
 		a, c, err := cnx.getRandomKeyClient()
@@ -177,11 +215,19 @@ func (cnx *AdminConnections) GetDifferentFrom(addr string) (ClientInterface, err
 
 // GetAll returns a map of all clients per address
 func (cnx *AdminConnections) GetAll() map[string]ClientInterface {
-	return cnx.clients
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
+	clients := make(map[string]ClientInterface, len(cnx.clients))
+	for addr, c := range cnx.clients {
+		clients[addr] = c
+	}
+	return clients
 }
 
 //GetSelected returns a map of clients based on the input addresses
 func (cnx *AdminConnections) GetSelected(addrs []string) map[string]ClientInterface {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	clientsSelected := make(map[string]ClientInterface)
 	for _, addr := range addrs {
 		if client, ok := cnx.clients[addr]; ok {
@@ -217,6 +263,8 @@ func (cnx *AdminConnections) ReplaceAll(addrs []string) {
 
 // Reset close all connections and clear the connection map
 func (cnx *AdminConnections) Reset() {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	for _, c := range cnx.clients {
 		c.Close()
 	}
@@ -227,13 +275,15 @@ func (cnx *AdminConnections) Reset() {
 // in case of error, customize the error, log it and return it
 func (cnx *AdminConnections) ValidateResp(resp *redis.Resp, addr, errMessage string) error {
 	if resp == nil {
+		cnx.breaker.record(addr, errNoResponse)
 		glog.Errorf("%s: Unable to connect to node %s", errMessage, addr)
-		return fmt.Errorf("%s: Unable to connect to node %s", errMessage, addr)
+		return &NodeError{Addr: addr, Message: errMessage, Err: errNoResponse}
 	}
+	cnx.breaker.record(addr, resp.Err)
 	if resp.Err != nil {
 		cnx.handleError(addr, resp.Err)
 		glog.Errorf("%s: Unexpected error on node %s: %v", errMessage, addr, resp.Err)
-		return fmt.Errorf("%s: Unexpected error on node %s: %v", errMessage, addr, resp.Err)
+		return &NodeError{Addr: addr, Message: errMessage, Err: resp.Err}
 	}
 	return nil
 }
@@ -242,31 +292,44 @@ func (cnx *AdminConnections) ValidateResp(resp *redis.Resp, addr, errMessage str
 // in case of network issue clear the pipe and return
 // in case of error, return false
 func (cnx *AdminConnections) ValidatePipeResp(client ClientInterface, addr, errMessage string) bool {
-	ok := true
+	return cnx.ValidatePipeRespError(client, addr, errMessage) == nil
+}
+
+// ValidatePipeRespError wait for all answers in the pipe and validate the response
+// in case of network issue clear the pipe and return the error
+// in case of error, customize the first error, log it and return it
+func (cnx *AdminConnections) ValidatePipeRespError(client ClientInterface, addr, errMessage string) error {
+	var firstErr error
 	for {
 		resp := client.PipeResp()
 		if resp == nil {
+			cnx.breaker.record(addr, errNoResponse)
 			glog.Errorf("%s: Unable to connect to node %s", errMessage, addr)
-			return false
+			return &NodeError{Addr: addr, Message: errMessage, Err: errNoResponse}
+		}
+		if resp.Err == redis.ErrPipelineEmpty {
+			break
 		}
+		cnx.breaker.record(addr, resp.Err)
 		if resp.Err != nil {
-			if resp.Err == redis.ErrPipelineEmpty {
-				break
-			}
 			glog.Errorf("%s: Unexpected error on node %s: %v", errMessage, addr, resp.Err)
 			if cnx.handleError(addr, resp.Err) {
 				// network error, no need to continue
-				return false
+				return &NodeError{Addr: addr, Message: errMessage, Err: resp.Err}
+			}
+			if firstErr == nil {
+				firstErr = &NodeError{Addr: addr, Message: errMessage, Err: resp.Err}
 			}
-			ok = false
 		}
 	}
 
-	return ok
+	return firstErr
 }
 
 // GetRandom returns a client connection to a random node of the client map
 func (cnx *AdminConnections) getRandomKeyClient() (string, ClientInterface, error) {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	nbClient := len(cnx.clients)
 	if nbClient == 0 {
 		return "", nil, errors.New(ErrNotFound)
@@ -282,27 +345,19 @@ func (cnx *AdminConnections) getRandomKeyClient() (string, ClientInterface, erro
 	return "", nil, errors.New(ErrNotFound)
 }
 
-// handleError handle a network error, reconnects if necessary, in that case, returns true
+// handleError handle a network error (timeout, connection refused or reset), reconnects if necessary, in that case, returns true
 func (cnx *AdminConnections) handleError(addr string, err error) bool {
-	if err == nil {
+	if ClassifyError(err) != ErrorClassNetwork {
 		return false
-	} else if netError, ok := err.(net.Error); ok && netError.Timeout() {
-		// timeout, reconnect
-		cnx.Reconnect(addr)
-		return true
-	}
-	switch err.(type) {
-	case *net.OpError:
-		// connection refused, reconnect
-		cnx.Reconnect(addr)
-		return true
 	}
-	return false
+	cnx.Reconnect(addr)
+	return true
 }
 
 func (cnx *AdminConnections) connect(addr string) (ClientInterface, error) {
 	c, err := NewClient(addr, cnx.connectionTimeout, cnx.commandsMapping)
 	if err != nil {
+		cnx.breaker.record(addr, err)
 		return nil, err
 	}
 	if cnx.clientName != "" {
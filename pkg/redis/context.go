@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/mediocregopher/radix.v2/redis"
+)
+
+// WithContext returns an admin sharing the connections of the admin, whose operations stop once the context is done:
+// no command is sent anymore and the waits (failovers, keys migrations, fan-out calls) are interrupted.
+// The deadline of the context also bounds the failovers and the fan-out calls.
+func (a *Admin) WithContext(ctx context.Context) AdminInterface {
+	bound := *a
+	bound.ctx = ctx
+	return &bound
+}
+
+// Context returns the context of the admin, context.Background() if the admin is not bound to a context
+func (a *Admin) Context() context.Context {
+	if a.ctx == nil {
+		return context.Background()
+	}
+	return a.ctx
+}
+
+// sleep waits for the duration, returns the context error if the context is done before
+func (a *Admin) sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-a.Context().Done():
+		return a.Context().Err()
+	}
+}
+
+// deadline returns the earliest time between the given deadline and the deadline of the context
+func (a *Admin) deadline(deadline time.Time) time.Time {
+	if ctxDeadline, ok := a.Context().Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+// contextConnections is the connection map of an admin bound to a context,
+// the client connections are not returned anymore once the context is done, and record their mutating commands
+// if the context is audited
+type contextConnections struct {
+	AdminConnectionsInterface
+	ctx context.Context
+}
+
+// Get returns a client connection for the given address, or the context error if the context is done
+func (cnx *contextConnections) Get(addr string) (ClientInterface, error) {
+	if err := cnx.ctx.Err(); err != nil {
+		return nil, err
+	}
+	c, err := cnx.AdminConnectionsInterface.Get(addr)
+	return cnx.audited(addr, c), err
+}
+
+// GetRandom returns a client connection to a random node, or the context error if the context is done
+func (cnx *contextConnections) GetRandom() (ClientInterface, error) {
+	if err := cnx.ctx.Err(); err != nil {
+		return nil, err
+	}
+	c, err := cnx.AdminConnectionsInterface.GetRandom()
+	return cnx.audited(cnx.addrOf(c), c), err
+}
+
+// GetDifferentFrom returns a random client connection different from the address, or the context error if the context is done
+func (cnx *contextConnections) GetDifferentFrom(addr string) (ClientInterface, error) {
+	if err := cnx.ctx.Err(); err != nil {
+		return nil, err
+	}
+	c, err := cnx.AdminConnectionsInterface.GetDifferentFrom(addr)
+	return cnx.audited(cnx.addrOf(c), c), err
+}
+
+// ValidateResp check the redis resp like the connection map, but doesn't reconnect to the node once the context is
+// done: the connection may have been closed on purpose, like by the timeout of a fan-out call
+func (cnx *contextConnections) ValidateResp(resp *redis.Resp, addr, errMessage string) error {
+	if err := cnx.ctx.Err(); err != nil && (resp == nil || resp.Err != nil) {
+		return &NodeError{Addr: addr, Message: errMessage, Err: err}
+	}
+	return cnx.AdminConnectionsInterface.ValidateResp(resp, addr, errMessage)
+}
+
+// ValidatePipeResp wait for all answers in the pipe and validate the response, without reconnecting to the node
+// once the context is done
+func (cnx *contextConnections) ValidatePipeResp(c ClientInterface, addr, errMessage string) bool {
+	return cnx.ValidatePipeRespError(c, addr, errMessage) == nil
+}
+
+// ValidatePipeRespError wait for all answers in the pipe and validate the response, without reconnecting to the node
+// once the context is done
+func (cnx *contextConnections) ValidatePipeRespError(c ClientInterface, addr, errMessage string) error {
+	if err := cnx.ctx.Err(); err != nil {
+		return &NodeError{Addr: addr, Message: errMessage, Err: err}
+	}
+	return cnx.AdminConnectionsInterface.ValidatePipeRespError(c, addr, errMessage)
+}
+
+// GetAll returns the client connections of all the nodes, recording their mutating commands if the context is audited
+func (cnx *contextConnections) GetAll() map[string]ClientInterface {
+	return cnx.auditedMap(cnx.AdminConnectionsInterface.GetAll())
+}
+
+// GetSelected returns the client connections of the addresses, recording their mutating commands if the context is audited
+func (cnx *contextConnections) GetSelected(addrs []string) map[string]ClientInterface {
+	return cnx.auditedMap(cnx.AdminConnectionsInterface.GetSelected(addrs))
+}
+
+// audited returns the client connection recording its mutating commands if the context is audited, c otherwise
+func (cnx *contextConnections) audited(addr string, c ClientInterface) ClientInterface {
+	info := auditFromContext(cnx.ctx)
+	if info == nil || c == nil {
+		return c
+	}
+	return &auditClient{ClientInterface: c, addr: addr, audit: info}
+}
+
+func (cnx *contextConnections) auditedMap(clients map[string]ClientInterface) map[string]ClientInterface {
+	if auditFromContext(cnx.ctx) == nil {
+		return clients
+	}
+	audited := make(map[string]ClientInterface, len(clients))
+	for addr, c := range clients {
+		audited[addr] = cnx.audited(addr, c)
+	}
+	return audited
+}
+
+// addrOf returns the address of the client connection picked by the connection map
+func (cnx *contextConnections) addrOf(c ClientInterface) string {
+	if c == nil || auditFromContext(cnx.ctx) == nil {
+		return ""
+	}
+	for addr, client := range cnx.AdminConnectionsInterface.GetAll() {
+		if client == c {
+			return addr
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAdmin_WithContext(t *testing.T) {
+	a := NewAdmin(nil, nil).(*Admin)
+	ctx, cancel := context.WithCancel(context.Background())
+	bound := a.WithContext(ctx)
+	if bound.Context() != ctx || a.Context() != context.Background() {
+		t.Errorf("WithContext() should bind only the returned admin to the context")
+	}
+
+	cancel()
+	if _, err := bound.Connections().Get("10.0.0.1:6379"); err != context.Canceled {
+		t.Errorf("Get() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := bound.Connections().GetRandom(); err != context.Canceled {
+		t.Errorf("GetRandom() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := bound.MigrateKeys("10.0.0.1:6379", &Node{}, []Slot{1}, 10, 10, false); err != context.Canceled {
+		t.Errorf("MigrateKeys() error = %v, want %v", err, context.Canceled)
+	}
+	if err := bound.(*Admin).sleep(time.Hour); err != context.Canceled {
+		t.Errorf("sleep() error = %v, want %v", err, context.Canceled)
+	}
+
+	_, errs := bound.(*Admin).fanOut([]string{"10.0.0.1:6379", "10.0.0.2:6379"}, func(_ *Admin, addr string) (interface{}, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+	if len(errs) != 2 || errs["10.0.0.1:6379"] != context.Canceled {
+		t.Errorf("fanOut() errors = %v, want the context error for all the nodes", errs)
+	}
+}
+
+func TestAdmin_deadline(t *testing.T) {
+	a := NewAdmin(nil, nil).(*Admin)
+	later := time.Now().Add(time.Hour)
+	if got := a.deadline(later); !got.Equal(later) {
+		t.Errorf("deadline() = %v, want %v without context", got, later)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ctxDeadline, _ := ctx.Deadline()
+	if got := a.WithContext(ctx).(*Admin).deadline(later); !got.Equal(ctxDeadline) {
+		t.Errorf("deadline() = %v, want the context deadline %v", got, ctxDeadline)
+	}
+}
@@ -1,6 +1,13 @@
 package redis
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
 
 // Error used to represent an error
 type Error string
@@ -68,3 +75,148 @@ func IsInconsistentError(err error) bool {
 	e, ok := err.(ClusterInfosError)
 	return ok && e.Inconsistent()
 }
+
+// FailoverAttemptError represents a failed attempt to promote a slave during a failover
+type FailoverAttemptError struct {
+	SlaveAddr string
+	Mode      string
+	Err       error
+}
+
+// Error error string
+func (e FailoverAttemptError) Error() string {
+	mode := e.Mode
+	if mode == FailoverDefault {
+		mode = "DEFAULT"
+	}
+	return fmt.Sprintf("slave %s, mode %s: %v", e.SlaveAddr, mode, e.Err)
+}
+
+// FailoverError error type returned when the failover of a master failed, contains all the attempts
+type FailoverError struct {
+	MasterAddr string
+	Attempts   []FailoverAttemptError
+}
+
+// Error error string
+func (e *FailoverError) Error() string {
+	s := fmt.Sprintf("Unable to trigger failover for node '%s'", e.MasterAddr)
+	for i, attempt := range e.Attempts {
+		if i == 0 {
+			s += ": "
+		} else {
+			s += ", "
+		}
+		s += fmt.Sprintf("'%s'", attempt.Error())
+	}
+	return s
+}
+
+// IsFailoverError returns true if the error is due to a failed failover
+func IsFailoverError(err error) bool {
+	_, ok := err.(*FailoverError)
+	return ok
+}
+
+// ErrorClass is the class of an error returned by a command sent to a redis node, it tells if the command can be retried
+type ErrorClass int
+
+const (
+	// ErrorClassNone no error
+	ErrorClassNone ErrorClass = iota
+	// ErrorClassPermanent error reply of the node that won't change if the command is sent again
+	ErrorClassPermanent
+	// ErrorClassTransient error reply of a node temporarily unable to serve the command: dataset loading,
+	// slots being migrated, cluster down
+	ErrorClassTransient
+	// ErrorClassNetwork the node cannot be reached: timeout, connection refused or reset
+	ErrorClassNetwork
+)
+
+// transientErrorPrefixes are the prefixes of the error replies sent by a node temporarily unable to serve a command
+var transientErrorPrefixes = []string{"LOADING", "TRYAGAIN", "CLUSTERDOWN", "MASTERDOWN"}
+
+// errNoResponse is returned when no response was read from the node
+const errNoResponse = Error("no response")
+
+// NodeError error returned by a command sent to a redis node, it wraps the error returned by the node or the connection
+type NodeError struct {
+	Addr    string
+	Message string
+	Err     error
+}
+
+// Error error string
+func (e *NodeError) Error() string {
+	if e.Err == errNoResponse {
+		return fmt.Sprintf("%s: Unable to connect to node %s", e.Message, e.Addr)
+	}
+	return fmt.Sprintf("%s: Unexpected error on node %s: %v", e.Message, e.Addr, e.Err)
+}
+
+// CircuitOpenError is returned when the commands to a node are not sent anymore after repeated failures,
+// until the end of the cool down
+type CircuitOpenError struct {
+	Addr  string
+	Until time.Time
+}
+
+// Error error string
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for node %s until %s after repeated failures", e.Addr, e.Until.Format(time.RFC3339))
+}
+
+// IsCircuitOpenError returns true if the error is due to the circuit breaker of the node
+func IsCircuitOpenError(err error) bool {
+	_, ok := err.(*CircuitOpenError)
+	return ok
+}
+
+// IsSlotsAlreadyAppliedError returns true if the error is the reply of CLUSTER ADDSLOTS for a slot already assigned,
+// or of CLUSTER DELSLOTS for a slot already unassigned
+func IsSlotsAlreadyAppliedError(err error) bool {
+	if nodeErr, ok := err.(*NodeError); ok {
+		err = nodeErr.Err
+	}
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.HasPrefix(msg, "ERR Slot") && (strings.HasSuffix(msg, "is already busy") || strings.HasSuffix(msg, "is already unassigned"))
+}
+
+// ClassifyError returns the class of an error returned by a command sent to a redis node
+func ClassifyError(err error) ErrorClass {
+	if nodeErr, ok := err.(*NodeError); ok {
+		err = nodeErr.Err
+	}
+	switch err {
+	case nil:
+		return ErrorClassNone
+	case errNoResponse, io.EOF, io.ErrUnexpectedEOF, syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.EPIPE:
+		return ErrorClassNetwork
+	}
+	switch err.(type) {
+	case *CircuitOpenError:
+		// the node is not reached at all, retrying before the end of the cool down is useless
+		return ErrorClassPermanent
+	case net.Error:
+		return ErrorClassNetwork
+	}
+	msg := err.Error()
+	for _, prefix := range transientErrorPrefixes {
+		if strings.HasPrefix(msg, prefix) {
+			return ErrorClassTransient
+		}
+	}
+	if strings.Contains(msg, "connection reset by peer") || strings.Contains(msg, "broken pipe") {
+		return ErrorClassNetwork
+	}
+	return ErrorClassPermanent
+}
+
+// IsRetryableError returns true if the command may succeed if sent again to the node
+func IsRetryableError(err error) bool {
+	class := ClassifyError(err)
+	return class == ErrorClassTransient || class == ErrorClassNetwork
+}
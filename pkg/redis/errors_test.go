@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{name: "no error", err: nil, want: ErrorClassNone},
+		{name: "loading", err: &NodeError{Err: errors.New("LOADING Redis is loading the dataset in memory")}, want: ErrorClassTransient},
+		{name: "try again", err: errors.New("TRYAGAIN Multiple keys request during rehashing of slot"), want: ErrorClassTransient},
+		{name: "cluster down", err: &NodeError{Err: errors.New("CLUSTERDOWN The cluster is down")}, want: ErrorClassTransient},
+		{name: "no response", err: &NodeError{Err: errNoResponse}, want: ErrorClassNetwork},
+		{name: "connection closed", err: &NodeError{Err: io.EOF}, want: ErrorClassNetwork},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: ErrorClassNetwork},
+		{name: "connection reset", err: errors.New("read tcp 10.0.0.1:6379: read: connection reset by peer"), want: ErrorClassNetwork},
+		{name: "error reply", err: &NodeError{Err: errors.New("ERR Unknown node 1234")}, want: ErrorClassPermanent},
+		{name: "circuit open", err: &CircuitOpenError{Addr: "10.0.0.1:6379", Until: time.Now()}, want: ErrorClassPermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError() = %v, want %v", got, tt.want)
+			}
+			wantRetryable := tt.want == ErrorClassTransient || tt.want == ErrorClassNetwork
+			if got := IsRetryableError(tt.err); got != wantRetryable {
+				t.Errorf("IsRetryableError() = %v, want %v", got, wantRetryable)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package redis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// FailoverDefault default mode of the CLUSTER FAILOVER command, the master has to be reachable
+	FailoverDefault = ""
+	// FailoverForce FORCE mode of the CLUSTER FAILOVER command, doesn't require the master agreement
+	FailoverForce = "FORCE"
+	// FailoverTakeover TAKEOVER mode of the CLUSTER FAILOVER command, doesn't require the agreement of the other masters
+	FailoverTakeover = "TAKEOVER"
+
+	// DefaultFailoverTimeout default maximum duration of a failover
+	DefaultFailoverTimeout = 60 * time.Second
+
+	// failoverAttemptTimeout maximum duration of a single failover attempt
+	failoverAttemptTimeout = 15 * time.Second
+	// failoverMaxPollInterval maximum interval between two checks of the failover completion
+	failoverMaxPollInterval = 4 * time.Second
+)
+
+// FailoverOptions optional options for the failover of a master
+type FailoverOptions struct {
+	// Deadline of the failover, defaulted to now + DefaultFailoverTimeout
+	Deadline time.Time
+	// AvoidedSlaves addresses of the slaves that should be promoted only if no other slave is available,
+	// for instance the slaves hosted on the same host than the master, or on an host being drained
+	AvoidedSlaves []string
+	// Modes of the CLUSTER FAILOVER command tried in order. If empty, the default mode is used when the master
+	// is reachable, FORCE then TAKEOVER otherwise.
+	Modes []string
+}
+
+// failoverCandidate slave candidate to a failover with its replication info
+type failoverCandidate struct {
+	node    *Node
+	info    *ReplicationInfo
+	avoided bool
+}
+
+// StartFailover used to force the failover of a specific redis master node. The slaves are ranked by link status,
+// placement and replication offset, and the most up-to-date slave is promoted. If the master is unreachable, the FORCE
+// then the TAKEOVER modes are used. Each failed attempt is reported in the returned FailoverError.
+func (a *Admin) StartFailover(addr string, options *FailoverOptions) error {
+	deadline := time.Now().Add(DefaultFailoverTimeout)
+	var avoidedSlaves, modes []string
+	if options != nil {
+		if !options.Deadline.IsZero() {
+			deadline = options.Deadline
+		}
+		avoidedSlaves = options.AvoidedSlaves
+		modes = options.Modes
+	}
+	deadline = a.deadline(deadline)
+
+	master, slaves, masterReachable, err := a.getMasterAndSlaves(addr)
+	if err != nil {
+		return err
+	}
+	if master == nil {
+		// if not a Master dont failover
+		return nil
+	}
+	if len(slaves) == 0 {
+		return fmt.Errorf("Master id:%s dont have associated slave", master.ID)
+	}
+
+	failoverErr := &FailoverError{MasterAddr: addr}
+	candidates := []failoverCandidate{}
+	for _, slave := range slaves {
+		info, err := a.GetReplicationInfo(slave.IPPort())
+		if err != nil {
+			failoverErr.Attempts = append(failoverErr.Attempts, FailoverAttemptError{SlaveAddr: slave.IPPort(), Err: err})
+			continue
+		}
+		candidates = append(candidates, failoverCandidate{
+			node:    slave,
+			info:    info,
+			avoided: containsAddr(avoidedSlaves, slave.IPPort()),
+		})
+	}
+	sortFailoverCandidates(candidates)
+
+	if len(modes) == 0 {
+		modes = []string{FailoverDefault}
+		if !masterReachable {
+			modes = []string{FailoverForce, FailoverTakeover}
+		}
+	}
+	for _, mode := range modes {
+		for _, candidate := range candidates {
+			if time.Now().After(deadline) {
+				failoverErr.Attempts = append(failoverErr.Attempts, FailoverAttemptError{SlaveAddr: candidate.node.IPPort(), Mode: mode, Err: fmt.Errorf("failover deadline exceeded")})
+				return failoverErr
+			}
+			if err = a.Context().Err(); err != nil {
+				failoverErr.Attempts = append(failoverErr.Attempts, FailoverAttemptError{SlaveAddr: candidate.node.IPPort(), Mode: mode, Err: err})
+				return failoverErr
+			}
+			glog.Infof("failover of master %s, promoting slave %s (offset %d) with mode '%s'", master.ID, candidate.node.ID, candidate.info.SlaveReplOffset, mode)
+			attemptDeadline := time.Now().Add(failoverAttemptTimeout)
+			if attemptDeadline.After(deadline) {
+				attemptDeadline = deadline
+			}
+			err = a.failoverAttempt(addr, candidate.node, mode, masterReachable, attemptDeadline)
+			if err == nil {
+				glog.Info("failover completed")
+				return nil
+			}
+			failoverErr.Attempts = append(failoverErr.Attempts, FailoverAttemptError{SlaveAddr: candidate.node.IPPort(), Mode: mode, Err: err})
+		}
+	}
+
+	return failoverErr
+}
+
+// getMasterAndSlaves returns the master corresponding to the address and its slaves. If the master is unreachable,
+// the cluster view of the other nodes is used. Returns a nil master if the node is not a master.
+func (a *Admin) getMasterAndSlaves(addr string) (*Node, Nodes, bool, error) {
+	if me, err := a.getInfos(addr); err == nil {
+		if me.Node.Role != redisMasterRole {
+			return nil, nil, true, nil
+		}
+		slaves, err := selectMySlaves(me.Node, me.Friends)
+		if err != nil {
+			return nil, nil, true, fmt.Errorf("Unable to found associated slaves, err:%s", err)
+		}
+		return me.Node, slaves, true, nil
+	}
+
+	glog.Warningf("master %s unreachable, retrieving its slaves from the other nodes", addr)
+	for otherAddr := range a.Connections().GetAll() {
+		if otherAddr == addr {
+			continue
+		}
+		infos, err := a.getInfos(otherAddr)
+		if err != nil {
+			continue
+		}
+		nodes := append(Nodes{infos.Node}, infos.Friends...)
+		masters := nodes.FilterByFunc(func(n *Node) bool { return n.IPPort() == addr })
+		if len(masters) == 0 {
+			continue
+		}
+		if masters[0].Role != redisMasterRole {
+			return nil, nil, false, nil
+		}
+		slaves := nodes.FilterByFunc(func(n *Node) bool { return n.MasterReferent == masters[0].ID })
+		return masters[0], slaves, false, nil
+	}
+	return nil, nil, false, fmt.Errorf("Unable to retrieve the node %s from the cluster", addr)
+}
+
+// failoverAttempt sends the CLUSTER FAILOVER command to the slave and waits for its completion until the deadline
+func (a *Admin) failoverAttempt(masterAddr string, slave *Node, mode string, masterReachable bool, deadline time.Time) error {
+	slaveClient, err := a.Connections().Get(slave.IPPort())
+	if err != nil {
+		return err
+	}
+	args := []interface{}{"FAILOVER"}
+	if mode != FailoverDefault {
+		args = append(args, mode)
+	}
+	resp := slaveClient.Cmd("CLUSTER", args...)
+	if err = a.Connections().ValidateResp(resp, slave.IPPort(), "Unable to execute Failover"); err != nil {
+		return err
+	}
+
+	pollInterval := 500 * time.Millisecond
+	for {
+		if a.isFailoverCompleted(masterAddr, slave, masterReachable) {
+			return nil
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return fmt.Errorf("failover not completed before the deadline")
+		}
+		glog.Info("waiting failover to be complete...")
+		if err = a.sleep(pollInterval); err != nil {
+			return err
+		}
+		if pollInterval *= 2; pollInterval > failoverMaxPollInterval {
+			pollInterval = failoverMaxPollInterval
+		}
+	}
+}
+
+// isFailoverCompleted returns true if the old master doesn't own slots anymore, or if the master is unreachable,
+// if the slave has been promoted
+func (a *Admin) isFailoverCompleted(masterAddr string, slave *Node, masterReachable bool) bool {
+	addr := slave.IPPort()
+	if masterReachable {
+		addr = masterAddr
+	}
+	me, err := a.getInfos(addr)
+	if err != nil {
+		return false
+	}
+	if masterReachable {
+		// we should wait that all slots have been moved to the new master
+		// this is the only way to know that we can stop this master with no impact on the cluster
+		return me.Node.TotalSlots() == 0
+	}
+	return me.Node.Role == redisMasterRole && me.Node.TotalSlots() > 0
+}
+
+// sortFailoverCandidates sorts the slaves from the best to the worst candidate: link up first,
+// then the slaves not avoided, then the highest replication offset
+func sortFailoverCandidates(candidates []failoverCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.info.IsLinkUp() != cj.info.IsLinkUp() {
+			return ci.info.IsLinkUp()
+		}
+		if ci.avoided != cj.avoided {
+			return !ci.avoided
+		}
+		return ci.info.SlaveReplOffset > cj.info.SlaveReplOffset
+	})
+}
+
+func containsAddr(addrs []string, addr string) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// BumpConfigEpoch exec the CLUSTER BUMPEPOCH command: the node gets a config epoch greater than the ones it knows,
+// without the agreement of the other masters. Used to resolve the config epoch collisions after a takeover.
+func (a *Admin) BumpConfigEpoch(addr string) error {
+	_, err := a.cmd(addr, "Unable to run CLUSTER BUMPEPOCH", "CLUSTER", "BUMPEPOCH")
+	return err
+}
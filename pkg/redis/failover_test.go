@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_sortFailoverCandidates(t *testing.T) {
+	upToDate := failoverCandidate{node: &Node{ID: "upToDate"}, info: &ReplicationInfo{Role: "slave", MasterLinkStatus: RedisMasterLinkStatusUp, SlaveReplOffset: 1000}}
+	late := failoverCandidate{node: &Node{ID: "late"}, info: &ReplicationInfo{Role: "slave", MasterLinkStatus: RedisMasterLinkStatusUp, SlaveReplOffset: 500}}
+	avoided := failoverCandidate{node: &Node{ID: "avoided"}, info: &ReplicationInfo{Role: "slave", MasterLinkStatus: RedisMasterLinkStatusUp, SlaveReplOffset: 2000}, avoided: true}
+	linkDown := failoverCandidate{node: &Node{ID: "linkDown"}, info: &ReplicationInfo{Role: "slave", MasterLinkStatus: RedisMasterLinkStatusDown, SlaveReplOffset: 3000}}
+	linkDownLate := failoverCandidate{node: &Node{ID: "linkDownLate"}, info: &ReplicationInfo{Role: "slave", MasterLinkStatus: RedisMasterLinkStatusDown, SlaveReplOffset: 100}}
+
+	tests := []struct {
+		name       string
+		candidates []failoverCandidate
+		want       []string
+	}{
+		{
+			name:       "by offset",
+			candidates: []failoverCandidate{late, upToDate},
+			want:       []string{"upToDate", "late"},
+		},
+		{
+			name:       "avoided slave last",
+			candidates: []failoverCandidate{avoided, late, upToDate},
+			want:       []string{"upToDate", "late", "avoided"},
+		},
+		{
+			name:       "link down last",
+			candidates: []failoverCandidate{linkDownLate, linkDown, avoided, late, upToDate},
+			want:       []string{"upToDate", "late", "avoided", "linkDown", "linkDownLate"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortFailoverCandidates(tt.candidates)
+			got := []string{}
+			for _, c := range tt.candidates {
+				got = append(got, c.node.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortFailoverCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailoverError_Error(t *testing.T) {
+	err := &FailoverError{
+		MasterAddr: "10.0.0.1:6379",
+		Attempts: []FailoverAttemptError{
+			{SlaveAddr: "10.0.0.2:6379", Mode: FailoverForce, Err: fmt.Errorf("timeout")},
+			{SlaveAddr: "10.0.0.2:6379", Mode: FailoverTakeover, Err: fmt.Errorf("timeout")},
+		},
+	}
+	want := "Unable to trigger failover for node '10.0.0.1:6379': 'slave 10.0.0.2:6379, mode FORCE: timeout', 'slave 10.0.0.2:6379, mode TAKEOVER: timeout'"
+	if got := err.Error(); got != want {
+		t.Errorf("FailoverError.Error() = %v, want %v", got, want)
+	}
+	if !IsFailoverError(err) {
+		t.Errorf("IsFailoverError() = false, want true")
+	}
+}
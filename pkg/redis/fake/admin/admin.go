@@ -1,6 +1,9 @@
 package admin
 
 import (
+	"context"
+	"time"
+
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 )
 
@@ -28,6 +31,43 @@ type CountKeysInSlotRetType struct {
 	Err    error
 }
 
+// ReplicationInfoRetType structure to describe the return data of GetReplicationInfo method
+type ReplicationInfoRetType struct {
+	Info *redis.ReplicationInfo
+	Err  error
+}
+
+// ServerInfoRetType structure to describe the return data of GetServerInfo method
+type ServerInfoRetType struct {
+	Info *redis.ServerInfo
+	Err  error
+}
+
+// PersistenceInfoRetType structure to describe the return data of GetPersistenceInfo method
+type PersistenceInfoRetType struct {
+	Info *redis.PersistenceInfo
+	Err  error
+}
+
+// SentinelGetMasterAddrRetType structure to describe the return data of SentinelGetMasterAddr method
+type SentinelGetMasterAddrRetType struct {
+	Addr string
+	Err  error
+}
+
+// ScanKeysRetType structure to describe the return data of ScanKeys method
+type ScanKeysRetType struct {
+	Cursor string
+	Keys   []string
+	Err    error
+}
+
+// DumpKeysRetType structure to describe the return data of DumpKeys method
+type DumpKeysRetType struct {
+	Dumps []redis.KeyDump
+	Err   error
+}
+
 // ClusterInfosRetType structure to describe the return data of GetClusterInfosRet method
 type ClusterInfosRetType struct {
 	ClusterInfos *redis.ClusterInfos
@@ -51,6 +91,8 @@ type Admin struct {
 	UpdateClientsRet map[string]error
 	// StartFailoverRet map of returned error for StartFailover function
 	StartFailoverRet map[string]error
+	// BumpConfigEpochRet map of returned error for BumpConfigEpoch function
+	BumpConfigEpochRet map[string]error
 	// ForgetNodeRet map of returned error for ForgetNode function
 	ForgetNodeRet map[string]error
 	// SetSlotsRet map of returned error for SetSlots function
@@ -63,6 +105,24 @@ type Admin struct {
 	GetKeysInSlotRet map[string]GetKeysInSlotRetType
 	// CountKeysInSlotRet map of returned data for for CountKeysInSlot function
 	CountKeysInSlotRet map[string]CountKeysInSlotRetType
+	// GetReplicationInfoRet map of returned data for GetReplicationInfo function
+	GetReplicationInfoRet map[string]ReplicationInfoRetType
+	// GetServerInfoRet map of returned data for GetServerInfo function
+	GetServerInfoRet map[string]ServerInfoRetType
+	// SetReplicaOfRet map of returned error for SetReplicaOf function
+	SetReplicaOfRet map[string]error
+	// SentinelGetMasterAddrRet map of returned data for SentinelGetMasterAddr function
+	SentinelGetMasterAddrRet map[string]SentinelGetMasterAddrRetType
+	// SentinelMonitorRet map of returned error for SentinelMonitor function
+	SentinelMonitorRet map[string]error
+	// ScanKeysRet map of returned data for ScanKeys function
+	ScanKeysRet map[string]ScanKeysRetType
+	// DumpKeysRet map of returned data for DumpKeys function
+	DumpKeysRet map[string]DumpKeysRetType
+	// RestoreKeysRet map of returned error for RestoreKeys function
+	RestoreKeysRet map[string]error
+	// DeleteKeysRet map of returned error for DeleteKeys function
+	DeleteKeysRet map[string]error
 	// MigrateKeysRet map of returned error for MigrateKeys function
 	MigrateKeysRet map[string]MigrateKeyRetType
 	// AttachSlaveToMasterRet map of returned error for AttachSlaveToMaster function
@@ -71,7 +131,11 @@ type Admin struct {
 	DetachSlaveToMasterRet map[string]error
 	// ResetRet map of returned error for FlushAndReset function
 	FlushAndResetRet map[string]error
-	cnx              *Connections
+	// GetPersistenceInfoRet map of returned data for GetPersistenceInfo function
+	GetPersistenceInfoRet map[string]PersistenceInfoRetType
+	// BackupNodeRet map of returned error for BackupNode function
+	BackupNodeRet map[string]error
+	cnx           *Connections
 }
 
 // NewFakeAdmin returns new AdminInterface for fake admin
@@ -84,16 +148,28 @@ func NewFakeAdmin(addrs []string) *Admin {
 		AttachNodeToClusterRet:     make(map[string]error),
 		UpdateClientsRet:           make(map[string]error),
 		StartFailoverRet:           make(map[string]error),
+		BumpConfigEpochRet:         make(map[string]error),
 		ForgetNodeRet:              make(map[string]error),
 		SetSlotsRet:                make(map[string]error),
 		AddSlotsRet:                make(map[string]error),
 		DelSlotsRet:                make(map[string]error),
 		GetKeysInSlotRet:           make(map[string]GetKeysInSlotRetType),
 		CountKeysInSlotRet:         make(map[string]CountKeysInSlotRetType),
+		GetReplicationInfoRet:      make(map[string]ReplicationInfoRetType),
+		GetServerInfoRet:           make(map[string]ServerInfoRetType),
+		SetReplicaOfRet:            make(map[string]error),
+		SentinelGetMasterAddrRet:   make(map[string]SentinelGetMasterAddrRetType),
+		SentinelMonitorRet:         make(map[string]error),
+		ScanKeysRet:                make(map[string]ScanKeysRetType),
+		DumpKeysRet:                make(map[string]DumpKeysRetType),
+		RestoreKeysRet:             make(map[string]error),
+		DeleteKeysRet:              make(map[string]error),
 		MigrateKeysRet:             make(map[string]MigrateKeyRetType),
 		AttachSlaveToMasterRet:     make(map[string]error),
 		DetachSlaveToMasterRet:     make(map[string]error),
 		FlushAndResetRet:           make(map[string]error),
+		GetPersistenceInfoRet:      make(map[string]PersistenceInfoRetType),
+		BackupNodeRet:              make(map[string]error),
 		cnx:                        &Connections{},
 	}
 }
@@ -102,6 +178,16 @@ func NewFakeAdmin(addrs []string) *Admin {
 func (a *Admin) Close() {
 }
 
+// WithContext returns the fake admin itself, the fake operations are not interrupted
+func (a *Admin) WithContext(ctx context.Context) redis.AdminInterface {
+	return a
+}
+
+// Context returns context.Background()
+func (a *Admin) Context() context.Context {
+	return context.Background()
+}
+
 // Connections returns a connection map
 func (a *Admin) Connections() redis.AdminConnectionsInterface {
 	return a.cnx
@@ -141,7 +227,7 @@ func (a *Admin) GetClusterInfosSelected(addr []string) (*redis.ClusterInfos, err
 }
 
 // StartFailover used to force the failover of a specific redis master node
-func (a *Admin) StartFailover(addr string) error {
+func (a *Admin) StartFailover(addr string, options *redis.FailoverOptions) error {
 	val, ok := a.StartFailoverRet[addr]
 	if !ok {
 		val = nil
@@ -149,6 +235,11 @@ func (a *Admin) StartFailover(addr string) error {
 	return val
 }
 
+// BumpConfigEpoch exec the CLUSTER BUMPEPOCH command on the node
+func (a *Admin) BumpConfigEpoch(addr string) error {
+	return a.BumpConfigEpochRet[addr]
+}
+
 // ForgetNode used to force other redis cluster node to forget a specific node
 func (a *Admin) ForgetNode(addr string) error {
 	val, ok := a.ForgetNodeRet[addr]
@@ -212,6 +303,71 @@ func (a *Admin) CountKeysInSlot(addr string, node redis.Slot) (int64, error) {
 	return val.NbKeys, val.Err
 }
 
+// GetReplicationInfo exec the INFO REPLICATION redis command on the node and decode it
+func (a *Admin) GetReplicationInfo(addr string) (*redis.ReplicationInfo, error) {
+	val, ok := a.GetReplicationInfoRet[addr]
+	if !ok {
+		val = ReplicationInfoRetType{Info: &redis.ReplicationInfo{}, Err: nil}
+	}
+	return val.Info, val.Err
+}
+
+// GetServerInfo exec the INFO redis command on the node and decode it
+func (a *Admin) GetServerInfo(addr string) (*redis.ServerInfo, error) {
+	val, ok := a.GetServerInfoRet[addr]
+	if !ok {
+		val = ServerInfoRetType{Info: &redis.ServerInfo{}, Err: nil}
+	}
+	return val.Info, val.Err
+}
+
+// SetReplicaOf configures the node as a replica of the master, or as a master if masterIP is empty
+func (a *Admin) SetReplicaOf(addr, masterIP, masterPort string) error {
+	return a.SetReplicaOfRet[addr]
+}
+
+// SentinelGetMasterAddr returns the address of the master monitored by the sentinel, empty if not monitored
+func (a *Admin) SentinelGetMasterAddr(addr, name string) (string, error) {
+	val, ok := a.SentinelGetMasterAddrRet[addr]
+	if !ok {
+		val = SentinelGetMasterAddrRetType{Addr: "", Err: nil}
+	}
+	return val.Addr, val.Err
+}
+
+// SentinelMonitor makes the sentinel monitor the master under the given name
+func (a *Admin) SentinelMonitor(addr, name, masterIP, masterPort string, quorum int32) error {
+	return a.SentinelMonitorRet[addr]
+}
+
+// ScanKeys runs one iteration of the SCAN command on the node
+func (a *Admin) ScanKeys(addr string, cursor string, count int) (string, []string, error) {
+	val, ok := a.ScanKeysRet[addr]
+	if !ok {
+		val = ScanKeysRetType{Cursor: "0", Keys: []string{}, Err: nil}
+	}
+	return val.Cursor, val.Keys, val.Err
+}
+
+// DumpKeys serializes the keys of the node with the DUMP command
+func (a *Admin) DumpKeys(addr string, keys []string) ([]redis.KeyDump, error) {
+	val, ok := a.DumpKeysRet[addr]
+	if !ok {
+		val = DumpKeysRetType{Dumps: []redis.KeyDump{}, Err: nil}
+	}
+	return val.Dumps, val.Err
+}
+
+// RestoreKeys restores the serialized keys on the node
+func (a *Admin) RestoreKeys(addr string, dumps []redis.KeyDump) error {
+	return a.RestoreKeysRet[addr]
+}
+
+// DeleteKeys deletes the keys on the node
+func (a *Admin) DeleteKeys(addr string, keys []string) error {
+	return a.DeleteKeysRet[addr]
+}
+
 // MigrateKeys use to migrate keys from slots to other slots
 func (a *Admin) MigrateKeys(addr string, dest *redis.Node, slots []redis.Slot, batch, timeout int, replace bool) (int, error) {
 	val, ok := a.MigrateKeysRet[addr]
@@ -248,6 +404,20 @@ func (a *Admin) FlushAndReset(addr string, mode string) error {
 	return val
 }
 
+// GetPersistenceInfo exec the INFO PERSISTENCE redis command on the node and decode it
+func (a *Admin) GetPersistenceInfo(addr string) (*redis.PersistenceInfo, error) {
+	val, ok := a.GetPersistenceInfoRet[addr]
+	if !ok {
+		val = PersistenceInfoRetType{Info: &redis.PersistenceInfo{LastBgsaveStatus: "ok"}, Err: nil}
+	}
+	return val.Info, val.Err
+}
+
+// BackupNode dumps the keys of the node with BGSAVE in the given file of its working directory
+func (a *Admin) BackupNode(addr, filename string, timeout time.Duration) error {
+	return a.BackupNodeRet[addr]
+}
+
 // FlushAll flush all keys in cluster
 func (a *Admin) FlushAll() {
 }
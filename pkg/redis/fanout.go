@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// defaultParallelism is the default maximum number of nodes queried at the same time
+	defaultParallelism = 16
+	// callTimeoutFactor is the number of connection timeouts given to a node to answer a fan-out call,
+	// a call can send several commands
+	callTimeoutFactor = 2
+	// defaultCallTimeout is the default time given to a node to answer a fan-out call
+	defaultCallTimeout = callTimeoutFactor * defaultClientTimeout
+)
+
+// fanOutResult is the result of a fan-out call on a node
+type fanOutResult struct {
+	addr  string
+	value interface{}
+	err   error
+}
+
+// fanOut runs the call on each address, with at most parallelism calls at the same time. A call that doesn't return
+// before the call timeout, or before the context of the admin is done, is reported as failed and the connection to
+// the node is closed, its late result is ignored. Each call gets an admin whose context is canceled before the
+// connection is closed: the late call doesn't send commands, retry nor reconnect to the node anymore.
+// Returns the values of the successful calls and the errors of the failed ones, by address.
+func (a *Admin) fanOut(addrs []string, call func(admin *Admin, addr string) (interface{}, error)) (map[string]interface{}, map[string]error) {
+	results := make(chan fanOutResult, len(addrs))
+	tokens := make(chan struct{}, a.parallelism)
+	ctx := a.Context()
+	for _, addr := range addrs {
+		go func(addr string) {
+			select {
+			case tokens <- struct{}{}:
+				defer func() { <-tokens }()
+			case <-ctx.Done():
+				results <- fanOutResult{addr: addr, err: ctx.Err()}
+				return
+			}
+
+			callCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			callAdmin := a.WithContext(callCtx).(*Admin)
+			done := make(chan fanOutResult, 1)
+			go func() {
+				value, err := call(callAdmin, addr)
+				done <- fanOutResult{addr: addr, value: value, err: err}
+			}()
+			timer := time.NewTimer(time.Until(a.deadline(time.Now().Add(a.callTimeout))))
+			defer timer.Stop()
+			select {
+			case result := <-done:
+				results <- result
+			case <-ctx.Done():
+				a.Connections().Remove(addr)
+				results <- fanOutResult{addr: addr, err: ctx.Err()}
+			case <-timer.C:
+				glog.Warningf("no answer from node %s after %v", addr, a.callTimeout)
+				// closing the connection unblocks the call, once its context is canceled to not reconnect
+				cancel()
+				a.Connections().Remove(addr)
+				results <- fanOutResult{addr: addr, err: fmt.Errorf("no answer from node %s after %v", addr, a.callTimeout)}
+			}
+		}(addr)
+	}
+
+	values := map[string]interface{}{}
+	errs := map[string]error{}
+	for range addrs {
+		result := <-results
+		if result.err != nil {
+			errs[result.addr] = result.err
+			continue
+		}
+		values[result.addr] = result.value
+	}
+	return values, errs
+}
+
+// collectInfos retrieves concurrently the infos of each node, the nodes that can't be queried make the infos partial
+func (a *Admin) collectInfos(clients map[string]ClientInterface, infos *ClusterInfos, clusterErr *ClusterInfosError) {
+	addrs := make([]string, 0, len(clients))
+	for addr := range clients {
+		addrs = append(addrs, addr)
+	}
+	values, errs := a.fanOut(addrs, func(admin *Admin, addr string) (interface{}, error) {
+		return admin.getInfos(addr)
+	})
+	for addr, err := range errs {
+		infos.Status = ClusterInfosPartial
+		clusterErr.partial = true
+		clusterErr.errs[addr] = err
+	}
+	for addr, value := range values {
+		nodeinfos := value.(*NodeInfos)
+		if nodeinfos.Node != nil && nodeinfos.Node.IPPort() == addr {
+			infos.Infos[addr] = nodeinfos
+		} else {
+			glog.Warningf("Bad node info retreived from %s", addr)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdmin_fanOut(t *testing.T) {
+	a := &Admin{cnx: NewAdminConnections(nil, nil), parallelism: 3, callTimeout: 200 * time.Millisecond}
+	addrs := []string{}
+	for i := 0; i < 10; i++ {
+		addrs = append(addrs, fmt.Sprintf("10.0.0.%d:6379", i))
+	}
+	addrs = append(addrs, "failing:6379", "slow:6379")
+
+	var running, maxRunning int32
+	values, errs := a.fanOut(addrs, func(_ *Admin, addr string) (interface{}, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		switch addr {
+		case "failing:6379":
+			return nil, fmt.Errorf("connection refused")
+		case "slow:6379":
+			time.Sleep(time.Second)
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+		return addr, nil
+	})
+
+	if maxRunning > 3 {
+		t.Errorf("fanOut() ran %d calls at the same time, want at most 3", maxRunning)
+	}
+	if len(values) != 10 {
+		t.Errorf("fanOut() returned %d values, want 10", len(values))
+	}
+	for addr, value := range values {
+		if value.(string) != addr {
+			t.Errorf("fanOut() value of %s = %v", addr, value)
+		}
+	}
+	if len(errs) != 2 || errs["failing:6379"] == nil || errs["slow:6379"] == nil {
+		t.Errorf("fanOut() errors = %v, want the failing and the slow nodes", errs)
+	}
+}
+
+func TestAdmin_fanOutTimeout(t *testing.T) {
+	a := &Admin{cnx: NewAdminConnections(nil, nil), parallelism: 1, callTimeout: 50 * time.Millisecond}
+	lateErr := make(chan error, 1)
+	_, errs := a.fanOut([]string{"slow:6379"}, func(admin *Admin, addr string) (interface{}, error) {
+		time.Sleep(200 * time.Millisecond)
+		_, err := admin.Connections().Get(addr)
+		lateErr <- err
+		return nil, err
+	})
+	if errs["slow:6379"] == nil {
+		t.Errorf("fanOut() errors = %v, want the slow node", errs)
+	}
+	// the late call must not reconnect to the node whose connection has been closed
+	if err := <-lateErr; err != context.Canceled {
+		t.Errorf("late call Get() error = %v, want %v", err, context.Canceled)
+	}
+	if len(a.Connections().GetAll()) != 0 {
+		t.Errorf("the connection to the slow node should not be added back")
+	}
+}
@@ -0,0 +1,211 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+)
+
+const (
+	// guardRailAllTargets is the override allowing all the targets of a guard rail
+	guardRailAllTargets = "*"
+	// guardRailFlushAllTarget is the target of the guard rail refusing a FLUSHALL on a random node
+	guardRailFlushAllTarget = "all"
+)
+
+// GuardRails is the policy applied by a GuardedAdmin before the destructive commands
+type GuardRails struct {
+	// MaxFlushKeys is the number of keys above which a node is not flushed, no limit if negative
+	MaxFlushKeys int64
+	// Overrides contains by rule the targets allowed despite the guard rails, "*" allows them all
+	Overrides map[v1.RedisClusterGuardRailRule][]string
+	// Report is called with the result of each guard rail checked, err is nil if the command is allowed
+	Report func(rule v1.RedisClusterGuardRailRule, target string, err error)
+}
+
+// GuardRailError is returned when a destructive command is refused by a guard rail
+type GuardRailError struct {
+	Rule    v1.RedisClusterGuardRailRule
+	Target  string
+	Message string
+}
+
+func (e *GuardRailError) Error() string {
+	return e.Message
+}
+
+// IsGuardRailError returns true if the command has been refused by a guard rail
+func IsGuardRailError(err error) bool {
+	_, ok := err.(*GuardRailError)
+	return ok
+}
+
+// GuardedAdmin wraps an AdminInterface, the destructive commands are refused when they break the guard rails.
+// The commands sent on the client connections returned by Connections() are not checked, the destructive commands
+// must be sent with the methods of the admin.
+type GuardedAdmin struct {
+	AdminInterface
+	rails GuardRails
+}
+
+// NewGuardedAdmin returns the admin applying the guard rails before the destructive commands
+func NewGuardedAdmin(admin AdminInterface, rails GuardRails) *GuardedAdmin {
+	return &GuardedAdmin{AdminInterface: admin, rails: rails}
+}
+
+// GuardAs returns admin guarded by the same guard rails as guarded, admin itself if guarded is not a GuardedAdmin
+func GuardAs(guarded, admin AdminInterface) AdminInterface {
+	if g, ok := guarded.(*GuardedAdmin); ok {
+		return NewGuardedAdmin(admin, g.rails)
+	}
+	return admin
+}
+
+// WithContext returns a guarded admin sharing the connections, whose operations stop once the context is done
+func (g *GuardedAdmin) WithContext(ctx context.Context) AdminInterface {
+	return NewGuardedAdmin(g.AdminInterface.WithContext(ctx), g.rails)
+}
+
+// FlushAndReset flushes and resets the node if it holds at most MaxFlushKeys keys
+func (g *GuardedAdmin) FlushAndReset(addr string, mode string) error {
+	if err := g.checkFlush(addr); err != nil {
+		return err
+	}
+	return g.AdminInterface.FlushAndReset(addr, mode)
+}
+
+// FlushAll flushes a random node if none of the nodes holds more than MaxFlushKeys keys
+func (g *GuardedAdmin) FlushAll() {
+	if g.rails.MaxFlushKeys >= 0 && !g.overridden(v1.GuardRailFlushKeys, guardRailFlushAllTarget) {
+		infos, _ := g.GetClusterInfos()
+		for addr := range infos.Infos {
+			if err := g.countKeys(addr, guardRailFlushAllTarget); err != nil {
+				g.report(v1.GuardRailFlushKeys, guardRailFlushAllTarget, err)
+				return
+			}
+		}
+	}
+	g.report(v1.GuardRailFlushKeys, guardRailFlushAllTarget, nil)
+	g.AdminInterface.FlushAll()
+}
+
+// ForgetNode makes the cluster forget the node if it owns no slot and, for a master, if its own view confirms it
+func (g *GuardedAdmin) ForgetNode(id string) error {
+	infos, _ := g.GetClusterInfos()
+	if err := g.checkForget(infos, id); err != nil {
+		return err
+	}
+	return g.AdminInterface.ForgetNode(id)
+}
+
+// ForgetNodeByAddr makes the cluster forget the node if it owns no slot and, for a master, if its own view confirms it
+func (g *GuardedAdmin) ForgetNodeByAddr(addr string) error {
+	infos, _ := g.GetClusterInfos()
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil || nodeinfos.Node == nil {
+			continue
+		}
+		for _, node := range append(Nodes{nodeinfos.Node}, nodeinfos.Friends...) {
+			if node.IPPort() == addr {
+				if err := g.checkForget(infos, node.ID); err != nil {
+					return err
+				}
+				return g.AdminInterface.ForgetNodeByAddr(addr)
+			}
+		}
+	}
+	return g.AdminInterface.ForgetNodeByAddr(addr)
+}
+
+// checkFlush returns a GuardRailError if the node holds more than MaxFlushKeys keys, or if its keys can't be counted
+func (g *GuardedAdmin) checkFlush(addr string) error {
+	if g.rails.MaxFlushKeys < 0 || g.overridden(v1.GuardRailFlushKeys, addr) {
+		g.report(v1.GuardRailFlushKeys, addr, nil)
+		return nil
+	}
+	err := g.countKeys(addr, addr)
+	g.report(v1.GuardRailFlushKeys, addr, err)
+	return err
+}
+
+// countKeys returns a GuardRailError if the node holds more than MaxFlushKeys keys, or if its keys can't be counted
+func (g *GuardedAdmin) countKeys(addr, target string) error {
+	info, err := g.GetServerInfo(addr)
+	if err != nil {
+		return g.refuse(v1.GuardRailFlushKeys, target, "node %s not flushed, its keys can't be counted: %v", addr, err)
+	}
+	if info.Keys > g.rails.MaxFlushKeys {
+		return g.refuse(v1.GuardRailFlushKeys, target, "node %s not flushed, it holds %d keys, more than the %d allowed", addr, info.Keys, g.rails.MaxFlushKeys)
+	}
+	return nil
+}
+
+// checkForget returns a GuardRailError if a node still sees the node as owner of slots, or if the node is a master
+// not failed whose own view doesn't confirm that it owns no slot
+func (g *GuardedAdmin) checkForget(infos *ClusterInfos, id string) error {
+	var own *Node
+	master, failed := false, false
+	for _, nodeinfos := range infos.Infos {
+		if nodeinfos == nil || nodeinfos.Node == nil {
+			continue
+		}
+		for _, node := range append(Nodes{nodeinfos.Node}, nodeinfos.Friends...) {
+			if node.ID != id {
+				continue
+			}
+			if node == nodeinfos.Node {
+				own = node
+			}
+			if len(node.Slots) > 0 && !g.overridden(v1.GuardRailForgetNodeWithSlots, id) {
+				err := g.refuse(v1.GuardRailForgetNodeWithSlots, id, "node %s not forgotten, it still owns %d slots", id, len(node.Slots))
+				g.report(v1.GuardRailForgetNodeWithSlots, id, err)
+				return err
+			}
+			master = master || node.GetRole() == v1.RedisClusterNodeRoleMaster
+			failed = failed || node.HasStatus(NodeStatusFail)
+		}
+	}
+	g.report(v1.GuardRailForgetNodeWithSlots, id, nil)
+
+	if !master || failed || g.overridden(v1.GuardRailMasterRemoval, id) {
+		g.report(v1.GuardRailMasterRemoval, id, nil)
+		return nil
+	}
+	var err error
+	switch {
+	case own == nil:
+		err = g.refuse(v1.GuardRailMasterRemoval, id, "master %s not removed, its slots can't be verified since it can't be reached", id)
+	case len(own.Slots) > 0:
+		err = g.refuse(v1.GuardRailMasterRemoval, id, "master %s not removed, it still owns %d slots", id, len(own.Slots))
+	case len(own.MigratingSlots) > 0 || len(own.ImportingSlots) > 0:
+		err = g.refuse(v1.GuardRailMasterRemoval, id, "master %s not removed, it still has slots in migrating or importing state", id)
+	}
+	g.report(v1.GuardRailMasterRemoval, id, err)
+	return err
+}
+
+// overridden returns true if the target is allowed despite the guard rail
+func (g *GuardedAdmin) overridden(rule v1.RedisClusterGuardRailRule, target string) bool {
+	for _, allowed := range g.rails.Overrides[rule] {
+		if allowed == target || allowed == guardRailAllTargets {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *GuardedAdmin) refuse(rule v1.RedisClusterGuardRailRule, target string, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	message = fmt.Sprintf("%s, annotate the RedisCluster with %s=%s to allow it", message, v1.GuardRailOverrideAnnotationKey(rule), target)
+	glog.Warningf("guard rail %s: %s", rule, message)
+	return &GuardRailError{Rule: rule, Target: target, Message: message}
+}
+
+func (g *GuardedAdmin) report(rule v1.RedisClusterGuardRailRule, target string, err error) {
+	if g.rails.Report != nil {
+		g.rails.Report(rule, target, err)
+	}
+}
@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
+)
+
+// guardedStubAdmin records the destructive commands reaching the wrapped admin
+type guardedStubAdmin struct {
+	AdminInterface
+	infos     *ClusterInfos
+	keys      map[string]int64
+	flushed   []string
+	forgotten []string
+}
+
+func (a *guardedStubAdmin) GetClusterInfos() (*ClusterInfos, error) {
+	return a.infos, nil
+}
+
+func (a *guardedStubAdmin) GetServerInfo(addr string) (*ServerInfo, error) {
+	keys, ok := a.keys[addr]
+	if !ok {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &ServerInfo{Keys: keys}, nil
+}
+
+func (a *guardedStubAdmin) FlushAndReset(addr string, mode string) error {
+	a.flushed = append(a.flushed, addr)
+	return nil
+}
+
+func (a *guardedStubAdmin) ForgetNode(id string) error {
+	a.forgotten = append(a.forgotten, id)
+	return nil
+}
+
+func TestGuardedAdmin_FlushAndReset(t *testing.T) {
+	stub := &guardedStubAdmin{keys: map[string]int64{"10.0.0.1:6379": 10, "10.0.0.2:6379": 1000}}
+	reports := map[string]error{}
+	rails := GuardRails{
+		MaxFlushKeys: 100,
+		Report: func(rule v1.RedisClusterGuardRailRule, target string, err error) {
+			reports[target] = err
+		},
+	}
+	admin := NewGuardedAdmin(stub, rails)
+
+	for _, addr := range []string{"10.0.0.1:6379", "10.0.0.2:6379", "10.0.0.3:6379"} {
+		admin.FlushAndReset(addr, ResetHard)
+	}
+	if !reflect.DeepEqual(stub.flushed, []string{"10.0.0.1:6379"}) {
+		t.Errorf("FlushAndReset() flushed %v, want only the node holding less keys than the limit", stub.flushed)
+	}
+	if reports["10.0.0.1:6379"] != nil || !IsGuardRailError(reports["10.0.0.2:6379"]) || !IsGuardRailError(reports["10.0.0.3:6379"]) {
+		t.Errorf("FlushAndReset() reports = %v, want the nodes too big or unreachable refused", reports)
+	}
+	wantMessage := "node 10.0.0.2:6379 not flushed, it holds 1000 keys, more than the 100 allowed, annotate the RedisCluster with redis-operator.k8s.io/allow-flush-keys=10.0.0.2:6379 to allow it"
+	if reports["10.0.0.2:6379"].Error() != wantMessage {
+		t.Errorf("FlushAndReset() error = %q, want %q", reports["10.0.0.2:6379"], wantMessage)
+	}
+
+	rails.Overrides = map[v1.RedisClusterGuardRailRule][]string{v1.GuardRailFlushKeys: {"10.0.0.2:6379"}}
+	if err := NewGuardedAdmin(stub, rails).FlushAndReset("10.0.0.2:6379", ResetHard); err != nil || reports["10.0.0.2:6379"] != nil {
+		t.Errorf("FlushAndReset() error = %v, want the override to allow the flush", err)
+	}
+
+	rails = GuardRails{MaxFlushKeys: -1}
+	if err := NewGuardedAdmin(stub, rails).FlushAndReset("10.0.0.3:6379", ResetHard); err != nil {
+		t.Errorf("FlushAndReset() error = %v, want no limit", err)
+	}
+}
+
+func TestGuardedAdmin_ForgetNode(t *testing.T) {
+	master := &Node{ID: "master", IP: "10.0.0.1", Port: "6379", Role: "master", Slots: BuildSlotSlice(0, 100)}
+	removed := &Node{ID: "removed", IP: "10.0.0.2", Port: "6379", Role: "master"}
+	staleView := &Node{ID: "removed", IP: "10.0.0.2", Port: "6379", Role: "master", Slots: BuildSlotSlice(101, 200)}
+	gone := &Node{ID: "gone", IP: "10.0.0.3", Port: "6379", Role: "master"}
+	failed := &Node{ID: "failed", IP: "10.0.0.4", Port: "6379", Role: "master", FailStatus: []string{NodeStatusFail}}
+	slave := &Node{ID: "slave", IP: "10.0.0.5", Port: "6379", Role: "slave", MasterReferent: "gone"}
+
+	tests := []struct {
+		name      string
+		infos     *ClusterInfos
+		id        string
+		overrides map[v1.RedisClusterGuardRailRule][]string
+		wantRule  v1.RedisClusterGuardRailRule
+	}{
+		{
+			name:  "master verified empty",
+			infos: &ClusterInfos{Infos: map[string]*NodeInfos{"10.0.0.1:6379": {Node: master, Friends: Nodes{removed}}, "10.0.0.2:6379": {Node: removed, Friends: Nodes{master}}}},
+			id:    "removed",
+		},
+		{
+			name:     "node still owning slots in a view",
+			infos:    &ClusterInfos{Infos: map[string]*NodeInfos{"10.0.0.1:6379": {Node: master, Friends: Nodes{staleView}}, "10.0.0.2:6379": {Node: removed, Friends: Nodes{master}}}},
+			id:       "removed",
+			wantRule: v1.GuardRailForgetNodeWithSlots,
+		},
+		{
+			name:     "master not reachable",
+			infos:    &ClusterInfos{Infos: map[string]*NodeInfos{"10.0.0.1:6379": {Node: master, Friends: Nodes{gone}}}},
+			id:       "gone",
+			wantRule: v1.GuardRailMasterRemoval,
+		},
+		{
+			name:      "master not reachable overridden",
+			infos:     &ClusterInfos{Infos: map[string]*NodeInfos{"10.0.0.1:6379": {Node: master, Friends: Nodes{gone}}}},
+			id:        "gone",
+			overrides: map[v1.RedisClusterGuardRailRule][]string{v1.GuardRailMasterRemoval: {"*"}},
+		},
+		{
+			name:  "failed master",
+			infos: &ClusterInfos{Infos: map[string]*NodeInfos{"10.0.0.1:6379": {Node: master, Friends: Nodes{failed}}}},
+			id:    "failed",
+		},
+		{
+			name:  "slave",
+			infos: &ClusterInfos{Infos: map[string]*NodeInfos{"10.0.0.1:6379": {Node: master, Friends: Nodes{slave}}}},
+			id:    "slave",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &guardedStubAdmin{infos: tt.infos}
+			refused := map[v1.RedisClusterGuardRailRule]bool{}
+			admin := NewGuardedAdmin(stub, GuardRails{MaxFlushKeys: -1, Overrides: tt.overrides, Report: func(rule v1.RedisClusterGuardRailRule, target string, err error) {
+				refused[rule] = err != nil
+			}})
+			err := admin.ForgetNode(tt.id)
+			if tt.wantRule == "" {
+				if err != nil || len(stub.forgotten) != 1 {
+					t.Errorf("ForgetNode() error = %v, forgotten %v, want the node forgotten", err, stub.forgotten)
+				}
+				return
+			}
+			if guardErr, ok := err.(*GuardRailError); !ok || guardErr.Rule != tt.wantRule || guardErr.Target != tt.id || len(stub.forgotten) != 0 {
+				t.Errorf("ForgetNode() error = %v, forgotten %v, want a refusal by %s", err, stub.forgotten, tt.wantRule)
+			}
+			if !refused[tt.wantRule] {
+				t.Errorf("ForgetNode() should report the refusal by %s", tt.wantRule)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"fmt"
+
+	"github.com/mediocregopher/radix.v2/redis"
+)
+
+// KeyDump is a key serialized with the DUMP command, with its remaining time to live
+type KeyDump struct {
+	Key string
+	// TTL remaining time to live in milliseconds, 0 if the key doesn't expire
+	TTL int64
+	// Value serialized value returned by DUMP
+	Value []byte
+}
+
+// ScanKeys runs one iteration of the SCAN command on the node, returns the next cursor ("0" when the iteration
+// is complete) and the keys returned by this iteration
+func (a *Admin) ScanKeys(addr string, cursor string, count int) (string, []string, error) {
+	c, err := a.Connections().Get(addr)
+	if err != nil {
+		return cursor, nil, err
+	}
+	if cursor == "" {
+		cursor = "0"
+	}
+
+	resp := c.Cmd("SCAN", cursor, "COUNT", count)
+	if err = a.Connections().ValidateResp(resp, addr, "Unable to run command SCAN"); err != nil {
+		return cursor, nil, err
+	}
+	array, err := resp.Array()
+	if err != nil || len(array) != 2 {
+		return cursor, nil, fmt.Errorf("Wrong format from SCAN: %v", err)
+	}
+	next, err := array[0].Str()
+	if err != nil {
+		return cursor, nil, fmt.Errorf("Wrong cursor format from SCAN: %v", err)
+	}
+	keys, err := array[1].List()
+	if err != nil {
+		return cursor, nil, fmt.Errorf("Wrong keys format from SCAN: %v", err)
+	}
+	return next, keys, nil
+}
+
+// DumpKeys serializes the keys with the DUMP command in a pipeline. The keys removed in the meantime are ignored.
+func (a *Admin) DumpKeys(addr string, keys []string) ([]KeyDump, error) {
+	dumps := []KeyDump{}
+	if len(keys) == 0 {
+		return dumps, nil
+	}
+	c, err := a.Connections().Get(addr)
+	if err != nil {
+		return dumps, err
+	}
+
+	for _, key := range keys {
+		c.PipeAppend("PTTL", key)
+		c.PipeAppend("DUMP", key)
+	}
+	for _, key := range keys {
+		ttlResp := c.PipeResp()
+		dumpResp := c.PipeResp()
+		if err = a.Connections().ValidateResp(ttlResp, addr, "Unable to run command PTTL"); err != nil {
+			c.PipeClear()
+			return dumps, err
+		}
+		if err = a.Connections().ValidateResp(dumpResp, addr, "Unable to run command DUMP"); err != nil {
+			c.PipeClear()
+			return dumps, err
+		}
+		if dumpResp.IsType(redis.Nil) {
+			// key removed or expired since the scan
+			continue
+		}
+		ttl, err := ttlResp.Int64()
+		if err != nil {
+			c.PipeClear()
+			return dumps, fmt.Errorf("Wrong format from PTTL: %v", err)
+		}
+		value, err := dumpResp.Bytes()
+		if err != nil {
+			c.PipeClear()
+			return dumps, fmt.Errorf("Wrong format from DUMP: %v", err)
+		}
+		if ttl < 0 {
+			ttl = 0
+		}
+		dumps = append(dumps, KeyDump{Key: key, TTL: ttl, Value: value})
+	}
+	return dumps, nil
+}
+
+// RestoreKeys restores the serialized keys with the RESTORE REPLACE command in a pipeline
+func (a *Admin) RestoreKeys(addr string, dumps []KeyDump) error {
+	if len(dumps) == 0 {
+		return nil
+	}
+	c, err := a.Connections().Get(addr)
+	if err != nil {
+		return err
+	}
+
+	for _, dump := range dumps {
+		c.PipeAppend("RESTORE", dump.Key, dump.TTL, dump.Value, "REPLACE")
+	}
+	if !a.Connections().ValidatePipeResp(c, addr, "Cannot RESTORE keys") {
+		return fmt.Errorf("Error occured during RESTORE on node %s", addr)
+	}
+	c.PipeClear()
+	return nil
+}
+
+// DeleteKeys deletes the keys with the DEL command in a pipeline, one command per key to support keys of different slots
+func (a *Admin) DeleteKeys(addr string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	c, err := a.Connections().Get(addr)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		c.PipeAppend("DEL", key)
+	}
+	if !a.Connections().ValidatePipeResp(c, addr, "Cannot DEL keys") {
+		return fmt.Errorf("Error occured during DEL on node %s", addr)
+	}
+	c.PipeClear()
+	return nil
+}
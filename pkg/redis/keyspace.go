@@ -0,0 +1,202 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/mediocregopher/radix.v2/redis"
+)
+
+const (
+	// keyspaceChannelPrefix prefix of the channels of the keyspace notifications of the database 0
+	keyspaceChannelPrefix = "__keyspace@0__:"
+	// keyspaceMaxModifiedKeys maximum number of modified keys kept by a KeyspaceWatcher
+	keyspaceMaxModifiedKeys = 1000000
+)
+
+// KeyspaceWatcher collects the keys modified on a redis node from its keyspace notifications
+type KeyspaceWatcher struct {
+	Addr string
+
+	client ClientInterface
+	mutex  sync.Mutex
+	keys   map[string]struct{}
+	err    error
+	stop   chan struct{}
+
+	// originalEvents is the notify-keyspace-events configuration of the node before the watcher, restored by Close
+	originalEvents  string
+	cnxTimeout      time.Duration
+	commandsMapping map[string]string
+}
+
+// NewKeyspaceWatcher enables the keyspace notifications on the node and starts collecting the keys modified in the database 0.
+// The notify-keyspace-events configuration of the node is restored by Close.
+func NewKeyspaceWatcher(addr string, cnxTimeout time.Duration, commandsMapping map[string]string) (*KeyspaceWatcher, error) {
+	c, err := NewClient(addr, cnxTimeout, commandsMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := c.Cmd("CONFIG", "GET", "notify-keyspace-events")
+	if resp.Err != nil {
+		c.Close()
+		return nil, fmt.Errorf("unable to get notify-keyspace-events on %s: %v", addr, resp.Err)
+	}
+	current := ""
+	if values, err := resp.List(); err == nil && len(values) == 2 {
+		current = values[1]
+	}
+	if resp = c.Cmd("CONFIG", "SET", "notify-keyspace-events", mergeKeyspaceEventsFlags(current)); resp.Err != nil {
+		c.Close()
+		return nil, fmt.Errorf("unable to enable the keyspace notifications on %s: %v", addr, resp.Err)
+	}
+	if resp = c.Cmd("PSUBSCRIBE", keyspaceChannelPrefix+"*"); resp.Err != nil {
+		c.Close()
+		if err := restoreKeyspaceEvents(addr, cnxTimeout, commandsMapping, current); err != nil {
+			glog.Warning(err)
+		}
+		return nil, fmt.Errorf("unable to subscribe to the keyspace notifications on %s: %v", addr, resp.Err)
+	}
+
+	w := &KeyspaceWatcher{
+		Addr:            addr,
+		client:          c,
+		keys:            map[string]struct{}{},
+		stop:            make(chan struct{}),
+		originalEvents:  current,
+		cnxTimeout:      cnxTimeout,
+		commandsMapping: commandsMapping,
+	}
+	go w.run()
+	return w, nil
+}
+
+// ModifiedKeys returns and forgets up to max keys modified since the previous call
+func (w *KeyspaceWatcher) ModifiedKeys(max int) []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	keys := []string{}
+	for key := range w.keys {
+		if len(keys) >= max {
+			break
+		}
+		keys = append(keys, key)
+		delete(w.keys, key)
+	}
+	return keys
+}
+
+// MarkModified adds keys to the modified keys, for instance to retry the copy of keys returned by ModifiedKeys
+func (w *KeyspaceWatcher) MarkModified(keys []string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, key := range keys {
+		w.keys[key] = struct{}{}
+	}
+}
+
+// Len returns the number of modified keys
+func (w *KeyspaceWatcher) Len() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return len(w.keys)
+}
+
+// Err returns the error that stopped the watcher, some modified keys may have been missed
+func (w *KeyspaceWatcher) Err() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.err
+}
+
+// Close stops the watcher and restores the notify-keyspace-events configuration of the node
+func (w *KeyspaceWatcher) Close() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	select {
+	case <-w.stop:
+		return
+	default:
+		close(w.stop)
+	}
+	// the client of the watcher is subscribed, the configuration is restored with another connection
+	if err := restoreKeyspaceEvents(w.Addr, w.cnxTimeout, w.commandsMapping, w.originalEvents); err != nil {
+		glog.Warning(err)
+	}
+}
+
+// restoreKeyspaceEvents sets back the notify-keyspace-events configuration of the node
+func restoreKeyspaceEvents(addr string, cnxTimeout time.Duration, commandsMapping map[string]string, events string) error {
+	c, err := NewClient(addr, cnxTimeout, commandsMapping)
+	if err != nil {
+		return fmt.Errorf("unable to restore notify-keyspace-events on %s: %v", addr, err)
+	}
+	defer c.Close()
+	if resp := c.Cmd("CONFIG", "SET", "notify-keyspace-events", events); resp.Err != nil {
+		return fmt.Errorf("unable to restore notify-keyspace-events on %s: %v", addr, resp.Err)
+	}
+	return nil
+}
+
+func (w *KeyspaceWatcher) run() {
+	defer w.client.Close()
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		resp := w.client.ReadResp()
+		if resp.IsType(redis.IOErr) && redis.IsTimeout(resp) {
+			continue
+		}
+		if resp.Err != nil {
+			w.setErr(fmt.Errorf("keyspace notifications of %s interrupted: %v", w.Addr, resp.Err))
+			return
+		}
+		key, ok := parseKeyspaceMessage(resp)
+		if !ok {
+			continue
+		}
+		w.mutex.Lock()
+		w.keys[key] = struct{}{}
+		overflow := len(w.keys) > keyspaceMaxModifiedKeys
+		w.mutex.Unlock()
+		if overflow {
+			w.setErr(fmt.Errorf("more than %d keys modified on %s", keyspaceMaxModifiedKeys, w.Addr))
+			return
+		}
+	}
+}
+
+func (w *KeyspaceWatcher) setErr(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.err = err
+}
+
+// parseKeyspaceMessage returns the key of a "pmessage" keyspace notification
+func parseKeyspaceMessage(resp *redis.Resp) (string, bool) {
+	values, err := resp.List()
+	if err != nil || len(values) != 4 || values[0] != "pmessage" || !strings.HasPrefix(values[2], keyspaceChannelPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(values[2], keyspaceChannelPrefix), true
+}
+
+// mergeKeyspaceEventsFlags adds the flags needed to receive the keyspace notifications of all the commands
+// to the current notify-keyspace-events configuration
+func mergeKeyspaceEventsFlags(current string) string {
+	flags := current
+	for _, flag := range []string{"K", "A"} {
+		if !strings.Contains(flags, flag) {
+			flags += flag
+		}
+	}
+	return flags
+}
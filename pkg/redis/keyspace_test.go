@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mediocregopher/radix.v2/redis"
+
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake"
+)
+
+func TestParseKeyspaceMessage(t *testing.T) {
+	testTable := []struct {
+		resp     *redis.Resp
+		expected string
+		ok       bool
+	}{
+		{redis.NewResp([]string{"pmessage", "__keyspace@0__:*", "__keyspace@0__:foo", "set"}), "foo", true},
+		{redis.NewResp([]string{"pmessage", "__keyspace@0__:*", "__keyspace@0__:{user}:a", "del"}), "{user}:a", true},
+		{redis.NewResp([]string{"psubscribe", "__keyspace@0__:*", "1"}), "", false},
+		{redis.NewResp([]string{"pmessage", "__keyevent@0__:*", "__keyevent@0__:set", "foo"}), "", false},
+	}
+
+	for i, tt := range testTable {
+		key, ok := parseKeyspaceMessage(tt.resp)
+		if key != tt.expected || ok != tt.ok {
+			t.Errorf("[case %d]expected (%q, %v), got (%q, %v)", i, tt.expected, tt.ok, key, ok)
+		}
+	}
+}
+
+func TestMergeKeyspaceEventsFlags(t *testing.T) {
+	testTable := []struct {
+		current  string
+		expected string
+	}{
+		{"", "KA"},
+		{"Ex", "ExKA"},
+		{"KEA", "KEA"},
+	}
+
+	for i, tt := range testTable {
+		if flags := mergeKeyspaceEventsFlags(tt.current); flags != tt.expected {
+			t.Errorf("[case %d]expected %q, got %q", i, tt.expected, flags)
+		}
+	}
+}
+
+func TestRestoreKeyspaceEvents(t *testing.T) {
+	redisSrv := fake.NewRedisServer(t)
+	defer redisSrv.Close()
+
+	redisSrv.PushResponse("CONFIG SET notify-keyspace-events Ex", "OK")
+	if err := restoreKeyspaceEvents(redisSrv.GetHostPort(), time.Second, nil, "Ex"); err != nil {
+		t.Errorf("restoreKeyspaceEvents() error = %v", err)
+	}
+	if len(redisSrv.Responses["CONFIG SET notify-keyspace-events Ex"]) != 0 {
+		t.Errorf("restoreKeyspaceEvents() should set back the original notify-keyspace-events")
+	}
+}
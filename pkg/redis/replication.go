@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	// RedisMasterLinkStatusUp replication link between a slave and its master is up
+	RedisMasterLinkStatusUp = "up"
+	// RedisMasterLinkStatusDown replication link between a slave and its master is down
+	RedisMasterLinkStatusDown = "down"
+)
+
+// ReplicationInfo represents the data returned by the INFO REPLICATION redis command
+type ReplicationInfo struct {
+	Role                   string
+	MasterHost             string
+	MasterPort             string
+	MasterLinkStatus       string
+	MasterLastIOSecondsAgo int64
+	MasterSyncInProgress   bool
+	ConnectedSlaves        int
+	MasterReplOffset       int64
+	SlaveReplOffset        int64
+}
+
+// IsLinkUp returns true if the node is a slave with an up and running replication link
+func (r *ReplicationInfo) IsLinkUp() bool {
+	return r.Role == redisSlaveRole && r.MasterLinkStatus == RedisMasterLinkStatusUp && !r.MasterSyncInProgress
+}
+
+// IsMaster returns true if the node is a master
+func (r *ReplicationInfo) IsMaster() bool {
+	return r.Role == redisMasterRole
+}
+
+// IsReplicaOf returns true if the node is a slave of the master with the given address
+func (r *ReplicationInfo) IsReplicaOf(masterAddr string) bool {
+	return r.Role == redisSlaveRole && net.JoinHostPort(r.MasterHost, r.MasterPort) == masterAddr
+}
+
+// Lag returns the replication lag in bytes between a slave and the given master
+func (r *ReplicationInfo) Lag(master *ReplicationInfo) int64 {
+	lag := master.MasterReplOffset - r.SlaveReplOffset
+	if lag < 0 {
+		return 0
+	}
+	return lag
+}
+
+// DecodeReplicationInfo decode from the INFO REPLICATION cmd output the Redis replication info
+func DecodeReplicationInfo(input *string) (*ReplicationInfo, error) {
+	info := &ReplicationInfo{}
+	found := false
+	lines := strings.Split(*input, "\n")
+	for _, line := range lines {
+		values := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(values) != 2 {
+			continue
+		}
+		var err error
+		switch values[0] {
+		case "role":
+			found = true
+			info.Role = values[1]
+		case "master_host":
+			info.MasterHost = values[1]
+		case "master_port":
+			info.MasterPort = values[1]
+		case "master_link_status":
+			info.MasterLinkStatus = values[1]
+		case "master_last_io_seconds_ago":
+			info.MasterLastIOSecondsAgo, err = strconv.ParseInt(values[1], 10, 64)
+		case "master_sync_in_progress":
+			info.MasterSyncInProgress = values[1] == "1"
+		case "connected_slaves":
+			info.ConnectedSlaves, err = strconv.Atoi(values[1])
+		case "master_repl_offset":
+			info.MasterReplOffset, err = strconv.ParseInt(values[1], 10, 64)
+		case "slave_repl_offset":
+			info.SlaveReplOffset, err = strconv.ParseInt(values[1], 10, 64)
+		}
+		if err != nil {
+			return info, fmt.Errorf("Error while decoding replication info '%s': %v", line, err)
+		}
+	}
+	if !found {
+		return info, fmt.Errorf("Error while decoding replication info. No role found")
+	}
+	return info, nil
+}
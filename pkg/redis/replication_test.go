@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeReplicationInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *ReplicationInfo
+		wantErr bool
+	}{
+		{
+			name:    "empty input",
+			input:   "",
+			want:    &ReplicationInfo{},
+			wantErr: true,
+		},
+		{
+			name:  "master",
+			input: "# Replication\r\nrole:master\r\nconnected_slaves:1\r\nslave0:ip=10.0.0.2,port=6379,state=online,offset=1234,lag=0\r\nmaster_replid:8d3e8a4f0d4bd2a3e3e8a4f0d4bd2a3e3e8a4f0d\r\nmaster_repl_offset:1234\r\n",
+			want: &ReplicationInfo{
+				Role:             "master",
+				ConnectedSlaves:  1,
+				MasterReplOffset: 1234,
+			},
+		},
+		{
+			name:  "slave",
+			input: "# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\nmaster_port:6379\r\nmaster_link_status:up\r\nmaster_last_io_seconds_ago:1\r\nmaster_sync_in_progress:0\r\nslave_repl_offset:1200\r\nslave_priority:100\r\nmaster_repl_offset:1200\r\n",
+			want: &ReplicationInfo{
+				Role:                   "slave",
+				MasterHost:             "10.0.0.1",
+				MasterPort:             "6379",
+				MasterLinkStatus:       "up",
+				MasterLastIOSecondsAgo: 1,
+				MasterReplOffset:       1200,
+				SlaveReplOffset:        1200,
+			},
+		},
+		{
+			name:    "bad offset",
+			input:   "role:slave\r\nslave_repl_offset:abc\r\n",
+			want:    &ReplicationInfo{Role: "slave"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeReplicationInfo(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeReplicationInfo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeReplicationInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplicationInfo_Lag(t *testing.T) {
+	master := &ReplicationInfo{Role: "master", MasterReplOffset: 1000}
+	slave := &ReplicationInfo{Role: "slave", MasterLinkStatus: RedisMasterLinkStatusUp, SlaveReplOffset: 800}
+	if !slave.IsLinkUp() {
+		t.Errorf("ReplicationInfo.IsLinkUp() should be true")
+	}
+	if lag := slave.Lag(master); lag != 200 {
+		t.Errorf("ReplicationInfo.Lag() = %d, want 200", lag)
+	}
+	slave.SlaveReplOffset = 1100
+	if lag := slave.Lag(master); lag != 0 {
+		t.Errorf("ReplicationInfo.Lag() = %d, want 0", lag)
+	}
+}
+
+func TestReplicationInfo_IsReplicaOf(t *testing.T) {
+	master := &ReplicationInfo{Role: "master"}
+	if !master.IsMaster() || master.IsReplicaOf("10.0.0.1:6379") {
+		t.Errorf("ReplicationInfo master should be a master and not a replica")
+	}
+	slave := &ReplicationInfo{Role: "slave", MasterHost: "10.0.0.1", MasterPort: "6379"}
+	if slave.IsMaster() {
+		t.Errorf("ReplicationInfo.IsMaster() should be false")
+	}
+	if !slave.IsReplicaOf("10.0.0.1:6379") {
+		t.Errorf("ReplicationInfo.IsReplicaOf() should be true")
+	}
+	if slave.IsReplicaOf("10.0.0.2:6379") {
+		t.Errorf("ReplicationInfo.IsReplicaOf() should be false")
+	}
+}
@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/mediocregopher/radix.v2/redis"
+)
+
+const (
+	// defaultRetryAttempts is the default number of times an idempotent command is sent to a node
+	defaultRetryAttempts = 3
+	// defaultRetryBackoff is the default wait before the first retry
+	defaultRetryBackoff = 100 * time.Millisecond
+	// defaultMaxRetryBackoff is the default maximum wait between two retries
+	defaultMaxRetryBackoff = 2 * time.Second
+)
+
+// retryPolicy tells how the idempotent commands failing with a retryable error are sent again:
+// at most attempts times, with an exponential backoff
+type retryPolicy struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	attempts:   defaultRetryAttempts,
+	backoff:    defaultRetryBackoff,
+	maxBackoff: defaultMaxRetryBackoff,
+}
+
+// newRetryPolicy returns a retryPolicy, the default values are used for the zero values
+func newRetryPolicy(attempts int, backoff, maxBackoff time.Duration) retryPolicy {
+	policy := defaultRetryPolicy
+	if attempts > 0 {
+		policy.attempts = attempts
+	}
+	if backoff > 0 {
+		policy.backoff = backoff
+	}
+	if maxBackoff > 0 {
+		policy.maxBackoff = maxBackoff
+	}
+	if policy.maxBackoff < policy.backoff {
+		policy.maxBackoff = policy.backoff
+	}
+	return policy
+}
+
+// retry runs the operation until it succeeds, fails with an error that is not retryable, the attempts are exhausted
+// or the context of the admin is done. The operation must be idempotent.
+// Returns the error of the last attempt.
+func (a *Admin) retry(addr string, op func() error) error {
+	backoff := a.retryPolicy.backoff
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= a.retryPolicy.attempts || !IsRetryableError(err) {
+			return err
+		}
+		glog.V(2).Infof("attempt %d/%d failed on node %s, retrying in %v: %v", attempt, a.retryPolicy.attempts, addr, backoff, err)
+		if a.sleep(backoff) != nil {
+			return err
+		}
+		backoff *= 2
+		if backoff > a.retryPolicy.maxBackoff {
+			backoff = a.retryPolicy.maxBackoff
+		}
+	}
+}
+
+// cmd sends the idempotent command to the node and validates the response, the command is retried while it fails
+// with a retryable error
+func (a *Admin) cmd(addr, errMessage string, cmd string, args ...interface{}) (*redis.Resp, error) {
+	var resp *redis.Resp
+	err := a.retry(addr, func() error {
+		c, err := a.Connections().Get(addr)
+		if err != nil {
+			return err
+		}
+		resp = c.Cmd(cmd, args...)
+		return a.Connections().ValidateResp(resp, addr, errMessage)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
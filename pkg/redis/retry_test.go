@@ -0,0 +1,119 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zh168654/Redis-Operator/pkg/redis/fake"
+)
+
+func TestAdmin_cmd(t *testing.T) {
+	redisSrv := fake.NewRedisServer(t)
+	defer redisSrv.Close()
+	addr := redisSrv.GetHostPort()
+	a := NewAdmin([]string{addr}, &AdminOptions{RetryBackoff: time.Millisecond}).(*Admin)
+	defer a.Close()
+
+	redisSrv.PushResponse("CLUSTER COUNTKEYSINSLOT 1", errors.New("LOADING Redis is loading the dataset in memory"))
+	redisSrv.PushResponse("CLUSTER COUNTKEYSINSLOT 1", errors.New("TRYAGAIN Multiple keys request during rehashing of slot"))
+	redisSrv.PushResponse("CLUSTER COUNTKEYSINSLOT 1", 10)
+	count, err := a.CountKeysInSlot(addr, 1)
+	if err != nil || count != 10 {
+		t.Errorf("CountKeysInSlot() = %d, %v, want 10 after the retries", count, err)
+	}
+
+	redisSrv.PushResponse("CLUSTER COUNTKEYSINSLOT 2", errors.New("ERR Invalid slot"))
+	redisSrv.PushResponse("CLUSTER COUNTKEYSINSLOT 2", 10)
+	if _, err = a.CountKeysInSlot(addr, 2); err == nil || IsRetryableError(err) {
+		t.Errorf("CountKeysInSlot() error = %v, want the error reply without retry", err)
+	}
+
+	for i := 0; i < defaultRetryAttempts; i++ {
+		redisSrv.PushResponse("CLUSTER COUNTKEYSINSLOT 3", errors.New("CLUSTERDOWN The cluster is down"))
+	}
+	redisSrv.PushResponse("CLUSTER COUNTKEYSINSLOT 3", 10)
+	if _, err = a.CountKeysInSlot(addr, 3); ClassifyError(err) != ErrorClassTransient {
+		t.Errorf("CountKeysInSlot() error = %v, want the last error once the attempts are exhausted", err)
+	}
+}
+
+func Test_newRetryPolicy(t *testing.T) {
+	if got := newRetryPolicy(0, 0, 0); got != defaultRetryPolicy {
+		t.Errorf("newRetryPolicy() = %v, want the default policy", got)
+	}
+	want := retryPolicy{attempts: 1, backoff: 5 * time.Second, maxBackoff: 5 * time.Second}
+	if got := newRetryPolicy(1, 5*time.Second, 0); got != want {
+		t.Errorf("newRetryPolicy() = %v, want %v", got, want)
+	}
+}
+
+func TestAdmin_SetSlots(t *testing.T) {
+	redisSrv := fake.NewRedisServer(t)
+	defer redisSrv.Close()
+	addr := redisSrv.GetHostPort()
+	a := NewAdmin([]string{addr}, &AdminOptions{RetryBackoff: time.Millisecond}).(*Admin)
+	defer a.Close()
+
+	redisSrv.PushResponse("CLUSTER SETSLOT 5 STABLE", errors.New("LOADING Redis is loading the dataset in memory"))
+	redisSrv.PushResponse("CLUSTER SETSLOT 5 STABLE", "OK")
+	if err := a.SetSlots(addr, "STABLE", []Slot{5}, ""); err != nil {
+		t.Errorf("SetSlots() error = %v, want no error after the retry", err)
+	}
+
+	redisSrv.PushResponse("CLUSTER SETSLOT 6 NODE unknown", errors.New("ERR I don't know about node unknown"))
+	redisSrv.PushResponse("CLUSTER SETSLOT 6 NODE unknown", "OK")
+	if err := a.SetSlots(addr, "NODE", []Slot{6}, "unknown"); err == nil || IsRetryableError(err) {
+		t.Errorf("SetSlots() error = %v, want the error reply without retry", err)
+	}
+}
+
+func TestAdmin_AddSlots(t *testing.T) {
+	redisSrv := fake.NewRedisServer(t)
+	defer redisSrv.Close()
+	addr := redisSrv.GetHostPort()
+	a := NewAdmin([]string{addr}, &AdminOptions{RetryBackoff: time.Millisecond}).(*Admin)
+	defer a.Close()
+
+	// the first attempt is applied but its response is lost
+	redisSrv.PushResponse("CLUSTER ADDSLOTS 5 6", errors.New("LOADING Redis is loading the dataset in memory"))
+	redisSrv.PushResponse("CLUSTER ADDSLOTS 5 6", errors.New("ERR Slot 5 is already busy"))
+	redisSrv.PushResponse("CLUSTER NODES", "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 127.0.0.1:30002 myself,master - 0 1426238316232 2 connected 5-6")
+	if err := a.AddSlots(addr, []Slot{5, 6}); err != nil {
+		t.Errorf("AddSlots() error = %v, want no error when the node owns the slots", err)
+	}
+
+	redisSrv.PushResponse("CLUSTER ADDSLOTS 7", errors.New("LOADING Redis is loading the dataset in memory"))
+	redisSrv.PushResponse("CLUSTER ADDSLOTS 7", errors.New("ERR Slot 7 is already busy"))
+	redisSrv.PushResponse("CLUSTER NODES", "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 127.0.0.1:30002 myself,master - 0 1426238316232 2 connected 5-6")
+	if err := a.AddSlots(addr, []Slot{7}); err == nil {
+		t.Errorf("AddSlots() no error, want the busy reply when another node owns the slot")
+	}
+
+	redisSrv.PushResponse("CLUSTER ADDSLOTS 8", errors.New("ERR Slot 8 is already busy"))
+	if err := a.AddSlots(addr, []Slot{8}); err == nil {
+		t.Errorf("AddSlots() no error, want the busy reply of the first attempt")
+	}
+}
+
+func TestAdmin_DelSlots(t *testing.T) {
+	redisSrv := fake.NewRedisServer(t)
+	defer redisSrv.Close()
+	addr := redisSrv.GetHostPort()
+	a := NewAdmin([]string{addr}, &AdminOptions{RetryBackoff: time.Millisecond}).(*Admin)
+	defer a.Close()
+
+	redisSrv.PushResponse("CLUSTER DELSLOTS 7", errors.New("TRYAGAIN Multiple keys request during rehashing of slot"))
+	redisSrv.PushResponse("CLUSTER DELSLOTS 7", errors.New("ERR Slot 7 is already unassigned"))
+	redisSrv.PushResponse("CLUSTER NODES", "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 127.0.0.1:30002 myself,master - 0 1426238316232 2 connected 5-6")
+	if err := a.DelSlots(addr, []Slot{7}); err != nil {
+		t.Errorf("DelSlots() error = %v, want no error when the node doesn't own the slot", err)
+	}
+
+	redisSrv.PushResponse("CLUSTER DELSLOTS 6", errors.New("TRYAGAIN Multiple keys request during rehashing of slot"))
+	redisSrv.PushResponse("CLUSTER DELSLOTS 6", errors.New("ERR Slot 6 is already unassigned"))
+	redisSrv.PushResponse("CLUSTER NODES", "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 127.0.0.1:30002 myself,master - 0 1426238316232 2 connected 5-6")
+	if err := a.DelSlots(addr, []Slot{6}); err == nil {
+		t.Errorf("DelSlots() no error, want the unassigned reply when the node still owns the slot")
+	}
+}
@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/mediocregopher/radix.v2/redis"
+)
+
+const (
+	// DefaultSentinelPort define the default Redis Sentinel Port
+	DefaultSentinelPort = "26379"
+)
+
+// SetReplicaOf configures the node as a replica of the master with the SLAVEOF command.
+// If masterIP is empty, the node is promoted as a master with SLAVEOF NO ONE.
+func (a *Admin) SetReplicaOf(addr, masterIP, masterPort string) error {
+	c, err := a.Connections().Get(addr)
+	if err != nil {
+		return err
+	}
+
+	var resp *redis.Resp
+	if masterIP == "" {
+		resp = c.Cmd("SLAVEOF", "NO", "ONE")
+	} else {
+		resp = c.Cmd("SLAVEOF", masterIP, masterPort)
+	}
+	return a.Connections().ValidateResp(resp, addr, "Unable to run command SLAVEOF")
+}
+
+// SentinelGetMasterAddr returns the address of the master monitored by the sentinel under the given name,
+// or an empty string if the sentinel doesn't monitor this name
+func (a *Admin) SentinelGetMasterAddr(addr, name string) (string, error) {
+	c, err := a.Connections().Get(addr)
+	if err != nil {
+		return "", err
+	}
+
+	resp := c.Cmd("SENTINEL", "GET-MASTER-ADDR-BY-NAME", name)
+	if err = a.Connections().ValidateResp(resp, addr, "Unable to retrieve the master address"); err != nil {
+		return "", err
+	}
+	if resp.IsType(redis.Nil) {
+		return "", nil
+	}
+	hostPort, err := resp.List()
+	if err != nil {
+		return "", fmt.Errorf("Wrong format from SENTINEL GET-MASTER-ADDR-BY-NAME: %v", err)
+	}
+	if len(hostPort) != 2 {
+		return "", fmt.Errorf("Wrong format from SENTINEL GET-MASTER-ADDR-BY-NAME: %v", hostPort)
+	}
+	return net.JoinHostPort(hostPort[0], hostPort[1]), nil
+}
+
+// SentinelMonitor makes the sentinel monitor the master under the given name. A previous monitoring of the
+// same name is removed first, the name not being monitored yet is not an error.
+func (a *Admin) SentinelMonitor(addr, name, masterIP, masterPort string, quorum int32) error {
+	c, err := a.Connections().Get(addr)
+	if err != nil {
+		return err
+	}
+
+	// the name may not be monitored yet
+	if resp := c.Cmd("SENTINEL", "REMOVE", name); resp.Err != nil && !strings.Contains(resp.Err.Error(), "No such master") {
+		return a.Connections().ValidateResp(resp, addr, "Unable to run command SENTINEL REMOVE")
+	}
+	resp := c.Cmd("SENTINEL", "MONITOR", name, masterIP, masterPort, quorum)
+	return a.Connections().ValidateResp(resp, addr, "Unable to run command SENTINEL MONITOR")
+}
@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ServerInfo represents the data returned by the INFO redis command used to describe a node
+type ServerInfo struct {
+	RedisVersion     string
+	UsedMemory       int64
+	MaxMemory        int64
+	ConnectedClients int64
+	// Keys is the number of keys stored in all the databases of the node
+	Keys        int64
+	Replication ReplicationInfo
+}
+
+// DecodeServerInfo decode from the INFO cmd output the version, memory, clients, keyspace and replication info of the node
+func DecodeServerInfo(input *string) (*ServerInfo, error) {
+	info := &ServerInfo{}
+	replication, err := DecodeReplicationInfo(input)
+	if replication != nil {
+		info.Replication = *replication
+	}
+	if err != nil {
+		return info, err
+	}
+	lines := strings.Split(*input, "\n")
+	for _, line := range lines {
+		values := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(values) != 2 {
+			continue
+		}
+		switch {
+		case values[0] == "redis_version":
+			info.RedisVersion = values[1]
+		case values[0] == "used_memory":
+			info.UsedMemory, err = strconv.ParseInt(values[1], 10, 64)
+		case values[0] == "maxmemory":
+			info.MaxMemory, err = strconv.ParseInt(values[1], 10, 64)
+		case values[0] == "connected_clients":
+			info.ConnectedClients, err = strconv.ParseInt(values[1], 10, 64)
+		case strings.HasPrefix(values[0], "db"):
+			var keys int64
+			keys, err = decodeKeyspaceKeys(values[1])
+			info.Keys += keys
+		}
+		if err != nil {
+			return info, fmt.Errorf("Error while decoding server info '%s': %v", line, err)
+		}
+	}
+	return info, nil
+}
+
+// decodeKeyspaceKeys returns the number of keys of a keyspace line, for instance "keys=12,expires=0,avg_ttl=0"
+func decodeKeyspaceKeys(value string) (int64, error) {
+	for _, field := range strings.Split(value, ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) == 2 && kv[0] == "keys" {
+			return strconv.ParseInt(kv[1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("no keys field")
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeServerInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *ServerInfo
+		wantErr bool
+	}{
+		{
+			name:    "empty input",
+			input:   "",
+			want:    &ServerInfo{},
+			wantErr: true,
+		},
+		{
+			name:  "master",
+			input: "# Server\r\nredis_version:4.0.9\r\nredis_mode:cluster\r\n\r\n# Clients\r\nconnected_clients:7\r\n\r\n# Memory\r\nused_memory:2097152\r\nmaxmemory:104857600\r\n\r\n# Replication\r\nrole:master\r\nconnected_slaves:1\r\nmaster_repl_offset:1234\r\n\r\n# Keyspace\r\ndb0:keys=12,expires=0,avg_ttl=0\r\ndb1:keys=3,expires=1,avg_ttl=10\r\n",
+			want: &ServerInfo{
+				RedisVersion:     "4.0.9",
+				UsedMemory:       2097152,
+				MaxMemory:        104857600,
+				ConnectedClients: 7,
+				Keys:             15,
+				Replication:      ReplicationInfo{Role: "master", ConnectedSlaves: 1, MasterReplOffset: 1234},
+			},
+		},
+		{
+			name:  "slave without keys",
+			input: "redis_version:4.0.9\r\nconnected_clients:1\r\nused_memory:1024\r\nmaxmemory:0\r\nrole:slave\r\nmaster_link_status:down\r\nslave_repl_offset:1200\r\n# Keyspace\r\n",
+			want: &ServerInfo{
+				RedisVersion:     "4.0.9",
+				UsedMemory:       1024,
+				ConnectedClients: 1,
+				Replication:      ReplicationInfo{Role: "slave", MasterLinkStatus: "down", SlaveReplOffset: 1200},
+			},
+		},
+		{
+			name:    "bad keyspace",
+			input:   "role:master\r\ndb0:expires=0\r\n",
+			want:    &ServerInfo{Replication: ReplicationInfo{Role: "master"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeServerInfo(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeServerInfo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeServerInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
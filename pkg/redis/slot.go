@@ -191,3 +191,29 @@ func BuildSlotSlice(min, max Slot) []Slot {
 	}
 	return slots
 }
+
+// KeySlot returns the slot of a key: the CRC16 of the key, or of its hash tag if any, modulo the number of slots
+func KeySlot(key string) Slot {
+	if start := strings.Index(key, "{"); start >= 0 {
+		if end := strings.Index(key[start+1:], "}"); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return Slot(crc16(key) % (HashMaxSlots + 1))
+}
+
+// crc16 implements the CRC16-CCITT (XMODEM) checksum used by redis cluster
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
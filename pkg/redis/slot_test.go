@@ -159,3 +159,23 @@ func TestAddSlots(t *testing.T) {
 		}
 	}
 }
+
+func TestKeySlot(t *testing.T) {
+	testTable := []struct {
+		key      string
+		expected Slot
+	}{
+		{"123456789", 12739},
+		{"foo", 12182},
+		{"bar", 5061},
+		{"{user1000}.following", KeySlot("user1000")},
+		{"foo{}{bar}", Slot(crc16("foo{}{bar}") % (HashMaxSlots + 1))},
+		{"foo{{bar}}zap", KeySlot("{bar")},
+	}
+
+	for i, tt := range testTable {
+		if slot := KeySlot(tt.key); slot != tt.expected {
+			t.Errorf("[case %d]expected slot of %q to be %d, got %d", i, tt.key, tt.expected, slot)
+		}
+	}
+}
@@ -12,19 +12,22 @@ const (
 	RedisStartWaitDefault = 10 * time.Second
 	// RedisStartDelayDefault default start delay duration (sec)
 	RedisStartDelayDefault = 10 * time.Second
+	// RedisStopTimeoutDefault default maximum duration of the failover and the forget of the node when it stops
+	RedisStopTimeoutDefault = 25 * time.Second
 	// HTTPServerAddrDefault default http server address
 	HTTPServerAddrDefault = "0.0.0.0:8080"
 )
 
 // Config contains configuration for redis-operator
 type Config struct {
-	KubeConfigFile  string
-	Master          string
-	Redis           config.Redis
-	Cluster         config.Cluster
-	RedisStartWait  time.Duration
-	RedisStartDelay time.Duration
-	HTTPServerAddr  string
+	KubeConfigFile   string
+	Master           string
+	Redis            config.Redis
+	Cluster          config.Cluster
+	RedisStartWait   time.Duration
+	RedisStartDelay  time.Duration
+	RedisStopTimeout time.Duration
+	HTTPServerAddr   string
 }
 
 // NewRedisNodeConfig builds and returns a redis-operator Config
@@ -39,6 +42,7 @@ func (c *Config) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.Master, "master", c.Master, "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	fs.DurationVar(&c.RedisStartWait, "t", RedisStartWaitDefault, "Max time waiting for redis to start")
 	fs.DurationVar(&c.RedisStartDelay, "d", RedisStartDelayDefault, "delay before that the redis-server is started")
+	fs.DurationVar(&c.RedisStopTimeout, "stop-timeout", RedisStopTimeoutDefault, "max time given to the failover and the forget of the node when it stops")
 	fs.StringVar(&c.HTTPServerAddr, "http-addr", HTTPServerAddrDefault, "the http server listen address")
 
 	c.Redis.AddFlags(fs)
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strconv"
 
+	radix "github.com/mediocregopher/radix.v2/redis"
+
 	"github.com/zh168654/Redis-Operator/pkg/config"
 	"github.com/zh168654/Redis-Operator/pkg/redis"
 	"github.com/golang/glog"
@@ -55,8 +57,10 @@ func (n *Node) UpdateNodeConfigFile() error {
 		return err
 	}
 
-	if err := n.addSettingInConfigFile("cluster-enabled yes"); err != nil {
-		return err
+	if n.isClusterEnabled() {
+		if err := n.addSettingInConfigFile("cluster-enabled yes"); err != nil {
+			return err
+		}
 	}
 
 	if n.config.Redis.MaxMemory > 0 {
@@ -74,16 +78,20 @@ func (n *Node) UpdateNodeConfigFile() error {
 		return err
 	}
 
-	if err := n.addSettingInConfigFile("cluster-config-file /redis-data/node.conf"); err != nil {
-		return err
+	if n.isClusterEnabled() {
+		if err := n.addSettingInConfigFile("cluster-config-file /redis-data/node.conf"); err != nil {
+			return err
+		}
 	}
 
 	if err := n.addSettingInConfigFile("dir /redis-data"); err != nil {
 		return err
 	}
 
-	if err := n.addSettingInConfigFile("cluster-node-timeout " + strconv.Itoa(n.config.Redis.ClusterNodeTimeout)); err != nil {
-		return err
+	if n.isClusterEnabled() {
+		if err := n.addSettingInConfigFile("cluster-node-timeout " + strconv.Itoa(n.config.Redis.ClusterNodeTimeout)); err != nil {
+			return err
+		}
 	}
 	if n.config.Redis.GetRenameCommandsFile() != "" {
 
@@ -102,6 +110,47 @@ func (n *Node) UpdateNodeConfigFile() error {
 	return nil
 }
 
+// EnableClusterConfig adds the cluster settings at the end of the redis config file of a standalone redis node of a
+// standby cluster, they are applied by the next start of the redis server
+func (n *Node) EnableClusterConfig() error {
+	settings := []string{
+		"cluster-enabled yes",
+		"cluster-config-file /redis-data/node.conf",
+		"cluster-node-timeout " + strconv.Itoa(n.config.Redis.ClusterNodeTimeout),
+	}
+	for _, setting := range settings {
+		if err := n.addSettingInConfigFile(setting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isClusterEnabled returns false if the redis node runs standalone: in Sentinel mode, or in Standby mode until the
+// standby cluster is promoted
+func (n *Node) isClusterEnabled() bool {
+	return !n.config.Cluster.IsSentinelMode() && !n.config.Cluster.IsStandbyMode()
+}
+
+// Shutdown stops the redis server, its keys are saved first if save is true
+func (n *Node) Shutdown(save bool) error {
+	mode := "NOSAVE"
+	if save {
+		mode = "SAVE"
+	}
+	c, err := n.RedisAdmin.Connections().Get(n.Addr)
+	if err != nil {
+		return err
+	}
+	defer n.RedisAdmin.Connections().Remove(n.Addr)
+	resp := c.Cmd("SHUTDOWN", mode)
+	if resp.IsType(radix.AppErr) {
+		// the server answers only if it can't stop, otherwise it closes the connection
+		return resp.Err
+	}
+	return nil
+}
+
 // addSettingInConfigFile add a line in the redis configuration file
 func (n *Node) addSettingInConfigFile(line string) error {
 	f, err := os.OpenFile(n.config.Redis.ConfigFileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
@@ -145,7 +194,7 @@ func (n *Node) ForgetNode() error {
 func (n *Node) StartFailover() error {
 	glog.Info("StartFailover... starting")
 
-	return n.RedisAdmin.StartFailover(n.Addr)
+	return n.RedisAdmin.StartFailover(n.Addr, nil)
 }
 
 // ClearDataFolder completely erase all files in the /data folder
@@ -57,6 +57,94 @@ include bar.cfg
 	}
 }
 
+func TestUpdateNodeConfigFileSentinelMode(t *testing.T) {
+	temp, _ := ioutil.TempDir("", "test")
+	configfile, createerr := os.Create(filepath.Join(temp, "redisconfig.conf"))
+	if createerr != nil {
+		t.Errorf("Couldn' t create temporary config file: %v", createerr)
+	}
+	defer os.RemoveAll(temp)
+	configfile.Close()
+
+	a := admin.NewFakeAdmin([]string{})
+	c := Config{
+		Redis: config.Redis{
+			ServerPort:         "1234",
+			MaxMemoryPolicy:    config.RedisMaxMemoryPolicyDefault,
+			ClusterNodeTimeout: 321,
+			ConfigFileName:     configfile.Name(),
+		},
+		Cluster: config.Cluster{Mode: config.SentinelMode},
+	}
+
+	node := NewNode(&c, a)
+	defer node.Clear()
+	if err := node.UpdateNodeConfigFile(); err != nil {
+		t.Errorf("Unexpected error while updating config file: %v", err)
+	}
+
+	// no cluster setting in sentinel mode
+	content, _ := ioutil.ReadFile(configfile.Name())
+	var expected = `include /redis-conf/redis.conf
+port 1234
+bind 0.0.0.0
+dir /redis-data
+`
+	if expected != string(content) {
+		t.Errorf("Wrong file content, expected '%s', got '%s'", expected, string(content))
+	}
+}
+
+func TestUpdateNodeConfigFileStandbyMode(t *testing.T) {
+	temp, _ := ioutil.TempDir("", "test")
+	configfile, createerr := os.Create(filepath.Join(temp, "redisconfig.conf"))
+	if createerr != nil {
+		t.Errorf("Couldn' t create temporary config file: %v", createerr)
+	}
+	defer os.RemoveAll(temp)
+	configfile.Close()
+
+	a := admin.NewFakeAdmin([]string{})
+	c := Config{
+		Redis: config.Redis{
+			ServerPort:         "1234",
+			MaxMemoryPolicy:    config.RedisMaxMemoryPolicyDefault,
+			ClusterNodeTimeout: 321,
+			ConfigFileName:     configfile.Name(),
+		},
+		Cluster: config.Cluster{Mode: config.StandbyMode},
+	}
+
+	node := NewNode(&c, a)
+	defer node.Clear()
+	if err := node.UpdateNodeConfigFile(); err != nil {
+		t.Errorf("Unexpected error while updating config file: %v", err)
+	}
+
+	// no cluster setting until the standby cluster is promoted
+	content, _ := ioutil.ReadFile(configfile.Name())
+	var expected = `include /redis-conf/redis.conf
+port 1234
+bind 0.0.0.0
+dir /redis-data
+`
+	if expected != string(content) {
+		t.Errorf("Wrong file content, expected '%s', got '%s'", expected, string(content))
+	}
+
+	if err := node.EnableClusterConfig(); err != nil {
+		t.Errorf("Unexpected error while enabling the cluster mode: %v", err)
+	}
+	content, _ = ioutil.ReadFile(configfile.Name())
+	expected += `cluster-enabled yes
+cluster-config-file /redis-data/node.conf
+cluster-node-timeout 321
+`
+	if expected != string(content) {
+		t.Errorf("Wrong file content, expected '%s', got '%s'", expected, string(content))
+	}
+}
+
 func TestAdminCommands(t *testing.T) {
 	a := admin.NewFakeAdmin([]string{})
 	c := Config{
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
@@ -32,6 +33,18 @@ import (
 	"strconv"
 )
 
+const (
+	// standbyPromotionInterval interval between two checks of the role annotation of the pod of a standby cluster
+	standbyPromotionInterval = 5 * time.Second
+)
+
+// standby states of the redis node, only in Standby mode
+const (
+	notStandby int32 = iota
+	standbyRunning
+	standbyRestarting
+)
+
 // RedisNode constains all info to run the redis-node.
 type RedisNode struct {
 	config     *Config
@@ -39,6 +52,10 @@ type RedisNode struct {
 	redisAdmin redis.AdminInterface
 	admOptions redis.AdminOptions
 
+	// standbyState is notStandby, standbyRunning while the redis server runs standalone in a standby cluster, and
+	// standbyRestarting while it restarts in cluster mode once the standby cluster is promoted
+	standbyState int32
+
 	// Kubernetes Probes handler
 	health healthcheck.Handler
 
@@ -74,7 +91,7 @@ func (r *RedisNode) Run(stop <-chan struct{}) error {
 
 	go r.runHTTPServer(stop)
 
-	node, err = r.run(node)
+	node, err = r.run(node, stop)
 	if err != nil {
 		return err
 	}
@@ -101,6 +118,16 @@ func (r *RedisNode) init() (*Node, error) {
 	// 2*nodetimeout for failed state detection, 2*nodetimeout for voting, 2*nodetimeout for safety
 	time.Sleep(r.config.RedisStartDelay)
 
+	if r.config.Cluster.IsStandbyMode() {
+		if role := r.getStandbyRole(); role != "" {
+			// the standby cluster has been promoted before the restart of the container
+			glog.Infof("Standby cluster already promoted, starting in cluster mode as %s", role)
+			r.config.Cluster.Mode = config.ClusterModeDefault
+		} else {
+			r.standbyState = standbyRunning
+		}
+	}
+
 	nodesAddr, err := getRedisNodesAddrs(r.kubeClient, r.config.Cluster)
 	if err != nil {
 		glog.Warning(err)
@@ -119,6 +146,9 @@ func (r *RedisNode) init() (*Node, error) {
 	r.admOptions = redis.AdminOptions{
 		ConnectionTimeout:  time.Duration(r.config.Redis.DialTimeout) * time.Millisecond,
 		RenameCommandsFile: r.config.Redis.GetRenameCommandsFile(),
+		RetryAttempts:      r.config.Redis.RetryAttempts,
+		BreakerThreshold:   r.config.Redis.CircuitBreakerThreshold,
+		BreakerCooldown:    time.Duration(r.config.Redis.CircuitBreakerCooldown) * time.Millisecond,
 	}
 	host, err := os.Hostname()
 	if err != nil {
@@ -150,9 +180,9 @@ func (r *RedisNode) init() (*Node, error) {
 	return me, nil
 }
 
-func (r *RedisNode) run(me *Node) (*Node, error) {
-	// Start redis server and wait for it to be accessible
-	chRedis := make(chan error)
+func (r *RedisNode) run(me *Node, stop <-chan struct{}) (*Node, error) {
+	// Start redis server and wait for it to be accessible, WrapRedis reports the error and the stop of the server
+	chRedis := make(chan error, 2)
 	go WrapRedis(r.config, chRedis)
 	starterr := testAndWaitConnection(me.Addr, r.config.RedisStartWait)
 	if starterr != nil {
@@ -160,6 +190,19 @@ func (r *RedisNode) run(me *Node) (*Node, error) {
 		return nil, starterr
 	}
 
+	if r.config.Cluster.IsSentinelMode() {
+		// the replication is configured by the operator and the failover handled by the sentinels
+		glog.Infof("RedisNode: Runnning properly in sentinel mode")
+		return me, nil
+	}
+
+	if r.config.Cluster.IsStandbyMode() && r.standbyState == standbyRunning {
+		// the replication is configured by the operator, the cluster is formed by the operator once promoted
+		go r.waitStandbyPromotion(me, chRedis, stop)
+		glog.Infof("RedisNode: Runnning properly in standby mode")
+		return me, nil
+	}
+
 	configFunc := func() (bool, error) {
 		// Initial redis server configuration
 		nodes, initCluster := r.isClusterInitialization(me.Addr)
@@ -188,6 +231,64 @@ func (r *RedisNode) run(me *Node) (*Node, error) {
 	return me, nil
 }
 
+// getStandbyRole returns the role of the redis node in the promoted standby cluster, set by the operator in the pod
+// annotations, empty if the standby cluster is not promoted
+func (r *RedisNode) getStandbyRole() string {
+	name, err := os.Hostname()
+	if err != nil {
+		glog.Errorf("unable to get the pod name, err:%v", err)
+		return ""
+	}
+	pod, err := r.kubeClient.CoreV1().Pods(r.config.Cluster.Namespace).Get(name, meta_v1.GetOptions{})
+	if err != nil {
+		glog.Errorf("unable to get pod %s/%s, err:%v", r.config.Cluster.Namespace, name, err)
+		return ""
+	}
+	return pod.Annotations[v1.StandbyRoleAnnotationKey]
+}
+
+// waitStandbyPromotion restarts the standalone redis server in cluster mode once the pod is annotated with its role
+// by the promotion of the standby cluster. A standby master saves its keys before it stops, the restarted server
+// loads them and claims the slots of its keys. A standby replica restarts empty.
+func (r *RedisNode) waitStandbyPromotion(me *Node, chRedis chan error, stop <-chan struct{}) {
+	role := ""
+	wait.PollUntil(standbyPromotionInterval, func() (bool, error) {
+		role = r.getStandbyRole()
+		return role != "", nil
+	}, stop)
+	if role == "" {
+		return
+	}
+
+	glog.Infof("Standby cluster promoted, restarting the redis server in cluster mode as %s", role)
+	atomic.StoreInt32(&r.standbyState, standbyRestarting)
+	me.RedisAdmin = redis.NewAdmin([]string{me.Addr}, &r.admOptions)
+	defer me.Clear()
+	wait.PollUntil(time.Second, func() (bool, error) {
+		if err := me.Shutdown(role == v1.StandbyRoleMaster); err != nil {
+			glog.Errorf("unable to stop the redis server, err:%v", err)
+			return false, nil
+		}
+		return true, nil
+	}, stop)
+	if err := <-chRedis; err != nil {
+		glog.Warningf("redis server stopped with error: %v", err)
+	}
+
+	if role != v1.StandbyRoleMaster {
+		me.ClearDataFolder()
+	}
+	if err := me.EnableClusterConfig(); err != nil {
+		glog.Fatal("Unable to update the configuration file, err:", err)
+	}
+	go WrapRedis(r.config, chRedis)
+	if err := testAndWaitConnection(me.Addr, r.config.RedisStartWait); err != nil {
+		glog.Error("Error while waiting for redis to restart in cluster mode: ", err)
+	}
+	atomic.StoreInt32(&r.standbyState, notStandby)
+	glog.Infof("RedisNode: Runnning properly in cluster mode")
+}
+
 func (r *RedisNode) isClusterInitialization(currentIP string) ([]string, bool) {
 	var initCluster = true
 	nodesAddr, _ := getRedisNodesAddrs(r.kubeClient, r.config.Cluster)
@@ -211,13 +312,26 @@ func (r *RedisNode) isClusterInitialization(currentIP string) ([]string, bool) {
 }
 
 func (r *RedisNode) handleStop(me *Node) error {
+	if r.config.Cluster.IsSentinelMode() {
+		// the sentinels promote a replica if this node was the master
+		return nil
+	}
+	if atomic.LoadInt32(&r.standbyState) != notStandby {
+		// not a cluster node yet, the operator assigns the shards of the standby cluster
+		return nil
+	}
+
 	nodesAddr, err := getRedisNodesAddrs(r.kubeClient, r.config.Cluster)
 	if err != nil {
 		glog.Error("Unable to retrieve Redis Node, err:", err)
 		return err
 	}
 
+	// the failover and the forget must not block the stop of the pod
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.RedisStopTimeout)
+	defer cancel()
 	r.redisAdmin.Connections().ReplaceAll(nodesAddr)
+	me.RedisAdmin = r.redisAdmin.WithContext(ctx)
 	if err = me.StartFailover(); err != nil {
 		glog.Errorf("Failover node:%s  error:%s", me.Addr, err)
 	}
@@ -232,8 +346,21 @@ func (r *RedisNode) handleStop(me *Node) error {
 func (r *RedisNode) configureHealth() error {
 	addr := net.JoinHostPort("127.0.0.1", r.config.Redis.ServerPort)
 	health := healthcheck.NewHandler()
+	readiness := readinessCheck
+	if r.config.Cluster.IsSentinelMode() {
+		// no cluster slots in sentinel mode, the node is ready as soon as it answers
+		readiness = livenessCheck
+	}
 	health.AddReadinessCheck("Check redis-node readiness", func() error {
-		if err := readinessCheck(addr); err != nil {
+		check := readiness
+		switch atomic.LoadInt32(&r.standbyState) {
+		case standbyRunning:
+			// no cluster slots while the standby cluster is not promoted
+			check = livenessCheck
+		case standbyRestarting:
+			return fmt.Errorf("Readiness failed, the redis server restarts in cluster mode")
+		}
+		if err := check(addr); err != nil {
 			glog.Errorf("readiness check failed, err:%v", err)
 			return err
 		}
@@ -241,6 +368,10 @@ func (r *RedisNode) configureHealth() error {
 	})
 
 	health.AddLivenessCheck("Check redis-node liveness", func() error {
+		if atomic.LoadInt32(&r.standbyState) == standbyRestarting {
+			// a restart of the container would clear the keys of the standby master
+			return nil
+		}
 		if err := livenessCheck(addr); err != nil {
 			glog.Errorf("liveness check failed, err:%v", err)
 			return err
@@ -11,6 +11,7 @@ import (
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kfakeclient "k8s.io/client-go/kubernetes/fake"
 
+	"github.com/zh168654/Redis-Operator/pkg/api/redis/v1"
 	"github.com/zh168654/Redis-Operator/pkg/config"
 	"github.com/zh168654/Redis-Operator/pkg/redis/fake"
 	"github.com/zh168654/Redis-Operator/pkg/redis/fake/admin"
@@ -123,3 +124,24 @@ func TestRedisInitializationAttach(t *testing.T) {
 		t.Error("node should not be nil")
 	}
 }
+
+func TestGetStandbyRole(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("unable to get the hostname: %v", err)
+	}
+	conf := Config{Cluster: config.Cluster{Namespace: "default", Mode: config.StandbyMode}}
+	pod := &kapi.Pod{ObjectMeta: kmetav1.ObjectMeta{Name: hostname, Namespace: "default"}}
+
+	node := &RedisNode{config: &conf, kubeClient: kfakeclient.NewSimpleClientset(pod)}
+	if role := node.getStandbyRole(); role != "" {
+		t.Errorf("getStandbyRole() = %q, want empty before the promotion", role)
+	}
+
+	promoted := pod.DeepCopy()
+	promoted.Annotations = map[string]string{v1.StandbyRoleAnnotationKey: v1.StandbyRoleMaster}
+	node = &RedisNode{config: &conf, kubeClient: kfakeclient.NewSimpleClientset(promoted)}
+	if role := node.getStandbyRole(); role != v1.StandbyRoleMaster {
+		t.Errorf("getStandbyRole() = %q, want %q", role, v1.StandbyRoleMaster)
+	}
+}